@@ -0,0 +1,263 @@
+package app
+
+import (
+	"10-typing/common"
+	"10-typing/controllers"
+	"10-typing/errors"
+	"10-typing/middlewares"
+	"10-typing/models"
+	"10-typing/services"
+	"10-typing/tracing"
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// Repositories are the dependencies of the services that connect to other systems
+type Repositories struct {
+	DB               common.DBRepository
+	Cache            common.CacheRepository
+	EmailTransaction common.EmailTransactionRepository
+	OpenAi           common.OpenAiRepository
+}
+
+// the rate limits of the routes that are expensive or can be abused, e.g. the generation of texts with the OpenAI API costs money
+var (
+	loginRateLimit        = models.RateLimit{Limit: 10, Per: time.Minute}
+	createUserRateLimit   = models.RateLimit{Limit: 5, Per: time.Hour}
+	createTextQuota       = models.RateLimit{Limit: 20, Per: 24 * time.Hour}
+	createCustomTextQuota = models.RateLimit{Limit: 30, Per: 24 * time.Hour}
+	adaptiveTextRateLimit = models.RateLimit{Limit: 30, Per: time.Hour}
+	createRoomRateLimit   = models.RateLimit{Limit: 10, Per: time.Minute}
+)
+
+// Config configures the API for the environment in which it runs
+type Config struct {
+	// AllowedOrigins are the origins of the frontend, e.g. http://localhost:3000, which may send requests with the session cookie and connect to the WebSockets of the rooms
+	AllowedOrigins []string
+	Cookie         models.CookieConfig
+}
+
+// App is the API with the services whose state must be shut down gracefully
+type App struct {
+	Router                  *gin.Engine
+	healthService           *services.HealthService
+	roomService             *services.RoomService
+	gameService             *services.GameService
+	textService             *services.TextService
+	userNotificationService *services.UserNotificationService
+	logger                  common.Logger
+}
+
+// New sets up the services and controllers with the repositories and the router that serves the API.
+// The clock is used by the services whose behavior depends on the elapsed time, e.g. the countdown of a game or the long polling of notifications.
+func New(repos Repositories, config Config, clock common.Clock, logger common.Logger) *App {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterValidation("typingerrors", models.TypingErrors)
+		v.RegisterValidation("keyboardlayout", models.KeyboardLayout)
+	}
+
+	// Setup services
+	ratingService := services.NewRatingService(repos.DB, repos.Cache, logger)
+	leaderboardService := services.NewLeaderboardService(repos.DB, repos.Cache, logger)
+	gameService := services.NewGameService(repos.DB, repos.Cache, ratingService, leaderboardService, clock, logger)
+	roomService := services.NewRoomService(repos.DB, repos.Cache, repos.EmailTransaction, clock, originHosts(config.AllowedOrigins), logger)
+	scoreService := services.NewScoreService(repos.DB, repos.Cache, leaderboardService, logger)
+	statsService := services.NewStatsService(repos.DB, repos.Cache, logger)
+	textService := services.NewTextService(repos.DB, repos.Cache, repos.OpenAi, statsService, logger)
+	userService := services.NewUserService(repos.DB, repos.Cache, logger, 32)
+	userNoticationService := services.NewUserNotificationService(repos.Cache, clock, logger)
+	matchmakingService := services.NewMatchmakingService(repos.DB, repos.Cache, roomService, gameService, textService, clock, logger)
+	healthService := services.NewHealthService(repos.DB, repos.Cache, logger)
+	systemStatsService := services.NewSystemStatsService(repos.DB, roomService, gameService, logger)
+
+	// Setup controllers
+	gameController := controllers.NewGameController(gameService, logger)
+	roomController := controllers.NewRoomController(roomService, logger)
+	scoreController := controllers.NewScoreController(scoreService, textService, logger)
+	textController := controllers.NewTextController(textService, logger)
+	userController := controllers.NewUserController(userService, config.Cookie, logger)
+	userNoticationController := controllers.NewUserNotificationController(userNoticationService, logger)
+	matchmakingController := controllers.NewMatchmakingController(matchmakingService, logger)
+	ratingController := controllers.NewRatingController(ratingService, logger)
+	leaderboardController := controllers.NewLeaderboardController(leaderboardService, logger)
+	statsController := controllers.NewStatsController(statsService, logger)
+	logLevelController := controllers.NewLogLevelController(logger)
+	healthController := controllers.NewHealthController(healthService, logger)
+	systemStatsController := controllers.NewSystemStatsController(systemStatsService, logger)
+
+	cors := cors.New(cors.Config{
+		AllowOrigins:     config.AllowedOrigins,
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+		AllowCredentials: true,
+		MaxAge:           12 * time.Hour,
+	})
+
+	router := gin.New()
+	router.Use(middlewares.RequestId(), otelgin.Middleware(tracing.ServiceName), middlewares.TraceIdLogField(), middlewares.GinZerologLogger(logger), gin.Recovery(), cors, middlewares.CSRF(config.AllowedOrigins, logger))
+	router.GET("/healthz", healthController.Healthz)
+	router.GET("/readyz", healthController.Readyz)
+	api := router.Group("/api")
+
+	authRequiredMiddleware := middlewares.AuthRequired(repos.Cache, repos.DB, logger)
+	isRoomMemberMiddleware := middlewares.IsRoomMember(repos.Cache, logger)
+	isRoomAdminMiddleware := middlewares.IsRoomAdmin(repos.Cache, logger)
+	isCurrentGameUserMiddleware := middlewares.IsCurrentGameUser(repos.Cache, logger)
+	userIdUrlParamMatchesAuthorizedUserMiddleware := middlewares.UserIdUrlParamMatchesAuthorizedUser(logger)
+	isModeratorMiddleware := middlewares.Authorize(middlewares.HasRole(models.ModeratorUserRole), logger)
+	isAdminMiddleware := middlewares.Authorize(middlewares.HasRole(models.AdminUserRole), logger)
+	loginRateLimitMiddleware := middlewares.RateLimit(repos.Cache, "login", loginRateLimit, middlewares.ByClientIp, logger)
+	createUserRateLimitMiddleware := middlewares.RateLimit(repos.Cache, "create_user", createUserRateLimit, middlewares.ByClientIp, logger)
+	createTextQuotaMiddleware := middlewares.RateLimit(repos.Cache, "create_text", createTextQuota, middlewares.ByUser, logger)
+	createCustomTextQuotaMiddleware := middlewares.RateLimit(repos.Cache, "create_custom_text", createCustomTextQuota, middlewares.ByUser, logger)
+	adaptiveTextRateLimitMiddleware := middlewares.RateLimit(repos.Cache, "adaptive_text", adaptiveTextRateLimit, middlewares.ByUser, logger)
+	createRoomRateLimitMiddleware := middlewares.RateLimit(repos.Cache, "create_room", createRoomRateLimit, middlewares.ByUser, logger)
+
+	// USERS
+	api.GET("/users", authRequiredMiddleware, userController.FindUsers)
+	api.GET("/users/:userid", authRequiredMiddleware, userController.FindUser)
+	api.GET("/users/:userid/scores", authRequiredMiddleware, scoreController.FindScoresByUser)
+	api.POST("/users/:userid/scores", authRequiredMiddleware, userIdUrlParamMatchesAuthorizedUserMiddleware, scoreController.CreateScore)
+	api.PUT("/users/:userid/keyboard-layout", authRequiredMiddleware, userIdUrlParamMatchesAuthorizedUserMiddleware, userController.UpdateUserKeyboardLayout)
+	api.GET("/users/:userid/ratings", authRequiredMiddleware, ratingController.FindRatingHistory)
+	api.GET("/users/:userid/stats", authRequiredMiddleware, statsController.FindUserStats)
+	api.GET("/users/:userid/stats/keyboard", authRequiredMiddleware, statsController.FindUserKeyboardStats)
+	// why use the userId here -> without a user id the middleware function UserIdUrlParamMatchesAuthorizedUser would be unnecessary
+	api.GET("/users/:userid/text", authRequiredMiddleware, userIdUrlParamMatchesAuthorizedUserMiddleware, textController.FindNewTextForUser)
+	api.GET("/users/:userid/text/adaptive", authRequiredMiddleware, userIdUrlParamMatchesAuthorizedUserMiddleware, adaptiveTextRateLimitMiddleware, textController.FindAdaptiveTextForUser)
+	api.GET("/users/:userid/texts", authRequiredMiddleware, userIdUrlParamMatchesAuthorizedUserMiddleware, textController.FindCustomTextsOfUser)
+	api.POST("/users", createUserRateLimitMiddleware, userController.CreateUser)
+
+	// USER
+	api.GET("/user", authRequiredMiddleware, userController.CurrentUser)
+	api.POST("/user/login", loginRateLimitMiddleware, userController.Login)
+	api.POST("/user/logout", authRequiredMiddleware, userController.Logout)
+
+	// NOTIFICATIONS
+	api.GET("/notification/realtime", authRequiredMiddleware, userNoticationController.FindRealtimeUserNotification)
+
+	// SCORES
+	api.GET("/scores", authRequiredMiddleware, scoreController.FindScores)
+
+	// LEADERBOARDS
+	api.GET("/leaderboards", authRequiredMiddleware, leaderboardController.FindLeaderboard)
+
+	// RATINGS
+	api.GET("/ratings", authRequiredMiddleware, ratingController.FindRatingLeaderboard)
+
+	// TEXTS
+	api.POST("/texts", authRequiredMiddleware, createTextQuotaMiddleware, textController.CreateText)
+	api.POST("/texts/custom", authRequiredMiddleware, createCustomTextQuotaMiddleware, textController.CreateCustomText)
+	api.GET("/texts/:textid", authRequiredMiddleware, textController.FindTextById)
+
+	// ADMIN
+	// moderators moderate the content and the rooms, admins additionally manage the users and the server
+	api.GET("/admin/texts/moderation", authRequiredMiddleware, isModeratorMiddleware, textController.FindModerationQueue)
+	api.PUT("/admin/texts/:textid/moderation", authRequiredMiddleware, isModeratorMiddleware, textController.ModerateText)
+	api.DELETE("/admin/texts/:textid", authRequiredMiddleware, isModeratorMiddleware, textController.DeleteText)
+	api.GET("/admin/rooms/:roomid", authRequiredMiddleware, isModeratorMiddleware, roomController.InspectRoom)
+	api.POST("/admin/rooms/:roomid/terminate", authRequiredMiddleware, isModeratorMiddleware, roomController.TerminateRoom)
+	api.GET("/admin/users", authRequiredMiddleware, isAdminMiddleware, userController.FindAllUsers)
+	api.PUT("/admin/users/:userid/role", authRequiredMiddleware, isAdminMiddleware, userController.UpdateUserRole)
+	api.PUT("/admin/users/:userid/ban", authRequiredMiddleware, isAdminMiddleware, userController.UpdateUserBanned)
+	api.GET("/admin/stats", authRequiredMiddleware, isAdminMiddleware, systemStatsController.FindSystemStats)
+	api.GET("/admin/log-level", authRequiredMiddleware, isAdminMiddleware, logLevelController.FindLogLevel)
+	api.PUT("/admin/log-level", authRequiredMiddleware, isAdminMiddleware, logLevelController.UpdateLogLevel)
+
+	// ROOMS
+	api.GET("/rooms/:roomid/ws", authRequiredMiddleware, isRoomMemberMiddleware, roomController.ConnectToRoom)
+	api.GET("/rooms", authRequiredMiddleware, roomController.FindRooms)
+	api.GET("/rooms/:roomid", authRequiredMiddleware, isRoomMemberMiddleware, roomController.FindRoom)
+	// TODO: get new text for room
+	// api.GET("/rooms/:roomid/text", authRequiredMiddleware, isRoomAdminMiddleware)
+	api.POST("/rooms", authRequiredMiddleware, createRoomRateLimitMiddleware, roomController.CreateRoom)
+	api.POST("/rooms/:roomid/leave", authRequiredMiddleware, isRoomMemberMiddleware, roomController.LeaveRoom)
+	api.PATCH("/rooms/:roomid", authRequiredMiddleware, isRoomAdminMiddleware, roomController.UpdateRoom)
+	api.POST("/rooms/:roomid/invitations", authRequiredMiddleware, isRoomAdminMiddleware, roomController.InviteToRoom)
+	api.DELETE("/rooms/:roomid/users/:userid", authRequiredMiddleware, isRoomAdminMiddleware, roomController.RemoveRoomMember)
+	api.PUT("/rooms/:roomid/admin", authRequiredMiddleware, isRoomAdminMiddleware, roomController.TransferRoomAdmin)
+	api.POST("/rooms/:roomid/game", authRequiredMiddleware, isRoomAdminMiddleware, gameController.CreateNewCurrentGame)
+	api.POST("/rooms/:roomid/start-game", authRequiredMiddleware, isRoomMemberMiddleware, gameController.StartGame)
+	api.POST("/rooms/:roomid/current-game/score",
+		authRequiredMiddleware,
+		isRoomMemberMiddleware,
+		isCurrentGameUserMiddleware,
+		gameController.FinishGame,
+	)
+
+	// MATCHMAKING
+	api.POST("/matchmaking/queue", authRequiredMiddleware, matchmakingController.JoinQueue)
+	api.GET("/matchmaking/queue", authRequiredMiddleware, matchmakingController.FindQueueStatus)
+	api.DELETE("/matchmaking/queue", authRequiredMiddleware, matchmakingController.LeaveQueue)
+
+	return &App{router, healthService, roomService, gameService, textService, userNoticationService, logger}
+}
+
+// LoadBigramFrequencies measures the bigram rarity of the texts that are created afterwards against the stored texts of their language
+func (a *App) LoadBigramFrequencies(ctx context.Context) error {
+	const op errors.Op = "app.App.LoadBigramFrequencies"
+
+	if err := a.textService.LoadBigramFrequencies(ctx); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+// SetNotReady marks the API as not ready at the beginning of the shutdown, so that the load balancer stops sending new requests to it
+func (a *App) SetNotReady() {
+	a.healthService.SetShuttingDown()
+}
+
+// CancelLongPolls ends the open long polls of notifications, so that the server doesn't wait for them when it stops accepting requests
+func (a *App) CancelLongPolls() {
+	a.userNotificationService.CancelLongPolls()
+}
+
+// Shutdown shuts down the state of the API after the server has stopped accepting requests.
+// It closes the WebSocket connections, so that the clients reconnect to another server instance, and waits for the running games.
+// The games that are still running handOffTimeout before the deadline of the context are aborted, so that they can be restarted on another server instance.
+func (a *App) Shutdown(ctx context.Context, handOffTimeout time.Duration) error {
+	const op errors.Op = "app.App.Shutdown"
+
+	a.healthService.SetShuttingDown()
+
+	if err := a.roomService.CloseConnections(ctx, "server restarting"); err != nil {
+		return errors.E(op, err)
+	}
+
+	drainCtx := ctx
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancel context.CancelFunc
+		drainCtx, cancel = context.WithDeadline(ctx, deadline.Add(-handOffTimeout))
+		defer cancel()
+	}
+	if err := a.gameService.DrainGames(drainCtx); err == nil {
+		return nil
+	}
+
+	a.logger.Info("aborting the games that are still running")
+	if err := a.gameService.AbortGames(ctx); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+// originHosts returns the hosts of the origins, which are the patterns of the origins that are accepted by websocket.Accept
+func originHosts(origins []string) []string {
+	hosts := make([]string, 0, len(origins))
+	for _, origin := range origins {
+		if u, err := url.Parse(origin); err == nil && u.Host != "" {
+			hosts = append(hosts, u.Host)
+		}
+	}
+
+	return hosts
+}
@@ -0,0 +1,53 @@
+package clock
+
+import (
+	"10-typing/common"
+	"time"
+)
+
+// RealClock is the clock of the system, which delegates to the time package
+type RealClock struct{}
+
+func New() common.Clock {
+	return RealClock{}
+}
+
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+func (RealClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (RealClock) NewTimer(d time.Duration) common.Timer {
+	return &realTimer{time.NewTimer(d)}
+}
+
+func (RealClock) NewTicker(d time.Duration) common.Ticker {
+	return &realTicker{time.NewTicker(d)}
+}
+
+type realTimer struct {
+	timer *time.Timer
+}
+
+func (t *realTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t *realTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+type realTicker struct {
+	ticker *time.Ticker
+}
+
+func (t *realTicker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+func (t *realTicker) Stop() {
+	t.ticker.Stop()
+}
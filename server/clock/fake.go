@@ -0,0 +1,200 @@
+package clock
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"context"
+	"sync"
+	"time"
+)
+
+// FakeClock is a clock whose time only changes when Advance is called.
+// Timers and tickers fire during Advance, so that code which waits for minutes runs in milliseconds.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*waiter
+	// waitersChanged is closed and replaced whenever a waiter is added, which wakes up BlockUntilTimers
+	waitersChanged chan struct{}
+}
+
+// waiter is a pending timer or ticker. A ticker has a period and stays a waiter until it is stopped.
+type waiter struct {
+	until  time.Time
+	period time.Duration
+	c      chan time.Time
+}
+
+func NewFake(now time.Time) *FakeClock {
+	return &FakeClock{
+		now:            now,
+		waitersChanged: make(chan struct{}),
+	}
+}
+
+func (fc *FakeClock) Now() time.Time {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	return fc.now
+}
+
+func (fc *FakeClock) After(d time.Duration) <-chan time.Time {
+	return fc.NewTimer(d).C()
+}
+
+func (fc *FakeClock) NewTimer(d time.Duration) common.Timer {
+	return &fakeTimer{fc, fc.addWaiter(d, 0)}
+}
+
+func (fc *FakeClock) NewTicker(d time.Duration) common.Ticker {
+	if d <= 0 {
+		panic("non-positive interval for FakeClock.NewTicker")
+	}
+
+	return &fakeTicker{fc, fc.addWaiter(d, d)}
+}
+
+// Advance moves the time forward and fires all timers and tickers that are due until the new time, in the order of their due time.
+// Like the tickers of the time package, a ticker drops ticks if its channel has not been read.
+func (fc *FakeClock) Advance(d time.Duration) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	end := fc.now.Add(d)
+	for {
+		next := fc.nextDueWaiter(end)
+		if next == nil {
+			break
+		}
+
+		fc.now = next.until
+		select {
+		case next.c <- fc.now:
+		default:
+		}
+
+		if next.period > 0 {
+			next.until = next.until.Add(next.period)
+		} else {
+			fc.removeWaiter(next)
+		}
+	}
+	fc.now = end
+}
+
+// Waiters returns the number of timers and tickers that have not fired or have not been stopped yet
+func (fc *FakeClock) Waiters() int {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	return len(fc.waiters)
+}
+
+// BlockUntilTimers blocks until at least n timers are due within d, e.g. until a goroutine has started to sleep, so that advancing the time by d afterwards wakes it up.
+// Tickers and timers that are due later are not counted, so the timers of long running connections don't need to be known.
+func (fc *FakeClock) BlockUntilTimers(ctx context.Context, d time.Duration, n int) error {
+	const op errors.Op = "clock.FakeClock.BlockUntilTimers"
+
+	for {
+		fc.mu.Lock()
+		timers := fc.dueTimers(fc.now.Add(d))
+		waitersChanged := fc.waitersChanged
+		fc.mu.Unlock()
+
+		if timers >= n {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.E(op, ctx.Err())
+		case <-waitersChanged:
+		}
+	}
+}
+
+func (fc *FakeClock) addWaiter(d, period time.Duration) *waiter {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	w := &waiter{
+		until:  fc.now.Add(d),
+		period: period,
+		c:      make(chan time.Time, 1),
+	}
+	fc.waiters = append(fc.waiters, w)
+
+	close(fc.waitersChanged)
+	fc.waitersChanged = make(chan struct{})
+
+	return w
+}
+
+// nextDueWaiter returns the waiter that is due first but not after end. The clock must be locked.
+func (fc *FakeClock) nextDueWaiter(end time.Time) *waiter {
+	var next *waiter
+	for _, w := range fc.waiters {
+		if !w.until.After(end) && (next == nil || w.until.Before(next.until)) {
+			next = w
+		}
+	}
+
+	return next
+}
+
+// dueTimers returns the number of timers that are due until end. The clock must be locked.
+func (fc *FakeClock) dueTimers(end time.Time) int {
+	timers := 0
+	for _, w := range fc.waiters {
+		if w.period == 0 && !w.until.After(end) {
+			timers++
+		}
+	}
+
+	return timers
+}
+
+// removeWaiter removes the waiter and reports whether it was still waiting. The clock must be locked.
+func (fc *FakeClock) removeWaiter(w *waiter) bool {
+	for i, other := range fc.waiters {
+		if other == w {
+			fc.waiters = append(fc.waiters[:i], fc.waiters[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+type fakeTimer struct {
+	clock  *FakeClock
+	waiter *waiter
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.waiter.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	return t.clock.removeWaiter(t.waiter)
+}
+
+type fakeTicker struct {
+	clock  *FakeClock
+	waiter *waiter
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.waiter.c
+}
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	t.clock.removeWaiter(t.waiter)
+}
@@ -11,15 +11,21 @@ import (
 type CacheRepository interface {
 	BeginPipeline() Transaction
 	BeginTx() Transaction
+	// Ping checks that the cache can be reached, e.g. for the readiness check
+	Ping(ctx context.Context) error
 	GameCacheRepository
+	LeaderboardCacheRepository
+	MatchmakingCacheRepository
 	RoomCacheRepository
 	RoomStreamCacheRepository
 	RoomSubscriberCacheRepository
 	TextCacheRepository
 	UserNotificationCacheRepository
 	UserCacheRepository
+	UserStatsCacheRepository
 	SessionCacheRepository
 	ScoreCacheRepository
+	RateLimitCacheRepository
 }
 
 type GameCacheRepository interface {
@@ -36,10 +42,26 @@ type GameCacheRepository interface {
 	IsCurrentGameUser(ctx context.Context, roomId, userId uuid.UUID) (bool, error)
 }
 
+type LeaderboardCacheRepository interface {
+	SetLeaderboardScore(ctx context.Context, tx Transaction, filter models.LeaderboardFilter, userId uuid.UUID, wordsPerMinute float64, expireAt time.Time) error
+	GetLeaderboardEntries(ctx context.Context, filter models.LeaderboardFilter, limit, offset int) ([]models.LeaderboardEntry, int64, error)
+	GetLeaderboardEntry(ctx context.Context, filter models.LeaderboardFilter, userId uuid.UUID) (*models.LeaderboardEntry, error)
+	DeleteAllLeaderboards(ctx context.Context) error
+}
+
+type MatchmakingCacheRepository interface {
+	CreateMatchmakingTicket(ctx context.Context, ticket models.MatchmakingTicket) (created bool, err error)
+	GetMatchmakingTicket(ctx context.Context, userId uuid.UUID) (*models.MatchmakingTicket, error)
+	DeleteMatchmakingTicket(ctx context.Context, ticket models.MatchmakingTicket) error
+	PopMatchmakingGroup(ctx context.Context, ticket models.MatchmakingTicket, groupSize int, now time.Time) ([]models.MatchmakingTicket, error)
+}
+
 type RoomCacheRepository interface {
 	GetRoomInCacheOrDb(ctx context.Context, dbRepo DBRepository, roomId uuid.UUID) (*models.Room, error)
 	GetRoomGameDurationSec(ctx context.Context, roomId uuid.UUID) (gameDurationSec int, err error)
 	SetRoom(ctx context.Context, tx Transaction, room models.Room) error
+	SetRoomAdmin(ctx context.Context, tx Transaction, roomId, adminId uuid.UUID) error
+	SetRoomGameDurationSec(ctx context.Context, tx Transaction, roomId uuid.UUID, gameDurationSec int) error
 	RoomHasAdmin(ctx context.Context, roomId, adminId uuid.UUID) (bool, error)
 	RoomHasSubscribers(ctx context.Context, roomId uuid.UUID, userIds ...uuid.UUID) (bool, error)
 	RoomExists(ctx context.Context, roomId uuid.UUID) (bool, error)
@@ -59,7 +81,9 @@ type RoomSubscriberCacheRepository interface {
 	GetRoomSubscriberStatus(ctx context.Context, roomId, userId uuid.UUID) (numberRoomSubscriberConns int64, roomSubscriberStatusHasBeenUpdated bool, err error)
 	// GetRoomSubscriberGameStatus(ctx context.Context, roomId, userId uuid.UUID) (models.SubscriberGameStatus, error)
 	GetRoomSubscribers(ctx context.Context, roomId uuid.UUID) ([]models.RoomSubscriber, error)
+	GetActiveRoomSubscribersNumber(ctx context.Context, roomId uuid.UUID) (int, error)
 	// GetRoomSubscribersIds(ctx context.Context, roomId uuid.UUID) ([]uuid.UUID, error)
+	SetRoomSubscriber(ctx context.Context, tx Transaction, roomId uuid.UUID, user models.User) error
 	SetRoomSubscriberGameStatus(ctx context.Context, tx Transaction, roomId, userId uuid.UUID, status models.SubscriberGameStatus) error
 	SetRoomSubscriberConnection(ctx context.Context, roomId, userId, newConnectionId uuid.UUID) (roomSubscriberStatusHasBeenUpdated bool, err error)
 	DeleteRoomSubscriber(ctx context.Context, roomId, userId uuid.UUID) error
@@ -71,6 +95,7 @@ type TextCacheRepository interface {
 	SetTextId(ctx context.Context, tx Transaction, textIds ...uuid.UUID) error
 	TextIdsKeyExists(ctx context.Context) (bool, error)
 	TextIdExists(ctx context.Context, textId uuid.UUID) (bool, error)
+	RemoveTextId(ctx context.Context, tx Transaction, textIds ...uuid.UUID) error
 	DeleteTextIdsKey(ctx context.Context, tx Transaction) error
 }
 
@@ -86,12 +111,22 @@ type UserCacheRepository interface {
 	UserExists(ctx context.Context, userId uuid.UUID) (bool, error)
 	SetUser(ctx context.Context, tx Transaction, user models.User) error
 	VerifyUser(ctx context.Context, tx Transaction, userId uuid.UUID) error
+	SetUserRating(ctx context.Context, tx Transaction, userId uuid.UUID, rating float64, ratedGames int) error
 	DeleteAllUsers(ctx context.Context) error
 }
 
+type UserStatsCacheRepository interface {
+	GetUserStats(ctx context.Context, userId uuid.UUID, interval models.StatsInterval) (*models.UserStats, error)
+	SetUserStats(ctx context.Context, tx Transaction, userId uuid.UUID, userStats models.UserStats) error
+	GetUserKeyboardStats(ctx context.Context, userId uuid.UUID, layoutName string) (*models.KeyboardStats, error)
+	SetUserKeyboardStats(ctx context.Context, tx Transaction, userId uuid.UUID, keyboardStats models.KeyboardStats) error
+	DeleteUserStats(ctx context.Context, tx Transaction, userId uuid.UUID) error
+}
+
 type SessionCacheRepository interface {
 	SetSession(ctx context.Context, tx Transaction, tokenHash string, userId uuid.UUID) error
 	DeleteSession(ctx context.Context, tx Transaction, tokenHash string) error
+	DeleteUserSessions(ctx context.Context, userId uuid.UUID) error
 	DeleteAllSessions(ctx context.Context) error
 }
 
@@ -100,3 +135,15 @@ type ScoreCacheRepository interface {
 	SetCurrentGameScore(ctx context.Context, tx Transaction, roomId uuid.UUID, score models.Score) error
 	DeleteCurrentGameScores(ctx context.Context, roomId uuid.UUID) error
 }
+
+type RateLimitCacheRepository interface {
+	// TakeRateLimitToken takes a token of the token bucket of the key, e.g. login:ip:[ip], and reports whether the request is allowed
+	TakeRateLimitToken(ctx context.Context, key string, limit models.RateLimit) (*models.RateLimitResult, error)
+	GetLoginFailures(ctx context.Context, email, clientIp string) (*models.LoginFailures, error)
+	// IncrementLoginFailures increments the failed logins of the email from the client ip and from all client ips in fixed windows,
+	// i.e. the failed logins are reset models.LoginLockoutDurationSec after the first failed login and further failed logins don't extend the lockout
+	IncrementLoginFailures(ctx context.Context, email, clientIp string) (*models.LoginFailures, error)
+	// DeleteLoginFailures deletes the failed logins of the email from the client ip. The failed logins from all client ips are kept,
+	// so that an attacker can't reset them by logging into an own account from one of the client ips.
+	DeleteLoginFailures(ctx context.Context, email, clientIp string) error
+}
@@ -3,44 +3,80 @@ package common
 import (
 	"10-typing/models"
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type DBRepository interface {
 	BeginTx() Transaction
+	// Ping checks that the database can be reached, e.g. for the readiness check
+	Ping(ctx context.Context) error
+	RatingDBRepository
 	RoomDBRepository
 	ScoreDBRepository
+	StatsDBRepository
 	TextDBRepository
 	TokenDBRepository
 	UserDBRepository
 	UserRoomDBRepository
 }
 
+type RatingDBRepository interface {
+	UpdateRatings(ctx context.Context, tx Transaction, users []models.User, ratingChanges []models.RatingChange) error
+	FindRatingLeaderboard(ctx context.Context, tx Transaction, limit, offset int) ([]models.RatingLeaderboardEntry, int64, error)
+	FindRatingChanges(ctx context.Context, tx Transaction, userId uuid.UUID, limit int) ([]models.RatingChange, error)
+}
+
 type RoomDBRepository interface {
 	FindRoomWithUsers(ctx context.Context, tx Transaction, roomId uuid.UUID) (*models.Room, error)
+	FindRoomsByUser(ctx context.Context, tx Transaction, userId uuid.UUID, sortOptions []models.SortOption, limit, offset int) ([]models.RoomOverview, int64, error)
 	FindRoom(ctx context.Context, tx Transaction, roomId uuid.UUID) (*models.Room, error)
 	CreateRoom(ctx context.Context, tx Transaction, newRoom models.Room) (*models.Room, error)
+	UpdateRoomAdmin(ctx context.Context, tx Transaction, roomId, adminId uuid.UUID) error
+	UpdateRoomGameDurationSec(ctx context.Context, tx Transaction, roomId uuid.UUID, gameDurationSec int) error
+	UpdateRoomLastGameAt(ctx context.Context, tx Transaction, roomId uuid.UUID, lastGameAt time.Time) error
 	SoftDeleteRoom(ctx context.Context, tx Transaction, roomId uuid.UUID) error
 	DeleteAllRooms(ctx context.Context, tx Transaction) error
 }
 
 type ScoreDBRepository interface {
-	FindScores(ctx context.Context, tx Transaction, userId, gameId uuid.UUID, username string, sortOptions []models.SortOption) ([]models.Score, error)
+	FindScores(ctx context.Context, tx Transaction, filter models.ScoreFilter, sortOption models.SortOption, cursor *models.ScoreCursor, limit int) ([]models.Score, error)
+	FindLeaderboardScores(ctx context.Context, tx Transaction, since time.Time, limit, offset int) ([]models.LeaderboardScore, error)
 	CreateScore(ctx context.Context, tx Transaction, score models.Score) (*models.Score, error)
 	DeleteAllScores(ctx context.Context, tx Transaction) error
 }
 
+type StatsDBRepository interface {
+	FindScoreTotals(ctx context.Context, tx Transaction, userId uuid.UUID) (*models.ScoreTotals, error)
+	FindScoreTrend(ctx context.Context, tx Transaction, userId uuid.UUID, interval models.StatsInterval, since time.Time) ([]models.ScoreTrendBucket, error)
+	FindKeyErrors(ctx context.Context, tx Transaction, userId uuid.UUID) ([]models.KeyErrorCount, error)
+	FindScoreDays(ctx context.Context, tx Transaction, userId uuid.UUID) ([]time.Time, error)
+	FindScoreTotalsByLanguage(ctx context.Context, tx Transaction, userId uuid.UUID) ([]models.ScoreTotalsByFilter, error)
+	FindScoreTotalsByPunctuation(ctx context.Context, tx Transaction, userId uuid.UUID) ([]models.ScoreTotalsByFilter, error)
+	FindTypedTexts(ctx context.Context, tx Transaction, userId uuid.UUID, limit int) ([]models.TypedText, error)
+	FindSystemStats(ctx context.Context, tx Transaction) (*models.SystemStats, error)
+}
+
 type TextDBRepository interface {
-	FindNewTextForUser(ctx context.Context,
-		tx Transaction,
-		userId uuid.UUID, language string,
-		punctuation bool,
-		specialCharactersGte, specialCharactersLte, numbersGte, numbersLte int,
-	) (*models.Text, error)
+	FindNewTextForUser(ctx context.Context, tx Transaction, userId uuid.UUID, filter models.TextFilter, sortOption models.SortOption) (*models.Text, error)
+	FindNewTextsForUser(ctx context.Context, tx Transaction, userId uuid.UUID, language string, punctuation bool, limit int) ([]models.Text, error)
+	FindNewTextForUsers(ctx context.Context, tx Transaction, userIds []uuid.UUID, language string, punctuation bool) (*models.Text, error)
 	FindAllTextIds(ctx context.Context, tx Transaction) ([]uuid.UUID, error)
 	FindTextById(ctx context.Context, tx Transaction, textId uuid.UUID) (*models.Text, error)
+	FindTextsForAnalysis(ctx context.Context, tx Transaction, afterId uuid.UUID, onlyUnanalyzed bool, limit int) ([]models.Text, error)
+	UpdateTextAnalysis(ctx context.Context, tx Transaction, text models.Text) error
+	FindTextsAfterId(ctx context.Context, tx Transaction, afterId uuid.UUID, limit int) ([]models.Text, error)
+	FindExistingContentHashes(ctx context.Context, tx Transaction, contentHashes []string) ([]string, error)
+	FindPublicTextByContentHash(ctx context.Context, tx Transaction, contentHash string) (*models.Text, error)
+	FindDuplicatePublicTextIds(ctx context.Context, tx Transaction) ([]uuid.UUID, error)
+	FindTextUsageStats(ctx context.Context, tx Transaction, textIds []uuid.UUID) ([]models.TextUsageStats, error)
 	CreateTextAndCache(ctx context.Context, tx Transaction, cacheRepo CacheRepository, text models.Text) (*models.Text, error)
+	CreateTextsAndCache(ctx context.Context, tx Transaction, cacheRepo CacheRepository, texts []models.Text) ([]models.Text, error)
+	FindTextsByUserId(ctx context.Context, tx Transaction, userId uuid.UUID, limit, offset int) ([]models.Text, int64, error)
+	FindTextsByStatus(ctx context.Context, tx Transaction, status models.TextStatus, limit, offset int) ([]models.Text, int64, error)
+	UpdateTextStatusAndCache(ctx context.Context, tx Transaction, cacheRepo CacheRepository, textId uuid.UUID, status models.TextStatus, moderationNote string) (*models.Text, error)
+	SoftDeleteTextAndCache(ctx context.Context, tx Transaction, cacheRepo CacheRepository, textId uuid.UUID) error
 }
 
 type TokenDBRepository interface {
@@ -51,11 +87,20 @@ type UserDBRepository interface {
 	FindUserByEmail(ctx context.Context, tx Transaction, email string) (*models.User, error)
 	FindUsers(ctx context.Context, tx Transaction, username, usernameSubstr string) ([]models.User, error)
 	FindUserById(ctx context.Context, tx Transaction, userId uuid.UUID) (*models.User, error)
+	FindUsersByIds(ctx context.Context, tx Transaction, userIds []uuid.UUID) ([]models.User, error)
+	FindUsersByIdsForUpdate(ctx context.Context, tx Transaction, userIds []uuid.UUID) ([]models.User, error)
+	FindAllUsers(ctx context.Context, tx Transaction, filter models.UserFilter, limit, offset int) ([]models.User, int64, error)
 	CreateUserAndCache(ctx context.Context, tx Transaction, cacheRepo CacheRepository, newUser models.User) (*models.User, error)
 	VerifyUserAndCache(ctx context.Context, tx Transaction, cacheRepo CacheRepository, userId uuid.UUID) error
+	UpdateUserRoleAndCache(ctx context.Context, tx Transaction, cacheRepo CacheRepository, userId uuid.UUID, role models.UserRole) (*models.User, error)
+	UpdateUserBannedAndCache(ctx context.Context, tx Transaction, cacheRepo CacheRepository, userId uuid.UUID, banned bool) (*models.User, error)
+	UpdateUserKeyboardLayoutAndCache(ctx context.Context, tx Transaction, cacheRepo CacheRepository, userId uuid.UUID, keyboardLayout string) (*models.User, error)
 	DeleteAllUsers(ctx context.Context, tx Transaction) error
 }
 
 type UserRoomDBRepository interface {
 	CreateUserRoom(ctx context.Context, tx Transaction, userId, roomId uuid.UUID) error
+	DeleteUserRoom(ctx context.Context, tx Transaction, userId, roomId uuid.UUID) error
+	FindRoomMemberIdsForUpdate(ctx context.Context, tx Transaction, roomId uuid.UUID) ([]uuid.UUID, error)
+	FindRoomIdsByUserId(ctx context.Context, tx Transaction, userId uuid.UUID) ([]uuid.UUID, error)
 }
@@ -1,11 +1,57 @@
 package common
 
 import (
+	"context"
 	"time"
 )
 
 type Logger interface {
+	Debug(v ...any)
 	Info(v ...any)
+	// Error writes errors.Error values as fields, e.g. the operations of the error chain
 	Error(v ...any)
 	RequestInfo(method, path, clientIP string, statusCode int, latency time.Duration)
+	// With returns a logger that writes the fields with every log line
+	With(fields LogFields) Logger
+	// WithContext returns a logger that writes the log fields of the context with every log line, e.g. the request id
+	WithContext(ctx context.Context) Logger
+	// SetLevel sets the minimum level of the written log lines at runtime: debug, info or error
+	SetLevel(level string) error
+	Level() string
+}
+
+type LogFields map[string]any
+
+const (
+	OpLogField           = "op"
+	UserIdLogField       = "user_id"
+	RoomIdLogField       = "room_id"
+	GameIdLogField       = "game_id"
+	RequestIdLogField    = "request_id"
+	ConnectionIdLogField = "connection_id"
+	TraceIdLogField      = "trace_id"
+)
+
+type logFieldsContextKey struct{}
+
+// ContextWithLogFields returns a copy of the context that carries the fields in addition to the log fields of the parent context
+func ContextWithLogFields(ctx context.Context, fields LogFields) context.Context {
+	parentFields := LogFieldsFromContext(ctx)
+
+	mergedFields := make(LogFields, len(parentFields)+len(fields))
+	for k, v := range parentFields {
+		mergedFields[k] = v
+	}
+	for k, v := range fields {
+		mergedFields[k] = v
+	}
+
+	return context.WithValue(ctx, logFieldsContextKey{}, mergedFields)
+}
+
+// LogFieldsFromContext returns the log fields of the context, which must not be modified
+func LogFieldsFromContext(ctx context.Context) LogFields {
+	fields, _ := ctx.Value(logFieldsContextKey{}).(LogFields)
+
+	return fields
 }
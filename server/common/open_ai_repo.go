@@ -1,5 +1,8 @@
 package common
 
+import "context"
+
 type OpenAiRepository interface {
-	GenerateTypingText(language string, punctuation bool, specialCharacters, numbers int) (string, error)
+	GenerateTypingText(ctx context.Context, language string, punctuation bool, specialCharacters, numbers int) (string, error)
+	GenerateDrillText(ctx context.Context, language string, punctuation bool, targetKeys []string) (string, error)
 }
@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"10-typing/errors"
+	"10-typing/models"
+	"10-typing/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateCustomTextInput struct {
+	Title      string                `json:"title" binding:"required,max=255"`
+	Language   string                `json:"language" binding:"required,oneof=de en fr"`
+	Source     string                `json:"source" binding:"max=255"`
+	Text       string                `json:"text" binding:"required"`
+	Visibility models.TextVisibility `json:"visibility" binding:"omitempty,oneof=private public"`
+}
+
+type ModerateTextInput struct {
+	Status models.TextStatus `json:"status" binding:"required,oneof=approved rejected"`
+	Note   string            `json:"note" binding:"max=1000"`
+}
+
+type FindTextsQuery struct {
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+const defaultFindTextsLimit = 20
+
+func (tc *TextController) CreateCustomText(c *gin.Context) {
+	const op errors.Op = "controllers.TextController.CreateCustomText"
+	var input CreateCustomTextInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), tc.logger)
+		return
+	}
+	if input.Visibility == "" {
+		input.Visibility = models.PrivateTextVisibility
+	}
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err), tc.logger)
+		return
+	}
+
+	text, err := tc.textService.CreateCustomText(c.Request.Context(), user.ID, input.Title, input.Language, input.Source, input.Text, input.Visibility)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err), tc.logger)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": text})
+}
+
+func (tc *TextController) FindCustomTextsOfUser(c *gin.Context) {
+	const op errors.Op = "controllers.TextController.FindCustomTextsOfUser"
+	var query FindTextsQuery
+
+	userId, err := utils.GetUserIdFromPath(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), tc.logger)
+		return
+	}
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), tc.logger)
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultFindTextsLimit
+	}
+
+	texts, total, err := tc.textService.FindCustomTextsOfUser(c.Request.Context(), userId, query.Limit, query.Offset)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err), tc.logger)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": texts,
+		"pagination": gin.H{
+			"total":  total,
+			"limit":  query.Limit,
+			"offset": query.Offset,
+		},
+	})
+}
+
+func (tc *TextController) FindModerationQueue(c *gin.Context) {
+	const op errors.Op = "controllers.TextController.FindModerationQueue"
+	var query FindTextsQuery
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), tc.logger)
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultFindTextsLimit
+	}
+
+	texts, total, err := tc.textService.FindModerationQueue(c.Request.Context(), query.Limit, query.Offset)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err), tc.logger)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": texts,
+		"pagination": gin.H{
+			"total":  total,
+			"limit":  query.Limit,
+			"offset": query.Offset,
+		},
+	})
+}
+
+func (tc *TextController) ModerateText(c *gin.Context) {
+	const op errors.Op = "controllers.TextController.ModerateText"
+	var input ModerateTextInput
+
+	textId, err := utils.GetTextIdFromPath(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), tc.logger)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), tc.logger)
+		return
+	}
+
+	text, err := tc.textService.ModerateText(c.Request.Context(), textId, input.Status, input.Note)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err), tc.logger)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": text})
+}
+
+func (tc *TextController) DeleteText(c *gin.Context) {
+	const op errors.Op = "controllers.TextController.DeleteText"
+
+	textId, err := utils.GetTextIdFromPath(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), tc.logger)
+		return
+	}
+
+	if err := tc.textService.DeleteText(c.Request.Context(), textId); err != nil {
+		utils.WriteError(c, errors.E(op, err), tc.logger)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": "OK"})
+}
@@ -102,6 +102,17 @@ func (gc *GameController) FinishGame(c *gin.Context) {
 		return
 	}
 
+	text, err := gc.gameService.FindCurrentGameText(c.Request.Context(), roomId)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err), gc.logger)
+		return
+	}
+
+	if err := validateTypingErrors(input.Errors, user, text); err != nil {
+		utils.WriteError(c, errors.E(op, err), gc.logger)
+		return
+	}
+
 	if err = gc.gameService.UserFinishesGame(c.Request.Context(), roomId, user.ID, input.TextId, input.WordsTyped, input.TimeElapsed, input.Errors); err != nil {
 		utils.WriteError(c, errors.E(op, err), gc.logger)
 		return
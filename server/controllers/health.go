@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"10-typing/common"
+	"10-typing/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthController struct {
+	healthService *services.HealthService
+	logger        common.Logger
+}
+
+func NewHealthController(healthService *services.HealthService, logger common.Logger) *HealthController {
+	return &HealthController{healthService, logger}
+}
+
+// Healthz reports that the process is alive. It does not check the dependencies, so that the process is not restarted if postgres or redis are down.
+func (hc *HealthController) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{"status": "ok"},
+	})
+}
+
+// Readyz reports whether the server can handle requests. It responds with 503 Service Unavailable if a dependency is down or the server is shutting down.
+func (hc *HealthController) Readyz(c *gin.Context) {
+	readiness := hc.healthService.CheckReadiness(c.Request.Context())
+
+	status := http.StatusOK
+	if !readiness.Ready {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.JSON(status, gin.H{
+		"data": readiness,
+	})
+}
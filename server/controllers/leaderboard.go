@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/models"
+	"10-typing/services"
+	"10-typing/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FindLeaderboardQuery struct {
+	Window      string `form:"window" binding:"omitempty,oneof=daily weekly monthly all_time"`
+	Language    string `form:"language" binding:"omitempty,oneof=de en fr"`
+	Punctuation *bool  `form:"punctuation"`
+	Limit       int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset      int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+const defaultFindLeaderboardLimit = 20
+
+type LeaderboardController struct {
+	leaderboardService *services.LeaderboardService
+	logger             common.Logger
+}
+
+func NewLeaderboardController(leaderboardService *services.LeaderboardService, logger common.Logger) *LeaderboardController {
+	return &LeaderboardController{leaderboardService, logger}
+}
+
+func (lc *LeaderboardController) FindLeaderboard(c *gin.Context) {
+	const op errors.Op = "controllers.LeaderboardController.FindLeaderboard"
+	var query FindLeaderboardQuery
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), lc.logger)
+		return
+	}
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), lc.logger)
+		return
+	}
+	if query.Window == "" {
+		query.Window = string(models.AllTimeLeaderboardWindow)
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultFindLeaderboardLimit
+	}
+
+	leaderboard, err := lc.leaderboardService.FindLeaderboard(
+		c.Request.Context(),
+		user.ID,
+		models.LeaderboardWindow(query.Window),
+		query.Language,
+		query.Punctuation,
+		query.Limit,
+		query.Offset,
+	)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err), lc.logger)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": leaderboard,
+		"pagination": gin.H{
+			"total":  leaderboard.Total,
+			"limit":  query.Limit,
+			"offset": query.Offset,
+		},
+	})
+}
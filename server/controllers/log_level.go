@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LogLevelController struct {
+	logger common.Logger
+}
+
+func NewLogLevelController(logger common.Logger) *LogLevelController {
+	return &LogLevelController{logger}
+}
+
+func (lc *LogLevelController) FindLogLevel(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{"level": lc.logger.Level()},
+	})
+}
+
+func (lc *LogLevelController) UpdateLogLevel(c *gin.Context) {
+	const op errors.Op = "controllers.LogLevelController.UpdateLogLevel"
+	var input struct {
+		Level string `json:"level" binding:"required,oneof=debug info error"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), lc.logger)
+		return
+	}
+
+	if err := lc.logger.SetLevel(input.Level); err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), lc.logger)
+		return
+	}
+
+	lc.logger.WithContext(c.Request.Context()).Info("log level changed to ", input.Level)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{"level": lc.logger.Level()},
+	})
+}
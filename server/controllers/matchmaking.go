@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/services"
+	"10-typing/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JoinMatchmakingQueueInput struct {
+	Language     string `json:"language" binding:"required,oneof=de en fr"`
+	Punctuation  bool   `json:"punctuation"`
+	SkillBracket string `json:"skillBracket" binding:"omitempty,oneof=beginner intermediate advanced"`
+}
+
+type MatchmakingController struct {
+	matchmakingService *services.MatchmakingService
+	logger             common.Logger
+}
+
+func NewMatchmakingController(matchmakingService *services.MatchmakingService, logger common.Logger) *MatchmakingController {
+	return &MatchmakingController{matchmakingService, logger}
+}
+
+func (mc *MatchmakingController) JoinQueue(c *gin.Context) {
+	const op errors.Op = "controllers.MatchmakingController.JoinQueue"
+	var input JoinMatchmakingQueueInput
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), mc.logger)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), mc.logger)
+		return
+	}
+
+	matchmakingStatus, err := mc.matchmakingService.JoinQueue(c.Request.Context(), *user, input.Language, input.Punctuation, input.SkillBracket)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err), mc.logger)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": matchmakingStatus})
+}
+
+func (mc *MatchmakingController) FindQueueStatus(c *gin.Context) {
+	const op errors.Op = "controllers.MatchmakingController.FindQueueStatus"
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), mc.logger)
+		return
+	}
+
+	matchmakingStatus, err := mc.matchmakingService.FindQueueStatus(c.Request.Context(), user.ID)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err), mc.logger)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": matchmakingStatus})
+}
+
+func (mc *MatchmakingController) LeaveQueue(c *gin.Context) {
+	const op errors.Op = "controllers.MatchmakingController.LeaveQueue"
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), mc.logger)
+		return
+	}
+
+	if err := mc.matchmakingService.LeaveQueue(c.Request.Context(), user.ID); err != nil {
+		utils.WriteError(c, errors.E(op, err), mc.logger)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": "OK"})
+}
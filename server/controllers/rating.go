@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/services"
+	"10-typing/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FindRatingLeaderboardQuery struct {
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+type FindRatingHistoryQuery struct {
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+const (
+	defaultFindRatingLeaderboardLimit = 20
+	defaultFindRatingHistoryLimit     = 20
+)
+
+type RatingController struct {
+	ratingService *services.RatingService
+	logger        common.Logger
+}
+
+func NewRatingController(ratingService *services.RatingService, logger common.Logger) *RatingController {
+	return &RatingController{ratingService, logger}
+}
+
+func (rc *RatingController) FindRatingLeaderboard(c *gin.Context) {
+	const op errors.Op = "controllers.RatingController.FindRatingLeaderboard"
+	var query FindRatingLeaderboardQuery
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), rc.logger)
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultFindRatingLeaderboardLimit
+	}
+
+	entries, total, err := rc.ratingService.FindRatingLeaderboard(c.Request.Context(), query.Limit, query.Offset)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err), rc.logger)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": entries,
+		"pagination": gin.H{
+			"total":  total,
+			"limit":  query.Limit,
+			"offset": query.Offset,
+		},
+	})
+}
+
+func (rc *RatingController) FindRatingHistory(c *gin.Context) {
+	const op errors.Op = "controllers.RatingController.FindRatingHistory"
+	var query FindRatingHistoryQuery
+
+	userId, err := utils.GetUserIdFromPath(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), rc.logger)
+		return
+	}
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), rc.logger)
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultFindRatingHistoryLimit
+	}
+
+	ratingChanges, err := rc.ratingService.FindRatingHistory(c.Request.Context(), userId, query.Limit)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err), rc.logger)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": ratingChanges})
+}
@@ -3,6 +3,7 @@ package controllers
 import (
 	"10-typing/common"
 	"10-typing/errors"
+	"10-typing/models"
 	"10-typing/services"
 	"10-typing/utils"
 	"net/http"
@@ -17,6 +18,32 @@ type CreateRoomInput struct {
 	GameDurationSec int         `json:"gameDurationSec"`
 }
 
+type InviteToRoomInput struct {
+	UserIds []uuid.UUID `json:"userIds"`
+	Emails  []string    `json:"emails" binding:"dive,email"`
+}
+
+type TransferRoomAdminInput struct {
+	UserId uuid.UUID `json:"userId" binding:"required"`
+}
+
+// UpdateRoomInput holds the settings of a room that can be changed after its creation. Settings that are not set are not changed.
+type UpdateRoomInput struct {
+	GameDurationSec *int `json:"gameDurationSec" binding:"omitempty,min=1,max=3600"`
+}
+
+type FindRoomsSortOption struct {
+	Column string `validate:"required,oneof=created_at updated_at last_game_at member_count"`
+	Order  string `validate:"required,oneof=desc asc"`
+}
+
+type FindRoomsQuery struct {
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+const defaultFindRoomsLimit = 20
+
 type RoomController struct {
 	roomService *services.RoomService
 	logger      common.Logger
@@ -26,6 +53,64 @@ func NewRoomController(roomService *services.RoomService, logger common.Logger)
 	return &RoomController{roomService, logger}
 }
 
+func (rc *RoomController) FindRooms(c *gin.Context) {
+	const op errors.Op = "controllers.RoomController.FindRooms"
+	var query FindRoomsQuery
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), rc.logger)
+		return
+	}
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), rc.logger)
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultFindRoomsLimit
+	}
+
+	sortOptions, err := models.BindSortByQuery(c, FindRoomsSortOption{})
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), rc.logger)
+		return
+	}
+
+	rooms, total, err := rc.roomService.FindRoomsByUser(c.Request.Context(), user.ID, sortOptions, query.Limit, query.Offset)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err), rc.logger)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": rooms,
+		"pagination": gin.H{
+			"total":  total,
+			"limit":  query.Limit,
+			"offset": query.Offset,
+		},
+	})
+}
+
+func (rc *RoomController) FindRoom(c *gin.Context) {
+	const op errors.Op = "controllers.RoomController.FindRoom"
+
+	roomId, err := utils.GetRoomIdFromPath(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), rc.logger)
+		return
+	}
+
+	room, err := rc.roomService.FindRoomWithMembers(c.Request.Context(), roomId)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err), rc.logger)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": room})
+}
+
 func (rc *RoomController) LeaveRoom(c *gin.Context) {
 	const op errors.Op = "controllers.RoomController.LeaveRoom"
 
@@ -96,3 +181,147 @@ func (rc *RoomController) ConnectToRoom(c *gin.Context) {
 		return
 	}
 }
+
+func (rc *RoomController) InviteToRoom(c *gin.Context) {
+	const op errors.Op = "controllers.RoomController.InviteToRoom"
+	var input InviteToRoomInput
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), rc.logger)
+		return
+	}
+
+	roomId, err := utils.GetRoomIdFromPath(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), rc.logger)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), rc.logger)
+		return
+	}
+
+	if err = rc.roomService.InviteToRoom(c.Request.Context(), roomId, input.UserIds, input.Emails, *user); err != nil {
+		utils.WriteError(c, errors.E(op, err), rc.logger)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": "OK"})
+}
+
+func (rc *RoomController) RemoveRoomMember(c *gin.Context) {
+	const op errors.Op = "controllers.RoomController.RemoveRoomMember"
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), rc.logger)
+		return
+	}
+
+	roomId, err := utils.GetRoomIdFromPath(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), rc.logger)
+		return
+	}
+
+	userId, err := utils.GetUserIdFromPath(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), rc.logger)
+		return
+	}
+
+	if err = rc.roomService.RemoveRoomMember(c.Request.Context(), roomId, userId, *user); err != nil {
+		utils.WriteError(c, errors.E(op, err), rc.logger)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": "OK"})
+}
+
+func (rc *RoomController) TransferRoomAdmin(c *gin.Context) {
+	const op errors.Op = "controllers.RoomController.TransferRoomAdmin"
+	var input TransferRoomAdminInput
+
+	roomId, err := utils.GetRoomIdFromPath(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), rc.logger)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), rc.logger)
+		return
+	}
+
+	if err = rc.roomService.TransferRoomAdmin(c.Request.Context(), roomId, input.UserId); err != nil {
+		utils.WriteError(c, errors.E(op, err), rc.logger)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": "OK"})
+}
+
+func (rc *RoomController) UpdateRoom(c *gin.Context) {
+	const op errors.Op = "controllers.RoomController.UpdateRoom"
+	var input UpdateRoomInput
+
+	roomId, err := utils.GetRoomIdFromPath(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), rc.logger)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), rc.logger)
+		return
+	}
+
+	settings := models.RoomSettings{
+		GameDurationSec: input.GameDurationSec,
+	}
+
+	room, err := rc.roomService.UpdateRoomSettings(c.Request.Context(), roomId, settings)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err), rc.logger)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": room})
+}
+
+func (rc *RoomController) InspectRoom(c *gin.Context) {
+	const op errors.Op = "controllers.RoomController.InspectRoom"
+
+	roomId, err := utils.GetRoomIdFromPath(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), rc.logger)
+		return
+	}
+
+	roomInspection, err := rc.roomService.InspectRoom(c.Request.Context(), roomId)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err), rc.logger)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": roomInspection})
+}
+
+func (rc *RoomController) TerminateRoom(c *gin.Context) {
+	const op errors.Op = "controllers.RoomController.TerminateRoom"
+
+	roomId, err := utils.GetRoomIdFromPath(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), rc.logger)
+		return
+	}
+
+	if err := rc.roomService.TerminateRoom(c.Request.Context(), roomId); err != nil {
+		utils.WriteError(c, errors.E(op, err), rc.logger)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": "OK"})
+}
@@ -6,7 +6,9 @@ import (
 	"10-typing/models"
 	"10-typing/services"
 	"10-typing/utils"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -20,23 +22,36 @@ type CreateScoreInput struct {
 }
 
 type FindScoresSortOption struct {
-	Column string `validate:"required,oneof=accuracy errors created_at"`
+	Column string `validate:"required,oneof=accuracy errors created_at words_per_minute"`
 	Order  string `validate:"required,oneof=desc asc"`
 }
 
 type FindScoresQuery struct {
-	UserId   uuid.UUID
-	GameId   uuid.UUID
-	Username string `form:"username"`
+	UserId            uuid.UUID
+	GameId            uuid.UUID
+	Username          string     `form:"username"`
+	From              *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To                *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Language          string     `form:"language"`
+	Punctuation       *bool      `form:"punctuation"`
+	MinWordsPerMinute *float64   `form:"min_wpm" binding:"omitempty,min=0"`
+	MaxWordsPerMinute *float64   `form:"max_wpm" binding:"omitempty,min=0"`
+	MinAccuracy       *float64   `form:"min_accuracy" binding:"omitempty,min=0,max=100"`
+	MaxAccuracy       *float64   `form:"max_accuracy" binding:"omitempty,min=0,max=100"`
+	Cursor            string     `form:"cursor"`
+	Limit             int        `form:"limit" binding:"omitempty,min=1,max=100"`
 }
 
+const defaultFindScoresLimit = 20
+
 type ScoreController struct {
 	scoreService *services.ScoreService
+	textService  *services.TextService
 	logger       common.Logger
 }
 
-func NewScoreController(scoreService *services.ScoreService, logger common.Logger) *ScoreController {
-	return &ScoreController{scoreService, logger}
+func NewScoreController(scoreService *services.ScoreService, textService *services.TextService, logger common.Logger) *ScoreController {
+	return &ScoreController{scoreService, textService, logger}
 }
 
 func (sc *ScoreController) CreateScore(c *gin.Context) {
@@ -54,6 +69,23 @@ func (sc *ScoreController) CreateScore(c *gin.Context) {
 		return
 	}
 
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), sc.logger)
+		return
+	}
+
+	text, err := sc.textService.FindTextById(c.Request.Context(), user, input.TextId)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err), sc.logger)
+		return
+	}
+
+	if err := validateTypingErrors(input.Errors, user, text); err != nil {
+		utils.WriteError(c, errors.E(op, err), sc.logger)
+		return
+	}
+
 	score, err := sc.scoreService.Create(c.Request.Context(), uuid.Nil, userId, input.TextId, input.WordsTyped, input.TimeElapsed, input.Errors)
 	if err != nil {
 		utils.WriteError(c, errors.E(op, err), sc.logger)
@@ -77,20 +109,16 @@ func (sc *ScoreController) FindScoresByUser(c *gin.Context) {
 		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), sc.logger)
 		return
 	}
+	query.UserId = userId
+	query.GameId = uuid.Nil
 
-	sortOptions, err := models.BindSortByQuery(c, FindScoresSortOption{})
+	sortOption, err := bindFindScoresSortOption(c)
 	if err != nil {
 		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), sc.logger)
 		return
 	}
 
-	scores, err := sc.scoreService.FindScores(c.Request.Context(), userId, uuid.Nil, query.Username, sortOptions)
-	if err != nil {
-		utils.WriteError(c, errors.E(op, err), sc.logger)
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{"data": scores})
+	sc.findScores(c, query, sortOption)
 }
 
 func (sc *ScoreController) FindScores(c *gin.Context) {
@@ -102,17 +130,72 @@ func (sc *ScoreController) FindScores(c *gin.Context) {
 		return
 	}
 
-	sortOptions, err := models.BindSortByQuery(c, FindScoresSortOption{})
+	sortOption, err := bindFindScoresSortOption(c)
 	if err != nil {
 		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), sc.logger)
 		return
 	}
 
-	scores, err := sc.scoreService.FindScores(c.Request.Context(), query.UserId, query.GameId, query.Username, sortOptions)
+	sc.findScores(c, query, sortOption)
+}
+
+func (sc *ScoreController) findScores(c *gin.Context, query FindScoresQuery, sortOption models.SortOption) {
+	const op errors.Op = "controllers.ScoreController.findScores"
+
+	if query.Limit == 0 {
+		query.Limit = defaultFindScoresLimit
+	}
+
+	filter := models.ScoreFilter{
+		UserId:            query.UserId,
+		GameId:            query.GameId,
+		Username:          query.Username,
+		From:              query.From,
+		To:                query.To,
+		Language:          query.Language,
+		Punctuation:       query.Punctuation,
+		MinWordsPerMinute: query.MinWordsPerMinute,
+		MaxWordsPerMinute: query.MaxWordsPerMinute,
+		MinAccuracy:       query.MinAccuracy,
+		MaxAccuracy:       query.MaxAccuracy,
+	}
+
+	scorePage, err := sc.scoreService.FindScores(c.Request.Context(), filter, sortOption, query.Cursor, query.Limit)
 	if err != nil {
 		utils.WriteError(c, errors.E(op, err), sc.logger)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": scores})
+	c.JSON(http.StatusOK, gin.H{
+		"data": scorePage.Scores,
+		"pagination": gin.H{
+			"limit":      query.Limit,
+			"nextCursor": scorePage.NextCursor,
+			"prevCursor": scorePage.PrevCursor,
+		},
+	})
+}
+
+// bindFindScoresSortOption binds the sort_by query. Cursor based paging only supports a single sort option, which defaults to created_at.desc.
+func bindFindScoresSortOption(c *gin.Context) (models.SortOption, error) {
+	const op errors.Op = "controllers.bindFindScoresSortOption"
+
+	sortOptions, err := models.BindSortByQuery(c, FindScoresSortOption{})
+	switch {
+	case err != nil:
+		return models.SortOption{}, errors.E(op, err)
+	case len(sortOptions) > 1:
+		err := fmt.Errorf("only a single sort_by query is supported")
+		return models.SortOption{}, errors.E(op, err)
+	case len(sortOptions) == 0:
+		return models.SortOption{Column: "created_at", Order: "desc"}, nil
+	}
+
+	sortOption := sortOptions[0]
+	// the errors column holds the errors per key, the number of errors is held in the number_errors column
+	if sortOption.Column == "errors" {
+		sortOption.Column = "number_errors"
+	}
+
+	return sortOption, nil
 }
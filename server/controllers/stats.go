@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/models"
+	"10-typing/services"
+	"10-typing/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FindUserStatsQuery struct {
+	Interval string `form:"interval" binding:"omitempty,oneof=day week"`
+}
+
+type FindUserKeyboardStatsQuery struct {
+	KeyboardLayout string `form:"layout" binding:"omitempty,keyboardlayout"`
+}
+
+type StatsController struct {
+	statsService *services.StatsService
+	logger       common.Logger
+}
+
+func NewStatsController(statsService *services.StatsService, logger common.Logger) *StatsController {
+	return &StatsController{statsService, logger}
+}
+
+func (sc *StatsController) FindUserStats(c *gin.Context) {
+	const op errors.Op = "controllers.StatsController.FindUserStats"
+	var query FindUserStatsQuery
+
+	userId, err := utils.GetUserIdFromPath(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), sc.logger)
+		return
+	}
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), sc.logger)
+		return
+	}
+	if query.Interval == "" {
+		query.Interval = string(models.DayStatsInterval)
+	}
+
+	userStats, err := sc.statsService.FindUserStats(c.Request.Context(), userId, models.StatsInterval(query.Interval))
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err), sc.logger)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": userStats})
+}
+
+func (sc *StatsController) FindUserKeyboardStats(c *gin.Context) {
+	const op errors.Op = "controllers.StatsController.FindUserKeyboardStats"
+	var query FindUserKeyboardStatsQuery
+
+	userId, err := utils.GetUserIdFromPath(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), sc.logger)
+		return
+	}
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), sc.logger)
+		return
+	}
+
+	keyboardStats, err := sc.statsService.FindUserKeyboardStats(c.Request.Context(), userId, query.KeyboardLayout)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err), sc.logger)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": keyboardStats})
+}
@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/services"
+	"10-typing/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SystemStatsController struct {
+	systemStatsService *services.SystemStatsService
+	logger             common.Logger
+}
+
+func NewSystemStatsController(systemStatsService *services.SystemStatsService, logger common.Logger) *SystemStatsController {
+	return &SystemStatsController{systemStatsService, logger}
+}
+
+func (sc *SystemStatsController) FindSystemStats(c *gin.Context) {
+	const op errors.Op = "controllers.SystemStatsController.FindSystemStats"
+
+	stats, err := sc.systemStatsService.FindSystemStats(c.Request.Context())
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err), sc.logger)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": stats})
+}
@@ -3,13 +3,20 @@ package controllers
 import (
 	"10-typing/common"
 	"10-typing/errors"
+	"10-typing/models"
 	"10-typing/services"
 	"10-typing/utils"
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
+type FindNewTextSortOption struct {
+	Column string `validate:"required,oneof=created_at difficulty words"`
+	Order  string `validate:"required,oneof=desc asc"`
+}
+
 type TextController struct {
 	textService *services.TextService
 	logger      common.Logger
@@ -29,12 +36,16 @@ func (tc *TextController) FindNewTextForUser(c *gin.Context) {
 	}
 
 	var query struct {
-		Language             string `form:"language" binding:"required"`
-		Punctuation          bool   `form:"punctuation"`
-		SpecialCharactersGte int    `form:"specialCharacters[gte]"`
-		SpecialCharactersLte int    `form:"specialCharacters[lte]"`
-		NumbersGte           int    `form:"numbers[gte]"`
-		NumbersLte           int    `form:"numbers[lte]"`
+		Language             string   `form:"language" binding:"required"`
+		Punctuation          bool     `form:"punctuation"`
+		SpecialCharactersGte int      `form:"specialCharacters[gte]"`
+		SpecialCharactersLte int      `form:"specialCharacters[lte]"`
+		NumbersGte           int      `form:"numbers[gte]"`
+		NumbersLte           int      `form:"numbers[lte]"`
+		DifficultyGte        *float64 `form:"difficulty[gte]" binding:"omitempty,min=0,max=100"`
+		DifficultyLte        *float64 `form:"difficulty[lte]" binding:"omitempty,min=0,max=100"`
+		WordsGte             *int     `form:"words[gte]" binding:"omitempty,min=0"`
+		WordsLte             *int     `form:"words[lte]" binding:"omitempty,min=0"`
 	}
 
 	if err := c.ShouldBindQuery(&query); err != nil {
@@ -42,22 +53,70 @@ func (tc *TextController) FindNewTextForUser(c *gin.Context) {
 		return
 	}
 
-	text, err := tc.textService.FindNewTextForUser(
+	sortOption, err := bindFindNewTextSortOption(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), tc.logger)
+		return
+	}
+
+	filter := models.TextFilter{
+		Language:             query.Language,
+		Punctuation:          query.Punctuation,
+		SpecialCharactersGte: query.SpecialCharactersGte,
+		SpecialCharactersLte: query.SpecialCharactersLte,
+		NumbersGte:           query.NumbersGte,
+		NumbersLte:           query.NumbersLte,
+		DifficultyGte:        query.DifficultyGte,
+		DifficultyLte:        query.DifficultyLte,
+		WordsGte:             query.WordsGte,
+		WordsLte:             query.WordsLte,
+	}
+
+	text, err := tc.textService.FindNewTextForUser(c.Request.Context(), userId, filter, sortOption)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err), tc.logger)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": text})
+}
+
+func (tc *TextController) FindAdaptiveTextForUser(c *gin.Context) {
+	const op errors.Op = "controllers.TextController.FindAdaptiveTextForUser"
+
+	userId, err := utils.GetUserIdFromPath(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), tc.logger)
+		return
+	}
+
+	var query struct {
+		Language    string `form:"language" binding:"required,oneof=de en fr"`
+		Punctuation bool   `form:"punctuation"`
+		Generator   string `form:"generator" binding:"omitempty,oneof=local openai"`
+	}
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), tc.logger)
+		return
+	}
+	if query.Generator == "" {
+		query.Generator = string(models.LocalTextGenerator)
+	}
+
+	adaptiveText, err := tc.textService.FindAdaptiveTextForUser(
 		c.Request.Context(),
 		userId,
 		query.Language,
 		query.Punctuation,
-		query.SpecialCharactersGte,
-		query.SpecialCharactersLte,
-		query.NumbersGte,
-		query.NumbersLte,
+		models.TextGenerator(query.Generator),
 	)
 	if err != nil {
 		utils.WriteError(c, errors.E(op, err), tc.logger)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": text})
+	c.JSON(http.StatusOK, gin.H{"data": adaptiveText})
 }
 
 func (tc *TextController) FindTextById(c *gin.Context) {
@@ -69,7 +128,13 @@ func (tc *TextController) FindTextById(c *gin.Context) {
 		return
 	}
 
-	text, err := tc.textService.FindTextById(c.Request.Context(), textId)
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err), tc.logger)
+		return
+	}
+
+	text, err := tc.textService.FindTextById(c.Request.Context(), user, textId)
 	if err != nil {
 		utils.WriteError(c, errors.E(op, err), tc.logger)
 		return
@@ -100,3 +165,21 @@ func (tc *TextController) CreateText(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"data": text})
 }
+
+// bindFindNewTextSortOption binds the sort_by query. Only a single sort option is supported, which defaults to created_at.desc.
+func bindFindNewTextSortOption(c *gin.Context) (models.SortOption, error) {
+	const op errors.Op = "controllers.bindFindNewTextSortOption"
+
+	sortOptions, err := models.BindSortByQuery(c, FindNewTextSortOption{})
+	switch {
+	case err != nil:
+		return models.SortOption{}, errors.E(op, err)
+	case len(sortOptions) > 1:
+		err := fmt.Errorf("only a single sort_by query is supported")
+		return models.SortOption{}, errors.E(op, err)
+	case len(sortOptions) == 0:
+		return models.SortOption{Column: "created_at", Order: "desc"}, nil
+	}
+
+	return sortOptions[0], nil
+}
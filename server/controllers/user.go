@@ -12,20 +12,43 @@ import (
 )
 
 type CreateUserInput struct {
-	Email     string `json:"email" binding:"required,email"`
-	Username  string `json:"username" binding:"required,min=3,max=255"`
-	Password  string `json:"password" binding:"omitempty,min=6,max=255"`
-	FirstName string `json:"firstName" binding:"omitempty,min=3,max=255"`
-	LastName  string `json:"lastName" binding:"omitempty,min=3,max=255"`
+	Email          string `json:"email" binding:"required,email"`
+	Username       string `json:"username" binding:"required,min=3,max=255"`
+	Password       string `json:"password" binding:"omitempty,min=6,max=255"`
+	FirstName      string `json:"firstName" binding:"omitempty,min=3,max=255"`
+	LastName       string `json:"lastName" binding:"omitempty,min=3,max=255"`
+	KeyboardLayout string `json:"keyboardLayout" binding:"omitempty,keyboardlayout"`
 }
 
+type FindAllUsersQuery struct {
+	Role   models.UserRole `form:"role" binding:"omitempty,oneof=user moderator admin"`
+	Banned *bool           `form:"banned"`
+	Limit  int             `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int             `form:"offset" binding:"omitempty,min=0"`
+}
+
+type UpdateUserRoleInput struct {
+	Role models.UserRole `json:"role" binding:"required,oneof=user moderator admin"`
+}
+
+type UpdateUserBannedInput struct {
+	Banned *bool `json:"banned" binding:"required"`
+}
+
+type UpdateUserKeyboardLayoutInput struct {
+	KeyboardLayout string `json:"keyboardLayout" binding:"required,keyboardlayout"`
+}
+
+const defaultFindAllUsersLimit = 50
+
 type UserController struct {
-	userService *services.UserService
-	logger      common.Logger
+	userService  *services.UserService
+	cookieConfig models.CookieConfig
+	logger       common.Logger
 }
 
-func NewUserController(userService *services.UserService, logger common.Logger) *UserController {
-	return &UserController{userService, logger}
+func NewUserController(userService *services.UserService, cookieConfig models.CookieConfig, logger common.Logger) *UserController {
+	return &UserController{userService, cookieConfig, logger}
 }
 
 func (uc *UserController) FindUsers(c *gin.Context) {
@@ -66,12 +89,128 @@ func (uc *UserController) FindUser(c *gin.Context) {
 		return
 	}
 
+	authenticatedUser, err := utils.GetUserFromContext(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), uc.logger)
+		return
+	}
+
 	user, err := uc.userService.FindUserById(c.Request.Context(), userId)
 	if err != nil {
 		utils.WriteError(c, errors.E(op, err), uc.logger)
 		return
 	}
 
+	users := []models.User{*user}
+	stripSensitiveUserInformation(users, authenticatedUser)
+
+	c.JSON(http.StatusOK, gin.H{"data": users[0]})
+}
+
+func (uc *UserController) FindAllUsers(c *gin.Context) {
+	const op errors.Op = "controllers.UserController.FindAllUsers"
+	var query FindAllUsersQuery
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), uc.logger)
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultFindAllUsersLimit
+	}
+
+	filter := models.UserFilter{Role: query.Role, Banned: query.Banned}
+	users, total, err := uc.userService.FindAllUsers(c.Request.Context(), filter, query.Limit, query.Offset)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err), uc.logger)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": users,
+		"pagination": gin.H{
+			"total":  total,
+			"limit":  query.Limit,
+			"offset": query.Offset,
+		},
+	})
+}
+
+func (uc *UserController) UpdateUserRole(c *gin.Context) {
+	const op errors.Op = "controllers.UserController.UpdateUserRole"
+	var input UpdateUserRoleInput
+
+	userId, err := utils.GetUserIdFromPath(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), uc.logger)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), uc.logger)
+		return
+	}
+
+	authenticatedUser, err := utils.GetUserFromContext(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), uc.logger)
+		return
+	}
+
+	user, err := uc.userService.UpdateUserRole(c.Request.Context(), userId, input.Role, *authenticatedUser)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err), uc.logger)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": user})
+}
+
+func (uc *UserController) UpdateUserBanned(c *gin.Context) {
+	const op errors.Op = "controllers.UserController.UpdateUserBanned"
+	var input UpdateUserBannedInput
+
+	userId, err := utils.GetUserIdFromPath(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), uc.logger)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), uc.logger)
+		return
+	}
+
+	user, err := uc.userService.UpdateUserBanned(c.Request.Context(), userId, *input.Banned)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err), uc.logger)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": user})
+}
+
+func (uc *UserController) UpdateUserKeyboardLayout(c *gin.Context) {
+	const op errors.Op = "controllers.UserController.UpdateUserKeyboardLayout"
+	var input UpdateUserKeyboardLayoutInput
+
+	userId, err := utils.GetUserIdFromPath(c)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), uc.logger)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.WriteError(c, errors.E(op, err, http.StatusBadRequest), uc.logger)
+		return
+	}
+
+	user, err := uc.userService.UpdateUserKeyboardLayout(c.Request.Context(), userId, input.KeyboardLayout)
+	if err != nil {
+		utils.WriteError(c, errors.E(op, err), uc.logger)
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"data": user})
 }
 
@@ -84,7 +223,7 @@ func (uc *UserController) CreateUser(c *gin.Context) {
 		return
 	}
 
-	user, err := uc.userService.Create(c.Request.Context(), input.Email, input.Username, input.FirstName, input.LastName, input.Password)
+	user, err := uc.userService.Create(c.Request.Context(), input.Email, input.Username, input.FirstName, input.LastName, input.Password, input.KeyboardLayout)
 	if err != nil {
 		utils.WriteError(c, errors.E(op, err), uc.logger)
 		return
@@ -105,13 +244,13 @@ func (uc *UserController) Login(c *gin.Context) {
 		return
 	}
 
-	user, sessionToken, err := uc.userService.Login(c.Request.Context(), input.Email, input.Password)
+	user, sessionToken, err := uc.userService.Login(c.Request.Context(), input.Email, input.Password, c.ClientIP())
 	if err != nil {
 		utils.WriteError(c, errors.E(op, err), uc.logger)
 		return
 	}
 
-	utils.SetCookie(c.Writer, models.CookieSession, sessionToken)
+	utils.SetCookie(c.Writer, models.CookieSession, sessionToken, uc.cookieConfig)
 	c.JSON(http.StatusOK, gin.H{"data": user})
 }
 
@@ -130,7 +269,7 @@ func (uc *UserController) Logout(c *gin.Context) {
 		return
 	}
 
-	utils.DeleteCookie(c.Writer, models.CookieSession)
+	utils.DeleteCookie(c.Writer, models.CookieSession, uc.cookieConfig)
 	c.JSON(http.StatusOK, gin.H{"data": "Successfully logged out"})
 }
 
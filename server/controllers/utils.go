@@ -1,13 +1,33 @@
 package controllers
 
 import (
+	"10-typing/errors"
+	"10-typing/keyboard"
 	"10-typing/models"
+	"net/http"
 )
 
-// strips sensitive user information from users ex
-func stripSensitiveUserInformation(users []models.User, exception *models.User) {
+// validateTypingErrors returns an error with the status 400 if the user can't have typed a key of the errors
+// with the keyboard layout of the user or with the layout of the language of the text
+func validateTypingErrors(keyErrors models.ErrorsJSON, user *models.User, text *models.Text) error {
+	const op errors.Op = "controllers.validateTypingErrors"
+
+	if err := models.ValidateTypingErrorsForLayouts(keyErrors, user.KeyboardLayout, keyboard.LayoutNameForLanguage(text.Language)); err != nil {
+		return errors.E(op, err, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// strips sensitive user information from users except from the authenticated user.
+// Moderators and admins see the information of all users.
+func stripSensitiveUserInformation(users []models.User, authenticatedUser *models.User) {
+	if authenticatedUser != nil && authenticatedUser.HasRole(models.ModeratorUserRole) {
+		return
+	}
+
 	for i := range users {
-		if exception != nil && users[i].ID == exception.ID {
+		if authenticatedUser != nil && users[i].ID == authenticatedUser.ID {
 			continue
 		}
 
@@ -15,5 +35,6 @@ func stripSensitiveUserInformation(users []models.User, exception *models.User)
 		users[i].Email = ""
 		users[i].LastName = ""
 		users[i].IsVerified = false
+		users[i].BannedAt = nil
 	}
 }
@@ -0,0 +1,218 @@
+package e2e
+
+import (
+	"10-typing/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"nhooyr.io/websocket"
+	"nhooyr.io/websocket/wsjson"
+)
+
+const clientPassword = "password"
+
+// client is a simulated user that calls the API over HTTP and receives the push messages of a room over a WebSocket connection
+type client struct {
+	user       models.User
+	harness    *harness
+	httpClient *http.Client
+	conn       *websocket.Conn
+	messages   chan pushMessage
+	readErr    error
+}
+
+// pushMessage is a push message as the frontend receives it
+type pushMessage struct {
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+func (m pushMessage) String() string {
+	return m.Type + " " + string(m.Payload)
+}
+
+// newClient registers and verifies a user and logs the user in. The WebSocket connection of the client is closed when the test has finished.
+func (h *harness) newClient(t *testing.T, ctx context.Context, username string) *client {
+	t.Helper()
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatalf("cookiejar.New() error = %v", err)
+	}
+
+	c := &client{
+		harness:    h,
+		httpClient: &http.Client{Jar: jar},
+	}
+	t.Cleanup(c.close)
+
+	email := username + "@example.com"
+	if err := c.do(ctx, http.MethodPost, "/api/users", map[string]string{
+		"email":    email,
+		"username": username,
+		"password": clientPassword,
+	}, &c.user); err != nil {
+		t.Fatalf("registering %s error = %v", username, err)
+	}
+
+	if err := h.dbRepo.VerifyUserAndCache(ctx, nil, h.cacheRepo, c.user.ID); err != nil {
+		t.Fatalf("VerifyUserAndCache() error = %v", err)
+	}
+
+	if err := c.do(ctx, http.MethodPost, "/api/user/login", map[string]string{
+		"email":    email,
+		"password": clientPassword,
+	}, &c.user); err != nil {
+		t.Fatalf("logging in %s error = %v", username, err)
+	}
+
+	return c
+}
+
+func (c *client) createRoom(t *testing.T, ctx context.Context, userIds []uuid.UUID, gameDurationSec int) *models.Room {
+	t.Helper()
+	var room models.Room
+
+	if err := c.do(ctx, http.MethodPost, "/api/rooms", map[string]any{
+		"userIds":         userIds,
+		"gameDurationSec": gameDurationSec,
+	}, &room); err != nil {
+		t.Fatalf("%s creating the room error = %v", c.user.Username, err)
+	}
+
+	return &room
+}
+
+func (c *client) createGame(t *testing.T, ctx context.Context, roomId, textId uuid.UUID) {
+	t.Helper()
+
+	if err := c.do(ctx, http.MethodPost, "/api/rooms/"+roomId.String()+"/game", map[string]any{
+		"textId": textId,
+	}, nil); err != nil {
+		t.Fatalf("%s creating the game error = %v", c.user.Username, err)
+	}
+}
+
+func (c *client) startGame(t *testing.T, ctx context.Context, roomId uuid.UUID) {
+	t.Helper()
+
+	if err := c.do(ctx, http.MethodPost, "/api/rooms/"+roomId.String()+"/start-game", nil, nil); err != nil {
+		t.Fatalf("%s starting the game error = %v", c.user.Username, err)
+	}
+}
+
+func (c *client) finishGame(t *testing.T, ctx context.Context, roomId, textId uuid.UUID, wordsTyped int, timeElapsed float64, errorsJSON models.ErrorsJSON) {
+	t.Helper()
+
+	if err := c.do(ctx, http.MethodPost, "/api/rooms/"+roomId.String()+"/current-game/score", map[string]any{
+		"textId":      textId,
+		"wordsTyped":  wordsTyped,
+		"timeElapsed": timeElapsed,
+		"errors":      errorsJSON,
+	}, nil); err != nil {
+		t.Fatalf("%s finishing the game error = %v", c.user.Username, err)
+	}
+}
+
+// connect opens the WebSocket connection to the room and starts to receive its push messages
+func (c *client) connect(t *testing.T, ctx context.Context, roomId uuid.UUID) {
+	t.Helper()
+
+	url := "ws" + strings.TrimPrefix(c.harness.server.URL, "http") + "/api/rooms/" + roomId.String() + "/ws"
+	conn, _, err := websocket.Dial(ctx, url, &websocket.DialOptions{HTTPClient: c.httpClient})
+	if err != nil {
+		t.Fatalf("%s connecting to the room error = %v", c.user.Username, err)
+	}
+
+	c.conn = conn
+	c.messages = make(chan pushMessage, 100)
+
+	go func() {
+		defer close(c.messages)
+
+		for {
+			var message pushMessage
+			if err := wsjson.Read(context.Background(), conn, &message); err != nil {
+				c.readErr = err
+				return
+			}
+
+			c.messages <- message
+		}
+	}()
+}
+
+// close closes the WebSocket connection
+func (c *client) close() {
+	if c.conn != nil {
+		c.conn.Close(websocket.StatusNormalClosure, "")
+	}
+}
+
+// next returns the next push message that the client has received
+func (c *client) next(ctx context.Context) (pushMessage, error) {
+	select {
+	case <-ctx.Done():
+		return pushMessage{}, ctx.Err()
+	case message, ok := <-c.messages:
+		if !ok {
+			return pushMessage{}, fmt.Errorf("connection closed: %w", c.readErr)
+		}
+
+		return message, nil
+	}
+}
+
+// do sends the JSON of the body to the API and decodes the data of the response into out, if out is not nil
+func (c *client) do(ctx context.Context, method, path string, body any, out any) error {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		bodyReader = bytes.NewReader(bodyBytes)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.harness.server.URL+path, bodyReader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: %d %s", method, path, res.StatusCode, resBody)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	var data struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(resBody, &data); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data.Data, out)
+}
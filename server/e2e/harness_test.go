@@ -0,0 +1,94 @@
+package e2e
+
+import (
+	"10-typing/app"
+	"10-typing/clock"
+	"10-typing/common"
+	"10-typing/models"
+	local_text_repo "10-typing/repositories/local_text"
+	memory_cache_repo "10-typing/repositories/memory_cache"
+	memory_db_repo "10-typing/repositories/memory_db"
+	"10-typing/zerologger"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// harness serves the API with the in-memory repositories on a local port, so that simulated clients can play games end to end.
+// The services and repositories share a fake clock, which the tests advance instead of waiting for countdowns and game durations.
+type harness struct {
+	clock     *clock.FakeClock
+	dbRepo    *memory_db_repo.MemoryDBRepository
+	cacheRepo *memory_cache_repo.MemoryCacheRepository
+	server    *httptest.Server
+}
+
+// newHarness starts the server and shuts it down when the test has finished.
+// The server logs are printed in verbose mode.
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	var logger common.Logger = zerologger.New(io.Discard)
+	if testing.Verbose() {
+		logger = zerologger.New(os.Stderr)
+	}
+
+	fakeClock := clock.NewFake(time.Now())
+	dbRepo := memory_db_repo.NewMemoryDBRepository(fakeClock)
+	cacheRepo := memory_cache_repo.NewMemoryCacheRepository(fakeClock)
+
+	application := app.New(app.Repositories{
+		DB:               dbRepo,
+		Cache:            cacheRepo,
+		EmailTransaction: &emailTransactionRepository{},
+		OpenAi:           local_text_repo.NewLocalTextRepository(1),
+	}, app.Config{
+		AllowedOrigins: []string{"http://localhost:3000"},
+		// the test server is served over http
+		Cookie: models.CookieConfig{Secure: false, SameSite: http.SameSiteLaxMode},
+	}, fakeClock, logger)
+
+	h := &harness{
+		clock:     fakeClock,
+		dbRepo:    dbRepo,
+		cacheRepo: cacheRepo,
+		server:    httptest.NewServer(application.Router),
+	}
+	// the server blocks until all requests have been handled, so the cleanups of the clients, which are registered later, close their WebSocket connections before
+	t.Cleanup(h.server.Close)
+
+	return h
+}
+
+func (h *harness) createText(t *testing.T, ctx context.Context, text string) *models.Text {
+	t.Helper()
+
+	createdText, err := h.dbRepo.CreateTextAndCache(ctx, nil, h.cacheRepo, models.Text{
+		Language: "en",
+		Text:     text,
+	})
+	if err != nil {
+		t.Fatalf("CreateTextAndCache() error = %v", err)
+	}
+
+	return createdText
+}
+
+// emailTransactionRepository doesn't send emails, since all users of the tests are registered
+type emailTransactionRepository struct{}
+
+func (r *emailTransactionRepository) InviteNewUserToRoom(email string, token uuid.UUID) error {
+	return nil
+}
+
+func (r *emailTransactionRepository) InviteUserToRoom(email, username string) error {
+	return nil
+}
@@ -0,0 +1,208 @@
+package e2e
+
+import (
+	"10-typing/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	raceGameDurationSec        = 30
+	raceCountdownDuration      = 5 * time.Second
+	raceWaitForResultsDuration = 10 * time.Second
+)
+
+// expectedMessage is a push message that a client must receive. A nil payload matches any payload.
+type expectedMessage struct {
+	typ     string
+	payload any
+}
+
+func (m expectedMessage) String() string {
+	if m.payload == nil {
+		return m.typ + " *"
+	}
+
+	payload, _ := json.Marshal(m.payload)
+	return m.typ + " " + string(payload)
+}
+
+func (m expectedMessage) matches(message pushMessage) bool {
+	if m.typ != message.Type {
+		return false
+	}
+	if m.payload == nil {
+		return true
+	}
+
+	payload, err := json.Marshal(m.payload)
+	if err != nil {
+		return false
+	}
+
+	var compactPayload bytes.Buffer
+	if err := json.Compact(&compactPayload, message.Payload); err != nil {
+		return false
+	}
+
+	return bytes.Equal(payload, compactPayload.Bytes())
+}
+
+// TestRace plays a game of two users from the creation of the room to the game result
+// and asserts that both users receive exactly the expected push messages during the game
+func TestRace(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	h := newHarness(t)
+	text := h.createText(t, ctx, "the quick brown fox jumps over the lazy dog")
+	alice := h.newClient(t, ctx, "alice")
+	bob := h.newClient(t, ctx, "bob")
+	clients := []*client{alice, bob}
+	room := alice.createRoom(t, ctx, []uuid.UUID{bob.user.ID}, raceGameDurationSec)
+
+	// the steps depend on each other, so the race stops at the first failed step
+	steps := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "connect",
+			run: func(t *testing.T) {
+				// the initial state and the user_joined message of a connection are sent concurrently, so their order is not asserted
+				alice.connect(t, ctx, room.ID)
+				expectUnordered(t, ctx, alice, expectedMessage{"initial_state", nil}, expectedMessage{"user_joined", alice.user.ID})
+				// the subscription of a connection starts at the millisecond in which the connection is opened, so the time must move on
+				// for bob to not receive the messages that have been published before
+				h.clock.Advance(time.Millisecond)
+				bob.connect(t, ctx, room.ID)
+				expectUnordered(t, ctx, bob, expectedMessage{"initial_state", nil}, expectedMessage{"user_joined", bob.user.ID})
+				expectUnordered(t, ctx, alice, expectedMessage{"user_joined", bob.user.ID})
+			},
+		},
+		{
+			name: "countdown",
+			run: func(t *testing.T) {
+				// from here on all push messages are published in a deterministic order
+				alice.createGame(t, ctx, room.ID, text.ID)
+				alice.startGame(t, ctx, room.ID)
+				bob.startGame(t, ctx, room.ID)
+				expect(t, ctx, clients,
+					expectedMessage{"new_game", nil},
+					expectedMessage{"user_started_game", alice.user.ID},
+					expectedMessage{"user_started_game", bob.user.ID},
+					expectedMessage{"countdown", 5},
+				)
+
+				// the countdown waits for one second per step while the game waits for the whole countdown
+				for i := 4; i >= 0; i-- {
+					if err := h.clock.BlockUntilTimers(ctx, raceCountdownDuration, 2); err != nil {
+						t.Fatalf("waiting for the countdown: %v", err)
+					}
+					h.clock.Advance(time.Second)
+
+					next := expectedMessage{"countdown", i}
+					if i == 0 {
+						next = expectedMessage{"game_started", nil}
+					}
+					expect(t, ctx, clients, next)
+				}
+			},
+		},
+		{
+			name: "finish",
+			run: func(t *testing.T) {
+				alice.finishGame(t, ctx, room.ID, text.ID, 9, 4, models.ErrorsJSON{"q": 1})
+				expect(t, ctx, clients, expectedMessage{"user_finished_game", alice.user.ID})
+				bob.finishGame(t, ctx, room.ID, text.ID, 9, 6, models.ErrorsJSON{})
+				expect(t, ctx, clients, expectedMessage{"user_finished_game", bob.user.ID})
+			},
+		},
+		{
+			name: "results",
+			run: func(t *testing.T) {
+				// the results are collected after the game duration, for which the game waits even if all users have finished
+				if err := h.clock.BlockUntilTimers(ctx, raceGameDurationSec*time.Second, 1); err != nil {
+					t.Fatalf("waiting for the game: %v", err)
+				}
+				h.clock.Advance(raceGameDurationSec * time.Second)
+
+				// the results of the users that finished during the game have already been received, so the game waits until the results timeout
+				if err := h.clock.BlockUntilTimers(ctx, raceWaitForResultsDuration, 1); err != nil {
+					t.Fatalf("waiting for the results: %v", err)
+				}
+				h.clock.Advance(raceWaitForResultsDuration)
+
+				for _, c := range clients {
+					message, err := c.next(ctx)
+					if err != nil {
+						t.Fatalf("%s did not receive game_result: %v", c.user.Username, err)
+					}
+					if !(expectedMessage{"game_result", nil}).matches(message) {
+						t.Fatalf("%s received %s, want game_result", c.user.Username, message)
+					}
+
+					var scores []models.Score
+					if err := json.Unmarshal(message.Payload, &scores); err != nil {
+						t.Fatalf("unmarshal game_result error = %v", err)
+					}
+					// alice typed 135 words per minute and bob 90
+					if len(scores) != 2 || scores[0].UserId != alice.user.ID || scores[1].UserId != bob.user.ID {
+						t.Errorf("%s received game_result with unexpected scores %s", c.user.Username, message.Payload)
+					}
+				}
+			},
+		},
+	}
+
+	for _, step := range steps {
+		if !t.Run(step.name, step.run) {
+			t.FailNow()
+		}
+	}
+}
+
+// expect asserts that each client receives exactly the expected messages in their order
+func expect(t *testing.T, ctx context.Context, clients []*client, expected ...expectedMessage) {
+	t.Helper()
+
+	for _, c := range clients {
+		for i, e := range expected {
+			message, err := c.next(ctx)
+			if err != nil {
+				t.Fatalf("%s did not receive %s: %v", c.user.Username, e, err)
+			}
+
+			if !e.matches(message) {
+				t.Fatalf("%s received %s as message %d of %v, want %s", c.user.Username, message, i+1, expected, e)
+			}
+		}
+	}
+}
+
+// expectUnordered asserts that the client receives exactly the expected messages in any order
+func expectUnordered(t *testing.T, ctx context.Context, c *client, expected ...expectedMessage) {
+	t.Helper()
+
+	remaining := append([]expectedMessage(nil), expected...)
+	for len(remaining) > 0 {
+		message, err := c.next(ctx)
+		if err != nil {
+			t.Fatalf("%s did not receive %v: %v", c.user.Username, remaining, err)
+		}
+
+		i := 0
+		for i < len(remaining) && !remaining[i].matches(message) {
+			i++
+		}
+		if i == len(remaining) {
+			t.Fatalf("%s received %s, want one of %v", c.user.Username, message, remaining)
+		}
+		remaining = append(remaining[:i], remaining[i+1:]...)
+	}
+}
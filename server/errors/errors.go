@@ -123,6 +123,50 @@ func (e *Error) Unwrap() error {
 	return e.err
 }
 
+// Ops returns the operations of the chain of errors, starting with the outermost operation
+func Ops(err error) []Op {
+	var ops []Op
+
+	for e := (*Error)(nil); As(err, &e); err = e.err {
+		ops = append(ops, e.op)
+	}
+
+	return ops
+}
+
+// Cause returns the innermost error of the chain of errors, which is not an *Error
+func Cause(err error) error {
+	for e := (*Error)(nil); As(err, &e); err = e.err {
+		if e.err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
+// Username returns the first username of the chain of errors
+func Username(err error) string {
+	for e := (*Error)(nil); As(err, &e); err = e.err {
+		if e.username != "" {
+			return e.username
+		}
+	}
+
+	return ""
+}
+
+// Where returns the first location of the chain of errors, which is only recorded in development
+func Where(err error) string {
+	for e := (*Error)(nil); As(err, &e); err = e.err {
+		if e.where != "" {
+			return e.where
+		}
+	}
+
+	return ""
+}
+
 func Is(err, target error) bool {
 	return errors.Is(err, target)
 }
@@ -0,0 +1,143 @@
+package keyboard
+
+import "sort"
+
+type Finger string
+
+const (
+	LeftPinky   Finger = "left_pinky"
+	LeftRing    Finger = "left_ring"
+	LeftMiddle  Finger = "left_middle"
+	LeftIndex   Finger = "left_index"
+	Thumb       Finger = "thumb"
+	RightIndex  Finger = "right_index"
+	RightMiddle Finger = "right_middle"
+	RightRing   Finger = "right_ring"
+	RightPinky  Finger = "right_pinky"
+)
+
+var Fingers = []Finger{LeftPinky, LeftRing, LeftMiddle, LeftIndex, Thumb, RightIndex, RightMiddle, RightRing, RightPinky}
+
+type Hand string
+
+const (
+	LeftHand  Hand = "left"
+	RightHand Hand = "right"
+	BothHands Hand = "both"
+)
+
+// Hand returns the hand the finger belongs to. Thumbs belong to both hands because the space bar can be pressed with either of them.
+func (f Finger) Hand() Hand {
+	switch f {
+	case LeftPinky, LeftRing, LeftMiddle, LeftIndex:
+		return LeftHand
+	case RightIndex, RightMiddle, RightRing, RightPinky:
+		return RightHand
+	default:
+		return BothHands
+	}
+}
+
+type Row string
+
+const (
+	NumberRow Row = "number"
+	TopRow    Row = "top"
+	HomeRow   Row = "home"
+	BottomRow Row = "bottom"
+	SpaceRow  Row = "space"
+)
+
+var Rows = []Row{NumberRow, TopRow, HomeRow, BottomRow, SpaceRow}
+
+// Modifier is what needs to be pressed in addition to the key to type a character
+type Modifier string
+
+const (
+	NoModifier      Modifier = "none"
+	ShiftModifier   Modifier = "shift"
+	AltGrModifier   Modifier = "alt_gr"
+	DeadKeyModifier Modifier = "dead_key"
+	// CapsLockModifier is used for the characters that can only be typed with caps lock, e.g. the upper case accented letters of AZERTY
+	CapsLockModifier Modifier = "caps_lock"
+)
+
+// KeyPosition describes where a character is typed on a keyboard and with which finger
+type KeyPosition struct {
+	Finger   Finger   `json:"finger"`
+	Hand     Hand     `json:"hand"`
+	Row      Row      `json:"row"`
+	Modifier Modifier `json:"modifier"`
+}
+
+type Layout struct {
+	Name      string
+	positions map[string]KeyPosition
+}
+
+// Position returns the position of the key that types the character
+func (l *Layout) Position(char string) (KeyPosition, bool) {
+	keyPosition, ok := l.positions[char]
+	return keyPosition, ok
+}
+
+// IsValidKey reports whether the character can be typed with the layout
+func (l *Layout) IsValidKey(char string) bool {
+	_, ok := l.positions[char]
+	return ok
+}
+
+// Keys returns all characters that can be typed with the layout in sorted order
+func (l *Layout) Keys() []string {
+	keys := make([]string, 0, len(l.positions))
+	for key := range l.positions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+const DefaultLayoutName = "qwerty"
+
+var layouts = map[string]*Layout{
+	"qwerty": newLayout("qwerty", qwertyRows, nil, nil, nil),
+	"qwertz": newLayout("qwertz", qwertzRows, qwertzAltGr, nil, nil),
+	"azerty": newLayout("azerty", azertyRows, azertyAltGr, azertyDeadKeys, azertyCapsLock),
+	"dvorak": newLayout("dvorak", dvorakRows, nil, nil, nil),
+}
+
+// LayoutNames holds the names of all supported layouts
+var LayoutNames = []string{"qwerty", "qwertz", "azerty", "dvorak"}
+
+// languageLayoutNames maps the languages of the texts to the layout with which they are usually typed
+var languageLayoutNames = map[string]string{
+	"en": "qwerty",
+	"de": "qwertz",
+	"fr": "azerty",
+}
+
+// LayoutNameForLanguage returns the name of the layout with which texts of the language are usually typed or the default layout for unknown languages
+func LayoutNameForLanguage(language string) string {
+	if layoutName, ok := languageLayoutNames[language]; ok {
+		return layoutName
+	}
+
+	return DefaultLayoutName
+}
+
+func GetLayout(name string) (*Layout, bool) {
+	layout, ok := layouts[name]
+	return layout, ok
+}
+
+// IsValidKeyInAnyLayout reports whether the character can be typed with at least one of the supported layouts
+func IsValidKeyInAnyLayout(char string) bool {
+	for _, layout := range layouts {
+		if layout.IsValidKey(char) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,117 @@
+package keyboard
+
+// rowDefinition defines the characters of a physical keyboard row. The characters at the same index of base and shift
+// are typed with the same key; a space in shift means that the key types no character together with shift.
+type rowDefinition struct {
+	row   Row
+	base  string
+	shift string
+}
+
+// the fingers that type the keys of the physical rows of an ISO keyboard, starting with the left most key of the row.
+// The number row starts with the key left of 1, the top row with the key of Q on a QWERTY layout,
+// the home row with the key of A and the bottom row with the key left of Z.
+var rowFingers = map[Row][]Finger{
+	NumberRow: {LeftPinky, LeftPinky, LeftRing, LeftMiddle, LeftIndex, LeftIndex, RightIndex, RightIndex, RightMiddle, RightRing, RightPinky, RightPinky, RightPinky},
+	TopRow:    {LeftPinky, LeftRing, LeftMiddle, LeftIndex, LeftIndex, RightIndex, RightIndex, RightMiddle, RightRing, RightPinky, RightPinky, RightPinky, RightPinky},
+	HomeRow:   {LeftPinky, LeftRing, LeftMiddle, LeftIndex, LeftIndex, RightIndex, RightIndex, RightMiddle, RightRing, RightPinky, RightPinky, RightPinky},
+	BottomRow: {LeftPinky, LeftPinky, LeftRing, LeftMiddle, LeftIndex, LeftIndex, RightIndex, RightIndex, RightMiddle, RightRing, RightPinky},
+}
+
+// ANSI keyboards have no key left of Z, so the bottom rows of the US layouts start with a space
+var qwertyRows = []rowDefinition{
+	{NumberRow, "`1234567890-=", "~!@#$%^&*()_+"},
+	{TopRow, "qwertyuiop[]\\", "QWERTYUIOP{}|"},
+	{HomeRow, "asdfghjkl;'", "ASDFGHJKL:\""},
+	{BottomRow, " zxcvbnm,./", " ZXCVBNM<>?"},
+}
+
+var qwertzRows = []rowDefinition{
+	{NumberRow, "^1234567890ß´", "°!\"§$%&/()=?`"},
+	{TopRow, "qwertzuiopü+", "QWERTZUIOPÜ*"},
+	{HomeRow, "asdfghjklöä#", "ASDFGHJKLÖÄ'"},
+	{BottomRow, "<yxcvbnm,.-", ">YXCVBNM;:_"},
+}
+
+// qwertzAltGr maps the characters that are typed with AltGr to the base character of their key
+var qwertzAltGr = map[string]string{
+	"²": "2", "³": "3", "{": "7", "[": "8", "]": "9", "}": "0", "\\": "ß",
+	"@": "q", "€": "e", "~": "+", "|": "<", "µ": "m",
+}
+
+var azertyRows = []rowDefinition{
+	{NumberRow, "²&é\"'(-è_çà)=", " 1234567890°+"},
+	{TopRow, "azertyuiop^$", "AZERTYUIOP¨£"},
+	{HomeRow, "qsdfghjklmù*", "QSDFGHJKLM%µ"},
+	{BottomRow, "<wxcvbn,;:!", ">WXCVBN?./§"},
+}
+
+var azertyAltGr = map[string]string{
+	"~": "é", "#": "\"", "{": "'", "[": "(", "|": "-", "`": "è", "\\": "_", "@": "à", "]": ")", "}": "=", "€": "e",
+}
+
+// azertyDeadKeys maps the characters that are typed with the ^ or ¨ dead key to the key of their letter
+var azertyDeadKeys = map[string]string{
+	"â": "a", "ê": "e", "î": "i", "ô": "o", "û": "u",
+	"Â": "A", "Ê": "E", "Î": "I", "Ô": "O", "Û": "U",
+	"ä": "a", "ë": "e", "ï": "i", "ö": "o", "ü": "u", "ÿ": "y",
+	"Ä": "A", "Ë": "E", "Ï": "I", "Ö": "O", "Ü": "U",
+}
+
+// azertyCapsLock maps the upper case accented letters, which have no key of their own, to the key of their lower case letter.
+// They are typed with caps lock, e.g. the É of "État".
+var azertyCapsLock = map[string]string{
+	"É": "é", "È": "è", "À": "à", "Ç": "ç", "Ù": "ù",
+}
+
+var dvorakRows = []rowDefinition{
+	{NumberRow, "`1234567890[]", "~!@#$%^&*(){}"},
+	{TopRow, "',.pyfgcrl/=\\", "\"<>PYFGCRL?+|"},
+	{HomeRow, "aoeuidhtns-", "AOEUIDHTNS_"},
+	{BottomRow, " ;qjkxbmwvz", " :QJKXBMWVZ"},
+}
+
+func newLayout(name string, rows []rowDefinition, altGr, deadKeys, capsLock map[string]string) *Layout {
+	// tab and enter have the same position on all ISO layouts. Enter spans the top and the home row and is typed on the home row.
+	positions := map[string]KeyPosition{
+		" ":  {Finger: Thumb, Hand: Thumb.Hand(), Row: SpaceRow, Modifier: NoModifier},
+		"\t": {Finger: LeftPinky, Hand: LeftPinky.Hand(), Row: TopRow, Modifier: NoModifier},
+		"\n": {Finger: RightPinky, Hand: RightPinky.Hand(), Row: HomeRow, Modifier: NoModifier},
+	}
+
+	for _, rowDefinition := range rows {
+		fingers := rowFingers[rowDefinition.row]
+		shiftChars := []rune(rowDefinition.shift)
+
+		for i, baseChar := range []rune(rowDefinition.base) {
+			finger := fingers[i]
+
+			if baseChar != ' ' {
+				positions[string(baseChar)] = KeyPosition{Finger: finger, Hand: finger.Hand(), Row: rowDefinition.row, Modifier: NoModifier}
+			}
+			if i < len(shiftChars) && shiftChars[i] != ' ' {
+				positions[string(shiftChars[i])] = KeyPosition{Finger: finger, Hand: finger.Hand(), Row: rowDefinition.row, Modifier: ShiftModifier}
+			}
+		}
+	}
+
+	for char, baseChar := range altGr {
+		keyPosition := positions[baseChar]
+		keyPosition.Modifier = AltGrModifier
+		positions[char] = keyPosition
+	}
+
+	for char, letter := range deadKeys {
+		keyPosition := positions[letter]
+		keyPosition.Modifier = DeadKeyModifier
+		positions[char] = keyPosition
+	}
+
+	for char, letter := range capsLock {
+		keyPosition := positions[letter]
+		keyPosition.Modifier = CapsLockModifier
+		positions[char] = keyPosition
+	}
+
+	return &Layout{Name: name, positions: positions}
+}
@@ -1,26 +1,35 @@
 package main
 
 import (
-	"10-typing/controllers"
-	"10-typing/middlewares"
+	"10-typing/app"
+	"10-typing/clock"
+	"10-typing/common"
+	"10-typing/metrics"
 	email_transaction_repo "10-typing/repositories/email_transaction"
+	local_text_repo "10-typing/repositories/local_text"
 	open_ai_repo "10-typing/repositories/open_ai"
 	redis_repo "10-typing/repositories/redis"
 	sql_repo "10-typing/repositories/sql"
+	"10-typing/tracing"
 	"10-typing/zerologger"
+	"context"
+	"net"
+	"net/http"
+	"os/signal"
 	"runtime"
+	"syscall"
 
 	"10-typing/models"
-	"10-typing/services"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/gin-contrib/cors"
-	"github.com/gin-gonic/gin"
-	"github.com/gin-gonic/gin/binding"
-	"github.com/go-playground/validator/v10"
 	"github.com/joho/godotenv"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 func main() {
@@ -43,90 +52,254 @@ func main() {
 	multi := zerolog.MultiLevelWriter(consoleWriter, logFile)
 	logger := zerologger.New(multi)
 
+	// the log level can be changed at runtime with PUT /api/admin/log-level
+	logLevel := os.Getenv("LOG_LEVEL")
+	if logLevel == "" {
+		logLevel = "info"
+	}
+	if err := logger.SetLevel(logLevel); err != nil {
+		panic("Error setting LOG_LEVEL: >> " + err.Error())
+	}
+
 	logger.Info("GOMAXPROCS: >> ", runtime.GOMAXPROCS(0))
 
-	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
-		v.RegisterValidation("typingerrors", models.TypingErrors)
-	}
-
-	// Setup repos
-	dbRepo := sql_repo.NewSQLRepository(models.DB)
-	cacheRepo := redis_repo.NewRedisRepository(models.RedisClient)
-	emailTransactionRepo := email_transaction_repo.NewEmailTransactionRepository(os.Getenv("POSTMARK_API_KEY"))
-	openAiRepo := open_ai_repo.NewOpenAiRepository(os.Getenv("OPENAI_API_KEY"))
-
-	// Setup services
-	gameService := services.NewGameService(dbRepo, cacheRepo, logger)
-	roomService := services.NewRoomService(dbRepo, cacheRepo, emailTransactionRepo, logger)
-	scoreService := services.NewScoreService(dbRepo, logger)
-	textService := services.NewTextService(dbRepo, cacheRepo, openAiRepo, logger)
-	userService := services.NewUserService(dbRepo, cacheRepo, logger, 32)
-	userNoticationService := services.NewUserNotificationService(cacheRepo, logger)
-
-	// Setup controllers
-	gameController := controllers.NewGameController(gameService, logger)
-	roomController := controllers.NewRoomController(roomService, logger)
-	scoreController := controllers.NewScoreController(scoreService, logger)
-	textController := controllers.NewTextController(textService, logger)
-	userController := controllers.NewUserController(userService, logger)
-	userNoticationController := controllers.NewUserNotificationController(userNoticationService, logger)
-
-	cors := cors.New(cors.Config{
-		// todo AllowOrigins based on production or development environment
-		AllowOrigins:     []string{"http://localhost:3000"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
+	shutdownTracing := initTracing(logger)
+	defer shutdownTracing(context.Background())
+
+	models.ConnectDatabase()
+
+	application := app.New(app.Repositories{
+		DB:               sql_repo.NewSQLRepository(models.DB),
+		Cache:            redis_repo.NewRedisRepository(models.RedisClient),
+		EmailTransaction: email_transaction_repo.NewEmailTransactionRepository(os.Getenv("POSTMARK_API_KEY")),
+		OpenAi:           newTextGenerator(logger),
+	}, newConfig(), clock.New(), logger)
+
+	// the bigram rarity of new texts falls back to the corpora of the languages if the stored texts can't be loaded
+	if err := application.LoadBigramFrequencies(context.Background()); err != nil {
+		logger.Error(err)
+	}
+
+	// the client ip of the rate limits is only read from the X-Forwarded-For header of the proxies of TRUSTED_PROXIES (comma separated ips or cidrs)
+	var trustedProxies []string
+	if trustedProxiesStr := os.Getenv("TRUSTED_PROXIES"); trustedProxiesStr != "" {
+		trustedProxies = strings.Split(trustedProxiesStr, ",")
+	}
+	if err := application.Router.SetTrustedProxies(trustedProxies); err != nil {
+		panic("Error parsing TRUSTED_PROXIES: >> " + err.Error())
+	}
+
+	server := newServer(application.Router)
+	go func() {
+		logger.Info("listening on ", server.Addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			panic("Error listening: >> " + err.Error())
+		}
+	}()
+
+	metricsServer := newMetricsServer()
+	go func() {
+		logger.Info("serving metrics on ", metricsServer.Addr)
+		if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			panic("Error listening for metrics: >> " + err.Error())
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	<-ctx.Done()
+	stop()
+
+	shutdownTimeout := defaultShutdownTimeout
+	if shutdownTimeoutStr := os.Getenv("SHUTDOWN_TIMEOUT"); shutdownTimeoutStr != "" {
+		parsedShutdownTimeout, err := time.ParseDuration(shutdownTimeoutStr)
+		if err != nil {
+			panic("Error parsing SHUTDOWN_TIMEOUT")
+		}
+		shutdownTimeout = parsedShutdownTimeout
+	}
+	logger.Info("shutting down within ", shutdownTimeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	readinessDrainPeriod := defaultReadinessDrainPeriod
+	if readinessDrainPeriodStr := os.Getenv("READINESS_DRAIN_PERIOD"); readinessDrainPeriodStr != "" {
+		parsedReadinessDrainPeriod, err := time.ParseDuration(readinessDrainPeriodStr)
+		if err != nil {
+			panic("Error parsing READINESS_DRAIN_PERIOD")
+		}
+		readinessDrainPeriod = parsedReadinessDrainPeriod
+	}
+
+	// keep serving requests until the load balancer has seen the failing readiness check and stopped sending new requests
+	application.SetNotReady()
+	select {
+	case <-time.After(readinessDrainPeriod):
+	case <-shutdownCtx.Done():
+	}
+
+	// stop accepting connections and wait for the requests, but not for the long polls, which are canceled, and the WebSocket connections, which are hijacked
+	application.CancelLongPolls()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error(err)
+	}
+	if err := application.Shutdown(shutdownCtx, gameHandOffTimeout); err != nil {
+		logger.Error(err)
+	}
+	// the metrics are served until the games have been shut down, so that the last scrape sees them
+	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+		logger.Error(err)
+	}
+	logger.Info("shut down")
+}
+
+const (
+	// defaultShutdownTimeout is shorter than the grace period of 30 seconds of kubernetes and docker compose before the process is killed
+	defaultShutdownTimeout = 25 * time.Second
+	// defaultReadinessDrainPeriod is the duration between failing the readiness check and closing the listener, which should be longer than the period of the readiness probe
+	defaultReadinessDrainPeriod = 5 * time.Second
+	// gameHandOffTimeout is the duration before the shutdown deadline at which the running games are aborted, so that they are reset before the process exits
+	gameHandOffTimeout = 5 * time.Second
+)
+
+// newServer returns the server of the API on the port of the PORT environment variable, which defaults to 8080 like gin does.
+// The write timeout is longer than the long polling of notifications and the usual generation of a text.
+func newServer(handler http.Handler) *http.Server {
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	return &http.Server{
+		Addr:              ":" + port,
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       15 * time.Second,
+		WriteTimeout:      2 * time.Minute,
+		IdleTimeout:       2 * time.Minute,
+		// the deadlines of the timeouts stay set on hijacked connections, which would close the WebSocket connections
+		ConnState: func(conn net.Conn, state http.ConnState) {
+			if state == http.StateHijacked {
+				conn.SetDeadline(time.Time{})
+			}
+		},
+	}
+}
+
+// newMetricsServer returns the server of /metrics on the port of the METRICS_PORT environment variable, which defaults to 9090.
+// The metrics are not served by the API, because they reveal the load of the server. The port must only be reachable by the scraper, e.g. within the cluster.
+func newMetricsServer() *http.Server {
+	port := os.Getenv("METRICS_PORT")
+	if port == "" {
+		port = "9090"
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+
+	return &http.Server{
+		Addr:              ":" + port,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       15 * time.Second,
+		WriteTimeout:      30 * time.Second,
+	}
+}
+
+// newConfig returns the configuration of the API for the environment. ALLOWED_ORIGINS are the comma separated origins of the frontend, which default to http://localhost:3000.
+// The session cookie is Secure unless ENVIRONMENT is development and its SameSite attribute is set by COOKIE_SAME_SITE (lax, strict or none), which defaults to lax.
+func newConfig() app.Config {
+	allowedOrigins := []string{"http://localhost:3000"}
+	if allowedOriginsStr := os.Getenv("ALLOWED_ORIGINS"); allowedOriginsStr != "" {
+		allowedOrigins = strings.Split(allowedOriginsStr, ",")
+	}
+
+	cookieConfig := models.CookieConfig{
+		Secure:   os.Getenv("ENVIRONMENT") != "development",
+		SameSite: http.SameSiteLaxMode,
+	}
+	switch cookieSameSite := os.Getenv("COOKIE_SAME_SITE"); cookieSameSite {
+	case "", "lax":
+	case "strict":
+		cookieConfig.SameSite = http.SameSiteStrictMode
+	case "none":
+		// browsers reject cookies with SameSite=None that aren't Secure
+		if !cookieConfig.Secure {
+			panic("Error parsing COOKIE_SAME_SITE: none requires a Secure cookie outside of development")
+		}
+		cookieConfig.SameSite = http.SameSiteNoneMode
+	default:
+		panic("Error parsing COOKIE_SAME_SITE: " + cookieSameSite)
+	}
+
+	return app.Config{AllowedOrigins: allowedOrigins, Cookie: cookieConfig}
+}
+
+// newTextGenerator returns the repository that generates typing texts, which is selected by the TEXT_GENERATOR environment variable (openai or local).
+// Without TEXT_GENERATOR the OpenAI API is used if OPENAI_API_KEY is set and the local generator otherwise.
+// The local generator is seeded with TEXT_GENERATOR_SEED, if it is set, so that it generates the same texts on every start.
+// OPENAI_BASE_URL, OPENAI_MODEL and OPENAI_TEXT_WORDS optionally configure the OpenAI client, e.g. to use a local OpenAI compatible API.
+func newTextGenerator(logger common.Logger) common.OpenAiRepository {
+	openAiApiKey := os.Getenv("OPENAI_API_KEY")
+	textGenerator := os.Getenv("TEXT_GENERATOR")
+	if textGenerator == "" && openAiApiKey != "" {
+		textGenerator = "openai"
+	}
+
+	if textGenerator == "openai" {
+		var wordsNumber int
+		if wordsNumberStr := os.Getenv("OPENAI_TEXT_WORDS"); wordsNumberStr != "" {
+			parsedWordsNumber, err := strconv.Atoi(wordsNumberStr)
+			if err != nil || parsedWordsNumber <= 0 {
+				panic("Error parsing OPENAI_TEXT_WORDS")
+			}
+			wordsNumber = parsedWordsNumber
+		}
+
+		return open_ai_repo.NewOpenAiRepository(openAiApiKey, os.Getenv("OPENAI_BASE_URL"), os.Getenv("OPENAI_MODEL"), wordsNumber)
+	}
+
+	seed := time.Now().UnixNano()
+	if seedStr := os.Getenv("TEXT_GENERATOR_SEED"); seedStr != "" {
+		parsedSeed, err := strconv.ParseInt(seedStr, 10, 64)
+		if err != nil {
+			panic("Error parsing TEXT_GENERATOR_SEED")
+		}
+		seed = parsedSeed
+	}
+	logger.Info("using local text generator")
+
+	return local_text_repo.NewLocalTextRepository(seed)
+}
+
+// initTracing starts to export spans with the exporter of the OTEL_TRACES_EXPORTER environment variable (otlp, stdout or none).
+// Without OTEL_TRACES_EXPORTER no spans are recorded. The otlp exporter sends the spans over HTTP to OTEL_EXPORTER_OTLP_ENDPOINT, which defaults to http://localhost:4318,
+// and the service is named OTEL_SERVICE_NAME, which defaults to 10-typing. The returned function exports the remaining spans.
+func initTracing(logger common.Logger) func(ctx context.Context) error {
+	var exporter sdktrace.SpanExporter
+	var err error
+
+	switch tracesExporter := os.Getenv("OTEL_TRACES_EXPORTER"); tracesExporter {
+	case "", "none":
+		return func(ctx context.Context) error { return nil }
+	case "otlp":
+		exporter, err = otlptracehttp.New(context.Background())
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		panic("Error parsing OTEL_TRACES_EXPORTER: " + tracesExporter)
+	}
+	if err != nil {
+		panic("Error creating the traces exporter: >> " + err.Error())
+	}
+
+	shutdown, err := tracing.Init(context.Background(), exporter, func(err error) {
+		logger.Error(err)
 	})
+	if err != nil {
+		panic("Error initializing tracing: >> " + err.Error())
+	}
+	logger.Info("exporting traces to ", os.Getenv("OTEL_TRACES_EXPORTER"))
 
-	router := gin.New()
-	router.Use(middlewares.GinZerologLogger(logger), gin.Recovery(), cors)
-	api := router.Group("/api")
-
-	authRequiredMiddleware := middlewares.AuthRequired(cacheRepo, dbRepo, logger)
-	isRoomMemberMiddleware := middlewares.IsRoomMember(cacheRepo, logger)
-	isRoomAdminMiddleware := middlewares.IsRoomAdmin(cacheRepo, logger)
-	isCurrentGameUserMiddleware := middlewares.IsCurrentGameUser(cacheRepo, logger)
-	userIdUrlParamMatchesAuthorizedUserMiddleware := middlewares.UserIdUrlParamMatchesAuthorizedUser(logger)
-
-	// USERS
-	api.GET("/users", authRequiredMiddleware, userController.FindUsers)
-	api.GET("/users/:userid", authRequiredMiddleware, userController.FindUser)
-	api.GET("/users/:userid/scores", authRequiredMiddleware, scoreController.FindScoresByUser)
-	api.POST("/users/:userid/scores", authRequiredMiddleware, userIdUrlParamMatchesAuthorizedUserMiddleware, scoreController.CreateScore)
-	// why use the userId here -> without a user id the middleware function UserIdUrlParamMatchesAuthorizedUser would be unnecessary
-	api.GET("/users/:userid/text", authRequiredMiddleware, userIdUrlParamMatchesAuthorizedUserMiddleware, textController.FindNewTextForUser)
-	api.POST("/users", userController.CreateUser)
-
-	// USER
-	api.GET("/user", authRequiredMiddleware, userController.CurrentUser)
-	api.POST("/user/login", userController.Login)
-	api.POST("/user/logout", authRequiredMiddleware, userController.Logout)
-
-	// NOTIFICATIONS
-	api.GET("/notification/realtime", authRequiredMiddleware, userNoticationController.FindRealtimeUserNotification)
-
-	// SCORES
-	api.GET("/scores", authRequiredMiddleware, scoreController.FindScores)
-
-	// TEXTS
-	api.POST("/texts", authRequiredMiddleware, textController.CreateText)
-	api.GET("/texts/:textid", authRequiredMiddleware, textController.FindTextById)
-
-	// ROOMS
-	api.GET("/rooms/:roomid/ws", authRequiredMiddleware, isRoomMemberMiddleware, roomController.ConnectToRoom)
-	// TODO: get new text for room
-	// api.GET("/rooms/:roomid/text", authRequiredMiddleware, isRoomAdminMiddleware)
-	api.POST("/rooms", authRequiredMiddleware, roomController.CreateRoom)
-	api.POST("/rooms/:roomid/leave", authRequiredMiddleware, isRoomMemberMiddleware, roomController.LeaveRoom)
-	api.POST("/rooms/:roomid/game", authRequiredMiddleware, isRoomAdminMiddleware, gameController.CreateNewCurrentGame)
-	api.POST("/rooms/:roomid/start-game", authRequiredMiddleware, isRoomMemberMiddleware, gameController.StartGame)
-	api.POST("/rooms/:roomid/current-game/score",
-		authRequiredMiddleware,
-		isRoomMemberMiddleware,
-		isCurrentGameUserMiddleware,
-		gameController.FinishGame,
-	)
-
-	router.Run()
+	return shutdown
 }
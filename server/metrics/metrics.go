@@ -0,0 +1,97 @@
+// Package metrics registers the prometheus metrics of the server, which are scraped from /metrics of the internal metrics server.
+// The metrics are package level variables, so that every package can record them without passing them around.
+// They count the events of this server instance, e.g. the WebSocket connections that this instance holds.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Handler returns the handler that exposes the metrics of the server and of the Go runtime in the prometheus text format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+var (
+	HttpRequestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "http_request_duration_seconds",
+			Help: "Duration of HTTP requests by method, route and status code.",
+		},
+		[]string{"method", "route", "status"},
+	)
+
+	WebSocketConnections = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "websocket_connections_active",
+			Help: "Number of open room WebSocket connections.",
+		},
+	)
+
+	ActiveRooms = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "rooms_active",
+			Help: "Number of rooms with at least one open WebSocket connection.",
+		},
+	)
+
+	Games = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "games",
+			Help: "Number of running games by status.",
+		},
+		[]string{"status"},
+	)
+
+	CountdownToStartDuration = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "game_countdown_to_start_seconds",
+			Help:    "Duration from the start of the countdown until the game status is set to started.",
+			Buckets: []float64{1, 2, 3, 4, 5, 6, 7, 8, 10, 15},
+		},
+	)
+
+	PushMessagesPublished = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "push_messages_published_total",
+			Help: "Number of push messages published to room streams by type.",
+		},
+		[]string{"type"},
+	)
+
+	StreamReadLag = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "redis_stream_read_lag_seconds",
+			Help: "Duration from adding a stream entry until it is read with XREAD by stream, for the entries added after the start of the subscription.",
+		},
+		[]string{"stream"},
+	)
+
+	TextGenerationDuration = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "openai_text_generation_duration_seconds",
+			Help:    "Duration of generating a text with the OpenAI API including retries.",
+			Buckets: []float64{0.5, 1, 2.5, 5, 10, 20, 30, 60, 120},
+		},
+	)
+
+	TextGenerationErrors = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "openai_text_generation_errors_total",
+			Help: "Number of failed text generations by reason: request or validation.",
+		},
+		[]string{"reason"},
+	)
+
+	ScoresSubmitted = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "scores_submitted_total",
+			Help: "Number of submitted scores by source: game or single.",
+		},
+		[]string{"source"},
+	)
+)
@@ -0,0 +1,67 @@
+package middlewares
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/utils"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CSRF rejects the state changing requests of browsers that are sent from other origins than the allowed origins, e.g. the frontend, or the API itself.
+// Browsers send the Origin header with every cross origin request that isn't a GET or HEAD request and the Sec-Fetch-Site header with every request,
+// which can't be set by the page that sends the request. Requests without both headers are not sent by browsers and are allowed, e.g. the requests of the server side rendering of the frontend.
+func CSRF(allowedOrigins []string, logger common.Logger) gin.HandlerFunc {
+	const op errors.Op = "middlewares.CSRF"
+
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		// same-origin requests and requests of the user, e.g. typed into the address bar, are always allowed
+		fetchSite := c.GetHeader("Sec-Fetch-Site")
+		if fetchSite == "same-origin" || fetchSite == "none" {
+			c.Next()
+			return
+		}
+
+		origin := c.GetHeader("Origin")
+		if origin == "" && fetchSite == "" {
+			c.Next()
+			return
+		}
+
+		if origin != "" && isAllowedOrigin(c.Request, origin, allowedOrigins) {
+			c.Next()
+			return
+		}
+
+		err := fmt.Errorf("request from origin %q (Sec-Fetch-Site: %q) is not allowed", origin, fetchSite)
+		err = errors.E(op, err, http.StatusForbidden, errors.Messages{"message": "Origin not allowed"})
+		c.Abort()
+		utils.WriteError(c, err, logger)
+	}
+}
+
+// isAllowedOrigin reports whether the origin is one of the allowed origins or the origin of the host of the request
+func isAllowedOrigin(r *http.Request, origin string, allowedOrigins []string) bool {
+	for _, allowedOrigin := range allowedOrigins {
+		if strings.EqualFold(origin, allowedOrigin) {
+			return true
+		}
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(u.Host, r.Host)
+}
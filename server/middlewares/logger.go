@@ -2,11 +2,15 @@ package middlewares
 
 import (
 	"10-typing/common"
+	"10-typing/metrics"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// GinZerologLogger logs every request with the log fields of its context, which the following middleware functions may add to, e.g. the user id.
+// It records the duration of the request by route, so that the paths with ids are not recorded separately. It must be used after RequestId.
 func GinZerologLogger(logger common.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
@@ -20,6 +24,12 @@ func GinZerologLogger(logger common.Logger) gin.HandlerFunc {
 		method := c.Request.Method
 		statusCode := c.Writer.Status()
 
-		logger.RequestInfo(method, path, clientIP, statusCode, latency)
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.HttpRequestDuration.WithLabelValues(method, route, strconv.Itoa(statusCode)).Observe(latency.Seconds())
+
+		logger.WithContext(c.Request.Context()).RequestInfo(method, path, clientIP, statusCode, latency)
 	}
 }
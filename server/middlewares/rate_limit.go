@@ -0,0 +1,76 @@
+package middlewares
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/models"
+	"10-typing/utils"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitKey returns the key of the client whose requests are limited together
+type RateLimitKey func(c *gin.Context) (string, error)
+
+// ByClientIp limits the requests per client ip, e.g. of the routes that don't require authentication
+func ByClientIp(c *gin.Context) (string, error) {
+	return "ip:" + c.ClientIP(), nil
+}
+
+// ByUser limits the requests per authenticated user
+// this key function must be used after AuthRequired
+func ByUser(c *gin.Context) (string, error) {
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		return "", err
+	}
+
+	return "user:" + user.ID.String(), nil
+}
+
+// RateLimit limits the requests of the route to the rate limit per client key with a token bucket in the cache, so that the limit is shared by all server instances.
+// The name separates the buckets of different routes of the same client. Requests over the limit get the status 429 with the Retry-After header.
+// If the cache fails, the request is allowed, so that an unavailable cache doesn't take down the routes.
+func RateLimit(cacheRepo common.CacheRepository, name string, limit models.RateLimit, key RateLimitKey, logger common.Logger) gin.HandlerFunc {
+	const op errors.Op = "middlewares.RateLimit"
+
+	return func(c *gin.Context) {
+		clientKey, err := key(c)
+		if err != nil {
+			err = errors.E(op, err, http.StatusInternalServerError)
+			c.Abort()
+			utils.WriteError(c, err, logger)
+
+			return
+		}
+
+		result, err := cacheRepo.TakeRateLimitToken(c.Request.Context(), name+":"+clientKey, limit)
+		if err != nil {
+			logger.WithContext(c.Request.Context()).Error(errors.E(op, err))
+			c.Next()
+
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(limit.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+
+		if !result.Allowed {
+			retryAfterSec := int(math.Ceil(result.RetryAfter.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(retryAfterSec))
+
+			err := fmt.Errorf("rate limit %s of %d per %s exceeded by %s", name, limit.Limit, limit.Per, clientKey)
+			err = errors.E(op, err, http.StatusTooManyRequests, errors.Messages{"message": "Too many requests, please try again later"})
+			c.Abort()
+			utils.WriteError(c, err, logger)
+
+			return
+		}
+
+		c.Next()
+	}
+}
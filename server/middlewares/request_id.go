@@ -0,0 +1,31 @@
+package middlewares
+
+import (
+	"10-typing/common"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const RequestIdHeader = "X-Request-ID"
+
+// a request id from a client is only accepted if it can't break the log lines or the response header
+var validRequestId = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,128}$`)
+
+// RequestId adds the request id to the log fields of the request context and to the X-Request-ID response header.
+// The request id of the X-Request-ID request header is used, e.g. the one of a proxy, otherwise a new one is generated.
+func RequestId() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestId := c.GetHeader(RequestIdHeader)
+		if !validRequestId.MatchString(requestId) {
+			requestId = uuid.NewString()
+		}
+
+		c.Header(RequestIdHeader, requestId)
+		ctx := common.ContextWithLogFields(c.Request.Context(), common.LogFields{common.RequestIdLogField: requestId})
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
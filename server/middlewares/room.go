@@ -23,6 +23,7 @@ func IsRoomAdmin(cacheRepo common.CacheRepository, logger common.Logger) gin.Han
 
 			return
 		}
+		utils.AddLogFields(c, common.LogFields{common.RoomIdLogField: roomId})
 
 		user, err := utils.GetUserFromContext(c)
 		if err != nil {
@@ -67,6 +68,7 @@ func IsRoomMember(cacheRepo common.CacheRepository, logger common.Logger) gin.Ha
 
 			return
 		}
+		utils.AddLogFields(c, common.LogFields{common.RoomIdLogField: roomId})
 
 		user, err := utils.GetUserFromContext(c)
 		if err != nil {
@@ -0,0 +1,25 @@
+package middlewares
+
+import (
+	"10-typing/common"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceIdLogField adds the trace id of the server span of the request, which otelgin has started, to the log fields of the request context,
+// so that the log lines of a trace can be found
+func TraceIdLogField() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		spanContext := trace.SpanContextFromContext(c.Request.Context())
+		if !spanContext.IsValid() {
+			c.Next()
+			return
+		}
+
+		ctx := common.ContextWithLogFields(c.Request.Context(), common.LogFields{common.TraceIdLogField: spanContext.TraceID().String()})
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
@@ -35,7 +35,17 @@ func AuthRequired(cacheRepo common.CacheRepository, dbRepo common.DBRepository,
 			return
 		}
 
+		if user.IsBanned() {
+			err := fmt.Errorf("user %s is banned", user.ID)
+			err = errors.E(op, err, http.StatusForbidden, errors.Messages{"message": "user is banned"})
+			c.Abort()
+			utils.WriteError(c, err, logger)
+
+			return
+		}
+
 		c.Set("user", user)
+		utils.AddLogFields(c, common.LogFields{common.UserIdLogField: user.ID})
 
 		c.Next()
 	}
@@ -77,3 +87,41 @@ func UserIdUrlParamMatchesAuthorizedUser(logger common.Logger) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// Policy reports whether the authenticated user may access the route
+type Policy func(c *gin.Context, user *models.User) bool
+
+// HasRole allows the users with the site role or a role with more permissions, e.g. admins may do everything that moderators may do
+func HasRole(role models.UserRole) Policy {
+	return func(c *gin.Context, user *models.User) bool {
+		return user.HasRole(role)
+	}
+}
+
+// checks if the authenticated user satisfies the policy
+// this middleware function must be used after AuthRequired
+func Authorize(policy Policy, logger common.Logger) gin.HandlerFunc {
+	const op errors.Op = "middlewares.Authorize"
+
+	return func(c *gin.Context) {
+		user, err := utils.GetUserFromContext(c)
+		if err != nil {
+			err = errors.E(op, http.StatusInternalServerError, err)
+			c.Abort()
+			utils.WriteError(c, err, logger)
+
+			return
+		}
+
+		if !policy(c, user) {
+			err := fmt.Errorf("authenticated user %s with role %q is not allowed to access %s", user.Username, user.Role, c.FullPath())
+			err = errors.E(op, err, http.StatusForbidden, user.Username)
+			c.Abort()
+			utils.WriteError(c, err, logger)
+
+			return
+		}
+
+		c.Next()
+	}
+}
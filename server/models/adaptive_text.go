@@ -0,0 +1,24 @@
+package models
+
+type AdaptiveTextSource string
+
+const (
+	ExistingAdaptiveTextSource  AdaptiveTextSource = "existing"
+	GeneratedAdaptiveTextSource AdaptiveTextSource = "generated"
+)
+
+type TextGenerator string
+
+const (
+	LocalTextGenerator  TextGenerator = "local"
+	OpenAiTextGenerator TextGenerator = "openai"
+)
+
+// AdaptiveText is a text that has been selected or generated to practice the weak keys of a user
+type AdaptiveText struct {
+	Text       *Text              `json:"text"`
+	TargetKeys []string           `json:"targetKeys"`
+	Source     AdaptiveTextSource `json:"source"`
+	// Emphasis is the frequency of the target keys in the text relative to their average frequency in the candidate texts
+	Emphasis float64 `json:"emphasis"`
+}
@@ -0,0 +1,11 @@
+package models
+
+const (
+	PassedReadinessCheck = "ok"
+	FailedReadinessCheck = "failed"
+)
+
+type Readiness struct {
+	Ready  bool              `json:"ready"`
+	Checks map[string]string `json:"checks"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"10-typing/keyboard"
+	"time"
+)
+
+// KeyboardStatsScoresNumber is the number of the most recent scores that the keyboard statistics are calculated from
+const KeyboardStatsScoresNumber = 200
+
+// TypedText holds a score together with the text that has been typed
+type TypedText struct {
+	WordsTyped  int
+	TimeElapsed float64
+	Errors      ErrorsJSON
+	Text        string
+}
+
+// ZoneStats holds the errors and keystrokes of a zone of the keyboard, which is a single key, a finger, a hand or a row.
+// The keystrokes are estimated from the characters of the texts that have been typed.
+type ZoneStats struct {
+	Errors              int     `json:"errors"`
+	Keystrokes          int     `json:"keystrokes"`
+	ErrorRate           float64 `json:"errorRate"`
+	KeystrokesPerMinute float64 `json:"keystrokesPerMinute"`
+}
+
+type KeyStats struct {
+	Key string `json:"key"`
+	keyboard.KeyPosition
+	ZoneStats
+}
+
+type FingerStats struct {
+	Finger keyboard.Finger `json:"finger"`
+	Hand   keyboard.Hand   `json:"hand"`
+	ZoneStats
+}
+
+type HandStats struct {
+	Hand keyboard.Hand `json:"hand"`
+	ZoneStats
+}
+
+type RowStats struct {
+	Row keyboard.Row `json:"row"`
+	ZoneStats
+}
+
+type KeyboardStats struct {
+	KeyboardLayout string            `json:"keyboardLayout"`
+	Scores         int               `json:"scores"`
+	Keys           []KeyStats        `json:"keys"`
+	Fingers        []FingerStats     `json:"fingers"`
+	Hands          []HandStats       `json:"hands"`
+	Rows           []RowStats        `json:"rows"`
+	WeakestFingers []keyboard.Finger `json:"weakestFingers"`
+	CalculatedAt   time.Time         `json:"calculatedAt"`
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type LeaderboardWindow string
+
+const (
+	DailyLeaderboardWindow   LeaderboardWindow = "daily"
+	WeeklyLeaderboardWindow  LeaderboardWindow = "weekly"
+	MonthlyLeaderboardWindow LeaderboardWindow = "monthly"
+	AllTimeLeaderboardWindow LeaderboardWindow = "all_time"
+)
+
+var LeaderboardWindows = []LeaderboardWindow{DailyLeaderboardWindow, WeeklyLeaderboardWindow, MonthlyLeaderboardWindow, AllTimeLeaderboardWindow}
+
+// LeaderboardRetention is the time a leaderboard of a time window is kept after the time window has ended
+const LeaderboardRetention = 24 * time.Hour
+
+// Period returns the identifier of the time window that contains t, e.g. 2023-10-19 for a daily window, 2023-W42 for a weekly window
+// or 2023-10 for a monthly window. All time windows only have a single period.
+func (lw LeaderboardWindow) Period(t time.Time) string {
+	t = t.UTC()
+
+	switch lw {
+	case DailyLeaderboardWindow:
+		return t.Format("2006-01-02")
+	case WeeklyLeaderboardWindow:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case MonthlyLeaderboardWindow:
+		return t.Format("2006-01")
+	default:
+		return "all"
+	}
+}
+
+// PeriodEnd returns the end of the time window that contains t. All time windows never end, so the zero time is returned.
+func (lw LeaderboardWindow) PeriodEnd(t time.Time) time.Time {
+	t = t.UTC()
+	startOfDay := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch lw {
+	case DailyLeaderboardWindow:
+		return startOfDay.AddDate(0, 0, 1)
+	case WeeklyLeaderboardWindow:
+		// ISO weeks start on monday
+		daysSinceMonday := (int(t.Weekday()) + 6) % 7
+		return startOfDay.AddDate(0, 0, 7-daysSinceMonday)
+	case MonthlyLeaderboardWindow:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
+	default:
+		return time.Time{}
+	}
+}
+
+// LeaderboardFilter identifies a single leaderboard.
+// An empty Language and a nil Punctuation include the scores of all languages and punctuation settings.
+type LeaderboardFilter struct {
+	Window      LeaderboardWindow
+	Period      string
+	Language    string
+	Punctuation *bool
+}
+
+// LeaderboardScore is the data of a score that is needed to add it to the leaderboards
+type LeaderboardScore struct {
+	UserId         uuid.UUID
+	WordsPerMinute float64
+	Language       string
+	Punctuation    bool
+	CreatedAt      time.Time
+}
+
+type LeaderboardEntry struct {
+	Rank           int64     `json:"rank"`
+	UserId         uuid.UUID `json:"userId"`
+	Username       string    `json:"username"`
+	WordsPerMinute float64   `json:"wordsPerMinute"`
+}
+
+type Leaderboard struct {
+	Window      LeaderboardWindow  `json:"window"`
+	Period      string             `json:"period"`
+	Language    string             `json:"language,omitempty"`
+	Punctuation *bool              `json:"punctuation,omitempty"`
+	Entries     []LeaderboardEntry `json:"entries"`
+	Total       int64              `json:"total"`
+	OwnEntry    *LeaderboardEntry  `json:"ownEntry"`
+}
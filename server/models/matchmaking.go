@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MatchmakingGroupSize        = 2
+	MatchmakingTicketTTLSeconds = 60 * 10
+)
+
+// MatchmakingTicket represents a user waiting in the matchmaking queue.
+// Only tickets with the same language, punctuation and skill bracket are matched with each other.
+type MatchmakingTicket struct {
+	UserId       uuid.UUID `json:"userId"`
+	Language     string    `json:"language"`
+	Punctuation  bool      `json:"punctuation"`
+	SkillBracket string    `json:"skillBracket"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+type MatchmakingStatus struct {
+	Status string             `json:"status"` // queued or matched
+	Ticket *MatchmakingTicket `json:"ticket,omitempty"`
+	RoomId *uuid.UUID         `json:"roomId,omitempty"`
+	GameId *uuid.UUID         `json:"gameId,omitempty"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"10-typing/textanalysis"
 	"fmt"
 	"log"
 	"os"
@@ -21,10 +22,8 @@ const (
 
 var DB *gorm.DB
 
-func init() {
-	ConnectDatabase()
-}
-
+// ConnectDatabase connects to postgres, migrates the schema and sets DB.
+// It is not called on import, so that packages which only use the models don't need a database.
 func ConnectDatabase() {
 	newLogger := logger.New(
 		log.New(os.Stdout, "\r\n", log.LstdFlags),
@@ -43,7 +42,7 @@ func ConnectDatabase() {
 		panic("Failed to connect to database!")
 	}
 
-	err = db.AutoMigrate(&User{}, &Text{}, &Game{}, &Score{}, &Room{}, &Token{})
+	err = db.AutoMigrate(&User{}, &Text{}, &Game{}, &Score{}, &Room{}, &Token{}, &RatingChange{})
 	if err != nil {
 		panic("Failed to migrate database!")
 	}
@@ -55,5 +54,48 @@ func ConnectDatabase() {
 		panic("Failed to run custom migration!")
 	}
 
+	if err := backfillContentHashes(db); err != nil {
+		panic("Failed to backfill the content hashes of the texts!")
+	}
+
+	// the server still works without the index, which is created on the next start once the duplicates have been deleted
+	if err := CreateUniquePublicContentHashIndex(db); err != nil {
+		log.Print("Failed to create the unique index of the content hashes of the public texts, delete the duplicates with go run scripts/texts/texts.go dedupe: ", err)
+	}
+
 	DB = db
 }
+
+// contentHashBackfillBatchSize is the number of texts whose content hashes are computed at once
+const contentHashBackfillBatchSize = 500
+
+// backfillContentHashes computes the content hashes of the texts that have been created before texts had content hashes
+func backfillContentHashes(db *gorm.DB) error {
+	var texts []Text
+
+	return db.Unscoped().Select("id", "text").Where("content_hash = ''").FindInBatches(&texts, contentHashBackfillBatchSize, func(tx *gorm.DB, batch int) error {
+		for _, text := range texts {
+			if err := tx.Unscoped().Model(&Text{ID: text.ID}).Update("content_hash", textanalysis.ContentHash(text.Text)).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}).Error
+}
+
+// CreateUniquePublicContentHashIndex creates the unique index of the content hashes of the public texts, so that the same text can't be published twice.
+// Private texts may have the same content, e.g. when different users save the same text, and rejected texts may be submitted again.
+// The index can't be created while duplicates of public texts exist, which have been created before the index existed and are deleted by the dedupe command of scripts/texts.
+func CreateUniquePublicContentHashIndex(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		// the first version of the index also covered the rejected texts
+		if err := tx.Exec("DROP INDEX IF EXISTS idx_texts_public_content_hash;").Error; err != nil {
+			return err
+		}
+
+		return tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_texts_public_unrejected_content_hash " +
+			"ON texts (content_hash) " +
+			"WHERE visibility = 'public' AND status <> 'rejected' AND content_hash <> '' AND deleted_at IS NULL;").Error
+	})
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	MaxLoginFailures = 5
+	// MaxEmailLoginFailures locks the login of an email that fails from many client ips, e.g. in a distributed attack on an account.
+	// It is much higher than MaxLoginFailures, so that a single client can't lock other clients out of an account.
+	MaxEmailLoginFailures   = 50
+	LoginLockoutDurationSec = 60 * 15 // 15 minutes
+)
+
+// LoginFailures are the failed logins of an email, which are counted per client ip and from all client ips
+type LoginFailures struct {
+	FromClientIp     int
+	FromAllClientIps int
+}
+
+// IsLocked reports whether the login of the email is locked for the client ip
+func (f LoginFailures) IsLocked() bool {
+	return f.FromClientIp >= MaxLoginFailures || f.FromAllClientIps >= MaxEmailLoginFailures
+}
+
+// NormalizeLoginEmail returns the email in the form that failed logins are counted for, so that "User@Example.com " and "user@example.com" share their failed logins
+func NormalizeLoginEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// RateLimit allows Limit requests per Per. It is enforced with a token bucket that holds up to Limit tokens
+// and refills continuously, so that bursts of up to Limit requests are allowed.
+type RateLimit struct {
+	Limit int
+	Per   time.Duration
+}
+
+type RateLimitResult struct {
+	Allowed   bool
+	Remaining int
+	// RetryAfter is the duration until the next token is available if the request is not allowed
+	RetryAfter time.Duration
+}
+
+// TokenBucket is the state of a token bucket. The zero value is a full bucket.
+type TokenBucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// Take refills the bucket for the time since its last update and takes a token if one is available
+func (b *TokenBucket) Take(limit RateLimit, now time.Time) RateLimitResult {
+	capacity := float64(limit.Limit)
+	if b.UpdatedAt.IsZero() {
+		b.Tokens = capacity
+	} else if elapsed := now.Sub(b.UpdatedAt); elapsed > 0 {
+		b.Tokens = math.Min(capacity, b.Tokens+capacity*float64(elapsed)/float64(limit.Per))
+	}
+	b.UpdatedAt = now
+
+	if b.Tokens >= 1 {
+		b.Tokens--
+		return RateLimitResult{Allowed: true, Remaining: int(b.Tokens)}
+	}
+
+	retryAfter := time.Duration((1 - b.Tokens) / capacity * float64(limit.Per))
+
+	return RateLimitResult{Allowed: false, Remaining: 0, RetryAfter: retryAfter}
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const DefaultRating = 1500
+
+// RatingChange is an entry of a user's rating history. It is created for every participant of a finished multiplayer game.
+type RatingChange struct {
+	ID           uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UserId       uuid.UUID `json:"userId" gorm:"not null;index"`
+	GameId       uuid.UUID `json:"gameId" gorm:"not null"`
+	RatingBefore float64   `json:"ratingBefore" gorm:"not null"`
+	RatingAfter  float64   `json:"ratingAfter" gorm:"not null"`
+	Placement    int       `json:"placement" gorm:"not null"`
+	Participants int       `json:"participants" gorm:"not null"`
+}
+
+// RatingLeaderboardEntry is a user of the rating leaderboard, which only holds the public fields of the user
+type RatingLeaderboardEntry struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+	Rating   float64   `json:"rating"`
+}
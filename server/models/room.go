@@ -18,4 +18,47 @@ type Room struct {
 	Tokens          []Token         `json:"-"`
 	Games           []Game          `json:"-"`
 	GameDurationSec int             `json:"gameDurationSec" gorm:"default:5;not null"`
+	LastGameAt      *time.Time      `json:"lastGameAt"`
+}
+
+// RoomSettings holds the settings of a room that can be changed after its creation. Settings that are nil are not changed.
+type RoomSettings struct {
+	GameDurationSec *int
+}
+
+// IsEmpty reports whether no setting is changed
+func (s RoomSettings) IsEmpty() bool {
+	return s.GameDurationSec == nil
+}
+
+type RoomOverview struct {
+	ID                    uuid.UUID  `json:"id"`
+	CreatedAt             time.Time  `json:"createdAt"`
+	UpdatedAt             time.Time  `json:"updatedAt"`
+	AdminId               uuid.UUID  `json:"adminId"`
+	AdminUsername         string     `json:"adminUsername"`
+	GameDurationSec       int        `json:"gameDurationSec"`
+	MemberCount           int        `json:"memberCount"`
+	ActiveSubscriberCount int        `json:"activeSubscriberCount" gorm:"-"`
+	LastGameAt            *time.Time `json:"lastGameAt"`
+}
+
+type RoomMember struct {
+	UserId     uuid.UUID            `json:"userId"`
+	Username   string               `json:"username"`
+	IsAdmin    bool                 `json:"isAdmin"`
+	Status     SubscriberStatus     `json:"status"`
+	GameStatus SubscriberGameStatus `json:"gameStatus"`
+}
+
+type RoomWithMembers struct {
+	Room
+	Members []RoomMember `json:"members"`
+}
+
+// RoomInspection is the state of a room for moderators, including the current game and its scores
+type RoomInspection struct {
+	RoomWithMembers
+	CurrentGame       *Game   `json:"currentGame"`
+	CurrentGameScores []Score `json:"currentGameScores"`
 }
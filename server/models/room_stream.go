@@ -34,6 +34,10 @@ const (
 	GameStarted
 	UserStartedGame
 	UserFinishedGame
+	UserInvited
+	UserRemoved
+	AdminChanged
+	RoomSettingsChanged
 )
 
 func (p PushMessageType) String() (string, error) {
@@ -49,6 +53,10 @@ func (p PushMessageType) String() (string, error) {
 		"game_started",
 		"user_started_game",
 		"user_finished_game",
+		"user_invited",
+		"user_removed",
+		"admin_changed",
+		"room_settings_changed",
 	}
 
 	if int(p) >= len(f) {
@@ -77,16 +85,20 @@ func (p *PushMessageType) ParseFromString(data string) error {
 	const op errors.Op = "models.ParseFromString"
 
 	stringToPushMessageTypeMap := map[string]PushMessageType{
-		"user_joined":        UserJoined,
-		"new_game":           NewGame,
-		"cursor":             Cursor,
-		"countdown":          Countdown,
-		"user_left":          UserLeft,
-		"initial_state":      InitialState,
-		"game_result":        GameScores,
-		"game_started":       GameStarted,
-		"user_started_game":  UserStartedGame,
-		"user_finished_game": UserFinishedGame,
+		"user_joined":           UserJoined,
+		"new_game":              NewGame,
+		"cursor":                Cursor,
+		"countdown":             Countdown,
+		"user_left":             UserLeft,
+		"initial_state":         InitialState,
+		"game_result":           GameScores,
+		"game_started":          GameStarted,
+		"user_started_game":     UserStartedGame,
+		"user_finished_game":    UserFinishedGame,
+		"user_invited":          UserInvited,
+		"user_removed":          UserRemoved,
+		"admin_changed":         AdminChanged,
+		"room_settings_changed": RoomSettingsChanged,
 	}
 
 	pushMessageType, ok := stringToPushMessageTypeMap[data]
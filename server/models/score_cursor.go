@@ -0,0 +1,122 @@
+package models
+
+import (
+	"10-typing/errors"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CursorDirection string
+
+const (
+	NextCursorDirection CursorDirection = "next"
+	PrevCursorDirection CursorDirection = "prev"
+)
+
+// ScoreFilter holds all filters that can be applied when searching scores. Zero values and nil pointers are not applied.
+type ScoreFilter struct {
+	UserId            uuid.UUID
+	GameId            uuid.UUID
+	Username          string
+	From              *time.Time
+	To                *time.Time
+	Language          string
+	Punctuation       *bool
+	MinWordsPerMinute *float64
+	MaxWordsPerMinute *float64
+	MinAccuracy       *float64
+	MaxAccuracy       *float64
+}
+
+// ScoreCursor points to a score in a list of scores that is sorted by Column and the score id.
+// It holds the value of the sort column and the id of the score from where the next or previous page starts.
+type ScoreCursor struct {
+	Column    string          `json:"c"`
+	Direction CursorDirection `json:"d"`
+	CreatedAt *time.Time      `json:"t,omitempty"`
+	Number    *float64        `json:"n,omitempty"`
+	Id        uuid.UUID       `json:"i"`
+}
+
+type ScorePage struct {
+	Scores     []Score
+	NextCursor string
+	PrevCursor string
+}
+
+// NewScoreCursor returns a cursor that points to the score in a list of scores that is sorted by column
+func NewScoreCursor(score Score, column string, direction CursorDirection) ScoreCursor {
+	scoreCursor := ScoreCursor{
+		Column:    column,
+		Direction: direction,
+		Id:        score.ID,
+	}
+
+	var number float64
+	switch column {
+	case "created_at":
+		createdAt := score.CreatedAt
+		scoreCursor.CreatedAt = &createdAt
+		return scoreCursor
+	case "accuracy":
+		number = score.Accuracy
+	case "words_per_minute":
+		number = score.WordsPerMinute
+	case "number_errors":
+		number = float64(score.NumberErrors)
+	}
+	scoreCursor.Number = &number
+
+	return scoreCursor
+}
+
+// Value returns the value of the sort column the cursor points to
+func (sc ScoreCursor) Value() any {
+	if sc.CreatedAt != nil {
+		return *sc.CreatedAt
+	}
+	if sc.Number != nil {
+		return *sc.Number
+	}
+
+	return nil
+}
+
+// Encode returns the opaque string representation of the cursor
+func (sc ScoreCursor) Encode() (string, error) {
+	const op errors.Op = "models.ScoreCursor.Encode"
+
+	scoreCursorJson, err := json.Marshal(sc)
+	if err != nil {
+		return "", errors.E(op, err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(scoreCursorJson), nil
+}
+
+func DecodeScoreCursor(encodedScoreCursor string) (*ScoreCursor, error) {
+	const op errors.Op = "models.DecodeScoreCursor"
+
+	scoreCursorJson, err := base64.RawURLEncoding.DecodeString(encodedScoreCursor)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	var scoreCursor ScoreCursor
+	if err := json.Unmarshal(scoreCursorJson, &scoreCursor); err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	switch {
+	case scoreCursor.Direction != NextCursorDirection && scoreCursor.Direction != PrevCursorDirection:
+		return nil, errors.E(op, fmt.Errorf("invalid cursor direction"))
+	case scoreCursor.Value() == nil:
+		return nil, errors.E(op, fmt.Errorf("cursor has no value"))
+	}
+
+	return &scoreCursor, nil
+}
@@ -1,6 +1,14 @@
 package models
 
+import "net/http"
+
 const (
 	SessionDurationSec = 60 * 60 * 24 * 7 // 1 week
 	CookieSession      = "SID"
 )
+
+// CookieConfig sets the attributes of the session cookie, which depend on the environment, e.g. cookies can only be Secure if the API is served over https
+type CookieConfig struct {
+	Secure   bool
+	SameSite http.SameSite
+}
@@ -0,0 +1,19 @@
+package models
+
+// SystemStats are the totals of the database and the state of the server instance that handled the request
+type SystemStats struct {
+	Users       int64 `json:"users"`
+	BannedUsers int64 `json:"bannedUsers"`
+	Texts       int64 `json:"texts"`
+	// Games is the number of multiplayer games that have scores
+	Games    int64         `json:"games"`
+	Scores   int64         `json:"scores"`
+	Rooms    int64         `json:"rooms"`
+	Instance InstanceStats `json:"instance"`
+}
+
+type InstanceStats struct {
+	WebSocketConnections int `json:"webSocketConnections"`
+	RunningGames         int `json:"runningGames"`
+	Goroutines           int `json:"goroutines"`
+}
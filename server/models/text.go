@@ -1,12 +1,49 @@
 package models
 
 import (
+	"10-typing/textanalysis"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+type TextVisibility string
+
+const (
+	PublicTextVisibility  TextVisibility = "public"
+	PrivateTextVisibility TextVisibility = "private"
+)
+
+// TextStatus is the moderation status of a text. Generated texts and private custom texts are approved on creation,
+// public custom texts are pending until an admin approves or rejects them.
+type TextStatus string
+
+const (
+	PendingTextStatus  TextStatus = "pending"
+	ApprovedTextStatus TextStatus = "approved"
+	RejectedTextStatus TextStatus = "rejected"
+)
+
+const (
+	CustomTextMinLength = 20
+	CustomTextMaxLength = 5000
+)
+
+// TextFilter holds the filters of the search for a new text. Bounds of special characters and numbers of 0 and nil pointers are not applied.
+type TextFilter struct {
+	Language             string
+	Punctuation          bool
+	SpecialCharactersGte int
+	SpecialCharactersLte int
+	NumbersGte           int
+	NumbersLte           int
+	DifficultyGte        *float64
+	DifficultyLte        *float64
+	WordsGte             *int
+	WordsLte             *int
+}
+
 type Text struct {
 	ID                uuid.UUID       `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()" faker:"-"`
 	CreatedAt         time.Time       `json:"createdAt" faker:"-"`
@@ -17,6 +54,55 @@ type Text struct {
 	Punctuation       bool            `json:"punctuation" gorm:"not null;default:false"`
 	SpecialCharacters int             `json:"specialCharacters" gorm:"not null;default:0" faker:"boundary_start=1, boundary_end=20"`
 	Numbers           int             `json:"numbers" gorm:"not null;default:0" faker:"boundary_start=1, boundary_end=20"`
+	Characters        int             `json:"characters" gorm:"not null;default:0" faker:"-"`
+	Letters           int             `json:"letters" gorm:"not null;default:0" faker:"-"`
+	UpperCaseLetters  int             `json:"upperCaseLetters" gorm:"not null;default:0" faker:"-"`
+	Digits            int             `json:"digits" gorm:"not null;default:0" faker:"-"`
+	PunctuationMarks  int             `json:"punctuationMarks" gorm:"not null;default:0" faker:"-"`
+	Words             int             `json:"words" gorm:"not null;default:0;index" faker:"-"`
+	AverageWordLength float64         `json:"averageWordLength" gorm:"not null;default:0" faker:"-"`
+	BigramRarity      float64         `json:"bigramRarity" gorm:"not null;default:0" faker:"-"`
+	Difficulty        float64         `json:"difficulty" gorm:"not null;default:0;index" faker:"-"`
+	ContentHash       string          `json:"contentHash" gorm:"not null;type:varchar(64);default:'';index" faker:"-"`
+	Title             string          `json:"title,omitempty" gorm:"not null;type:varchar(255);default:''" faker:"-"`
+	Source            string          `json:"source,omitempty" gorm:"not null;type:varchar(255);default:''" faker:"-"`
+	UserId            *uuid.UUID      `json:"userId,omitempty" gorm:"type:uuid;index" faker:"-"`
+	Visibility        TextVisibility  `json:"visibility" gorm:"not null;type:varchar(255);default:public" faker:"-"`
+	Status            TextStatus      `json:"status" gorm:"not null;type:varchar(255);default:approved;index" faker:"-"`
+	ModerationNote    string          `json:"moderationNote,omitempty" gorm:"not null;type:text;default:''" faker:"-"`
+	ModeratedAt       *time.Time      `json:"moderatedAt,omitempty" faker:"-"`
+	User              *User           `json:"-" faker:"-"`
 	Scores            []Score         `json:"-" faker:"-"`
 	Games             []Game          `json:"-" faker:"-"`
 }
+
+// SetAnalysis sets the metadata that has been computed from the content of the text.
+// The special characters and numbers of the analysis replace the values that the creator of the text has requested.
+func (t *Text) SetAnalysis(analysis textanalysis.Analysis) {
+	t.SpecialCharacters = analysis.SpecialCharacters
+	t.Numbers = analysis.Numbers
+	t.Characters = analysis.Characters
+	t.Letters = analysis.Letters
+	t.UpperCaseLetters = analysis.UpperCaseLetters
+	t.Digits = analysis.Digits
+	t.PunctuationMarks = analysis.PunctuationMarks
+	t.Words = analysis.Words
+	t.AverageWordLength = analysis.AverageWordLength
+	t.BigramRarity = analysis.BigramRarity
+	t.Difficulty = analysis.Difficulty
+	t.ContentHash = analysis.ContentHash
+}
+
+// IsPublished reports whether the text may be served to all users, i.e. whether it belongs in the text ids cache
+func (t *Text) IsPublished() bool {
+	return t.Visibility == PublicTextVisibility && t.Status == ApprovedTextStatus
+}
+
+// IsVisibleTo reports whether the user may read the text. Unpublished texts are only visible to their owner and to moderators.
+func (t *Text) IsVisibleTo(user *User) bool {
+	if t.IsPublished() {
+		return true
+	}
+
+	return user != nil && (user.HasRole(ModeratorUserRole) || (t.UserId != nil && *t.UserId == user.ID))
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TextImportResult holds the number of texts of an import that have been created,
+// skipped because a text with the same content exists, or skipped because they are invalid
+type TextImportResult struct {
+	Created    int `json:"created"`
+	Duplicates int `json:"duplicates"`
+	Invalid    int `json:"invalid"`
+}
+
+// TextUsageStats holds how often and how well a text has been typed
+type TextUsageStats struct {
+	TextId            uuid.UUID  `json:"-"`
+	TimesTyped        int64      `json:"timesTyped"`
+	Typists           int64      `json:"typists"`
+	AvgWordsPerMinute float64    `json:"avgWordsPerMinute"`
+	AvgAccuracy       float64    `json:"avgAccuracy"`
+	LastTypedAt       *time.Time `json:"lastTypedAt"`
+}
+
+type TextExport struct {
+	Text
+	Usage TextUsageStats `json:"usage"`
+}
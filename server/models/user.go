@@ -1,19 +1,57 @@
 package models
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
+type UserRole string
+
+// a role has all permissions of the roles before it
+const (
+	UserUserRole      UserRole = "user"
+	ModeratorUserRole UserRole = "moderator"
+	AdminUserRole     UserRole = "admin"
+)
+
+var userRoleRanks = map[UserRole]int{UserUserRole: 0, ModeratorUserRole: 1, AdminUserRole: 2}
+
+// IsValid reports whether the role is one of the site roles
+func (r UserRole) IsValid() bool {
+	_, ok := userRoleRanks[r]
+	return ok
+}
+
 type User struct {
-	ID           uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()" faker:"-"`
-	Username     string    `json:"username" gorm:"uniqueIndex;not null;type:varchar(255)" faker:"username"`
-	Password     string    `json:"-" gorm:"-" faker:"password"`
-	PasswordHash string    `json:"-" gorm:"not null;type:varchar(510)" faker:"-"`
-	FirstName    string    `json:"firstName" gorm:"type:varchar(255)" faker:"first_name"`
-	Email        string    `json:"email" gorm:"uniqueIndex;not null;type:varchar(255)" faker:"email"`
-	LastName     string    `json:"lastName" gorm:"type:varchar(255)" faker:"last_name"`
-	IsVerified   bool      `json:"isVerified" gorm:"default:false; not null" faker:"-"`
-	Scores       []Score   `json:"-" faker:"-"`
-	Rooms        []*Room   `json:"-" gorm:"many2many:user_rooms" faker:"-"`
-	RoomsAdmin   []Room    `json:"-" gorm:"foreignKey:AdminId" faker:"-"`
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()" faker:"-"`
+	Username       string     `json:"username" gorm:"uniqueIndex;not null;type:varchar(255)" faker:"username"`
+	Password       string     `json:"-" gorm:"-" faker:"password"`
+	PasswordHash   string     `json:"-" gorm:"not null;type:varchar(510)" faker:"-"`
+	FirstName      string     `json:"firstName" gorm:"type:varchar(255)" faker:"first_name"`
+	Email          string     `json:"email" gorm:"uniqueIndex;not null;type:varchar(255)" faker:"email"`
+	LastName       string     `json:"lastName" gorm:"type:varchar(255)" faker:"last_name"`
+	IsVerified     bool       `json:"isVerified" gorm:"default:false; not null" faker:"-"`
+	Rating         float64    `json:"rating" gorm:"default:1500;not null" faker:"-"`
+	RatedGames     int        `json:"ratedGames" gorm:"default:0;not null" faker:"-"`
+	KeyboardLayout string     `json:"keyboardLayout" gorm:"type:varchar(255);default:qwerty;not null" faker:"-"`
+	Role           UserRole   `json:"role" gorm:"type:varchar(255);default:user;not null" faker:"-"`
+	BannedAt       *time.Time `json:"bannedAt,omitempty" faker:"-"`
+	Scores         []Score    `json:"-" faker:"-"`
+	Rooms          []*Room    `json:"-" gorm:"many2many:user_rooms" faker:"-"`
+	RoomsAdmin     []Room     `json:"-" gorm:"foreignKey:AdminId" faker:"-"`
+}
+
+// HasRole reports whether the user has the role or a role with more permissions. Users without role, e.g. cached before roles existed, are plain users.
+func (u *User) HasRole(role UserRole) bool {
+	return userRoleRanks[u.Role] >= userRoleRanks[role]
+}
+
+func (u *User) IsBanned() bool {
+	return u.BannedAt != nil
+}
+
+type UserFilter struct {
+	Role   UserRole
+	Banned *bool
 }
@@ -10,11 +10,12 @@ type UserNotificationType int
 
 const (
 	RoomInvitation UserNotificationType = iota
+	MatchFound
 )
 
 func (n UserNotificationType) String() (string, error) {
 	const op errors.Op = "models.UserNotificationType.String"
-	f := []string{"room_invitation"}
+	f := []string{"room_invitation", "match_found"}
 
 	if int(n) >= len(f) {
 		err := fmt.Errorf("invalid UserNotificationType")
@@ -45,6 +46,7 @@ func (n *UserNotificationType) ParseFromString(data string) error {
 
 	stringToUserNotificationTypeMap := map[string]UserNotificationType{
 		"room_invitation": RoomInvitation,
+		"match_found":     MatchFound,
 	}
 
 	userNotificationType, ok := stringToUserNotificationTypeMap[data]
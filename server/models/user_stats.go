@@ -0,0 +1,62 @@
+package models
+
+import (
+	"10-typing/keyboard"
+	"time"
+)
+
+type StatsInterval string
+
+const (
+	DayStatsInterval  StatsInterval = "day"
+	WeekStatsInterval StatsInterval = "week"
+)
+
+// UserStatsTTLSeconds is the time the statistics of a user are cached
+const UserStatsTTLSeconds = 300
+
+type UserStats struct {
+	Totals         ScoreTotals             `json:"totals"`
+	Interval       StatsInterval           `json:"interval"`
+	Trend          []ScoreTrendBucket      `json:"trend"`
+	KeyErrors      map[string]int          `json:"keyErrors"`
+	KeyboardLayout string                  `json:"keyboardLayout"`
+	FingerErrors   map[keyboard.Finger]int `json:"fingerErrors"`
+	BestKeys       []KeyErrorCount         `json:"bestKeys"`
+	WorstKeys      []KeyErrorCount         `json:"worstKeys"`
+	CurrentStreak  int                     `json:"currentStreak"`
+	LongestStreak  int                     `json:"longestStreak"`
+	ByLanguage     []ScoreTotalsByFilter   `json:"byLanguage"`
+	ByPunctuation  []ScoreTotalsByFilter   `json:"byPunctuation"`
+	CalculatedAt   time.Time               `json:"calculatedAt"`
+}
+
+type ScoreTotals struct {
+	Scores             int64   `json:"scores"`
+	WordsTyped         int64   `json:"wordsTyped"`
+	TimeTypedSec       float64 `json:"timeTypedSec"`
+	AvgWordsPerMinute  float64 `json:"avgWordsPerMinute"`
+	BestWordsPerMinute float64 `json:"bestWordsPerMinute"`
+	AvgAccuracy        float64 `json:"avgAccuracy"`
+}
+
+// ScoreTrendBucket holds the aggregated scores of a single day or week
+type ScoreTrendBucket struct {
+	Start              time.Time `json:"start"`
+	Scores             int64     `json:"scores"`
+	AvgWordsPerMinute  float64   `json:"avgWordsPerMinute"`
+	BestWordsPerMinute float64   `json:"bestWordsPerMinute"`
+	AvgAccuracy        float64   `json:"avgAccuracy"`
+}
+
+type KeyErrorCount struct {
+	Key    string `json:"key"`
+	Errors int    `json:"errors"`
+}
+
+// ScoreTotalsByFilter holds the aggregated scores of all texts with the same language or punctuation setting
+type ScoreTotalsByFilter struct {
+	Language    string `json:"language,omitempty"`
+	Punctuation *bool  `json:"punctuation,omitempty"`
+	ScoreTotals
+}
@@ -1,11 +1,14 @@
 package models
 
 import (
+	"10-typing/keyboard"
+	"fmt"
+
 	"github.com/go-playground/validator/v10"
 )
 
-var validKeys = []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l"}
-
+// TypingErrors validates that every key of the errors can be typed with one of the supported keyboard layouts
+// and that the number of errors is not negative.
 var TypingErrors validator.Func = func(fl validator.FieldLevel) bool {
 	keyErrors, ok := fl.Field().Interface().(ErrorsJSON)
 
@@ -13,8 +16,8 @@ var TypingErrors validator.Func = func(fl validator.FieldLevel) bool {
 		return false
 	}
 
-	for key := range keyErrors {
-		if !keyIsValid(key) {
+	for key, numberErrors := range keyErrors {
+		if !keyboard.IsValidKeyInAnyLayout(key) || numberErrors < 0 {
 			return false
 		}
 	}
@@ -22,12 +25,45 @@ var TypingErrors validator.Func = func(fl validator.FieldLevel) bool {
 	return true
 }
 
-func keyIsValid(key string) bool {
-	for _, validKey := range validKeys {
-		if key == validKey {
+// ValidateTypingErrorsForLayouts returns an error if a key of the errors can't be typed with any of the keyboard layouts,
+// e.g. the keyboard layout of the user that submits the errors and the layout of the language of the text. Unknown layouts are validated against the default layout.
+func ValidateTypingErrorsForLayouts(keyErrors ErrorsJSON, layoutNames ...string) error {
+	layouts := make([]*keyboard.Layout, 0, len(layoutNames))
+	for _, layoutName := range layoutNames {
+		layout, ok := keyboard.GetLayout(layoutName)
+		if !ok {
+			layout, _ = keyboard.GetLayout(keyboard.DefaultLayoutName)
+		}
+		layouts = append(layouts, layout)
+	}
+
+	for key := range keyErrors {
+		if !isValidKeyInLayouts(key, layouts) {
+			return fmt.Errorf("key %q can't be typed with the keyboard layouts %v", key, layoutNames)
+		}
+	}
+
+	return nil
+}
+
+func isValidKeyInLayouts(key string, layouts []*keyboard.Layout) bool {
+	for _, layout := range layouts {
+		if layout.IsValidKey(key) {
 			return true
 		}
 	}
 
 	return false
 }
+
+var KeyboardLayout validator.Func = func(fl validator.FieldLevel) bool {
+	layoutName, ok := fl.Field().Interface().(string)
+
+	if !ok {
+		return false
+	}
+
+	_, ok = keyboard.GetLayout(layoutName)
+
+	return ok
+}
@@ -0,0 +1,46 @@
+package rating
+
+import "math"
+
+const kFactor = 32.0
+
+// Elo computes the new ratings of all participants of a multiplayer game.
+// The game is treated as a round robin of one on one games between all participants, in which the participant with the better placement wins.
+// placements[i] holds the placement of the participant with the rating ratings[i]; a lower placement is better and equal placements are draws.
+// The rating change of every participant is scaled by the number of opponents, so that the maximum change does not grow with the number of participants.
+func Elo(ratings []float64, placements []int) []float64 {
+	newRatings := make([]float64, len(ratings))
+	copy(newRatings, ratings)
+
+	if len(ratings) < 2 || len(ratings) != len(placements) {
+		return newRatings
+	}
+
+	opponents := float64(len(ratings) - 1)
+
+	for i := range ratings {
+		var delta float64
+
+		for j := range ratings {
+			if i == j {
+				continue
+			}
+
+			expected := 1 / (1 + math.Pow(10, (ratings[j]-ratings[i])/400))
+
+			var actual float64
+			switch {
+			case placements[i] < placements[j]:
+				actual = 1
+			case placements[i] == placements[j]:
+				actual = 0.5
+			}
+
+			delta += actual - expected
+		}
+
+		newRatings[i] = ratings[i] + kFactor/opponents*delta
+	}
+
+	return newRatings
+}
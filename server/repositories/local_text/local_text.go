@@ -0,0 +1,51 @@
+package local_text_repo
+
+import (
+	"10-typing/errors"
+	"10-typing/textgen"
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+)
+
+// textWordsNumber is the number of words of a generated text, which matches the length that is requested from OpenAI
+const textWordsNumber = 100
+
+// LocalTextRepository generates texts without network access from the bundled word lists and markov chains of the textgen package.
+// It implements common.OpenAiRepository, so it can be used in place of the OpenAI API.
+// Two repositories that are created with the same seed generate the same sequence of texts.
+type LocalTextRepository struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+func NewLocalTextRepository(seed int64) *LocalTextRepository {
+	return &LocalTextRepository{rng: rand.New(rand.NewSource(seed))}
+}
+
+func (ltr *LocalTextRepository) GenerateTypingText(ctx context.Context, language string, punctuation bool, specialCharacters, numbers int) (string, error) {
+	const op errors.Op = "local_text_repo.LocalTextRepository.GenerateTypingText"
+	ltr.mu.Lock()
+	defer ltr.mu.Unlock()
+
+	text, err := textgen.GenerateTypingText(ltr.rng, language, punctuation, specialCharacters, numbers, textWordsNumber)
+	if err != nil {
+		return "", errors.E(op, err)
+	}
+
+	return text, nil
+}
+
+func (ltr *LocalTextRepository) GenerateDrillText(ctx context.Context, language string, punctuation bool, targetKeys []string) (string, error) {
+	const op errors.Op = "local_text_repo.LocalTextRepository.GenerateDrillText"
+	ltr.mu.Lock()
+	defer ltr.mu.Unlock()
+
+	text := textgen.GenerateDrill(ltr.rng, language, targetKeys, punctuation, textWordsNumber)
+	if text == "" {
+		return "", errors.E(op, fmt.Errorf("language %s is not supported", language))
+	}
+
+	return text, nil
+}
@@ -0,0 +1,139 @@
+package memory_cache_repo
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// uuidSet is the equivalent of a redis SET of uuids
+type uuidSet map[uuid.UUID]struct{}
+
+// sortedSet is the equivalent of a redis SORTED SET of uuids
+type sortedSet map[uuid.UUID]float64
+
+type sortedSetMember struct {
+	member uuid.UUID
+	score  float64
+}
+
+// sadd adds the members to the set of the key and creates the key if it doesn't exist. The repository must be locked.
+func (repo *MemoryCacheRepository) sadd(key string, members ...uuid.UUID) {
+	if len(members) == 0 {
+		return
+	}
+
+	s, ok := get[uuidSet](repo, key)
+	if !ok {
+		s = make(uuidSet)
+		repo.set(key, s, 0)
+	}
+
+	for _, member := range members {
+		s[member] = struct{}{}
+	}
+}
+
+// srem removes the members from the set of the key and deletes the key if the set is empty. The repository must be locked.
+func (repo *MemoryCacheRepository) srem(key string, members ...uuid.UUID) {
+	s, ok := get[uuidSet](repo, key)
+	if !ok {
+		return
+	}
+
+	for _, member := range members {
+		delete(s, member)
+	}
+	if len(s) == 0 {
+		repo.del(key)
+	}
+}
+
+// smembers returns the members of the set of the key ordered by their string representation
+func (repo *MemoryCacheRepository) smembers(key string) []uuid.UUID {
+	s, _ := get[uuidSet](repo, key)
+
+	members := make([]uuid.UUID, 0, len(s))
+	for member := range s {
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].String() < members[j].String()
+	})
+
+	return members
+}
+
+func (repo *MemoryCacheRepository) sismember(key string, member uuid.UUID) bool {
+	s, _ := get[uuidSet](repo, key)
+	_, ok := s[member]
+
+	return ok
+}
+
+// zadd sets the score of the member in the sorted set of the key. If onlyGreater is true, an existing score is only replaced by a greater one, like ZADD GT.
+func (repo *MemoryCacheRepository) zadd(key string, member uuid.UUID, score float64, onlyGreater bool) {
+	z, ok := get[sortedSet](repo, key)
+	if !ok {
+		z = make(sortedSet)
+		repo.set(key, z, 0)
+	}
+
+	if currentScore, ok := z[member]; ok && onlyGreater && currentScore >= score {
+		return
+	}
+	z[member] = score
+}
+
+// zrem removes the members from the sorted set of the key and deletes the key if the sorted set is empty
+func (repo *MemoryCacheRepository) zrem(key string, members ...uuid.UUID) {
+	z, ok := get[sortedSet](repo, key)
+	if !ok {
+		return
+	}
+
+	for _, member := range members {
+		delete(z, member)
+	}
+	if len(z) == 0 {
+		repo.del(key)
+	}
+}
+
+// zremRangeByScore removes the members whose score is between min and max (inclusive)
+func (repo *MemoryCacheRepository) zremRangeByScore(key string, min, max float64) {
+	z, ok := get[sortedSet](repo, key)
+	if !ok {
+		return
+	}
+
+	for member, score := range z {
+		if score >= min && score <= max {
+			delete(z, member)
+		}
+	}
+	if len(z) == 0 {
+		repo.del(key)
+	}
+}
+
+// zrange returns the members of the sorted set of the key ordered by their score and their string representation, like ZRANGE.
+// If rev is true, the order is reversed, like ZREVRANGE.
+func (repo *MemoryCacheRepository) zrange(key string, rev bool) []sortedSetMember {
+	z, _ := get[sortedSet](repo, key)
+
+	members := make([]sortedSetMember, 0, len(z))
+	for member, score := range z {
+		members = append(members, sortedSetMember{member, score})
+	}
+	sort.Slice(members, func(i, j int) bool {
+		less := members[i].score < members[j].score ||
+			members[i].score == members[j].score && members[i].member.String() < members[j].member.String()
+		if rev {
+			return !less
+		}
+		return less
+	})
+
+	return members
+}
@@ -0,0 +1,140 @@
+package memory_cache_repo
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/models"
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// GET METHODS
+func (repo *MemoryCacheRepository) GetCurrentGameUserIds(ctx context.Context, roomId uuid.UUID) (gameUserIds []uuid.UUID, err error) {
+	repo.locked(func() {
+		gameUserIds = repo.smembers(getCurrentGameUserIdsKey(roomId))
+	})
+
+	return gameUserIds, nil
+}
+
+func (repo *MemoryCacheRepository) GetCurrentGameUsersNumber(ctx context.Context, roomId uuid.UUID) (gameUsersNumber int, err error) {
+	repo.locked(func() {
+		gameUserIds, _ := get[uuidSet](repo, getCurrentGameUserIdsKey(roomId))
+		gameUsersNumber = len(gameUserIds)
+	})
+
+	return gameUsersNumber, nil
+}
+
+func (repo *MemoryCacheRepository) GetCurrentGameStatus(ctx context.Context, roomId uuid.UUID) (models.GameStatus, error) {
+	const op errors.Op = "memory_cache_repo.MemoryCacheRepository.GetCurrentGameStatus"
+
+	currentGame, ok := repo.getCurrentGame(roomId)
+	if !ok {
+		return models.UnstartedGameStatus, errors.E(op, common.ErrNotFound)
+	}
+
+	return currentGame.status, nil
+}
+
+func (repo *MemoryCacheRepository) GetCurrentGameId(ctx context.Context, roomId uuid.UUID) (uuid.UUID, error) {
+	const op errors.Op = "memory_cache_repo.MemoryCacheRepository.GetCurrentGameId"
+
+	currentGame, ok := repo.getCurrentGame(roomId)
+	if !ok {
+		return uuid.Nil, errors.E(op, common.ErrNotFound)
+	}
+
+	return currentGame.gameId, nil
+}
+
+func (repo *MemoryCacheRepository) GetCurrentGame(ctx context.Context, roomId uuid.UUID) (*models.Game, error) {
+	const op errors.Op = "memory_cache_repo.MemoryCacheRepository.GetCurrentGame"
+
+	currentGame, ok := repo.getCurrentGame(roomId)
+	if !ok {
+		return nil, errors.E(op, common.ErrNotFound)
+	}
+
+	return &models.Game{
+		ID:     currentGame.gameId,
+		TextId: currentGame.textId,
+		RoomId: roomId,
+		Status: currentGame.status,
+	}, nil
+}
+
+// SET METHODS
+func (repo *MemoryCacheRepository) SetNewCurrentGame(ctx context.Context, tx common.Transaction, newGameId, textId, roomId uuid.UUID, userIds ...uuid.UUID) error {
+	const op errors.Op = "memory_cache_repo.MemoryCacheRepository.SetNewCurrentGame"
+	var currentGameKey = getCurrentGameKey(roomId)
+
+	if len(userIds) == 0 {
+		err := fmt.Errorf("at least one user id must be specified")
+		return errors.E(op, err)
+	}
+
+	repo.exec(tx, func() {
+		update(repo, currentGameKey, func(currentGame *currentGameValue) {
+			currentGame.gameId = newGameId
+			currentGame.textId = textId
+			currentGame.status = models.UnstartedGameStatus
+		})
+	})
+
+	return nil
+}
+
+func (repo *MemoryCacheRepository) SetCurrentGameUser(ctx context.Context, tx common.Transaction, roomId, userId uuid.UUID) error {
+	repo.exec(tx, func() {
+		repo.sadd(getCurrentGameUserIdsKey(roomId), userId)
+	})
+
+	return nil
+}
+
+func (repo *MemoryCacheRepository) SetCurrentGameStatus(ctx context.Context, tx common.Transaction, roomId uuid.UUID, gameStatus models.GameStatus) error {
+	repo.exec(tx, func() {
+		update(repo, getCurrentGameKey(roomId), func(currentGame *currentGameValue) {
+			currentGame.status = gameStatus
+		})
+	})
+
+	return nil
+}
+
+func (repo *MemoryCacheRepository) DeleteAllCurrentGameUsers(ctx context.Context, tx common.Transaction, roomId uuid.UUID) error {
+	repo.exec(tx, func() {
+		repo.del(getCurrentGameUserIdsKey(roomId))
+	})
+
+	return nil
+}
+
+// IS.. METHODS
+func (repo *MemoryCacheRepository) IsCurrentGame(ctx context.Context, roomId, gameId uuid.UUID) (bool, error) {
+	currentGame, ok := repo.getCurrentGame(roomId)
+
+	return ok && currentGame.gameId == gameId, nil
+}
+
+func (repo *MemoryCacheRepository) IsCurrentGameUser(ctx context.Context, roomId, userId uuid.UUID) (isCurrentGameUser bool, err error) {
+	repo.locked(func() {
+		isCurrentGameUser = repo.sismember(getCurrentGameUserIdsKey(roomId), userId)
+	})
+
+	return isCurrentGameUser, nil
+}
+
+func (repo *MemoryCacheRepository) getCurrentGame(roomId uuid.UUID) (currentGame currentGameValue, ok bool) {
+	repo.locked(func() {
+		var v *currentGameValue
+		if v, ok = get[*currentGameValue](repo, getCurrentGameKey(roomId)); ok {
+			currentGame = *v
+		}
+	})
+
+	return currentGame, ok
+}
@@ -0,0 +1,213 @@
+package memory_cache_repo
+
+import (
+	"10-typing/models"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// The keys are the same as the keys of the redis repository, so that deleting keys by prefix affects the same values.
+// Instead of redis hashes the keys hold pointers to structs.
+
+// ---- ROOM ----
+
+// getRoomKey returns a key: rooms:[room_id]
+//
+// The key holds a *roomValue
+func getRoomKey(roomId uuid.UUID) string {
+	return "rooms:" + roomId.String()
+}
+
+type roomValue struct {
+	adminId         uuid.UUID
+	createdAt       int64
+	updatedAt       int64
+	gameDurationSec int
+}
+
+// getCurrentGameKey returns a key: rooms:[room_id]:current_game
+//
+// The key holds a *currentGameValue
+func getCurrentGameKey(roomId uuid.UUID) string {
+	return getRoomKey(roomId) + ":current_game"
+}
+
+type currentGameValue struct {
+	gameId uuid.UUID
+	textId uuid.UUID
+	status models.GameStatus
+}
+
+// getCurrentGameUserIdsKey returns a key: rooms:[room_id]:current_game:user_ids
+//
+// The key holds a uuidSet
+func getCurrentGameUserIdsKey(roomId uuid.UUID) string {
+	return getCurrentGameKey(roomId) + ":user_ids"
+}
+
+// getCurrentGameScoresUserIdsKey returns a key: rooms:[room_id]:current_game:scores:user_ids
+//
+// The key holds a sortedSet: score:words per minute, member:user id
+func getCurrentGameScoresUserIdsKey(roomId uuid.UUID) string {
+	return getCurrentGameKey(roomId) + ":scores:user_ids"
+}
+
+// getCurrentGameScoreKey returns a key: rooms:[room_id]:current_game:scores:[user_id]
+//
+// The key holds a models.Score
+func getCurrentGameScoreKey(roomId, userId uuid.UUID) string {
+	return getCurrentGameKey(roomId) + ":scores:" + userId.String()
+}
+
+// getRoomSubscriberIdsKey returns a key: rooms:[room_id]:subscribers_ids
+//
+// The key holds a uuidSet
+func getRoomSubscriberIdsKey(roomId uuid.UUID) string {
+	return getRoomKey(roomId) + ":subscribers_ids"
+}
+
+// getRoomSubscriberKey returns a key: rooms:[room_id]:subscribers:[user_id]
+//
+// The key holds a *models.RoomSubscriber
+func getRoomSubscriberKey(roomId, userId uuid.UUID) string {
+	return getRoomKey(roomId) + ":subscribers:" + userId.String()
+}
+
+// getRoomSubscriberConnectionKey returns a key: rooms:[room_id]:subscribers:[user_id]:conns
+//
+// The key holds a sortedSet: score:expiration time in milliseconds, member:connection id
+func getRoomSubscriberConnectionKey(roomId, userId uuid.UUID) string {
+	return getRoomSubscriberKey(roomId, userId) + ":conns"
+}
+
+// getRoomStreamKey returns a key: rooms:[room_id]:stream
+//
+// The key holds a *stream
+func getRoomStreamKey(roomId uuid.UUID) string {
+	return getRoomKey(roomId) + ":stream"
+}
+
+const (
+	streamEntryTypeField    = "type"
+	streamEntryMessageField = "message"
+	streamEntryActionField  = "action"
+)
+
+// ---- USER ----
+
+// getUserKey returns a key: users:[userid]
+//
+// The key holds a *models.User
+func getUserKey(userId uuid.UUID) string {
+	return "users:" + userId.String()
+}
+
+// getUserEmailKey returns a key: user_emails:[email]
+//
+// The key holds a uuid.UUID: user id
+func getUserEmailKey(email string) string {
+	return "user_emails:" + email
+}
+
+// getUserStatsKey returns a key: users:[userid]:stats:[interval]
+//
+// The key holds a []byte: JSON representation of models.UserStats
+func getUserStatsKey(userId uuid.UUID, interval models.StatsInterval) string {
+	return getUserKey(userId) + ":stats:" + string(interval)
+}
+
+// getUserKeyboardStatsKey returns a key: users:[userid]:stats:keyboard:[layout]
+//
+// The key holds a []byte: JSON representation of models.KeyboardStats
+func getUserKeyboardStatsKey(userId uuid.UUID, layoutName string) string {
+	return getUserKey(userId) + ":stats:keyboard:" + layoutName
+}
+
+// getUserNotificationStreamKey returns a key: users:[userid]:notifications
+//
+// The key holds a *stream
+func getUserNotificationStreamKey(userId uuid.UUID) string {
+	return getUserKey(userId) + ":notifications"
+}
+
+// ---- TEXT ----
+
+const (
+	// text_ids holds a uuidSet
+	textIdsKey = "text_ids"
+)
+
+// ---- MATCHMAKING ----
+
+// getMatchmakingQueueKey returns a key: matchmaking:queues:[language]:[punctuation]:[skill_bracket]
+//
+// The key holds a sortedSet: score:time the user joined the queue in milliseconds, member:user id
+func getMatchmakingQueueKey(language string, punctuation bool, skillBracket string) string {
+	return "matchmaking:queues:" + language + ":" + strconv.FormatBool(punctuation) + ":" + skillBracket
+}
+
+// getMatchmakingTicketKey returns a key: matchmaking:tickets:[user_id]
+//
+// The key holds a models.MatchmakingTicket
+func getMatchmakingTicketKey(userId uuid.UUID) string {
+	return "matchmaking:tickets:" + userId.String()
+}
+
+// ---- LEADERBOARD ----
+
+// getLeaderboardKey returns a key: leaderboards:[window]:[period]:[language|all]:[punctuation|all]
+//
+// The key holds a sortedSet: score:best words per minute of the user in the period, member:user id
+func getLeaderboardKey(filter models.LeaderboardFilter) string {
+	language := "all"
+	if filter.Language != "" {
+		language = filter.Language
+	}
+
+	punctuation := "all"
+	if filter.Punctuation != nil {
+		punctuation = strconv.FormatBool(*filter.Punctuation)
+	}
+
+	return "leaderboards:" + string(filter.Window) + ":" + filter.Period + ":" + language + ":" + punctuation
+}
+
+// ---- SESSION ----
+
+// getSessionKey returns a key: sessions:[tokenhash]
+//
+// The key holds a uuid.UUID: user id
+func getSessionKey(tokenHash string) string {
+	return "sessions:" + tokenHash
+}
+
+// getUserSessionsKey returns a key: user_sessions:[user_id]
+//
+// The key holds a userSessions: the time each session of the user expires by its token hash
+func getUserSessionsKey(userId uuid.UUID) string {
+	return "user_sessions:" + userId.String()
+}
+
+// ---- RATE LIMIT ----
+
+// getRateLimitKey returns a key: rate_limits:[key]
+//
+// The key holds a models.TokenBucket
+func getRateLimitKey(key string) string {
+	return "rate_limits:" + key
+}
+
+// getLoginFailuresKey returns a key: login_failures:[email]:[client ip]
+//
+// The key holds an int: number of failed logins
+func getLoginFailuresKey(email, clientIp string) string {
+	return "login_failures:" + email + ":" + clientIp
+}
+
+// getEmailLoginFailuresKey returns a key: email_login_failures:[email]
+//
+// The key holds an int: number of failed logins from all client ips
+func getEmailLoginFailuresKey(email string) string {
+	return "email_login_failures:" + email
+}
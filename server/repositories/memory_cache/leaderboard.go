@@ -0,0 +1,79 @@
+package memory_cache_repo
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/models"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SetLeaderboardScore sets the words per minute of the user in the leaderboard if they are higher than the user's current best.
+// If expireAt is not the zero time, the leaderboard expires at that time.
+func (repo *MemoryCacheRepository) SetLeaderboardScore(ctx context.Context, tx common.Transaction, filter models.LeaderboardFilter, userId uuid.UUID, wordsPerMinute float64, expireAt time.Time) error {
+	var leaderboardKey = getLeaderboardKey(filter)
+
+	repo.exec(tx, func() {
+		repo.zadd(leaderboardKey, userId, wordsPerMinute, true)
+		if !expireAt.IsZero() {
+			repo.expireAt(leaderboardKey, expireAt)
+		}
+	})
+
+	return nil
+}
+
+// GetLeaderboardEntries returns the entries of the leaderboard ordered by words per minute together with the total number of entries.
+// The usernames of the entries are not set.
+func (repo *MemoryCacheRepository) GetLeaderboardEntries(ctx context.Context, filter models.LeaderboardFilter, limit, offset int) ([]models.LeaderboardEntry, int64, error) {
+	var members []sortedSetMember
+
+	repo.locked(func() {
+		members = repo.zrange(getLeaderboardKey(filter), true)
+	})
+
+	total := int64(len(members))
+	entries := make([]models.LeaderboardEntry, 0, limit)
+	for i := offset; i < len(members) && i < offset+limit; i++ {
+		entries = append(entries, models.LeaderboardEntry{
+			Rank:           int64(i) + 1,
+			UserId:         members[i].member,
+			WordsPerMinute: members[i].score,
+		})
+	}
+
+	return entries, total, nil
+}
+
+// GetLeaderboardEntry returns the entry of the user in the leaderboard. The username of the entry is not set.
+// It returns common.ErrNotFound if the user has no entry in the leaderboard.
+func (repo *MemoryCacheRepository) GetLeaderboardEntry(ctx context.Context, filter models.LeaderboardFilter, userId uuid.UUID) (*models.LeaderboardEntry, error) {
+	const op errors.Op = "memory_cache_repo.MemoryCacheRepository.GetLeaderboardEntry"
+	var members []sortedSetMember
+
+	repo.locked(func() {
+		members = repo.zrange(getLeaderboardKey(filter), true)
+	})
+
+	for i, member := range members {
+		if member.member == userId {
+			return &models.LeaderboardEntry{
+				Rank:           int64(i) + 1,
+				UserId:         userId,
+				WordsPerMinute: member.score,
+			}, nil
+		}
+	}
+
+	return nil, errors.E(op, common.ErrNotFound)
+}
+
+func (repo *MemoryCacheRepository) DeleteAllLeaderboards(ctx context.Context) error {
+	repo.locked(func() {
+		repo.delPrefix("leaderboards:")
+	})
+
+	return nil
+}
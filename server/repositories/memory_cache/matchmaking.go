@@ -0,0 +1,96 @@
+package memory_cache_repo
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/models"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateMatchmakingTicket adds the ticket's user to the matchmaking queue that matches the ticket's preferences
+// and stores the ticket, which expires after models.MatchmakingTicketTTLSeconds.
+// Nothing is changed if the user already has a ticket, in which case created is false.
+func (repo *MemoryCacheRepository) CreateMatchmakingTicket(ctx context.Context, ticket models.MatchmakingTicket) (created bool, err error) {
+	var matchmakingQueueKey = getMatchmakingQueueKey(ticket.Language, ticket.Punctuation, ticket.SkillBracket)
+	var matchmakingTicketKey = getMatchmakingTicketKey(ticket.UserId)
+
+	repo.locked(func() {
+		if _, ok := get[models.MatchmakingTicket](repo, matchmakingTicketKey); ok {
+			return
+		}
+
+		repo.zadd(matchmakingQueueKey, ticket.UserId, float64(ticket.CreatedAt.UnixMilli()), false)
+		repo.set(matchmakingTicketKey, ticket, models.MatchmakingTicketTTLSeconds*time.Second)
+		created = true
+	})
+
+	return created, nil
+}
+
+func (repo *MemoryCacheRepository) GetMatchmakingTicket(ctx context.Context, userId uuid.UUID) (*models.MatchmakingTicket, error) {
+	const op errors.Op = "memory_cache_repo.MemoryCacheRepository.GetMatchmakingTicket"
+	var ticket models.MatchmakingTicket
+	var ok bool
+
+	repo.locked(func() {
+		ticket, ok = get[models.MatchmakingTicket](repo, getMatchmakingTicketKey(userId))
+	})
+	if !ok {
+		return nil, errors.E(op, common.ErrNotFound)
+	}
+
+	// the creation time is stored in milliseconds in redis
+	ticket.CreatedAt = time.UnixMilli(ticket.CreatedAt.UnixMilli())
+
+	return &ticket, nil
+}
+
+// DeleteMatchmakingTicket removes the user from the matchmaking queue and deletes the ticket.
+func (repo *MemoryCacheRepository) DeleteMatchmakingTicket(ctx context.Context, ticket models.MatchmakingTicket) error {
+	repo.locked(func() {
+		repo.zrem(getMatchmakingQueueKey(ticket.Language, ticket.Punctuation, ticket.SkillBracket), ticket.UserId)
+		repo.del(getMatchmakingTicketKey(ticket.UserId))
+	})
+
+	return nil
+}
+
+// PopMatchmakingGroup removes the groupSize users that have been waiting the longest from the queue that the ticket belongs to
+// and deletes their tickets, which are returned. Users whose tickets have expired at the time now are dropped from the queue beforehand.
+// It returns common.ErrNotFound if the queue does not hold enough users.
+func (repo *MemoryCacheRepository) PopMatchmakingGroup(ctx context.Context, ticket models.MatchmakingTicket, groupSize int, now time.Time) (tickets []models.MatchmakingTicket, err error) {
+	const op errors.Op = "memory_cache_repo.MemoryCacheRepository.PopMatchmakingGroup"
+	var matchmakingQueueKey = getMatchmakingQueueKey(ticket.Language, ticket.Punctuation, ticket.SkillBracket)
+
+	repo.locked(func() {
+		expiredMilli := now.Add(-models.MatchmakingTicketTTLSeconds * time.Second).UnixMilli()
+		repo.zremRangeByScore(matchmakingQueueKey, 0, float64(expiredMilli))
+
+		members := repo.zrange(matchmakingQueueKey, false)
+		if len(members) < groupSize {
+			err = errors.E(op, common.ErrNotFound)
+			return
+		}
+
+		// the tickets of a queue only differ in their user and their creation time, which is the score of the user in the queue
+		for _, member := range members[:groupSize] {
+			tickets = append(tickets, models.MatchmakingTicket{
+				UserId:       member.member,
+				Language:     ticket.Language,
+				Punctuation:  ticket.Punctuation,
+				SkillBracket: ticket.SkillBracket,
+				CreatedAt:    time.UnixMilli(int64(member.score)),
+			})
+			repo.zrem(matchmakingQueueKey, member.member)
+			repo.del(getMatchmakingTicketKey(member.member))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tickets, nil
+}
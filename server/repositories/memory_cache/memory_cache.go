@@ -0,0 +1,210 @@
+package memory_cache_repo
+
+import (
+	realclock "10-typing/clock"
+	"10-typing/common"
+	"10-typing/errors"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ common.CacheRepository = (*MemoryCacheRepository)(nil)
+
+// MemoryCacheRepository implements common.CacheRepository without redis. It holds the same keys as the redis repository,
+// expires them lazily with the given clock and queues the commands of transactions until they are committed,
+// so that services can be tested deterministically.
+type MemoryCacheRepository struct {
+	mu      sync.Mutex
+	entries map[string]*entry
+	// streamAdded is closed and replaced whenever an entry is added to a stream, which wakes up blocked stream readers
+	streamAdded chan struct{}
+	clock       common.Clock
+}
+
+type entry struct {
+	value    any
+	expireAt time.Time
+}
+
+// NewMemoryCacheRepository returns an empty cache. If clock is nil, the real clock is used.
+func NewMemoryCacheRepository(clock common.Clock) *MemoryCacheRepository {
+	if clock == nil {
+		clock = realclock.New()
+	}
+
+	return &MemoryCacheRepository{
+		entries:     make(map[string]*entry),
+		streamAdded: make(chan struct{}),
+		clock:       clock,
+	}
+}
+
+// MemoryTransaction queues commands like a redis pipeline and runs all of them at once on Commit.
+type MemoryTransaction struct {
+	mu   sync.Mutex
+	repo *MemoryCacheRepository
+	cmds []func()
+}
+
+func (t *MemoryTransaction) Conn() any {
+	return t
+}
+
+func (t *MemoryTransaction) Commit(ctx context.Context) error {
+	const op errors.Op = "memory_cache_repo.MemoryTransaction.Commit"
+
+	if err := ctx.Err(); err != nil {
+		return errors.E(op, err)
+	}
+
+	t.mu.Lock()
+	cmds := t.cmds
+	t.cmds = nil
+	t.mu.Unlock()
+
+	t.repo.mu.Lock()
+	defer t.repo.mu.Unlock()
+	for _, cmd := range cmds {
+		cmd()
+	}
+
+	return nil
+}
+
+func (t *MemoryTransaction) Rollback() error {
+	t.mu.Lock()
+	t.cmds = nil
+	t.mu.Unlock()
+
+	return nil
+}
+
+func (repo *MemoryCacheRepository) BeginPipeline() common.Transaction {
+	return &MemoryTransaction{repo: repo}
+}
+
+func (repo *MemoryCacheRepository) BeginTx() common.Transaction {
+	return &MemoryTransaction{repo: repo}
+}
+
+// Ping only fails if the context is done, since the cache is always reachable
+func (repo *MemoryCacheRepository) Ping(ctx context.Context) error {
+	const op errors.Op = "memory_cache_repo.MemoryCacheRepository.Ping"
+
+	if err := ctx.Err(); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+// exec runs the command immediately or queues it in the transaction if one is given.
+// The command is always run while the repository is locked.
+func (repo *MemoryCacheRepository) exec(tx common.Transaction, cmd func()) {
+	if tx != nil {
+		t := tx.Conn().(*MemoryTransaction)
+		t.mu.Lock()
+		t.cmds = append(t.cmds, cmd)
+		t.mu.Unlock()
+		return
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	cmd()
+}
+
+// locked runs f while the repository is locked
+func (repo *MemoryCacheRepository) locked(f func()) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	f()
+}
+
+// lookup returns the entry of the key and deletes it if it has expired. The repository must be locked.
+func (repo *MemoryCacheRepository) lookup(key string) (*entry, bool) {
+	e, ok := repo.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	if !e.expireAt.IsZero() && !repo.clock.Now().Before(e.expireAt) {
+		delete(repo.entries, key)
+		return nil, false
+	}
+
+	return e, true
+}
+
+func (repo *MemoryCacheRepository) exists(key string) bool {
+	_, ok := repo.lookup(key)
+	return ok
+}
+
+// set replaces the value of the key. A ttl of 0 means that the key does not expire.
+func (repo *MemoryCacheRepository) set(key string, value any, ttl time.Duration) {
+	e := &entry{value: value}
+	if ttl > 0 {
+		e.expireAt = repo.clock.Now().Add(ttl)
+	}
+
+	repo.entries[key] = e
+}
+
+func (repo *MemoryCacheRepository) expireAt(key string, expireAt time.Time) {
+	if e, ok := repo.lookup(key); ok {
+		e.expireAt = expireAt
+	}
+}
+
+func (repo *MemoryCacheRepository) del(keys ...string) {
+	for _, key := range keys {
+		delete(repo.entries, key)
+	}
+}
+
+// delPrefix deletes all keys that start with prefix, like deleting the keys that match the pattern [prefix]* in redis
+func (repo *MemoryCacheRepository) delPrefix(prefix string) {
+	for key := range repo.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(repo.entries, key)
+		}
+	}
+}
+
+// get returns the value of the key if it exists and is of type T
+func get[T any](repo *MemoryCacheRepository, key string) (T, bool) {
+	var zero T
+
+	e, ok := repo.lookup(key)
+	if !ok {
+		return zero, false
+	}
+
+	v, ok := e.value.(T)
+	if !ok {
+		return zero, false
+	}
+
+	return v, true
+}
+
+// update changes the value of the key in place and creates the key if it doesn't exist, like HSET on a single field.
+// The expiration time of an existing key is kept.
+func update[T any](repo *MemoryCacheRepository, key string, f func(v *T)) {
+	e, ok := repo.lookup(key)
+	if !ok {
+		e = &entry{}
+		repo.entries[key] = e
+	}
+
+	v, _ := e.value.(*T)
+	if v == nil {
+		v = new(T)
+		e.value = v
+	}
+
+	f(v)
+}
@@ -0,0 +1,220 @@
+package memory_cache_repo
+
+import (
+	"10-typing/clock"
+	"10-typing/common"
+	"10-typing/models"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestMemoryTransaction(t *testing.T) {
+	tests := []struct {
+		name string
+		// finish ends the transaction in which the text id has been added
+		finish     func(ctx context.Context, tx common.Transaction) error
+		wantErr    bool
+		wantExists bool
+	}{
+		{
+			name:       "commit runs the queued commands",
+			finish:     func(ctx context.Context, tx common.Transaction) error { return tx.Commit(ctx) },
+			wantExists: true,
+		},
+		{
+			name:   "rollback discards the queued commands",
+			finish: func(ctx context.Context, tx common.Transaction) error { return tx.Rollback() },
+		},
+		{
+			name: "commit after rollback runs no commands",
+			finish: func(ctx context.Context, tx common.Transaction) error {
+				if err := tx.Rollback(); err != nil {
+					return err
+				}
+				return tx.Commit(ctx)
+			},
+		},
+		{
+			name: "commit of a canceled context fails",
+			finish: func(ctx context.Context, tx common.Transaction) error {
+				ctx, cancel := context.WithCancel(ctx)
+				cancel()
+				return tx.Commit(ctx)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		for _, begin := range []string{"pipeline", "transaction"} {
+			t.Run(tt.name+" of "+begin, func(t *testing.T) {
+				ctx := context.Background()
+				repo := NewMemoryCacheRepository(clock.NewFake(time.Now()))
+				textId := uuid.New()
+
+				tx := repo.BeginTx()
+				if begin == "pipeline" {
+					tx = repo.BeginPipeline()
+				}
+				if err := repo.SetTextId(ctx, tx, textId); err != nil {
+					t.Fatalf("SetTextId() error = %v", err)
+				}
+
+				// the commands are queued until the transaction is committed
+				if exists, _ := repo.TextIdExists(ctx, textId); exists {
+					t.Fatalf("text id exists before commit")
+				}
+
+				if err := tt.finish(ctx, tx); (err != nil) != tt.wantErr {
+					t.Fatalf("finishing the transaction error = %v, wantErr %v", err, tt.wantErr)
+				}
+
+				if exists, _ := repo.TextIdExists(ctx, textId); exists != tt.wantExists {
+					t.Errorf("text id exists = %v, want %v", exists, tt.wantExists)
+				}
+			})
+		}
+	}
+}
+
+func TestMemoryCacheRepositoryExpiration(t *testing.T) {
+	tests := []struct {
+		name         string
+		advance      time.Duration
+		wantFailures int
+	}{
+		{name: "keeps the key until it expires", advance: models.LoginLockoutDurationSec*time.Second - time.Millisecond, wantFailures: 2},
+		{name: "expires the key after its ttl", advance: models.LoginLockoutDurationSec * time.Second, wantFailures: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			fakeClock := clock.NewFake(time.Now())
+			repo := NewMemoryCacheRepository(fakeClock)
+
+			if _, err := repo.IncrementLoginFailures(ctx, "user@example.com", "127.0.0.1"); err != nil {
+				t.Fatalf("IncrementLoginFailures() error = %v", err)
+			}
+			// the second failed login doesn't extend the expiration of the first one
+			fakeClock.Advance(time.Second)
+			if _, err := repo.IncrementLoginFailures(ctx, "user@example.com", "127.0.0.1"); err != nil {
+				t.Fatalf("IncrementLoginFailures() error = %v", err)
+			}
+			fakeClock.Advance(tt.advance - time.Second)
+
+			loginFailures, err := repo.GetLoginFailures(ctx, "user@example.com", "127.0.0.1")
+			if err != nil {
+				t.Fatalf("GetLoginFailures() error = %v", err)
+			}
+			wantLoginFailures := models.LoginFailures{FromClientIp: tt.wantFailures, FromAllClientIps: tt.wantFailures}
+			if *loginFailures != wantLoginFailures {
+				t.Errorf("GetLoginFailures() = %+v, want %+v", *loginFailures, wantLoginFailures)
+			}
+		})
+	}
+}
+
+func TestMemoryCacheRepositoryGetPushMessages(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	fakeClock := clock.NewFake(time.Now())
+	repo := NewMemoryCacheRepository(fakeClock)
+	roomId := uuid.New()
+
+	// the message published before the start time is not read
+	if err := repo.PublishPushMessage(ctx, nil, roomId, models.PushMessage{Type: models.Cursor, Payload: 1}); err != nil {
+		t.Fatalf("PublishPushMessage() error = %v", err)
+	}
+	fakeClock.Advance(time.Millisecond)
+	pushMessages := repo.GetPushMessages(ctx, roomId, fakeClock.Now())
+
+	tx := repo.BeginTx()
+	if err := repo.PublishPushMessage(ctx, tx, roomId, models.PushMessage{Type: models.Cursor, Payload: 2}); err != nil {
+		t.Fatalf("PublishPushMessage() error = %v", err)
+	}
+	if err := repo.PublishAction(ctx, tx, roomId, models.TerminateAction); err != nil {
+		t.Fatalf("PublishAction() error = %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	result := <-pushMessages
+	if result.Error != nil {
+		t.Fatalf("GetPushMessages() error = %v", result.Error)
+	}
+	var pushMessage struct {
+		Payload int `json:"payload"`
+	}
+	if err := json.Unmarshal(result.Value, &pushMessage); err != nil {
+		t.Fatalf("unmarshal push message error = %v", err)
+	}
+	if pushMessage.Payload != 2 {
+		t.Errorf("push message payload = %d, want 2", pushMessage.Payload)
+	}
+
+	// the terminate action ends the subscription
+	for result := range pushMessages {
+		if result.Error == nil {
+			t.Errorf("GetPushMessages() = %s after the terminate action", result.Value)
+		}
+	}
+}
+
+func TestMemoryCacheRepositoryCreateMatchmakingTicket(t *testing.T) {
+	ctx := context.Background()
+	fakeClock := clock.NewFake(time.Now())
+	repo := NewMemoryCacheRepository(fakeClock)
+	ticket := models.MatchmakingTicket{UserId: uuid.New(), Language: "en", SkillBracket: "novice", CreatedAt: fakeClock.Now()}
+
+	if created, err := repo.CreateMatchmakingTicket(ctx, ticket); err != nil || !created {
+		t.Fatalf("CreateMatchmakingTicket() = %v, %v, want created", created, err)
+	}
+
+	// the user can't be put into another queue while the ticket exists
+	otherTicket := ticket
+	otherTicket.Punctuation = true
+	if created, err := repo.CreateMatchmakingTicket(ctx, otherTicket); err != nil || created {
+		t.Fatalf("CreateMatchmakingTicket() of a second ticket = %v, %v, want not created", created, err)
+	}
+	if _, err := repo.PopMatchmakingGroup(ctx, otherTicket, 1, fakeClock.Now()); err == nil {
+		t.Errorf("PopMatchmakingGroup() of the queue of the second ticket found the user")
+	}
+
+	fakeClock.Advance(models.MatchmakingTicketTTLSeconds * time.Second)
+	otherTicket.CreatedAt = fakeClock.Now()
+	if created, err := repo.CreateMatchmakingTicket(ctx, otherTicket); err != nil || !created {
+		t.Fatalf("CreateMatchmakingTicket() after the expiration of the ticket = %v, %v, want created", created, err)
+	}
+}
+
+func TestMemoryCacheRepositoryDeleteLoginFailures(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryCacheRepository(clock.NewFake(time.Now()))
+
+	for _, clientIp := range []string{"127.0.0.1", "127.0.0.2"} {
+		if _, err := repo.IncrementLoginFailures(ctx, "user@example.com", clientIp); err != nil {
+			t.Fatalf("IncrementLoginFailures() error = %v", err)
+		}
+	}
+
+	// the failed logins from all client ips are kept after a successful login
+	if err := repo.DeleteLoginFailures(ctx, "user@example.com", "127.0.0.1"); err != nil {
+		t.Fatalf("DeleteLoginFailures() error = %v", err)
+	}
+
+	loginFailures, err := repo.GetLoginFailures(ctx, "user@example.com", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("GetLoginFailures() error = %v", err)
+	}
+	wantLoginFailures := models.LoginFailures{FromClientIp: 0, FromAllClientIps: 2}
+	if *loginFailures != wantLoginFailures {
+		t.Errorf("GetLoginFailures() = %+v, want %+v", *loginFailures, wantLoginFailures)
+	}
+}
@@ -0,0 +1,69 @@
+package memory_cache_repo
+
+import (
+	"10-typing/models"
+	"context"
+	"time"
+)
+
+// TakeRateLimitToken refills the token bucket of the key and takes a token if one is available.
+// The bucket expires once it would be full again.
+func (repo *MemoryCacheRepository) TakeRateLimitToken(ctx context.Context, key string, limit models.RateLimit) (*models.RateLimitResult, error) {
+	var rateLimitKey = getRateLimitKey(key)
+	var result models.RateLimitResult
+
+	repo.locked(func() {
+		bucket, _ := get[models.TokenBucket](repo, rateLimitKey)
+		result = bucket.Take(limit, repo.clock.Now())
+		repo.set(rateLimitKey, bucket, limit.Per)
+	})
+
+	return &result, nil
+}
+
+func (repo *MemoryCacheRepository) GetLoginFailures(ctx context.Context, email, clientIp string) (*models.LoginFailures, error) {
+	var loginFailures models.LoginFailures
+
+	repo.locked(func() {
+		loginFailures.FromClientIp, _ = get[int](repo, getLoginFailuresKey(email, clientIp))
+		loginFailures.FromAllClientIps, _ = get[int](repo, getEmailLoginFailuresKey(email))
+	})
+
+	return &loginFailures, nil
+}
+
+// IncrementLoginFailures counts the failed logins in fixed windows: the keys are only created with an expiration by the first failed login,
+// the following failed logins increment them without changing their expiration.
+func (repo *MemoryCacheRepository) IncrementLoginFailures(ctx context.Context, email, clientIp string) (*models.LoginFailures, error) {
+	var loginFailures models.LoginFailures
+
+	repo.locked(func() {
+		loginFailures.FromClientIp = repo.incrementLoginFailures(getLoginFailuresKey(email, clientIp))
+		loginFailures.FromAllClientIps = repo.incrementLoginFailures(getEmailLoginFailuresKey(email))
+	})
+
+	return &loginFailures, nil
+}
+
+// incrementLoginFailures increments the failed logins of the key, which must be called while the repository is locked
+func (repo *MemoryCacheRepository) incrementLoginFailures(key string) int {
+	e, ok := repo.lookup(key)
+	if !ok {
+		repo.set(key, 1, models.LoginLockoutDurationSec*time.Second)
+		return 1
+	}
+
+	loginFailures, _ := e.value.(int)
+	loginFailures++
+	e.value = loginFailures
+
+	return loginFailures
+}
+
+func (repo *MemoryCacheRepository) DeleteLoginFailures(ctx context.Context, email, clientIp string) error {
+	repo.locked(func() {
+		repo.del(getLoginFailuresKey(email, clientIp))
+	})
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package memory_cache_repo
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/models"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (repo *MemoryCacheRepository) GetRoomInCacheOrDb(ctx context.Context, dbRepo common.DBRepository, roomId uuid.UUID) (*models.Room, error) {
+	const op errors.Op = "memory_cache_repo.MemoryCacheRepository.GetRoomInCacheOrDb"
+
+	if room, ok := repo.getRoom(roomId); ok {
+		return room, nil
+	}
+
+	room, err := dbRepo.FindRoomWithUsers(ctx, nil, roomId)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	if err = repo.SetRoom(ctx, nil, *room); err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return room, nil
+}
+
+func (repo *MemoryCacheRepository) GetRoomGameDurationSec(ctx context.Context, roomId uuid.UUID) (gameDurationSec int, err error) {
+	const op errors.Op = "memory_cache_repo.MemoryCacheRepository.GetRoomGameDurationSec"
+
+	room, ok := repo.getRoom(roomId)
+	if !ok {
+		return 0, errors.E(op, common.ErrNotFound)
+	}
+
+	return room.GameDurationSec, nil
+}
+
+func (repo *MemoryCacheRepository) SetRoom(ctx context.Context, tx common.Transaction, room models.Room) error {
+	var roomKey = getRoomKey(room.ID)
+	var roomSubscriberIdsKey = getRoomSubscriberIdsKey(room.ID)
+
+	repo.exec(tx, func() {
+		update(repo, roomKey, func(v *roomValue) {
+			v.adminId = room.AdminId
+			v.createdAt = room.CreatedAt.UnixMilli()
+			v.updatedAt = room.UpdatedAt.UnixMilli()
+			v.gameDurationSec = room.GameDurationSec
+		})
+
+		for _, subscriber := range room.Users {
+			update(repo, getRoomSubscriberKey(room.ID, subscriber.ID), func(roomSubscriber *models.RoomSubscriber) {
+				roomSubscriber.UserId = subscriber.ID
+				roomSubscriber.Username = subscriber.Username
+				roomSubscriber.Status = models.InactiveSubscriberStatus
+				roomSubscriber.GameStatus = models.UnstartedSubscriberGameStatus
+			})
+			repo.sadd(roomSubscriberIdsKey, subscriber.ID)
+		}
+	})
+
+	return nil
+}
+
+func (repo *MemoryCacheRepository) SetRoomAdmin(ctx context.Context, tx common.Transaction, roomId, adminId uuid.UUID) error {
+	repo.exec(tx, func() {
+		update(repo, getRoomKey(roomId), func(v *roomValue) {
+			v.adminId = adminId
+		})
+	})
+
+	return nil
+}
+
+func (repo *MemoryCacheRepository) SetRoomGameDurationSec(ctx context.Context, tx common.Transaction, roomId uuid.UUID, gameDurationSec int) error {
+	repo.exec(tx, func() {
+		update(repo, getRoomKey(roomId), func(v *roomValue) {
+			v.gameDurationSec = gameDurationSec
+		})
+	})
+
+	return nil
+}
+
+func (repo *MemoryCacheRepository) RoomHasAdmin(ctx context.Context, roomId, adminId uuid.UUID) (bool, error) {
+	const op errors.Op = "memory_cache_repo.MemoryCacheRepository.RoomHasAdmin"
+
+	room, ok := repo.getRoom(roomId)
+	if !ok {
+		return false, errors.E(op, common.ErrNotFound)
+	}
+
+	return room.AdminId == adminId, nil
+}
+
+func (repo *MemoryCacheRepository) RoomHasSubscribers(ctx context.Context, roomId uuid.UUID, userIds ...uuid.UUID) (hasSubscribers bool, err error) {
+	const op errors.Op = "memory_cache_repo.MemoryCacheRepository.RoomHasSubscribers"
+	var roomSubscriberIdsKey = getRoomSubscriberIdsKey(roomId)
+
+	if len(userIds) == 0 {
+		err := fmt.Errorf("at least one user id must be specified")
+		return false, errors.E(op, err)
+	}
+
+	repo.locked(func() {
+		hasSubscribers = true
+		for _, userId := range userIds {
+			if !repo.sismember(roomSubscriberIdsKey, userId) {
+				hasSubscribers = false
+				return
+			}
+		}
+	})
+
+	return hasSubscribers, nil
+}
+
+func (repo *MemoryCacheRepository) RoomExists(ctx context.Context, roomId uuid.UUID) (exists bool, err error) {
+	repo.locked(func() {
+		exists = repo.exists(getRoomKey(roomId))
+	})
+
+	return exists, nil
+}
+
+func (repo *MemoryCacheRepository) DeleteRoom(ctx context.Context, roomId uuid.UUID) error {
+	repo.locked(func() {
+		repo.delPrefix(getRoomKey(roomId))
+	})
+
+	return nil
+}
+
+func (repo *MemoryCacheRepository) DeleteAllRooms(ctx context.Context) error {
+	repo.locked(func() {
+		repo.delPrefix("rooms:")
+	})
+
+	return nil
+}
+
+func (repo *MemoryCacheRepository) getRoom(roomId uuid.UUID) (room *models.Room, ok bool) {
+	repo.locked(func() {
+		var v *roomValue
+		if v, ok = get[*roomValue](repo, getRoomKey(roomId)); ok {
+			room = &models.Room{
+				ID:              roomId,
+				AdminId:         v.adminId,
+				CreatedAt:       time.UnixMilli(v.createdAt),
+				UpdatedAt:       time.UnixMilli(v.updatedAt),
+				GameDurationSec: v.gameDurationSec,
+			}
+		}
+	})
+
+	return room, ok
+}
@@ -0,0 +1,115 @@
+package memory_cache_repo
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (repo *MemoryCacheRepository) PublishPushMessage(ctx context.Context, tx common.Transaction, roomId uuid.UUID, pushMessage models.PushMessage) error {
+	const op errors.Op = "memory_cache_repo.MemoryCacheRepository.PublishPushMessage"
+	var roomStreamKey = getRoomStreamKey(roomId)
+
+	pushMessageData, err := json.Marshal(pushMessage)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	repo.exec(tx, func() {
+		repo.xadd(roomStreamKey, 0, map[string]string{
+			streamEntryTypeField:    strconv.Itoa(int(models.PushMessageStreamEntryType)),
+			streamEntryMessageField: string(pushMessageData),
+		})
+	})
+
+	return nil
+}
+
+func (repo *MemoryCacheRepository) PublishAction(ctx context.Context, tx common.Transaction, roomId uuid.UUID, action models.StreamActionType) error {
+	var roomStreamKey = getRoomStreamKey(roomId)
+
+	repo.exec(tx, func() {
+		repo.xadd(roomStreamKey, 0, map[string]string{
+			streamEntryTypeField:   strconv.Itoa(int(models.ActionStreamEntryType)),
+			streamEntryActionField: strconv.Itoa(int(action)),
+		})
+	})
+
+	return nil
+}
+
+func (repo *MemoryCacheRepository) GetPushMessages(ctx context.Context, roomId uuid.UUID, startTime time.Time) <-chan models.StreamSubscriptionResult[[]byte] {
+	const op errors.Op = "memory_cache_repo.MemoryCacheRepository.GetPushMessages"
+	var roomStreamKey = getRoomStreamKey(roomId)
+
+	startId := getStreamStartId(startTime)
+
+	return getStreamEntry[[]byte](ctx, repo, roomStreamKey, startId, func(values map[string]string, entryId string) ([]byte, error) {
+		streamEntryType, err := getStreamEntryType(values)
+		if err != nil {
+			return nil, errors.E(op, err)
+		}
+
+		switch streamEntryType {
+		case models.PushMessageStreamEntryType:
+			message, ok := values[streamEntryMessageField]
+			if !ok {
+				err := fmt.Errorf("%s key not found in %s map", streamEntryMessageField, values)
+				return nil, errors.E(op, err)
+			}
+
+			return []byte(message), nil
+		case models.ActionStreamEntryType:
+			if values[streamEntryActionField] == strconv.Itoa(int(models.TerminateAction)) {
+				return nil, errors.E(op, errReceivedStreamTerminationAction)
+			}
+
+			return nil, errors.E(op, errIsIgnoredStreamEntry)
+		default:
+			return nil, errors.E(op, errIsIgnoredStreamEntry)
+		}
+	})
+}
+
+func (repo *MemoryCacheRepository) GetAction(ctx context.Context, roomId uuid.UUID, startTime time.Time) <-chan models.StreamSubscriptionResult[models.StreamActionType] {
+	const op errors.Op = "memory_cache_repo.MemoryCacheRepository.GetAction"
+	var roomStreamKey = getRoomStreamKey(roomId)
+
+	startId := getStreamStartId(startTime)
+
+	return getStreamEntry[models.StreamActionType](ctx, repo, roomStreamKey, startId, func(values map[string]string, entryId string) (models.StreamActionType, error) {
+		streamEntryType, err := getStreamEntryType(values)
+		if err != nil {
+			return models.TerminateAction, errors.E(op, err)
+		}
+
+		switch streamEntryType {
+		case models.ActionStreamEntryType:
+			action, ok := values[streamEntryActionField]
+			if !ok {
+				err := fmt.Errorf("%s key not found in %s map", streamEntryActionField, values)
+				return models.TerminateAction, errors.E(op, err)
+			}
+
+			actionInt, err := strconv.Atoi(action)
+			if err != nil {
+				return models.TerminateAction, errors.E(op, err)
+			}
+
+			if models.StreamActionType(actionInt) == models.TerminateAction {
+				return models.TerminateAction, errors.E(op, errReceivedStreamTerminationAction)
+			}
+
+			return models.StreamActionType(actionInt), nil
+		default:
+			return models.TerminateAction, errors.E(op, errIsIgnoredStreamEntry)
+		}
+	})
+}
@@ -0,0 +1,176 @@
+package memory_cache_repo
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/models"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const connectionExpirationMilli = 1000 * 60 * 10
+
+// GetRoomSubscriberStatus returns the number of connections of the room subscriber that have not expired
+// and sets the room subscriber's status to inactive if there are no connections left.
+func (repo *MemoryCacheRepository) GetRoomSubscriberStatus(ctx context.Context, roomId, userId uuid.UUID) (numberRoomSubscriberConns int64, roomSubscriberStatusHasBeenUpdated bool, err error) {
+	const op errors.Op = "memory_cache_repo.MemoryCacheRepository.GetRoomSubscriberStatus"
+
+	repo.locked(func() {
+		numberRoomSubscriberConns = repo.getNumberRoomSubscriberConnections(roomId, userId)
+
+		roomSubscriber, ok := get[*models.RoomSubscriber](repo, getRoomSubscriberKey(roomId, userId))
+		if !ok {
+			err = errors.E(op, common.ErrNotFound)
+			return
+		}
+
+		if numberRoomSubscriberConns == 0 && roomSubscriber.Status == models.ActiveSubscriberStatus {
+			roomSubscriber.Status = models.InactiveSubscriberStatus
+			roomSubscriberStatusHasBeenUpdated = true
+		}
+	})
+	if err != nil {
+		return 0, false, err
+	}
+
+	return numberRoomSubscriberConns, roomSubscriberStatusHasBeenUpdated, nil
+}
+
+func (repo *MemoryCacheRepository) GetRoomSubscribers(ctx context.Context, roomId uuid.UUID) (roomSubscribers []models.RoomSubscriber, err error) {
+	repo.locked(func() {
+		roomSubscriberIds := repo.smembers(getRoomSubscriberIdsKey(roomId))
+
+		roomSubscribers = make([]models.RoomSubscriber, 0, len(roomSubscriberIds))
+		for _, roomSubscriberId := range roomSubscriberIds {
+			roomSubscriber := models.RoomSubscriber{UserId: roomSubscriberId}
+			if v, ok := get[*models.RoomSubscriber](repo, getRoomSubscriberKey(roomId, roomSubscriberId)); ok {
+				roomSubscriber = *v
+			}
+
+			roomSubscribers = append(roomSubscribers, roomSubscriber)
+		}
+	})
+
+	return roomSubscribers, nil
+}
+
+// GetActiveRoomSubscribersNumber returns the number of room subscribers whose status is active.
+func (repo *MemoryCacheRepository) GetActiveRoomSubscribersNumber(ctx context.Context, roomId uuid.UUID) (activeRoomSubscribersNumber int, err error) {
+	roomSubscribers, err := repo.GetRoomSubscribers(ctx, roomId)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, roomSubscriber := range roomSubscribers {
+		if roomSubscriber.Status == models.ActiveSubscriberStatus {
+			activeRoomSubscribersNumber++
+		}
+	}
+
+	return activeRoomSubscribersNumber, nil
+}
+
+// SetRoomSubscriber adds the user to the room subscriber ids and creates the room subscriber with an inactive status and an unstarted game status.
+func (repo *MemoryCacheRepository) SetRoomSubscriber(ctx context.Context, tx common.Transaction, roomId uuid.UUID, user models.User) error {
+	repo.exec(tx, func() {
+		update(repo, getRoomSubscriberKey(roomId, user.ID), func(roomSubscriber *models.RoomSubscriber) {
+			roomSubscriber.UserId = user.ID
+			roomSubscriber.Username = user.Username
+			roomSubscriber.Status = models.InactiveSubscriberStatus
+			roomSubscriber.GameStatus = models.UnstartedSubscriberGameStatus
+		})
+		repo.sadd(getRoomSubscriberIdsKey(roomId), user.ID)
+	})
+
+	return nil
+}
+
+func (repo *MemoryCacheRepository) SetRoomSubscriberGameStatus(ctx context.Context, tx common.Transaction, roomId, userId uuid.UUID, status models.SubscriberGameStatus) error {
+	repo.exec(tx, func() {
+		update(repo, getRoomSubscriberKey(roomId, userId), func(roomSubscriber *models.RoomSubscriber) {
+			roomSubscriber.UserId = userId
+			roomSubscriber.GameStatus = status
+		})
+	})
+
+	return nil
+}
+
+// SetRoomSubscriberConnection adds a new connection that expires after connectionExpirationMilli to the room subscriber
+// and sets the room subscriber's status to active if it was inactive.
+func (repo *MemoryCacheRepository) SetRoomSubscriberConnection(ctx context.Context, roomId, userId, newConnectionId uuid.UUID) (roomSubscriberStatusHasBeenUpdated bool, err error) {
+	const op errors.Op = "memory_cache_repo.MemoryCacheRepository.SetRoomSubscriberConnection"
+
+	repo.locked(func() {
+		roomSubscriber, ok := get[*models.RoomSubscriber](repo, getRoomSubscriberKey(roomId, userId))
+		if !ok {
+			err = errors.E(op, common.ErrNotFound)
+			return
+		}
+
+		expirationTime := repo.clock.Now().Add(connectionExpirationMilli * time.Millisecond).UnixMilli()
+		repo.zadd(getRoomSubscriberConnectionKey(roomId, userId), newConnectionId, float64(expirationTime), false)
+
+		if roomSubscriber.Status == models.InactiveSubscriberStatus {
+			roomSubscriber.Status = models.ActiveSubscriberStatus
+			roomSubscriberStatusHasBeenUpdated = true
+		}
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return roomSubscriberStatusHasBeenUpdated, nil
+}
+
+// DeleteRoomSubscriber deletes the room subscriber, its connections and the user id from the room subscriber ids.
+func (repo *MemoryCacheRepository) DeleteRoomSubscriber(ctx context.Context, roomId, userId uuid.UUID) error {
+	repo.locked(func() {
+		repo.del(getRoomSubscriberKey(roomId, userId), getRoomSubscriberConnectionKey(roomId, userId))
+		repo.srem(getRoomSubscriberIdsKey(roomId), userId)
+	})
+
+	return nil
+}
+
+func (repo *MemoryCacheRepository) DeleteRoomSubscriberConnection(ctx context.Context, roomId, userId, connectionId uuid.UUID) (roomSubscriberStatusHasBeenUpdated bool, err error) {
+	const op errors.Op = "memory_cache_repo.MemoryCacheRepository.DeleteRoomSubscriberConnection"
+
+	repo.locked(func() {
+		repo.zrem(getRoomSubscriberConnectionKey(roomId, userId), connectionId)
+	})
+
+	_, roomSubscriberStatusHasBeenUpdated, err = repo.GetRoomSubscriberStatus(ctx, roomId, userId)
+	if err != nil {
+		return false, errors.E(op, err)
+	}
+
+	return roomSubscriberStatusHasBeenUpdated, nil
+}
+
+// MULTIOPERATIONS
+func (repo *MemoryCacheRepository) SetRoomSubscriberGameStatusForAllRoomSubscribers(ctx context.Context, roomId uuid.UUID, newSubscriberGameStatus models.SubscriberGameStatus) error {
+	repo.locked(func() {
+		for _, roomSubscriberId := range repo.smembers(getRoomSubscriberIdsKey(roomId)) {
+			update(repo, getRoomSubscriberKey(roomId, roomSubscriberId), func(roomSubscriber *models.RoomSubscriber) {
+				roomSubscriber.UserId = roomSubscriberId
+				roomSubscriber.GameStatus = newSubscriberGameStatus
+			})
+		}
+	})
+
+	return nil
+}
+
+// getNumberRoomSubscriberConnections deletes the expired connections of the room subscriber and returns the number of connections remaining.
+// The repository must be locked.
+func (repo *MemoryCacheRepository) getNumberRoomSubscriberConnections(roomId, userId uuid.UUID) int64 {
+	var roomSubscriberConnectionKey = getRoomSubscriberConnectionKey(roomId, userId)
+
+	repo.zremRangeByScore(roomSubscriberConnectionKey, 0, float64(repo.clock.Now().UnixMilli()))
+	connections, _ := get[sortedSet](repo, roomSubscriberConnectionKey)
+
+	return int64(len(connections))
+}
@@ -0,0 +1,41 @@
+package memory_cache_repo
+
+import (
+	"10-typing/common"
+	"10-typing/models"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+func (repo *MemoryCacheRepository) GetCurrentGameScores(ctx context.Context, roomId uuid.UUID) (scores []models.Score, err error) {
+	repo.locked(func() {
+		scoreUserIds := repo.zrange(getCurrentGameScoresUserIdsKey(roomId), true)
+
+		scores = make([]models.Score, 0, len(scoreUserIds))
+		for _, scoreUserId := range scoreUserIds {
+			if score, ok := get[models.Score](repo, getCurrentGameScoreKey(roomId, scoreUserId.member)); ok {
+				scores = append(scores, score)
+			}
+		}
+	})
+
+	return scores, nil
+}
+
+func (repo *MemoryCacheRepository) SetCurrentGameScore(ctx context.Context, tx common.Transaction, roomId uuid.UUID, score models.Score) error {
+	repo.exec(tx, func() {
+		repo.set(getCurrentGameScoreKey(roomId, score.UserId), score, 0)
+		repo.zadd(getCurrentGameScoresUserIdsKey(roomId), score.UserId, score.WordsPerMinute, false)
+	})
+
+	return nil
+}
+
+func (repo *MemoryCacheRepository) DeleteCurrentGameScores(ctx context.Context, roomId uuid.UUID) error {
+	repo.locked(func() {
+		repo.delPrefix(getCurrentGameKey(roomId) + ":scores:")
+	})
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package memory_cache_repo
+
+import (
+	"10-typing/common"
+	"10-typing/models"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// userSessions holds the time each session of a user expires by its token hash
+type userSessions map[string]time.Time
+
+// SetSession stores the session and adds its token hash to the sessions of the user, from which the sessions that have expired are removed,
+// so that all sessions of the user can be deleted.
+func (repo *MemoryCacheRepository) SetSession(ctx context.Context, tx common.Transaction, tokenHash string, userId uuid.UUID) error {
+	var userSessionsKey = getUserSessionsKey(userId)
+
+	repo.exec(tx, func() {
+		now := repo.clock.Now()
+		expiresAt := now.Add(models.SessionDurationSec * time.Second)
+
+		repo.set(getSessionKey(tokenHash), userId, models.SessionDurationSec*time.Second)
+
+		sessions, ok := get[userSessions](repo, userSessionsKey)
+		if !ok {
+			sessions = make(userSessions)
+			repo.set(userSessionsKey, sessions, 0)
+		}
+		for sessionTokenHash, sessionExpiresAt := range sessions {
+			if !sessionExpiresAt.After(now) {
+				delete(sessions, sessionTokenHash)
+			}
+		}
+		sessions[tokenHash] = expiresAt
+		repo.expireAt(userSessionsKey, expiresAt)
+	})
+
+	return nil
+}
+
+func (repo *MemoryCacheRepository) DeleteSession(ctx context.Context, tx common.Transaction, tokenHash string) error {
+	repo.exec(tx, func() {
+		repo.del(getSessionKey(tokenHash))
+	})
+
+	return nil
+}
+
+// DeleteUserSessions deletes all sessions of the user, e.g. when the user is banned.
+func (repo *MemoryCacheRepository) DeleteUserSessions(ctx context.Context, userId uuid.UUID) error {
+	var userSessionsKey = getUserSessionsKey(userId)
+
+	repo.locked(func() {
+		sessions, _ := get[userSessions](repo, userSessionsKey)
+		for tokenHash := range sessions {
+			repo.del(getSessionKey(tokenHash))
+		}
+		repo.del(userSessionsKey)
+	})
+
+	return nil
+}
+
+func (repo *MemoryCacheRepository) DeleteAllSessions(ctx context.Context) error {
+	repo.locked(func() {
+		repo.delPrefix("sessions:")
+		repo.delPrefix("user_sessions:")
+	})
+
+	return nil
+}
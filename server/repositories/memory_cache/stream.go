@@ -0,0 +1,216 @@
+package memory_cache_repo
+
+import (
+	"10-typing/errors"
+	"10-typing/models"
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	errReceivedStreamTerminationAction = errors.New("received stream termination action")
+	errIsIgnoredStreamEntry            = errors.New("stream entry is ignored")
+)
+
+// stream is the equivalent of a redis STREAM. Its entries are ordered by their ids.
+type stream struct {
+	entries []streamEntry
+	lastId  streamId
+}
+
+type streamEntry struct {
+	id     streamId
+	values map[string]string
+}
+
+// streamId is a redis stream id: [milliseconds]-[sequence number]
+type streamId struct {
+	ms  int64
+	seq uint64
+}
+
+func (id streamId) String() string {
+	return strconv.FormatInt(id.ms, 10) + "-" + strconv.FormatUint(id.seq, 10)
+}
+
+func (id streamId) after(other streamId) bool {
+	return id.ms > other.ms || id.ms == other.ms && id.seq > other.seq
+}
+
+// parseStreamId parses a complete stream id or a stream id without sequence number, which stands for the sequence number 0
+func parseStreamId(idStr string) (streamId, error) {
+	const op errors.Op = "memory_cache_repo.parseStreamId"
+
+	msStr, seqStr, hasSeq := strings.Cut(idStr, "-")
+	ms, err := strconv.ParseInt(msStr, 10, 64)
+	if err != nil {
+		return streamId{}, errors.E(op, err)
+	}
+
+	var seq uint64
+	if hasSeq {
+		seq, err = strconv.ParseUint(seqStr, 10, 64)
+		if err != nil {
+			return streamId{}, errors.E(op, err)
+		}
+	}
+
+	return streamId{ms, seq}, nil
+}
+
+// xadd appends an entry with a new id to the stream of the key and wakes up the blocked stream readers.
+// If maxLen is greater than 0, the oldest entries are trimmed so that the stream holds at most maxLen entries. The repository must be locked.
+func (repo *MemoryCacheRepository) xadd(key string, maxLen int, values map[string]string) {
+	s, ok := get[*stream](repo, key)
+	if !ok {
+		s = &stream{}
+		repo.set(key, s, 0)
+	}
+
+	id := streamId{ms: repo.clock.Now().UnixMilli()}
+	if !id.after(s.lastId) {
+		id = streamId{ms: s.lastId.ms, seq: s.lastId.seq + 1}
+	}
+	s.lastId = id
+	s.entries = append(s.entries, streamEntry{id, values})
+
+	if maxLen > 0 && len(s.entries) > maxLen {
+		s.entries = s.entries[len(s.entries)-maxLen:]
+	}
+
+	close(repo.streamAdded)
+	repo.streamAdded = make(chan struct{})
+}
+
+// getStreamStartId returns the id of the last possible entry before the millisecond of the start time.
+// Reading after this id returns all entries that have been added since the start time, including the first entry of the same millisecond, whose sequence number is 0.
+// Without start time an empty id is returned, so that only new entries are read.
+func getStreamStartId(startTime time.Time) string {
+	if (startTime == time.Time{}) {
+		return ""
+	}
+
+	return strconv.FormatInt(startTime.UnixMilli()-1, 10) + "-" + strconv.FormatUint(math.MaxUint64, 10)
+}
+
+// nextStreamEntry returns the first entry of the stream of the key whose id is greater than the given id
+func (repo *MemoryCacheRepository) nextStreamEntry(key string, id streamId) (streamEntry, bool) {
+	s, ok := get[*stream](repo, key)
+	if !ok {
+		return streamEntry{}, false
+	}
+
+	for _, entry := range s.entries {
+		if entry.id.after(id) {
+			return entry, true
+		}
+	}
+
+	return streamEntry{}, false
+}
+
+// getStreamEntry reads the entries of the stream after startId, like XREAD with BLOCK does, and sends the processed entries to the returned channel.
+// Without startId only entries that are added after getStreamEntry has been called are read.
+// The channel is closed when the context is done, when a termination action is received or after an error has been sent.
+func getStreamEntry[T []byte | models.StreamActionType | *models.UserNotification](
+	ctx context.Context,
+	repo *MemoryCacheRepository,
+	streamKey, startId string,
+	processStreamEntry func(values map[string]string, entryId string) (T, error),
+) chan models.StreamSubscriptionResult[T] {
+	const op errors.Op = "memory_cache_repo.getStreamEntry"
+
+	out := make(chan models.StreamSubscriptionResult[T])
+
+	var id streamId
+	var err error
+	if startId != "" {
+		id, err = parseStreamId(startId)
+	} else {
+		repo.locked(func() {
+			if s, ok := get[*stream](repo, streamKey); ok {
+				id = s.lastId
+			}
+		})
+	}
+
+	go func() {
+		defer close(out)
+
+		if err != nil {
+			sendErrorResult[T](ctx, out, errors.E(op, err))
+			return
+		}
+
+		for {
+			var entry streamEntry
+			var ok bool
+			var streamAdded chan struct{}
+			repo.locked(func() {
+				entry, ok = repo.nextStreamEntry(streamKey, id)
+				streamAdded = repo.streamAdded
+			})
+
+			if !ok {
+				select {
+				case <-ctx.Done():
+					return
+				case <-streamAdded:
+					continue
+				}
+			}
+
+			id = entry.id
+			v, err := processStreamEntry(entry.values, entry.id.String())
+			switch {
+			case errors.Is(err, errReceivedStreamTerminationAction):
+				return
+			case errors.Is(err, errIsIgnoredStreamEntry):
+				continue
+			case err != nil:
+				sendErrorResult[T](ctx, out, errors.E(op, err))
+				return
+			}
+
+			select {
+			case out <- models.StreamSubscriptionResult[T]{Value: v}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+func sendErrorResult[T []byte | models.StreamActionType | *models.UserNotification](ctx context.Context, outCh chan<- models.StreamSubscriptionResult[T], err error) {
+	result := models.StreamSubscriptionResult[T]{
+		Error: err,
+	}
+
+	select {
+	case outCh <- result:
+	case <-ctx.Done():
+	}
+}
+
+func getStreamEntryType(values map[string]string) (models.StreamEntryType, error) {
+	const op errors.Op = "memory_cache_repo.getStreamEntryType"
+
+	streamEntryTypeStr, ok := values[streamEntryTypeField]
+	if !ok {
+		err := fmt.Errorf("%s key not found in %s map", streamEntryTypeField, values)
+		return models.ActionStreamEntryType, errors.E(op, err)
+	}
+
+	streamEntryTypeInt, err := strconv.Atoi(streamEntryTypeStr)
+	if err != nil {
+		return models.ActionStreamEntryType, errors.E(op, err)
+	}
+
+	return models.StreamEntryType(streamEntryTypeInt), nil
+}
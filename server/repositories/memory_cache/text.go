@@ -0,0 +1,48 @@
+package memory_cache_repo
+
+import (
+	"10-typing/common"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+func (repo *MemoryCacheRepository) SetTextId(ctx context.Context, tx common.Transaction, textIds ...uuid.UUID) error {
+	repo.exec(tx, func() {
+		repo.sadd(textIdsKey, textIds...)
+	})
+
+	return nil
+}
+
+func (repo *MemoryCacheRepository) TextIdsKeyExists(ctx context.Context) (exists bool, err error) {
+	repo.locked(func() {
+		exists = repo.exists(textIdsKey)
+	})
+
+	return exists, nil
+}
+
+func (repo *MemoryCacheRepository) TextIdExists(ctx context.Context, textId uuid.UUID) (exists bool, err error) {
+	repo.locked(func() {
+		exists = repo.sismember(textIdsKey, textId)
+	})
+
+	return exists, nil
+}
+
+func (repo *MemoryCacheRepository) RemoveTextId(ctx context.Context, tx common.Transaction, textIds ...uuid.UUID) error {
+	repo.exec(tx, func() {
+		repo.srem(textIdsKey, textIds...)
+	})
+
+	return nil
+}
+
+func (repo *MemoryCacheRepository) DeleteTextIdsKey(ctx context.Context, tx common.Transaction) error {
+	repo.exec(tx, func() {
+		repo.del(textIdsKey)
+	})
+
+	return nil
+}
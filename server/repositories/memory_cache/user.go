@@ -0,0 +1,151 @@
+package memory_cache_repo
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/models"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// if not found, queries db
+func (repo *MemoryCacheRepository) GetUserByEmailInCacheOrDB(ctx context.Context, dbRepo common.DBRepository, email string) (*models.User, error) {
+	const op errors.Op = "memory_cache_repo.MemoryCacheRepository.GetUserByEmailInCacheOrDB"
+	var userId uuid.UUID
+	var ok bool
+
+	repo.locked(func() {
+		userId, ok = get[uuid.UUID](repo, getUserEmailKey(email))
+	})
+	if !ok {
+		return nil, errors.E(op, common.ErrNotFound)
+	}
+
+	user, err := repo.GetUserByIdInCacheOrDB(ctx, dbRepo, userId)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return user, nil
+}
+
+// if not found, queries db
+func (repo *MemoryCacheRepository) GetUserByIdInCacheOrDB(ctx context.Context, dbRepo common.DBRepository, userId uuid.UUID) (*models.User, error) {
+	const op errors.Op = "memory_cache_repo.MemoryCacheRepository.GetUserByIdInCacheOrDB"
+
+	if user, ok := repo.getUser(userId); ok {
+		return user, nil
+	}
+
+	user, err := dbRepo.FindUserById(ctx, nil, userId)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	if err = repo.SetUser(ctx, nil, *user); err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return user, nil
+}
+
+// read: read first in cache, if not exists, read from db and write to cache (in case, keys got evicted)
+func (repo *MemoryCacheRepository) GetUserBySessionTokenHashInCacheOrDB(
+	ctx context.Context,
+	dbRepo common.DBRepository,
+	tokenHash string,
+) (*models.User, error) {
+	const op errors.Op = "memory_cache_repo.MemoryCacheRepository.GetUserBySessionTokenHashInCacheOrDB"
+	var userId uuid.UUID
+	var ok bool
+
+	repo.locked(func() {
+		userId, ok = get[uuid.UUID](repo, getSessionKey(tokenHash))
+	})
+	if !ok {
+		return nil, errors.E(op, common.ErrNotFound)
+	}
+
+	user, err := repo.GetUserByIdInCacheOrDB(ctx, dbRepo, userId)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return user, nil
+}
+
+func (repo *MemoryCacheRepository) UserExists(ctx context.Context, userId uuid.UUID) (exists bool, err error) {
+	repo.locked(func() {
+		exists = repo.exists(getUserKey(userId))
+	})
+
+	return exists, nil
+}
+
+func (repo *MemoryCacheRepository) SetUser(ctx context.Context, tx common.Transaction, user models.User) error {
+	repo.exec(tx, func() {
+		repo.set(getUserEmailKey(user.Email), user.ID, 0)
+		update(repo, getUserKey(user.ID), func(cachedUser *models.User) {
+			*cachedUser = models.User{
+				ID:             user.ID,
+				Username:       user.Username,
+				PasswordHash:   user.PasswordHash,
+				FirstName:      user.FirstName,
+				LastName:       user.LastName,
+				Email:          user.Email,
+				IsVerified:     user.IsVerified,
+				Rating:         user.Rating,
+				RatedGames:     user.RatedGames,
+				KeyboardLayout: user.KeyboardLayout,
+				Role:           user.Role,
+				BannedAt:       user.BannedAt,
+			}
+		})
+	})
+
+	return nil
+}
+
+func (repo *MemoryCacheRepository) VerifyUser(ctx context.Context, tx common.Transaction, userId uuid.UUID) error {
+	repo.exec(tx, func() {
+		update(repo, getUserKey(userId), func(user *models.User) {
+			user.IsVerified = true
+		})
+	})
+
+	return nil
+}
+
+func (repo *MemoryCacheRepository) SetUserRating(ctx context.Context, tx common.Transaction, userId uuid.UUID, rating float64, ratedGames int) error {
+	repo.exec(tx, func() {
+		update(repo, getUserKey(userId), func(user *models.User) {
+			user.Rating = rating
+			user.RatedGames = ratedGames
+		})
+	})
+
+	return nil
+}
+
+func (repo *MemoryCacheRepository) DeleteAllUsers(ctx context.Context) error {
+	repo.locked(func() {
+		repo.delPrefix("users:")
+		repo.delPrefix("user_emails:")
+	})
+
+	return nil
+}
+
+func (repo *MemoryCacheRepository) getUser(userId uuid.UUID) (user *models.User, ok bool) {
+	repo.locked(func() {
+		var cachedUser *models.User
+		if cachedUser, ok = get[*models.User](repo, getUserKey(userId)); ok {
+			userCopy := *cachedUser
+			userCopy.ID = userId
+			user = &userCopy
+		}
+	})
+
+	return user, ok
+}
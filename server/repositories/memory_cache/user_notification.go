@@ -0,0 +1,56 @@
+package memory_cache_repo
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/models"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+const (
+	userNotificationStreamMaxlen = 10
+)
+
+func (repo *MemoryCacheRepository) PublishUserNotification(ctx context.Context, tx common.Transaction, userId uuid.UUID, userNotification models.UserNotification) error {
+	const op errors.Op = "memory_cache_repo.MemoryCacheRepository.PublishUserNotification"
+	var userNotificationStreamKey = getUserNotificationStreamKey(userId)
+
+	userNotificationData, err := json.Marshal(userNotification)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	repo.exec(tx, func() {
+		repo.xadd(userNotificationStreamKey, userNotificationStreamMaxlen, map[string]string{
+			streamEntryMessageField: string(userNotificationData),
+		})
+	})
+
+	return nil
+}
+
+func (repo *MemoryCacheRepository) GetUserNotification(ctx context.Context, userId uuid.UUID, startId string) chan models.StreamSubscriptionResult[*models.UserNotification] {
+	const op errors.Op = "memory_cache_repo.MemoryCacheRepository.GetUserNotification"
+	var userNotificationStreamKey = getUserNotificationStreamKey(userId)
+
+	return getStreamEntry[*models.UserNotification](ctx, repo, userNotificationStreamKey, startId, func(values map[string]string, entryId string) (*models.UserNotification, error) {
+		message, ok := values[streamEntryMessageField]
+		if !ok {
+			err := fmt.Errorf("%s key not found in %s map", streamEntryMessageField, values)
+			return nil, errors.E(op, err)
+		}
+
+		var userNotification models.UserNotification
+		if err := json.Unmarshal([]byte(message), &userNotification); err != nil {
+			return nil, errors.E(op, err)
+		}
+
+		userNotification.Id = entryId
+
+		return &userNotification, nil
+	})
+}
@@ -0,0 +1,99 @@
+package memory_cache_repo
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/keyboard"
+	"10-typing/models"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (repo *MemoryCacheRepository) GetUserStats(ctx context.Context, userId uuid.UUID, interval models.StatsInterval) (*models.UserStats, error) {
+	const op errors.Op = "memory_cache_repo.MemoryCacheRepository.GetUserStats"
+	var userStatsJson []byte
+	var ok bool
+
+	repo.locked(func() {
+		userStatsJson, ok = get[[]byte](repo, getUserStatsKey(userId, interval))
+	})
+	if !ok {
+		return nil, errors.E(op, common.ErrNotFound)
+	}
+
+	var userStats models.UserStats
+	if err := json.Unmarshal(userStatsJson, &userStats); err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return &userStats, nil
+}
+
+// SetUserStats caches the statistics of the user for models.UserStatsTTLSeconds
+func (repo *MemoryCacheRepository) SetUserStats(ctx context.Context, tx common.Transaction, userId uuid.UUID, userStats models.UserStats) error {
+	const op errors.Op = "memory_cache_repo.MemoryCacheRepository.SetUserStats"
+
+	userStatsJson, err := json.Marshal(&userStats)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	repo.exec(tx, func() {
+		repo.set(getUserStatsKey(userId, userStats.Interval), userStatsJson, models.UserStatsTTLSeconds*time.Second)
+	})
+
+	return nil
+}
+
+func (repo *MemoryCacheRepository) GetUserKeyboardStats(ctx context.Context, userId uuid.UUID, layoutName string) (*models.KeyboardStats, error) {
+	const op errors.Op = "memory_cache_repo.MemoryCacheRepository.GetUserKeyboardStats"
+	var keyboardStatsJson []byte
+	var ok bool
+
+	repo.locked(func() {
+		keyboardStatsJson, ok = get[[]byte](repo, getUserKeyboardStatsKey(userId, layoutName))
+	})
+	if !ok {
+		return nil, errors.E(op, common.ErrNotFound)
+	}
+
+	var keyboardStats models.KeyboardStats
+	if err := json.Unmarshal(keyboardStatsJson, &keyboardStats); err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return &keyboardStats, nil
+}
+
+// SetUserKeyboardStats caches the keyboard statistics of the user for models.UserStatsTTLSeconds
+func (repo *MemoryCacheRepository) SetUserKeyboardStats(ctx context.Context, tx common.Transaction, userId uuid.UUID, keyboardStats models.KeyboardStats) error {
+	const op errors.Op = "memory_cache_repo.MemoryCacheRepository.SetUserKeyboardStats"
+
+	keyboardStatsJson, err := json.Marshal(&keyboardStats)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	repo.exec(tx, func() {
+		repo.set(getUserKeyboardStatsKey(userId, keyboardStats.KeyboardLayout), keyboardStatsJson, models.UserStatsTTLSeconds*time.Second)
+	})
+
+	return nil
+}
+
+// DeleteUserStats deletes the cached statistics of the user for all intervals and the keyboard statistics for all layouts
+func (repo *MemoryCacheRepository) DeleteUserStats(ctx context.Context, tx common.Transaction, userId uuid.UUID) error {
+	userStatsKeys := []string{getUserStatsKey(userId, models.DayStatsInterval), getUserStatsKey(userId, models.WeekStatsInterval)}
+	for _, layoutName := range keyboard.LayoutNames {
+		userStatsKeys = append(userStatsKeys, getUserKeyboardStatsKey(userId, layoutName))
+	}
+
+	repo.exec(tx, func() {
+		repo.del(userStatsKeys...)
+	})
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package memory_db_repo
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func compareTimes(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareInts(a, b int) int {
+	return compareFloats(float64(a), float64(b))
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareUuids compares two uuids like postgres compares values of the uuid type
+func compareUuids(a, b uuid.UUID) int {
+	return bytes.Compare(a[:], b[:])
+}
@@ -0,0 +1,228 @@
+package memory_db_repo
+
+import (
+	realclock "10-typing/clock"
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/models"
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var _ common.DBRepository = (*MemoryDBRepository)(nil)
+
+// MemoryDBRepository implements common.DBRepository without postgres, so that services can be tested deterministically.
+// Timestamps are taken from the given clock. Soft deleted rows are kept but not returned, like gorm does.
+type MemoryDBRepository struct {
+	mu    sync.Mutex
+	state *dbState
+	clock common.Clock
+}
+
+// dbState holds the tables. Rows are stored by value and copied when they are returned.
+type dbState struct {
+	users         map[uuid.UUID]models.User
+	texts         map[uuid.UUID]models.Text
+	scores        map[uuid.UUID]models.Score
+	games         map[uuid.UUID]models.Game
+	rooms         map[uuid.UUID]models.Room
+	tokens        map[uuid.UUID]models.Token
+	ratingChanges []models.RatingChange
+	userRooms     map[userRoom]struct{}
+}
+
+type userRoom struct {
+	userId uuid.UUID
+	roomId uuid.UUID
+}
+
+// NewMemoryDBRepository returns an empty database. If clock is nil, the real clock is used.
+func NewMemoryDBRepository(clock common.Clock) *MemoryDBRepository {
+	if clock == nil {
+		clock = realclock.New()
+	}
+
+	return &MemoryDBRepository{
+		state: &dbState{
+			users:     make(map[uuid.UUID]models.User),
+			texts:     make(map[uuid.UUID]models.Text),
+			scores:    make(map[uuid.UUID]models.Score),
+			games:     make(map[uuid.UUID]models.Game),
+			rooms:     make(map[uuid.UUID]models.Room),
+			tokens:    make(map[uuid.UUID]models.Token),
+			userRooms: make(map[userRoom]struct{}),
+		},
+		clock: clock,
+	}
+}
+
+func (s *dbState) clone() *dbState {
+	c := &dbState{
+		users:         make(map[uuid.UUID]models.User, len(s.users)),
+		texts:         make(map[uuid.UUID]models.Text, len(s.texts)),
+		scores:        make(map[uuid.UUID]models.Score, len(s.scores)),
+		games:         make(map[uuid.UUID]models.Game, len(s.games)),
+		rooms:         make(map[uuid.UUID]models.Room, len(s.rooms)),
+		tokens:        make(map[uuid.UUID]models.Token, len(s.tokens)),
+		ratingChanges: append([]models.RatingChange(nil), s.ratingChanges...),
+		userRooms:     make(map[userRoom]struct{}, len(s.userRooms)),
+	}
+	for id, user := range s.users {
+		c.users[id] = user
+	}
+	for id, text := range s.texts {
+		c.texts[id] = text
+	}
+	for id, score := range s.scores {
+		c.scores[id] = score
+	}
+	for id, game := range s.games {
+		c.games[id] = game
+	}
+	for id, room := range s.rooms {
+		c.rooms[id] = room
+	}
+	for id, token := range s.tokens {
+		c.tokens[id] = token
+	}
+	for ur := range s.userRooms {
+		c.userRooms[ur] = struct{}{}
+	}
+
+	return c
+}
+
+// MemoryDBTransaction works on a snapshot of the database that is taken when the transaction begins.
+// Its writes are visible inside of the transaction and are applied to the database on Commit, all or none of them.
+type MemoryDBTransaction struct {
+	mu     sync.Mutex
+	repo   *MemoryDBRepository
+	state  *dbState
+	writes []func(s *dbState) error
+	done   bool
+}
+
+func (t *MemoryDBTransaction) Conn() any {
+	return t
+}
+
+func (t *MemoryDBTransaction) Commit(ctx context.Context) error {
+	const op errors.Op = "memory_db_repo.MemoryDBTransaction.Commit"
+
+	if err := ctx.Err(); err != nil {
+		return errors.E(op, err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.done {
+		return errors.E(op, fmt.Errorf("transaction has already been committed or rolled back"))
+	}
+	t.done = true
+
+	t.repo.mu.Lock()
+	defer t.repo.mu.Unlock()
+
+	state := t.repo.state.clone()
+	for _, write := range t.writes {
+		if err := write(state); err != nil {
+			return errors.E(op, err)
+		}
+	}
+	t.repo.state = state
+
+	return nil
+}
+
+func (t *MemoryDBTransaction) Rollback() error {
+	const op errors.Op = "memory_db_repo.MemoryDBTransaction.Rollback"
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.done {
+		return errors.E(op, fmt.Errorf("transaction has already been committed or rolled back"))
+	}
+	t.done = true
+	t.writes = nil
+
+	return nil
+}
+
+// Ping only fails if the context is done, since the database is always reachable
+func (repo *MemoryDBRepository) Ping(ctx context.Context) error {
+	const op errors.Op = "memory_db_repo.MemoryDBRepository.Ping"
+
+	if err := ctx.Err(); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+func (repo *MemoryDBRepository) BeginTx() common.Transaction {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return &MemoryDBTransaction{repo: repo, state: repo.state.clone()}
+}
+
+// view runs f on the state of the transaction or on the state of the database if no transaction is given
+func (repo *MemoryDBRepository) view(tx common.Transaction, f func(s *dbState)) {
+	if tx != nil {
+		t := tx.Conn().(*MemoryDBTransaction)
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		f(t.state)
+		return
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	f(repo.state)
+}
+
+// write runs the write on the state of the transaction and remembers it for Commit or runs it on the state of the database if no transaction is given.
+// The write must only depend on its arguments and the state, since it is run again on Commit.
+func (repo *MemoryDBRepository) write(tx common.Transaction, write func(s *dbState) error) error {
+	if tx != nil {
+		t := tx.Conn().(*MemoryDBTransaction)
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if err := write(t.state); err != nil {
+			return err
+		}
+		t.writes = append(t.writes, write)
+		return nil
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return write(repo.state)
+}
+
+func (repo *MemoryDBRepository) deletedAt() *gorm.DeletedAt {
+	return &gorm.DeletedAt{Time: repo.clock.Now(), Valid: true}
+}
+
+func isDeleted(deletedAt *gorm.DeletedAt) bool {
+	return deletedAt != nil && deletedAt.Valid
+}
+
+// page returns the part of the rows that a query with LIMIT and OFFSET returns. A limit below 1 means that there is no limit.
+func page[T any](rows []T, limit, offset int) []T {
+	if offset >= len(rows) {
+		return []T{}
+	}
+	rows = rows[offset:]
+
+	if limit > 0 && limit < len(rows) {
+		rows = rows[:limit]
+	}
+
+	return rows
+}
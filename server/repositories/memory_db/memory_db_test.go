@@ -0,0 +1,113 @@
+package memory_db_repo
+
+import (
+	"10-typing/clock"
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/models"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestMemoryDBTransaction(t *testing.T) {
+	tests := []struct {
+		name string
+		// finish ends the transaction in which the room and its member have been created
+		finish      func(ctx context.Context, repo *MemoryDBRepository, tx common.Transaction, roomId uuid.UUID) error
+		wantErr     bool
+		wantMembers int
+	}{
+		{
+			name: "commit applies the writes",
+			finish: func(ctx context.Context, repo *MemoryDBRepository, tx common.Transaction, roomId uuid.UUID) error {
+				return tx.Commit(ctx)
+			},
+			wantMembers: 1,
+		},
+		{
+			name: "rollback discards the writes",
+			finish: func(ctx context.Context, repo *MemoryDBRepository, tx common.Transaction, roomId uuid.UUID) error {
+				return tx.Rollback()
+			},
+		},
+		{
+			name: "commit after rollback fails",
+			finish: func(ctx context.Context, repo *MemoryDBRepository, tx common.Transaction, roomId uuid.UUID) error {
+				if err := tx.Rollback(); err != nil {
+					return err
+				}
+				return tx.Commit(ctx)
+			},
+			wantErr: true,
+		},
+		{
+			name: "commit twice fails",
+			finish: func(ctx context.Context, repo *MemoryDBRepository, tx common.Transaction, roomId uuid.UUID) error {
+				if err := tx.Commit(ctx); err != nil {
+					return err
+				}
+				return tx.Commit(ctx)
+			},
+			wantErr:     true,
+			wantMembers: 1,
+		},
+		{
+			name: "commit of a canceled context fails",
+			finish: func(ctx context.Context, repo *MemoryDBRepository, tx common.Transaction, roomId uuid.UUID) error {
+				ctx, cancel := context.WithCancel(ctx)
+				cancel()
+				return tx.Commit(ctx)
+			},
+			wantErr: true,
+		},
+		{
+			name: "commit applies none of the writes if one conflicts with a concurrent write",
+			finish: func(ctx context.Context, repo *MemoryDBRepository, tx common.Transaction, roomId uuid.UUID) error {
+				if _, err := repo.CreateRoom(ctx, nil, models.Room{ID: roomId}); err != nil {
+					return err
+				}
+				return tx.Commit(ctx)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := NewMemoryDBRepository(clock.NewFake(time.Now()))
+			roomId := uuid.New()
+
+			tx := repo.BeginTx()
+			if _, err := repo.CreateRoom(ctx, tx, models.Room{ID: roomId}); err != nil {
+				t.Fatalf("CreateRoom() error = %v", err)
+			}
+			if err := repo.CreateUserRoom(ctx, tx, uuid.New(), roomId); err != nil {
+				t.Fatalf("CreateUserRoom() error = %v", err)
+			}
+
+			// the writes are only visible inside of the transaction until it is committed
+			if memberIds, err := repo.FindRoomMemberIdsForUpdate(ctx, tx, roomId); err != nil || len(memberIds) != 1 {
+				t.Fatalf("FindRoomMemberIdsForUpdate() in transaction = %v, %v, want 1 member", memberIds, err)
+			}
+			if _, err := repo.FindRoom(ctx, nil, roomId); !errors.Is(err, common.ErrNotFound) {
+				t.Fatalf("FindRoom() before commit error = %v, want %v", err, common.ErrNotFound)
+			}
+
+			if err := tt.finish(ctx, repo, tx, roomId); (err != nil) != tt.wantErr {
+				t.Fatalf("finishing the transaction error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			memberIds, err := repo.FindRoomMemberIdsForUpdate(ctx, nil, roomId)
+			if err != nil && !errors.Is(err, common.ErrNotFound) {
+				t.Fatalf("FindRoomMemberIdsForUpdate() error = %v", err)
+			}
+			if len(memberIds) != tt.wantMembers {
+				t.Errorf("room has %d members, want %d", len(memberIds), tt.wantMembers)
+			}
+		})
+	}
+}
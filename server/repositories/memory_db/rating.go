@@ -0,0 +1,77 @@
+package memory_db_repo
+
+import (
+	"10-typing/common"
+	"10-typing/models"
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// UpdateRatings sets the rating and the number of rated games of the users and adds the rating changes to the rating history
+func (repo *MemoryDBRepository) UpdateRatings(ctx context.Context, tx common.Transaction, users []models.User, ratingChanges []models.RatingChange) error {
+	createdAt := repo.clock.Now()
+	newRatingChanges := make([]models.RatingChange, 0, len(ratingChanges))
+	for _, ratingChange := range ratingChanges {
+		if ratingChange.ID == uuid.Nil {
+			ratingChange.ID = uuid.New()
+		}
+		if ratingChange.CreatedAt.IsZero() {
+			ratingChange.CreatedAt = createdAt
+		}
+		newRatingChanges = append(newRatingChanges, ratingChange)
+	}
+
+	repo.write(tx, func(s *dbState) error {
+		for _, user := range users {
+			if storedUser, ok := s.users[user.ID]; ok {
+				storedUser.Rating = user.Rating
+				storedUser.RatedGames = user.RatedGames
+				s.users[user.ID] = storedUser
+			}
+		}
+		s.ratingChanges = append(s.ratingChanges, newRatingChanges...)
+		return nil
+	})
+
+	return nil
+}
+
+// FindRatingLeaderboard returns the users that have played at least one rated game ordered by their rating
+func (repo *MemoryDBRepository) FindRatingLeaderboard(ctx context.Context, tx common.Transaction, limit, offset int) ([]models.RatingLeaderboardEntry, int64, error) {
+	var entries []models.RatingLeaderboardEntry
+
+	repo.view(tx, func(s *dbState) {
+		for _, user := range s.users {
+			if user.RatedGames > 0 {
+				entries = append(entries, models.RatingLeaderboardEntry{ID: user.ID, Username: user.Username, Rating: user.Rating})
+			}
+		}
+	})
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Rating == entries[j].Rating {
+			return entries[i].Username < entries[j].Username
+		}
+		return entries[i].Rating > entries[j].Rating
+	})
+
+	return page(entries, limit, offset), int64(len(entries)), nil
+}
+
+func (repo *MemoryDBRepository) FindRatingChanges(ctx context.Context, tx common.Transaction, userId uuid.UUID, limit int) ([]models.RatingChange, error) {
+	var ratingChanges []models.RatingChange
+
+	repo.view(tx, func(s *dbState) {
+		for _, ratingChange := range s.ratingChanges {
+			if ratingChange.UserId == userId {
+				ratingChanges = append(ratingChanges, ratingChange)
+			}
+		}
+	})
+	sort.SliceStable(ratingChanges, func(i, j int) bool {
+		return ratingChanges[i].CreatedAt.After(ratingChanges[j].CreatedAt)
+	})
+
+	return page(ratingChanges, limit, 0), nil
+}
@@ -0,0 +1,254 @@
+package memory_db_repo
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/models"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (repo *MemoryDBRepository) FindRoomWithUsers(ctx context.Context, tx common.Transaction, roomId uuid.UUID) (*models.Room, error) {
+	const op errors.Op = "memory_db_repo.MemoryDBRepository.FindRoomWithUsers"
+
+	room, err := repo.FindRoom(ctx, tx, roomId)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return room, nil
+}
+
+// FindRoomsByUser returns an overview of every room the user is a member of. ActiveSubscriberCount is not set since it is only known by the cache.
+func (repo *MemoryDBRepository) FindRoomsByUser(ctx context.Context, tx common.Transaction, userId uuid.UUID, sortOptions []models.SortOption, limit, offset int) ([]models.RoomOverview, int64, error) {
+	var roomOverviews []models.RoomOverview
+
+	repo.view(tx, func(s *dbState) {
+		for ur := range s.userRooms {
+			room, ok := s.rooms[ur.roomId]
+			if ur.userId != userId || !ok || isDeleted(room.DeletedAt) {
+				continue
+			}
+
+			roomOverview := models.RoomOverview{
+				ID:              room.ID,
+				CreatedAt:       room.CreatedAt,
+				UpdatedAt:       room.UpdatedAt,
+				AdminId:         room.AdminId,
+				AdminUsername:   s.users[room.AdminId].Username,
+				GameDurationSec: room.GameDurationSec,
+				LastGameAt:      room.LastGameAt,
+			}
+			for member := range s.userRooms {
+				if member.roomId == room.ID {
+					roomOverview.MemberCount++
+				}
+			}
+
+			roomOverviews = append(roomOverviews, roomOverview)
+		}
+	})
+
+	if len(sortOptions) == 0 {
+		sortOptions = []models.SortOption{{Column: "created_at", Order: "desc"}}
+	}
+	sort.SliceStable(roomOverviews, func(i, j int) bool {
+		for _, sortOption := range sortOptions {
+			cmp := compareRoomOverviews(roomOverviews[i], roomOverviews[j], sortOption.Column)
+			if cmp == 0 {
+				continue
+			}
+			if sortOption.Order == "desc" {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+		return roomOverviews[i].ID.String() < roomOverviews[j].ID.String()
+	})
+
+	return page(roomOverviews, limit, offset), int64(len(roomOverviews)), nil
+}
+
+func (repo *MemoryDBRepository) FindRoom(ctx context.Context, tx common.Transaction, roomId uuid.UUID) (*models.Room, error) {
+	const op errors.Op = "memory_db_repo.MemoryDBRepository.FindRoom"
+	var room models.Room
+	var ok bool
+
+	repo.view(tx, func(s *dbState) {
+		if room, ok = s.rooms[roomId]; !ok || isDeleted(room.DeletedAt) {
+			ok = false
+			return
+		}
+
+		room.Users = []models.User{}
+		for ur := range s.userRooms {
+			if user, userExists := s.users[ur.userId]; ur.roomId == roomId && userExists {
+				room.Users = append(room.Users, user)
+			}
+		}
+	})
+	if !ok {
+		return nil, errors.E(op, common.ErrNotFound)
+	}
+	sortUsersByUsername(room.Users)
+
+	return &room, nil
+}
+
+func (repo *MemoryDBRepository) CreateRoom(ctx context.Context, tx common.Transaction, newRoom models.Room) (*models.Room, error) {
+	const op errors.Op = "memory_db_repo.MemoryDBRepository.CreateRoom"
+
+	now := repo.clock.Now()
+	if newRoom.ID == uuid.Nil {
+		newRoom.ID = uuid.New()
+	}
+	if newRoom.GameDurationSec == 0 {
+		newRoom.GameDurationSec = 5
+	}
+	newRoom.CreatedAt = now
+	newRoom.UpdatedAt = now
+	newRoom.Users = nil
+
+	err := repo.write(tx, func(s *dbState) error {
+		if _, ok := s.rooms[newRoom.ID]; ok {
+			return fmt.Errorf("duplicate key value violates unique constraint rooms_pkey")
+		}
+
+		s.rooms[newRoom.ID] = newRoom
+		return nil
+	})
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return &newRoom, nil
+}
+
+func (repo *MemoryDBRepository) UpdateRoomAdmin(ctx context.Context, tx common.Transaction, roomId, adminId uuid.UUID) error {
+	const op errors.Op = "memory_db_repo.MemoryDBRepository.UpdateRoomAdmin"
+	updatedAt := repo.clock.Now()
+
+	if err := repo.write(tx, func(s *dbState) error {
+		return updateRoom(s, roomId, func(room *models.Room) {
+			room.AdminId = adminId
+			room.UpdatedAt = updatedAt
+		})
+	}); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+func (repo *MemoryDBRepository) UpdateRoomGameDurationSec(ctx context.Context, tx common.Transaction, roomId uuid.UUID, gameDurationSec int) error {
+	const op errors.Op = "memory_db_repo.MemoryDBRepository.UpdateRoomGameDurationSec"
+	updatedAt := repo.clock.Now()
+
+	if err := repo.write(tx, func(s *dbState) error {
+		return updateRoom(s, roomId, func(room *models.Room) {
+			room.GameDurationSec = gameDurationSec
+			room.UpdatedAt = updatedAt
+		})
+	}); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+func (repo *MemoryDBRepository) UpdateRoomLastGameAt(ctx context.Context, tx common.Transaction, roomId uuid.UUID, lastGameAt time.Time) error {
+	const op errors.Op = "memory_db_repo.MemoryDBRepository.UpdateRoomLastGameAt"
+
+	if err := repo.write(tx, func(s *dbState) error {
+		return updateRoom(s, roomId, func(room *models.Room) {
+			room.LastGameAt = &lastGameAt
+		})
+	}); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+func (repo *MemoryDBRepository) SoftDeleteRoom(ctx context.Context, tx common.Transaction, roomId uuid.UUID) error {
+	deletedAt := repo.deletedAt()
+
+	repo.write(tx, func(s *dbState) error {
+		if room, ok := s.rooms[roomId]; ok && !isDeleted(room.DeletedAt) {
+			room.DeletedAt = deletedAt
+			s.rooms[roomId] = room
+		}
+		for id, game := range s.games {
+			if game.RoomId == roomId && !isDeleted(game.DeletedAt) {
+				game.DeletedAt = deletedAt
+				s.games[id] = game
+			}
+		}
+		for id, token := range s.tokens {
+			if token.RoomID == roomId && !isDeleted(token.DeletedAt) {
+				token.DeletedAt = deletedAt
+				s.tokens[id] = token
+			}
+		}
+		for ur := range s.userRooms {
+			if ur.roomId == roomId {
+				delete(s.userRooms, ur)
+			}
+		}
+		return nil
+	})
+
+	return nil
+}
+
+func (repo *MemoryDBRepository) DeleteAllRooms(ctx context.Context, tx common.Transaction) error {
+	repo.write(tx, func(s *dbState) error {
+		// TRUNCATE ... CASCADE deletes all rows that reference the rooms
+		s.rooms = make(map[uuid.UUID]models.Room)
+		s.games = make(map[uuid.UUID]models.Game)
+		s.tokens = make(map[uuid.UUID]models.Token)
+		s.userRooms = make(map[userRoom]struct{})
+		return nil
+	})
+
+	return nil
+}
+
+// updateRoom changes a room that has not been deleted and returns common.ErrNotFound otherwise
+func updateRoom(s *dbState, roomId uuid.UUID, f func(room *models.Room)) error {
+	room, ok := s.rooms[roomId]
+	if !ok || isDeleted(room.DeletedAt) {
+		return common.ErrNotFound
+	}
+
+	f(&room)
+	s.rooms[roomId] = room
+
+	return nil
+}
+
+// compareRoomOverviews compares the column of two room overviews and returns -1, 0 or 1. Rooms without games are sorted last, like NULL values in postgres.
+func compareRoomOverviews(a, b models.RoomOverview, column string) int {
+	switch column {
+	case "updated_at":
+		return compareTimes(a.UpdatedAt, b.UpdatedAt)
+	case "member_count":
+		return compareInts(a.MemberCount, b.MemberCount)
+	case "last_game_at":
+		switch {
+		case a.LastGameAt == nil && b.LastGameAt == nil:
+			return 0
+		case a.LastGameAt == nil:
+			return 1
+		case b.LastGameAt == nil:
+			return -1
+		}
+		return compareTimes(*a.LastGameAt, *b.LastGameAt)
+	default:
+		return compareTimes(a.CreatedAt, b.CreatedAt)
+	}
+}
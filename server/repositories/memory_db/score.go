@@ -0,0 +1,239 @@
+package memory_db_repo
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/models"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FindScores returns up to limit scores that match the filter, sorted by the sort option and the score id.
+// If a cursor is given, the scores after (next) or before (prev) the score the cursor points to are returned.
+// The scores are always returned in the order of the sort option.
+func (repo *MemoryDBRepository) FindScores(
+	ctx context.Context,
+	tx common.Transaction,
+	filter models.ScoreFilter,
+	sortOption models.SortOption,
+	cursor *models.ScoreCursor,
+	limit int,
+) ([]models.Score, error) {
+	var scores []models.Score
+
+	// a previous page is searched in reverse order and reversed afterwards
+	desc := sortOption.Order == "desc"
+	isPrevPage := cursor != nil && cursor.Direction == models.PrevCursorDirection
+	if isPrevPage {
+		desc = !desc
+	}
+
+	repo.view(tx, func(s *dbState) {
+		for _, score := range s.scores {
+			if !scoreMatchesFilter(s, score, filter) {
+				continue
+			}
+
+			if cursor != nil {
+				cmp := compareScoreToCursor(score, sortOption.Column, cursor)
+				if desc && cmp >= 0 || !desc && cmp <= 0 {
+					continue
+				}
+			}
+
+			scores = append(scores, score)
+		}
+	})
+
+	sort.Slice(scores, func(i, j int) bool {
+		cmp := compareScores(scores[i], scores[j], sortOption.Column)
+		if cmp == 0 {
+			cmp = compareUuids(scores[i].ID, scores[j].ID)
+		}
+		if desc {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+	scores = page(scores, limit, 0)
+
+	if isPrevPage {
+		for i, j := 0, len(scores)-1; i < j; i, j = i+1, j-1 {
+			scores[i], scores[j] = scores[j], scores[i]
+		}
+	}
+
+	return scores, nil
+}
+
+// FindLeaderboardScores returns the scores that have been created since the given time together with the language and punctuation setting of their texts,
+// ordered by their creation time.
+func (repo *MemoryDBRepository) FindLeaderboardScores(ctx context.Context, tx common.Transaction, since time.Time, limit, offset int) ([]models.LeaderboardScore, error) {
+	var scores []models.Score
+	var leaderboardScores []models.LeaderboardScore
+
+	repo.view(tx, func(s *dbState) {
+		for _, score := range s.scores {
+			if _, ok := s.texts[score.TextId]; ok && !score.CreatedAt.Before(since) {
+				scores = append(scores, score)
+			}
+		}
+
+		sortScoresByCreatedAt(scores, false)
+		for _, score := range page(scores, limit, offset) {
+			text := s.texts[score.TextId]
+			leaderboardScores = append(leaderboardScores, models.LeaderboardScore{
+				UserId:         score.UserId,
+				WordsPerMinute: score.WordsPerMinute,
+				Language:       text.Language,
+				Punctuation:    text.Punctuation,
+				CreatedAt:      score.CreatedAt,
+			})
+		}
+	})
+
+	return leaderboardScores, nil
+}
+
+// CreateScore creates the score and computes its words per minute and accuracy like the generated columns of the scores table
+func (repo *MemoryDBRepository) CreateScore(ctx context.Context, tx common.Transaction, score models.Score) (*models.Score, error) {
+	const op errors.Op = "memory_db_repo.MemoryDBRepository.CreateScore"
+
+	now := repo.clock.Now()
+	if score.ID == uuid.Nil {
+		score.ID = uuid.New()
+	}
+	if score.CreatedAt.IsZero() {
+		score.CreatedAt = now
+	}
+	score.UpdatedAt = now
+	if score.TimeElapsed != 0 {
+		score.WordsPerMinute = float64(score.WordsTyped) * 60.0 / score.TimeElapsed
+	}
+	if score.WordsTyped != 0 {
+		score.Accuracy = 100.0 - float64(score.NumberErrors)*100.0/float64(score.WordsTyped)
+	}
+
+	typingErrors := make(models.ErrorsJSON, len(score.Errors))
+	for key, errorsNumber := range score.Errors {
+		typingErrors[key] = errorsNumber
+	}
+	score.Errors = typingErrors
+
+	err := repo.write(tx, func(s *dbState) error {
+		if _, ok := s.scores[score.ID]; ok {
+			return fmt.Errorf("duplicate key value violates unique constraint scores_pkey")
+		}
+
+		s.scores[score.ID] = score
+		return nil
+	})
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return &score, nil
+}
+
+func (repo *MemoryDBRepository) DeleteAllScores(ctx context.Context, tx common.Transaction) error {
+	repo.write(tx, func(s *dbState) error {
+		s.scores = make(map[uuid.UUID]models.Score)
+		return nil
+	})
+
+	return nil
+}
+
+func scoreMatchesFilter(s *dbState, score models.Score, filter models.ScoreFilter) bool {
+	text, textExists := s.texts[score.TextId]
+
+	switch {
+	case filter.UserId != uuid.Nil && score.UserId != filter.UserId:
+		return false
+	case filter.GameId != uuid.Nil && score.GameId != filter.GameId:
+		return false
+	case filter.Username != "" && s.users[score.UserId].Username != filter.Username:
+		return false
+	case filter.From != nil && score.CreatedAt.Before(*filter.From):
+		return false
+	case filter.To != nil && !score.CreatedAt.Before(*filter.To):
+		return false
+	case (filter.Language != "" || filter.Punctuation != nil) && !textExists:
+		return false
+	case filter.Language != "" && text.Language != filter.Language:
+		return false
+	case filter.Punctuation != nil && text.Punctuation != *filter.Punctuation:
+		return false
+	case filter.MinWordsPerMinute != nil && score.WordsPerMinute < *filter.MinWordsPerMinute:
+		return false
+	case filter.MaxWordsPerMinute != nil && score.WordsPerMinute > *filter.MaxWordsPerMinute:
+		return false
+	case filter.MinAccuracy != nil && score.Accuracy < *filter.MinAccuracy:
+		return false
+	case filter.MaxAccuracy != nil && score.Accuracy > *filter.MaxAccuracy:
+		return false
+	}
+
+	return true
+}
+
+// compareScores compares the column of two scores and returns -1, 0 or 1
+func compareScores(a, b models.Score, column string) int {
+	switch column {
+	case "accuracy":
+		return compareFloats(a.Accuracy, b.Accuracy)
+	case "words_per_minute":
+		return compareFloats(a.WordsPerMinute, b.WordsPerMinute)
+	case "number_errors", "errors":
+		return compareInts(a.NumberErrors, b.NumberErrors)
+	default:
+		return compareTimes(a.CreatedAt, b.CreatedAt)
+	}
+}
+
+// compareScoreToCursor compares the score to the score the cursor points to by the column and the score id, like the row comparison (column, id) > (?, ?)
+func compareScoreToCursor(score models.Score, column string, cursor *models.ScoreCursor) int {
+	var cmp int
+	switch {
+	case cursor.CreatedAt != nil:
+		cmp = compareTimes(score.CreatedAt, *cursor.CreatedAt)
+	case cursor.Number != nil:
+		cmp = compareFloats(scoreNumberValue(score, column), *cursor.Number)
+	}
+
+	if cmp == 0 {
+		cmp = compareUuids(score.ID, cursor.Id)
+	}
+
+	return cmp
+}
+
+func scoreNumberValue(score models.Score, column string) float64 {
+	switch column {
+	case "accuracy":
+		return score.Accuracy
+	case "words_per_minute":
+		return score.WordsPerMinute
+	case "number_errors", "errors":
+		return float64(score.NumberErrors)
+	default:
+		return 0
+	}
+}
+
+func sortScoresByCreatedAt(scores []models.Score, desc bool) {
+	sort.Slice(scores, func(i, j int) bool {
+		cmp := compareTimes(scores[i].CreatedAt, scores[j].CreatedAt)
+		if cmp == 0 {
+			cmp = compareUuids(scores[i].ID, scores[j].ID)
+		}
+		if desc {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+}
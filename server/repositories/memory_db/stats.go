@@ -0,0 +1,242 @@
+package memory_db_repo
+
+import (
+	"10-typing/common"
+	"10-typing/models"
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (repo *MemoryDBRepository) FindScoreTotals(ctx context.Context, tx common.Transaction, userId uuid.UUID) (*models.ScoreTotals, error) {
+	scoreTotals := getScoreTotals(repo.findScoresOfUser(tx, userId))
+
+	return &scoreTotals, nil
+}
+
+// FindScoreTrend aggregates the scores of the user that have been created since the given time into buckets of a day or a week (UTC).
+func (repo *MemoryDBRepository) FindScoreTrend(ctx context.Context, tx common.Transaction, userId uuid.UUID, interval models.StatsInterval, since time.Time) ([]models.ScoreTrendBucket, error) {
+	scoresByStart := make(map[time.Time][]models.Score)
+	for _, score := range repo.findScoresOfUser(tx, userId) {
+		if score.CreatedAt.Before(since) {
+			continue
+		}
+
+		start := truncateToDay(score.CreatedAt)
+		if interval == models.WeekStatsInterval {
+			// weeks start on monday, like date_trunc('week', ...)
+			start = start.AddDate(0, 0, -(int(start.Weekday())+6)%7)
+		}
+		scoresByStart[start] = append(scoresByStart[start], score)
+	}
+
+	scoreTrendBuckets := make([]models.ScoreTrendBucket, 0, len(scoresByStart))
+	for start, scores := range scoresByStart {
+		scoreTotals := getScoreTotals(scores)
+		scoreTrendBuckets = append(scoreTrendBuckets, models.ScoreTrendBucket{
+			Start:              start,
+			Scores:             scoreTotals.Scores,
+			AvgWordsPerMinute:  scoreTotals.AvgWordsPerMinute,
+			BestWordsPerMinute: scoreTotals.BestWordsPerMinute,
+			AvgAccuracy:        scoreTotals.AvgAccuracy,
+		})
+	}
+	sort.Slice(scoreTrendBuckets, func(i, j int) bool {
+		return scoreTrendBuckets[i].Start.Before(scoreTrendBuckets[j].Start)
+	})
+
+	return scoreTrendBuckets, nil
+}
+
+// FindKeyErrors sums up the errors per key of all scores of the user, ordered by the number of errors.
+func (repo *MemoryDBRepository) FindKeyErrors(ctx context.Context, tx common.Transaction, userId uuid.UUID) ([]models.KeyErrorCount, error) {
+	errorsByKey := make(map[string]int)
+	for _, score := range repo.findScoresOfUser(tx, userId) {
+		for key, errorsNumber := range score.Errors {
+			errorsByKey[key] += errorsNumber
+		}
+	}
+
+	keyErrorCounts := make([]models.KeyErrorCount, 0, len(errorsByKey))
+	for key, errorsNumber := range errorsByKey {
+		keyErrorCounts = append(keyErrorCounts, models.KeyErrorCount{Key: key, Errors: errorsNumber})
+	}
+	sort.Slice(keyErrorCounts, func(i, j int) bool {
+		if keyErrorCounts[i].Errors == keyErrorCounts[j].Errors {
+			return keyErrorCounts[i].Key < keyErrorCounts[j].Key
+		}
+		return keyErrorCounts[i].Errors > keyErrorCounts[j].Errors
+	})
+
+	return keyErrorCounts, nil
+}
+
+// FindScoreDays returns the distinct days (UTC) on which the user created scores, starting with the most recent day.
+func (repo *MemoryDBRepository) FindScoreDays(ctx context.Context, tx common.Transaction, userId uuid.UUID) ([]time.Time, error) {
+	dayExists := make(map[time.Time]bool)
+	var days []time.Time
+	for _, score := range repo.findScoresOfUser(tx, userId) {
+		day := truncateToDay(score.CreatedAt)
+		if !dayExists[day] {
+			dayExists[day] = true
+			days = append(days, day)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].After(days[j])
+	})
+
+	return days, nil
+}
+
+func (repo *MemoryDBRepository) FindScoreTotalsByLanguage(ctx context.Context, tx common.Transaction, userId uuid.UUID) ([]models.ScoreTotalsByFilter, error) {
+	scoresByLanguage := make(map[string][]models.Score)
+	repo.view(tx, func(s *dbState) {
+		for _, score := range s.scores {
+			if text, ok := s.texts[score.TextId]; ok && score.UserId == userId {
+				scoresByLanguage[text.Language] = append(scoresByLanguage[text.Language], score)
+			}
+		}
+	})
+
+	scoreTotals := make([]models.ScoreTotalsByFilter, 0, len(scoresByLanguage))
+	for language, scores := range scoresByLanguage {
+		scoreTotals = append(scoreTotals, models.ScoreTotalsByFilter{Language: language, ScoreTotals: getScoreTotals(scores)})
+	}
+	sort.Slice(scoreTotals, func(i, j int) bool {
+		return scoreTotals[i].Language < scoreTotals[j].Language
+	})
+
+	return scoreTotals, nil
+}
+
+func (repo *MemoryDBRepository) FindScoreTotalsByPunctuation(ctx context.Context, tx common.Transaction, userId uuid.UUID) ([]models.ScoreTotalsByFilter, error) {
+	scoresByPunctuation := make(map[bool][]models.Score)
+	repo.view(tx, func(s *dbState) {
+		for _, score := range s.scores {
+			if text, ok := s.texts[score.TextId]; ok && score.UserId == userId {
+				scoresByPunctuation[text.Punctuation] = append(scoresByPunctuation[text.Punctuation], score)
+			}
+		}
+	})
+
+	scoreTotals := make([]models.ScoreTotalsByFilter, 0, len(scoresByPunctuation))
+	for _, punctuation := range []bool{false, true} {
+		scores, ok := scoresByPunctuation[punctuation]
+		if !ok {
+			continue
+		}
+
+		punctuation := punctuation
+		scoreTotals = append(scoreTotals, models.ScoreTotalsByFilter{Punctuation: &punctuation, ScoreTotals: getScoreTotals(scores)})
+	}
+
+	return scoreTotals, nil
+}
+
+// FindTypedTexts returns the most recent scores of the user together with the texts that have been typed
+func (repo *MemoryDBRepository) FindTypedTexts(ctx context.Context, tx common.Transaction, userId uuid.UUID, limit int) ([]models.TypedText, error) {
+	var typedTexts []models.TypedText
+
+	repo.view(tx, func(s *dbState) {
+		var scores []models.Score
+		for _, score := range s.scores {
+			if _, ok := s.texts[score.TextId]; ok && score.UserId == userId {
+				scores = append(scores, score)
+			}
+		}
+
+		sortScoresByCreatedAt(scores, true)
+		for _, score := range page(scores, limit, 0) {
+			typedTexts = append(typedTexts, models.TypedText{
+				WordsTyped:  score.WordsTyped,
+				TimeElapsed: score.TimeElapsed,
+				Errors:      score.Errors,
+				Text:        s.texts[score.TextId].Text,
+			})
+		}
+	})
+
+	return typedTexts, nil
+}
+
+func (repo *MemoryDBRepository) findScoresOfUser(tx common.Transaction, userId uuid.UUID) []models.Score {
+	var scores []models.Score
+
+	repo.view(tx, func(s *dbState) {
+		for _, score := range s.scores {
+			if score.UserId == userId {
+				scores = append(scores, score)
+			}
+		}
+	})
+
+	return scores
+}
+
+func getScoreTotals(scores []models.Score) models.ScoreTotals {
+	var scoreTotals models.ScoreTotals
+	if len(scores) == 0 {
+		return scoreTotals
+	}
+
+	var wordsPerMinuteSum, accuracySum float64
+	for _, score := range scores {
+		scoreTotals.Scores++
+		scoreTotals.WordsTyped += int64(score.WordsTyped)
+		scoreTotals.TimeTypedSec += score.TimeElapsed
+		wordsPerMinuteSum += score.WordsPerMinute
+		accuracySum += score.Accuracy
+		if score.WordsPerMinute > scoreTotals.BestWordsPerMinute {
+			scoreTotals.BestWordsPerMinute = score.WordsPerMinute
+		}
+	}
+	scoreTotals.AvgWordsPerMinute = wordsPerMinuteSum / float64(len(scores))
+	scoreTotals.AvgAccuracy = accuracySum / float64(len(scores))
+
+	return scoreTotals
+}
+
+func truncateToDay(t time.Time) time.Time {
+	t = t.UTC()
+
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// FindSystemStats counts the rows of the tables that are not soft deleted
+func (repo *MemoryDBRepository) FindSystemStats(ctx context.Context, tx common.Transaction) (*models.SystemStats, error) {
+	var stats models.SystemStats
+
+	repo.view(tx, func(s *dbState) {
+		stats.Users = int64(len(s.users))
+		for _, user := range s.users {
+			if user.IsBanned() {
+				stats.BannedUsers++
+			}
+		}
+		for _, text := range s.texts {
+			if !isDeleted(text.DeletedAt) {
+				stats.Texts++
+			}
+		}
+		games := make(map[uuid.UUID]bool)
+		for _, score := range s.scores {
+			if !isDeleted(score.DeletedAt) {
+				stats.Scores++
+				if score.GameId != uuid.Nil {
+					games[score.GameId] = true
+				}
+			}
+		}
+		stats.Games = int64(len(games))
+		for _, room := range s.rooms {
+			if !isDeleted(room.DeletedAt) {
+				stats.Rooms++
+			}
+		}
+	})
+
+	return &stats, nil
+}
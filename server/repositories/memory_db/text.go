@@ -0,0 +1,572 @@
+package memory_db_repo
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/models"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// FindNewTextForUser returns the first published text that matches the filter and that the user has not typed yet, sorted by the sort option
+func (repo *MemoryDBRepository) FindNewTextForUser(
+	ctx context.Context,
+	tx common.Transaction,
+	userId uuid.UUID,
+	filter models.TextFilter,
+	sortOption models.SortOption,
+) (*models.Text, error) {
+	const op errors.Op = "memory_db_repo.MemoryDBRepository.FindNewTextForUser"
+
+	texts := repo.findTexts(tx, func(s *dbState, text models.Text) bool {
+		return text.IsPublished() &&
+			text.Language == filter.Language &&
+			text.Punctuation == filter.Punctuation &&
+			textMatchesFilter(text, filter) &&
+			!isTypedByAnyUser(s, text.ID, userId)
+	})
+	sort.Slice(texts, func(i, j int) bool {
+		cmp := compareTexts(texts[i], texts[j], sortOption.Column)
+		if sortOption.Order == "desc" {
+			cmp = -cmp
+		}
+		if cmp == 0 {
+			cmp = compareUuids(texts[i].ID, texts[j].ID)
+		}
+		return cmp < 0
+	})
+
+	if len(texts) == 0 {
+		return nil, errors.E(op, common.ErrNotFound)
+	}
+
+	return &texts[0], nil
+}
+
+// FindNewTextsForUser returns up to limit of the newest published texts and private texts of the user with the given language and punctuation that the user has not typed yet
+func (repo *MemoryDBRepository) FindNewTextsForUser(ctx context.Context, tx common.Transaction, userId uuid.UUID, language string, punctuation bool, limit int) ([]models.Text, error) {
+	texts := repo.findTexts(tx, func(s *dbState, text models.Text) bool {
+		isOwnPrivateText := text.Visibility == models.PrivateTextVisibility && text.UserId != nil && *text.UserId == userId
+
+		return (text.IsPublished() || isOwnPrivateText) && text.Language == language && text.Punctuation == punctuation && !isTypedByAnyUser(s, text.ID, userId)
+	})
+	sortTextsByCreatedAt(texts, true)
+
+	return page(texts, limit, 0), nil
+}
+
+// FindNewTextForUsers returns the newest text with the given language and punctuation that none of the users has typed yet
+func (repo *MemoryDBRepository) FindNewTextForUsers(ctx context.Context, tx common.Transaction, userIds []uuid.UUID, language string, punctuation bool) (*models.Text, error) {
+	const op errors.Op = "memory_db_repo.MemoryDBRepository.FindNewTextForUsers"
+
+	texts := repo.findTexts(tx, func(s *dbState, text models.Text) bool {
+		return text.IsPublished() && text.Language == language && text.Punctuation == punctuation && !isTypedByAnyUser(s, text.ID, userIds...)
+	})
+	sortTextsByCreatedAt(texts, true)
+
+	if len(texts) == 0 {
+		return nil, errors.E(op, common.ErrNotFound)
+	}
+
+	return &texts[0], nil
+}
+
+// FindAllTextIds returns the ids of all published texts, which are public and approved
+func (repo *MemoryDBRepository) FindAllTextIds(ctx context.Context, tx common.Transaction) ([]uuid.UUID, error) {
+	texts := repo.findTexts(tx, func(s *dbState, text models.Text) bool {
+		return text.IsPublished()
+	})
+
+	textIds := make([]uuid.UUID, 0, len(texts))
+	for _, text := range texts {
+		textIds = append(textIds, text.ID)
+	}
+
+	return textIds, nil
+}
+
+// FindTextsForAnalysis returns up to limit texts ordered by id that come after the text with the id afterId.
+// If onlyUnanalyzed is true, only texts that have not been analyzed yet are returned.
+func (repo *MemoryDBRepository) FindTextsForAnalysis(ctx context.Context, tx common.Transaction, afterId uuid.UUID, onlyUnanalyzed bool, limit int) ([]models.Text, error) {
+	texts := repo.findTexts(tx, func(s *dbState, text models.Text) bool {
+		return compareUuids(text.ID, afterId) > 0 && (!onlyUnanalyzed || text.Words == 0)
+	})
+	sortTextsById(texts)
+
+	return page(texts, limit, 0), nil
+}
+
+// FindTextsAfterId returns up to limit texts ordered by id that come after the text with the id afterId
+func (repo *MemoryDBRepository) FindTextsAfterId(ctx context.Context, tx common.Transaction, afterId uuid.UUID, limit int) ([]models.Text, error) {
+	texts := repo.findTexts(tx, func(s *dbState, text models.Text) bool {
+		return compareUuids(text.ID, afterId) > 0
+	})
+	sortTextsById(texts)
+
+	return page(texts, limit, 0), nil
+}
+
+// FindExistingContentHashes returns the content hashes of the given ones that belong to an existing public text that has not been rejected, which are unique
+func (repo *MemoryDBRepository) FindExistingContentHashes(ctx context.Context, tx common.Transaction, contentHashes []string) ([]string, error) {
+	isSearched := make(map[string]bool, len(contentHashes))
+	for _, contentHash := range contentHashes {
+		isSearched[contentHash] = true
+	}
+
+	isFound := make(map[string]bool)
+	var existingContentHashes []string
+	for _, text := range repo.findTexts(tx, func(s *dbState, text models.Text) bool {
+		return isUniqueContentHashText(text) && isSearched[text.ContentHash]
+	}) {
+		if !isFound[text.ContentHash] {
+			isFound[text.ContentHash] = true
+			existingContentHashes = append(existingContentHashes, text.ContentHash)
+		}
+	}
+
+	return existingContentHashes, nil
+}
+
+// FindPublicTextByContentHash returns the public text with the content hash that has not been rejected, which is unique
+func (repo *MemoryDBRepository) FindPublicTextByContentHash(ctx context.Context, tx common.Transaction, contentHash string) (*models.Text, error) {
+	const op errors.Op = "memory_db_repo.MemoryDBRepository.FindPublicTextByContentHash"
+
+	texts := repo.findTexts(tx, func(s *dbState, text models.Text) bool {
+		return isUniqueContentHashText(text) && text.ContentHash == contentHash
+	})
+	if len(texts) == 0 {
+		return nil, errors.E(op, common.ErrNotFound)
+	}
+
+	return &texts[0], nil
+}
+
+// FindDuplicatePublicTextIds returns the ids of the public texts that have not been rejected and that have the content hash of an older one,
+// e.g. because they have been created before the content hashes of the public texts were unique
+func (repo *MemoryDBRepository) FindDuplicatePublicTextIds(ctx context.Context, tx common.Transaction) ([]uuid.UUID, error) {
+	texts := repo.findTexts(tx, func(s *dbState, text models.Text) bool {
+		return isUniqueContentHashText(text) && text.ContentHash != ""
+	})
+	sortTextsByCreatedAt(texts, false)
+
+	isFound := make(map[string]bool, len(texts))
+	var textIds []uuid.UUID
+	for _, text := range texts {
+		if isFound[text.ContentHash] {
+			textIds = append(textIds, text.ID)
+		}
+		isFound[text.ContentHash] = true
+	}
+	sort.Slice(textIds, func(i, j int) bool {
+		return compareUuids(textIds[i], textIds[j]) < 0
+	})
+
+	return textIds, nil
+}
+
+// FindTextUsageStats aggregates the scores of the texts. Texts that have never been typed are not included.
+func (repo *MemoryDBRepository) FindTextUsageStats(ctx context.Context, tx common.Transaction, textIds []uuid.UUID) ([]models.TextUsageStats, error) {
+	scoresByTextId := make(map[uuid.UUID][]models.Score, len(textIds))
+	repo.view(tx, func(s *dbState) {
+		for _, textId := range textIds {
+			scoresByTextId[textId] = nil
+		}
+		for _, score := range s.scores {
+			if _, ok := scoresByTextId[score.TextId]; ok {
+				scoresByTextId[score.TextId] = append(scoresByTextId[score.TextId], score)
+			}
+		}
+	})
+
+	var usageStats []models.TextUsageStats
+	for textId, scores := range scoresByTextId {
+		if len(scores) == 0 {
+			continue
+		}
+
+		scoreTotals := getScoreTotals(scores)
+		typists := make(map[uuid.UUID]bool)
+		lastTypedAt := scores[0].CreatedAt
+		for _, score := range scores {
+			typists[score.UserId] = true
+			if score.CreatedAt.After(lastTypedAt) {
+				lastTypedAt = score.CreatedAt
+			}
+		}
+
+		usageStats = append(usageStats, models.TextUsageStats{
+			TextId:            textId,
+			TimesTyped:        scoreTotals.Scores,
+			Typists:           int64(len(typists)),
+			AvgWordsPerMinute: scoreTotals.AvgWordsPerMinute,
+			AvgAccuracy:       scoreTotals.AvgAccuracy,
+			LastTypedAt:       &lastTypedAt,
+		})
+	}
+
+	return usageStats, nil
+}
+
+// UpdateTextAnalysis updates the columns of the text that are computed by the text analysis
+func (repo *MemoryDBRepository) UpdateTextAnalysis(ctx context.Context, tx common.Transaction, text models.Text) error {
+	updatedAt := repo.clock.Now()
+
+	repo.write(tx, func(s *dbState) error {
+		storedText, ok := s.texts[text.ID]
+		if !ok {
+			return nil
+		}
+
+		storedText.SpecialCharacters = text.SpecialCharacters
+		storedText.Numbers = text.Numbers
+		storedText.Characters = text.Characters
+		storedText.Letters = text.Letters
+		storedText.UpperCaseLetters = text.UpperCaseLetters
+		storedText.Digits = text.Digits
+		storedText.PunctuationMarks = text.PunctuationMarks
+		storedText.Words = text.Words
+		storedText.AverageWordLength = text.AverageWordLength
+		storedText.BigramRarity = text.BigramRarity
+		storedText.Difficulty = text.Difficulty
+		storedText.ContentHash = text.ContentHash
+		storedText.UpdatedAt = updatedAt
+		s.texts[text.ID] = storedText
+		return nil
+	})
+
+	return nil
+}
+
+func (repo *MemoryDBRepository) FindTextById(ctx context.Context, tx common.Transaction, textId uuid.UUID) (*models.Text, error) {
+	const op errors.Op = "memory_db_repo.MemoryDBRepository.FindTextById"
+
+	texts := repo.findTexts(tx, func(s *dbState, text models.Text) bool {
+		return text.ID == textId
+	})
+	if len(texts) == 0 {
+		return nil, errors.E(op, common.ErrNotFound)
+	}
+
+	return &texts[0], nil
+}
+
+// CreateTextAndCache creates the text and adds its id to the text ids cache if the text is published
+func (repo *MemoryDBRepository) CreateTextAndCache(ctx context.Context, tx common.Transaction, cacheRepo common.CacheRepository, text models.Text) (*models.Text, error) {
+	const op errors.Op = "memory_db_repo.MemoryDBRepository.CreateTextAndCache"
+
+	texts, err := repo.CreateTextsAndCache(ctx, tx, cacheRepo, []models.Text{text})
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return &texts[0], nil
+}
+
+// CreateTextsAndCache creates the texts at once and adds the ids of the published ones to the text ids cache
+func (repo *MemoryDBRepository) CreateTextsAndCache(ctx context.Context, tx common.Transaction, cacheRepo common.CacheRepository, texts []models.Text) ([]models.Text, error) {
+	const op errors.Op = "memory_db_repo.MemoryDBRepository.CreateTextsAndCache"
+
+	now := repo.clock.Now()
+	createdTexts := make([]models.Text, 0, len(texts))
+	for _, text := range texts {
+		if text.ID == uuid.Nil {
+			text.ID = uuid.New()
+		}
+		if text.Visibility == "" {
+			text.Visibility = models.PublicTextVisibility
+		}
+		if text.Status == "" {
+			text.Status = models.ApprovedTextStatus
+		}
+		text.CreatedAt = now
+		text.UpdatedAt = now
+		text.User = nil
+		text.Scores = nil
+		text.Games = nil
+		createdTexts = append(createdTexts, text)
+	}
+
+	err := repo.write(tx, func(s *dbState) error {
+		// like the insert, all texts are created or none
+		publicContentHashes := make(map[string]bool)
+		for _, text := range s.texts {
+			if isUniquePublicContentHash(text) {
+				publicContentHashes[text.ContentHash] = true
+			}
+		}
+		for _, text := range createdTexts {
+			if _, ok := s.texts[text.ID]; ok {
+				return fmt.Errorf("duplicate key value violates unique constraint texts_pkey")
+			}
+			if isUniquePublicContentHash(text) {
+				if publicContentHashes[text.ContentHash] {
+					return fmt.Errorf("duplicate key value violates unique constraint idx_texts_public_unrejected_content_hash")
+				}
+				publicContentHashes[text.ContentHash] = true
+			}
+		}
+
+		for _, text := range createdTexts {
+			s.texts[text.ID] = text
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	var publishedTextIds []uuid.UUID
+	for _, text := range createdTexts {
+		if text.IsPublished() {
+			publishedTextIds = append(publishedTextIds, text.ID)
+		}
+	}
+	if len(publishedTextIds) == 0 {
+		return createdTexts, nil
+	}
+
+	if err := repo.cacheTextId(ctx, tx, cacheRepo, publishedTextIds...); err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return createdTexts, nil
+}
+
+// isUniquePublicContentHash reports whether the text is covered by the unique index of the content hashes of the public texts
+func isUniquePublicContentHash(text models.Text) bool {
+	return isUniqueContentHashText(text) && text.ContentHash != "" && !isDeleted(text.DeletedAt)
+}
+
+// isUniqueContentHashText reports whether the text is public and has not been rejected, like the condition of the unique index of the content hashes
+func isUniqueContentHashText(text models.Text) bool {
+	return text.Visibility == models.PublicTextVisibility && text.Status != models.RejectedTextStatus
+}
+
+// FindTextsByUserId returns the custom texts of the user, newest first, and the total number of them
+func (repo *MemoryDBRepository) FindTextsByUserId(ctx context.Context, tx common.Transaction, userId uuid.UUID, limit, offset int) ([]models.Text, int64, error) {
+	texts := repo.findTexts(tx, func(s *dbState, text models.Text) bool {
+		return text.UserId != nil && *text.UserId == userId
+	})
+	sortTextsByCreatedAt(texts, true)
+
+	return page(texts, limit, offset), int64(len(texts)), nil
+}
+
+// FindTextsByStatus returns the public custom texts with the given moderation status, oldest first, and the total number of them
+func (repo *MemoryDBRepository) FindTextsByStatus(ctx context.Context, tx common.Transaction, status models.TextStatus, limit, offset int) ([]models.Text, int64, error) {
+	texts := repo.findTexts(tx, func(s *dbState, text models.Text) bool {
+		return text.Visibility == models.PublicTextVisibility && text.Status == status
+	})
+	sortTextsByCreatedAt(texts, false)
+
+	return page(texts, limit, offset), int64(len(texts)), nil
+}
+
+// UpdateTextStatusAndCache sets the moderation status of the text and adds its id to the text ids cache if the text has been published
+// or removes it from the cache if the text is not published anymore, e.g. because it has been rejected, so that no new games are started with it.
+func (repo *MemoryDBRepository) UpdateTextStatusAndCache(
+	ctx context.Context,
+	tx common.Transaction,
+	cacheRepo common.CacheRepository,
+	textId uuid.UUID,
+	status models.TextStatus,
+	moderationNote string,
+) (*models.Text, error) {
+	const op errors.Op = "memory_db_repo.MemoryDBRepository.UpdateTextStatusAndCache"
+	moderatedAt := repo.clock.Now()
+	var text models.Text
+
+	err := repo.write(tx, func(s *dbState) error {
+		storedText, ok := s.texts[textId]
+		if !ok || isDeleted(storedText.DeletedAt) {
+			return common.ErrNotFound
+		}
+
+		storedText.Status = status
+		// a rejected text that is not rejected anymore is covered by the unique index of the content hashes again
+		if isUniquePublicContentHash(storedText) {
+			for _, text := range s.texts {
+				if text.ID != textId && isUniquePublicContentHash(text) && text.ContentHash == storedText.ContentHash {
+					return fmt.Errorf("duplicate key value violates unique constraint idx_texts_public_unrejected_content_hash")
+				}
+			}
+		}
+		storedText.ModerationNote = moderationNote
+		storedText.ModeratedAt = &moderatedAt
+		storedText.UpdatedAt = moderatedAt
+		s.texts[textId] = storedText
+		text = storedText
+		return nil
+	})
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	if !text.IsPublished() {
+		if err := cacheRepo.RemoveTextId(ctx, nil, text.ID); err != nil {
+			return nil, errors.E(op, err)
+		}
+
+		return &text, nil
+	}
+
+	if err := repo.cacheTextId(ctx, tx, cacheRepo, text.ID); err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return &text, nil
+}
+
+// SoftDeleteTextAndCache deletes the text and removes its id from the text ids cache, so that it isn't served anymore and no new games are started with it.
+func (repo *MemoryDBRepository) SoftDeleteTextAndCache(ctx context.Context, tx common.Transaction, cacheRepo common.CacheRepository, textId uuid.UUID) error {
+	const op errors.Op = "memory_db_repo.MemoryDBRepository.SoftDeleteTextAndCache"
+	deletedAt := repo.deletedAt()
+
+	err := repo.write(tx, func(s *dbState) error {
+		text, ok := s.texts[textId]
+		if !ok || isDeleted(text.DeletedAt) {
+			return common.ErrNotFound
+		}
+
+		text.DeletedAt = deletedAt
+		s.texts[textId] = text
+		return nil
+	})
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	if err := cacheRepo.RemoveTextId(ctx, nil, textId); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+func (repo *MemoryDBRepository) DeleteAllTexts(ctx context.Context, tx common.Transaction) error {
+	repo.write(tx, func(s *dbState) error {
+		// TRUNCATE ... CASCADE deletes all rows that reference the texts
+		s.texts = make(map[uuid.UUID]models.Text)
+		s.scores = make(map[uuid.UUID]models.Score)
+		s.games = make(map[uuid.UUID]models.Game)
+		return nil
+	})
+
+	return nil
+}
+
+func (repo *MemoryDBRepository) cacheTextId(ctx context.Context, tx common.Transaction, cacheRepo common.CacheRepository, textIds ...uuid.UUID) error {
+	const op errors.Op = "memory_db_repo.MemoryDBRepository.cacheTextId"
+
+	// if no text ids key exists, all text ids are written to the text ids key
+	allTextsAreInCache, err := cacheRepo.TextIdsKeyExists(ctx)
+	switch {
+	case err != nil:
+		return errors.E(op, err)
+	case !allTextsAreInCache:
+		allTextIds, err := repo.FindAllTextIds(ctx, tx)
+		if err != nil {
+			return errors.E(op, err)
+		}
+
+		allTextIds = append(allTextIds, textIds...)
+
+		if err = cacheRepo.SetTextId(ctx, nil, allTextIds...); err != nil {
+			return errors.E(op, err)
+		}
+	default:
+		if err = cacheRepo.SetTextId(ctx, nil, textIds...); err != nil {
+			return errors.E(op, err)
+		}
+	}
+
+	return nil
+}
+
+// findTexts returns the texts that have not been deleted and match the condition
+func (repo *MemoryDBRepository) findTexts(tx common.Transaction, matches func(s *dbState, text models.Text) bool) []models.Text {
+	var texts []models.Text
+
+	repo.view(tx, func(s *dbState) {
+		for _, text := range s.texts {
+			if !isDeleted(text.DeletedAt) && matches(s, text) {
+				texts = append(texts, text)
+			}
+		}
+	})
+
+	return texts
+}
+
+func textMatchesFilter(text models.Text, filter models.TextFilter) bool {
+	switch {
+	case filter.SpecialCharactersGte != 0 && text.SpecialCharacters < filter.SpecialCharactersGte:
+		return false
+	case filter.SpecialCharactersLte != 0 && text.SpecialCharacters > filter.SpecialCharactersLte:
+		return false
+	case filter.NumbersGte != 0 && text.Numbers < filter.NumbersGte:
+		return false
+	case filter.NumbersLte != 0 && text.Numbers > filter.NumbersLte:
+		return false
+	case filter.DifficultyGte != nil && text.Difficulty < *filter.DifficultyGte:
+		return false
+	case filter.DifficultyLte != nil && text.Difficulty > *filter.DifficultyLte:
+		return false
+	case filter.WordsGte != nil && text.Words < *filter.WordsGte:
+		return false
+	case filter.WordsLte != nil && text.Words > *filter.WordsLte:
+		return false
+	}
+
+	return true
+}
+
+// isTypedByAnyUser reports whether one of the users has a score of the text
+func isTypedByAnyUser(s *dbState, textId uuid.UUID, userIds ...uuid.UUID) bool {
+	for _, score := range s.scores {
+		if score.TextId != textId {
+			continue
+		}
+		for _, userId := range userIds {
+			if score.UserId == userId {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// compareTexts compares the column of two texts and returns -1, 0 or 1
+func compareTexts(a, b models.Text, column string) int {
+	switch column {
+	case "difficulty":
+		return compareFloats(a.Difficulty, b.Difficulty)
+	case "words":
+		return compareInts(a.Words, b.Words)
+	default:
+		return compareTimes(a.CreatedAt, b.CreatedAt)
+	}
+}
+
+func sortTextsByCreatedAt(texts []models.Text, desc bool) {
+	sort.Slice(texts, func(i, j int) bool {
+		cmp := compareTimes(texts[i].CreatedAt, texts[j].CreatedAt)
+		if desc {
+			cmp = -cmp
+		}
+		if cmp == 0 {
+			cmp = compareUuids(texts[i].ID, texts[j].ID)
+		}
+		return cmp < 0
+	})
+}
+
+func sortTextsById(texts []models.Text) {
+	sort.Slice(texts, func(i, j int) bool {
+		return compareUuids(texts[i].ID, texts[j].ID) < 0
+	})
+}
@@ -0,0 +1,24 @@
+package memory_db_repo
+
+import (
+	"10-typing/common"
+	"10-typing/models"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+func (repo *MemoryDBRepository) CreateToken(ctx context.Context, tx common.Transaction, roomId uuid.UUID) (*models.Token, error) {
+	token := models.Token{
+		ID:        uuid.New(),
+		CreatedAt: repo.clock.Now(),
+		RoomID:    roomId,
+	}
+
+	repo.write(tx, func(s *dbState) error {
+		s.tokens[token.ID] = token
+		return nil
+	})
+
+	return &token, nil
+}
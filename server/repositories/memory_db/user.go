@@ -0,0 +1,289 @@
+package memory_db_repo
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/models"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (repo *MemoryDBRepository) FindUserByEmail(ctx context.Context, tx common.Transaction, email string) (*models.User, error) {
+	const op errors.Op = "memory_db_repo.MemoryDBRepository.FindUserByEmail"
+	var user *models.User
+
+	repo.view(tx, func(s *dbState) {
+		for _, u := range s.users {
+			if u.Email == email {
+				user = &u
+				return
+			}
+		}
+	})
+	if user == nil {
+		return nil, errors.E(op, common.ErrNotFound)
+	}
+
+	return user, nil
+}
+
+func (repo *MemoryDBRepository) FindUsers(ctx context.Context, tx common.Transaction, username, usernameSubstr string) ([]models.User, error) {
+	var users []models.User
+
+	repo.view(tx, func(s *dbState) {
+		for _, user := range s.users {
+			if username != "" && user.Username != username {
+				continue
+			}
+			if usernameSubstr != "" && !strings.Contains(strings.ToLower(user.Username), strings.ToLower(usernameSubstr)) {
+				continue
+			}
+
+			users = append(users, user)
+		}
+	})
+	sortUsersByUsername(users)
+
+	return users, nil
+}
+
+func (repo *MemoryDBRepository) FindUserById(ctx context.Context, tx common.Transaction, userId uuid.UUID) (*models.User, error) {
+	const op errors.Op = "memory_db_repo.MemoryDBRepository.FindUserById"
+	var user models.User
+	var ok bool
+
+	repo.view(tx, func(s *dbState) {
+		user, ok = s.users[userId]
+	})
+	if !ok {
+		return nil, errors.E(op, common.ErrNotFound)
+	}
+
+	return &user, nil
+}
+
+func (repo *MemoryDBRepository) FindUsersByIds(ctx context.Context, tx common.Transaction, userIds []uuid.UUID) ([]models.User, error) {
+	var users []models.User
+
+	repo.view(tx, func(s *dbState) {
+		for _, userId := range userIds {
+			if user, ok := s.users[userId]; ok {
+				users = append(users, user)
+			}
+		}
+	})
+	sortUsersByUsername(users)
+
+	return users, nil
+}
+
+// FindUsersByIdsForUpdate returns the users. The users aren't locked, but the writes of a transaction are only applied on Commit.
+func (repo *MemoryDBRepository) FindUsersByIdsForUpdate(ctx context.Context, tx common.Transaction, userIds []uuid.UUID) ([]models.User, error) {
+	return repo.FindUsersByIds(ctx, tx, userIds)
+}
+
+// FindAllUsers returns the users that match the filter, sorted by username, and the total number of them
+func (repo *MemoryDBRepository) FindAllUsers(ctx context.Context, tx common.Transaction, filter models.UserFilter, limit, offset int) ([]models.User, int64, error) {
+	var users []models.User
+
+	repo.view(tx, func(s *dbState) {
+		for _, user := range s.users {
+			if filter.Role != "" && user.Role != filter.Role {
+				continue
+			}
+			if filter.Banned != nil && user.IsBanned() != *filter.Banned {
+				continue
+			}
+
+			users = append(users, user)
+		}
+	})
+	sortUsersByUsername(users)
+
+	return page(users, limit, offset), int64(len(users)), nil
+}
+
+func (repo *MemoryDBRepository) CreateUserAndCache(ctx context.Context, tx common.Transaction, cacheRepo common.CacheRepository, newUser models.User) (*models.User, error) {
+	const op errors.Op = "memory_db_repo.MemoryDBRepository.CreateUserAndCache"
+
+	createdUser, err := repo.createUser(tx, newUser)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	if err = cacheRepo.SetUser(ctx, nil, *createdUser); err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return createdUser, nil
+}
+
+func (repo *MemoryDBRepository) VerifyUserAndCache(ctx context.Context, tx common.Transaction, cacheRepo common.CacheRepository, userId uuid.UUID) error {
+	const op errors.Op = "memory_db_repo.MemoryDBRepository.VerifyUserAndCache"
+
+	repo.write(tx, func(s *dbState) error {
+		if user, ok := s.users[userId]; ok {
+			user.IsVerified = true
+			s.users[userId] = user
+		}
+		return nil
+	})
+
+	userKeyExists, err := cacheRepo.UserExists(ctx, userId)
+	if err != nil {
+		return errors.E(op, err)
+	}
+	if !userKeyExists {
+		// if user is not in cache, then it also doesn't have to be verified in the cache
+		return nil
+	}
+
+	if err := cacheRepo.VerifyUser(ctx, nil, userId); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+func (repo *MemoryDBRepository) UpdateUserRoleAndCache(ctx context.Context, tx common.Transaction, cacheRepo common.CacheRepository, userId uuid.UUID, role models.UserRole) (*models.User, error) {
+	const op errors.Op = "memory_db_repo.MemoryDBRepository.UpdateUserRoleAndCache"
+
+	user, err := repo.updateUserAndCache(ctx, tx, cacheRepo, userId, func(user *models.User) {
+		user.Role = role
+	})
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return user, nil
+}
+
+// UpdateUserBannedAndCache bans the user now or unbans the user
+func (repo *MemoryDBRepository) UpdateUserBannedAndCache(ctx context.Context, tx common.Transaction, cacheRepo common.CacheRepository, userId uuid.UUID, banned bool) (*models.User, error) {
+	const op errors.Op = "memory_db_repo.MemoryDBRepository.UpdateUserBannedAndCache"
+	var bannedAt *time.Time
+	if banned {
+		now := repo.clock.Now()
+		bannedAt = &now
+	}
+
+	user, err := repo.updateUserAndCache(ctx, tx, cacheRepo, userId, func(user *models.User) {
+		user.BannedAt = bannedAt
+	})
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return user, nil
+}
+
+func (repo *MemoryDBRepository) UpdateUserKeyboardLayoutAndCache(ctx context.Context, tx common.Transaction, cacheRepo common.CacheRepository, userId uuid.UUID, keyboardLayout string) (*models.User, error) {
+	const op errors.Op = "memory_db_repo.MemoryDBRepository.UpdateUserKeyboardLayoutAndCache"
+
+	user, err := repo.updateUserAndCache(ctx, tx, cacheRepo, userId, func(user *models.User) {
+		user.KeyboardLayout = keyboardLayout
+	})
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return user, nil
+}
+
+func (repo *MemoryDBRepository) DeleteAllUsers(ctx context.Context, tx common.Transaction) error {
+	repo.write(tx, func(s *dbState) error {
+		// TRUNCATE ... CASCADE deletes all rows that reference the users
+		s.users = make(map[uuid.UUID]models.User)
+		s.texts = make(map[uuid.UUID]models.Text)
+		s.scores = make(map[uuid.UUID]models.Score)
+		s.games = make(map[uuid.UUID]models.Game)
+		s.rooms = make(map[uuid.UUID]models.Room)
+		s.tokens = make(map[uuid.UUID]models.Token)
+		s.ratingChanges = nil
+		s.userRooms = make(map[userRoom]struct{})
+		return nil
+	})
+
+	return nil
+}
+
+// updateUserAndCache updates the user and replaces the cached user, so that the change applies to the sessions of the user immediately
+func (repo *MemoryDBRepository) updateUserAndCache(ctx context.Context, tx common.Transaction, cacheRepo common.CacheRepository, userId uuid.UUID, update func(user *models.User)) (*models.User, error) {
+	const op errors.Op = "memory_db_repo.MemoryDBRepository.updateUserAndCache"
+	var user models.User
+
+	err := repo.write(tx, func(s *dbState) error {
+		storedUser, ok := s.users[userId]
+		if !ok {
+			return common.ErrNotFound
+		}
+
+		update(&storedUser)
+		s.users[userId] = storedUser
+		user = storedUser
+		return nil
+	})
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	if err := cacheRepo.SetUser(ctx, nil, user); err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return &user, nil
+}
+
+// createUser sets the column defaults of the users table and enforces the unique username and email
+func (repo *MemoryDBRepository) createUser(tx common.Transaction, newUser models.User) (*models.User, error) {
+	const op errors.Op = "memory_db_repo.MemoryDBRepository.createUser"
+
+	if newUser.ID == uuid.Nil {
+		newUser.ID = uuid.New()
+	}
+	if newUser.Rating == 0 {
+		newUser.Rating = models.DefaultRating
+	}
+	if newUser.KeyboardLayout == "" {
+		newUser.KeyboardLayout = "qwerty"
+	}
+	if newUser.Role == "" {
+		newUser.Role = models.UserUserRole
+	}
+	newUser.Password = ""
+	newUser.Scores = nil
+	newUser.Rooms = nil
+	newUser.RoomsAdmin = nil
+
+	err := repo.write(tx, func(s *dbState) error {
+		for _, user := range s.users {
+			switch {
+			case user.ID == newUser.ID:
+				return fmt.Errorf("duplicate key value violates unique constraint users_pkey")
+			case user.Username == newUser.Username:
+				return fmt.Errorf("duplicate key value violates unique constraint idx_users_username")
+			case user.Email == newUser.Email:
+				return fmt.Errorf("duplicate key value violates unique constraint idx_users_email")
+			}
+		}
+
+		s.users[newUser.ID] = newUser
+		return nil
+	})
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return &newUser, nil
+}
+
+func sortUsersByUsername(users []models.User) {
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Username < users[j].Username
+	})
+}
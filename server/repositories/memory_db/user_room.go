@@ -0,0 +1,80 @@
+package memory_db_repo
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+func (repo *MemoryDBRepository) CreateUserRoom(ctx context.Context, tx common.Transaction, userId, roomId uuid.UUID) error {
+	const op errors.Op = "memory_db_repo.MemoryDBRepository.CreateUserRoom"
+
+	err := repo.write(tx, func(s *dbState) error {
+		ur := userRoom{userId, roomId}
+		if _, ok := s.userRooms[ur]; ok {
+			return fmt.Errorf("duplicate key value violates unique constraint user_rooms_pkey")
+		}
+
+		s.userRooms[ur] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+func (repo *MemoryDBRepository) DeleteUserRoom(ctx context.Context, tx common.Transaction, userId, roomId uuid.UUID) error {
+	repo.write(tx, func(s *dbState) error {
+		delete(s.userRooms, userRoom{userId, roomId})
+		return nil
+	})
+
+	return nil
+}
+
+// FindRoomMemberIdsForUpdate returns the ids of the members of the room. The rows aren't locked,
+// but a transaction that adds a member who has been added concurrently fails on Commit with the duplicate key of user_rooms.
+func (repo *MemoryDBRepository) FindRoomMemberIdsForUpdate(ctx context.Context, tx common.Transaction, roomId uuid.UUID) ([]uuid.UUID, error) {
+	const op errors.Op = "memory_db_repo.MemoryDBRepository.FindRoomMemberIdsForUpdate"
+	var memberIds []uuid.UUID
+	var found bool
+
+	repo.view(tx, func(s *dbState) {
+		room, ok := s.rooms[roomId]
+		if !ok || isDeleted(room.DeletedAt) {
+			return
+		}
+
+		found = true
+		for ur := range s.userRooms {
+			if ur.roomId == roomId {
+				memberIds = append(memberIds, ur.userId)
+			}
+		}
+	})
+	if !found {
+		return nil, errors.E(op, common.ErrNotFound)
+	}
+
+	return memberIds, nil
+}
+
+// FindRoomIdsByUserId returns the ids of the rooms of which the user is a member
+func (repo *MemoryDBRepository) FindRoomIdsByUserId(ctx context.Context, tx common.Transaction, userId uuid.UUID) ([]uuid.UUID, error) {
+	var roomIds []uuid.UUID
+
+	repo.view(tx, func(s *dbState) {
+		for ur := range s.userRooms {
+			if room, ok := s.rooms[ur.roomId]; ur.userId == userId && ok && !isDeleted(room.DeletedAt) {
+				roomIds = append(roomIds, ur.roomId)
+			}
+		}
+	})
+
+	return roomIds, nil
+}
@@ -0,0 +1,241 @@
+package open_ai_repo
+
+import (
+	"10-typing/errors"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// requestTimeout is the maximum duration of a single request to the API including reading the response body
+	requestTimeout = 60 * time.Second
+	// maxRequestAttempts is the number of times a request is sent before giving up on rate limits, server errors and network errors
+	maxRequestAttempts = 3
+	// baseBackoff is the backoff before the first retry, which doubles with every following retry
+	baseBackoff = 1 * time.Second
+	// maxBackoff caps the backoff and the duration of a Retry-After header
+	maxBackoff = 30 * time.Second
+)
+
+type ChatCompletionRequest struct {
+	Model    string        `json:"model"`
+	Messages []ChatMessage `json:"messages"`
+}
+
+type ChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type ChatCompletionResponse struct {
+	Choices []Choice `json:"choices"`
+}
+
+type Choice struct {
+	Message ChatMessage `json:"message"`
+}
+
+// ErrorResponse is the body that the API returns for requests that failed
+type ErrorResponse struct {
+	Error *ApiError `json:"error"`
+}
+
+type ApiError struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Code    any    `json:"code"`
+}
+
+func (e *ApiError) Error() string {
+	return fmt.Sprintf("OpenAI API error (type: %s, code: %v): %s", e.Type, e.Code, e.Message)
+}
+
+// sendChatCompletionRequest sends the request to the chat completions endpoint and returns the content of the first choice.
+// Requests that fail with 429, a 5xx status code or a network error are retried with exponential backoff,
+// which is replaced by the duration of the Retry-After header if the response contains one.
+func (oar *OpenAiRepository) sendChatCompletionRequest(ctx context.Context, requestBody ChatCompletionRequest) (string, error) {
+	const op errors.Op = "open_ai_repo.OpenAiRepository.sendChatCompletionRequest"
+
+	jsonBody, err := json.Marshal(requestBody)
+	if err != nil {
+		return "", errors.E(op, err)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxRequestAttempts; attempt++ {
+		if attempt > 0 {
+			if err := sleep(ctx, backoff(attempt, lastErr)); err != nil {
+				return "", errors.E(op, err, http.StatusServiceUnavailable, errors.Messages{"message": "The text generation was canceled"})
+			}
+		}
+
+		content, err := oar.doChatCompletionRequest(ctx, jsonBody)
+		if err == nil {
+			return content, nil
+		}
+		if !isRetryable(err) || ctx.Err() != nil {
+			return "", wrapRequestError(op, err)
+		}
+
+		lastErr = err
+	}
+
+	return "", wrapRequestError(op, lastErr)
+}
+
+// wrapRequestError sets the status of errors of failed requests, so that exhausted rate limits are returned as
+// 503 Service Unavailable and all other failures of the API as 502 Bad Gateway
+func wrapRequestError(op errors.Op, err error) error {
+	var reqErr *requestError
+	if !errors.As(err, &reqErr) {
+		return errors.E(op, err)
+	}
+
+	if reqErr.status == http.StatusTooManyRequests {
+		return errors.E(op, err, http.StatusServiceUnavailable, errors.Messages{"message": "The text generation is currently busy, please try again later"})
+	}
+
+	return errors.E(op, err, http.StatusBadGateway, errors.Messages{"message": "The text could not be generated"})
+}
+
+func (oar *OpenAiRepository) doChatCompletionRequest(ctx context.Context, jsonBody []byte) (string, error) {
+	const op errors.Op = "open_ai_repo.OpenAiRepository.doChatCompletionRequest"
+
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, oar.baseUrl+"/chat/completions", bytes.NewReader(jsonBody))
+	if err != nil {
+		return "", errors.E(op, fmt.Errorf("error creating request to OpenAI: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+oar.apiKey)
+
+	resp, err := oar.httpClient.Do(req)
+	if err != nil {
+		return "", &requestError{err: fmt.Errorf("error sending request to OpenAI: %w", err), retryable: true}
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", &requestError{err: fmt.Errorf("error reading response body: %w", err), retryable: true}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", newResponseError(resp, responseBody)
+	}
+
+	var response ChatCompletionResponse
+	if err := json.Unmarshal(responseBody, &response); err != nil {
+		return "", errors.E(op, fmt.Errorf("error unmarshalling response body: %w", err), http.StatusBadGateway)
+	}
+
+	if len(response.Choices) == 0 {
+		return "", errors.E(op, fmt.Errorf("response contains no choices"), http.StatusBadGateway)
+	}
+
+	return response.Choices[0].Message.Content, nil
+}
+
+// requestError is returned for requests that did not succeed and holds whether the request can be retried
+// and the duration the API asked to wait before retrying
+type requestError struct {
+	err        error
+	status     int
+	retryable  bool
+	retryAfter time.Duration
+}
+
+func (e *requestError) Error() string {
+	if e.status != 0 {
+		return fmt.Sprintf("request to OpenAI failed with status %d: %s", e.status, e.err)
+	}
+
+	return e.err.Error()
+}
+
+func (e *requestError) Unwrap() error {
+	return e.err
+}
+
+// newResponseError parses the error payload of the response, which falls back to the raw body if it is not the expected JSON
+func newResponseError(resp *http.Response, responseBody []byte) *requestError {
+	var err error
+	var errorResponse ErrorResponse
+	if json.Unmarshal(responseBody, &errorResponse) == nil && errorResponse.Error != nil {
+		err = errorResponse.Error
+	} else {
+		err = fmt.Errorf("%s", bytes.TrimSpace(responseBody))
+	}
+
+	return &requestError{
+		err:        err,
+		status:     resp.StatusCode,
+		retryable:  resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError,
+		retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+}
+
+func isRetryable(err error) bool {
+	var reqErr *requestError
+	return errors.As(err, &reqErr) && reqErr.retryable
+}
+
+// parseRetryAfter supports both the delay in seconds and the HTTP date format of the Retry-After header
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+
+	return 0
+}
+
+// backoff returns the duration to wait before the given retry attempt, which is the Retry-After duration of the last error
+// or an exponential backoff with jitter
+func backoff(attempt int, lastErr error) time.Duration {
+	var reqErr *requestError
+	if errors.As(lastErr, &reqErr) && reqErr.retryAfter > 0 {
+		return minDuration(reqErr.retryAfter, maxBackoff)
+	}
+
+	d := baseBackoff << (attempt - 1)
+	d += time.Duration(rand.Int63n(int64(d) / 2))
+
+	return minDuration(d, maxBackoff)
+}
+
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
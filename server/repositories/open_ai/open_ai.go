@@ -2,101 +2,133 @@ package open_ai_repo
 
 import (
 	"10-typing/errors"
-	"bytes"
-	"encoding/json"
+	"10-typing/metrics"
+	"10-typing/textgen"
+	"context"
 	"fmt"
-	"io"
 	"net/http"
+	"strings"
+	"time"
 )
 
-type OpenAiRepository struct {
-	ApiKey string
-}
-
-func NewOpenAiRepository(apiKey string) *OpenAiRepository {
-	return &OpenAiRepository{apiKey}
-}
-
-type ChatCompletionRequest struct {
-	Model    string        `json:"model"`
-	Messages []ChatMessage `json:"messages"`
-}
+const (
+	DefaultBaseUrl     = "https://api.openai.com/v1"
+	DefaultModel       = "gpt-3.5-turbo"
+	DefaultWordsNumber = 100
+	// maxGenerationAttempts is the number of texts that are requested before giving up if the returned texts do not satisfy the requested constraints
+	maxGenerationAttempts = 3
+)
 
-type ChatMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+type OpenAiRepository struct {
+	apiKey      string
+	baseUrl     string
+	model       string
+	wordsNumber int
+	httpClient  *http.Client
 }
 
-type ChatCompletionResponse struct {
-	Choices []Choice `json:"choices"`
-}
+// NewOpenAiRepository returns a repository that sends requests to the OpenAI API or to any OpenAI compatible API at baseUrl.
+// Empty values of baseUrl and model and a wordsNumber of 0 are replaced by DefaultBaseUrl, DefaultModel and DefaultWordsNumber.
+func NewOpenAiRepository(apiKey, baseUrl, model string, wordsNumber int) *OpenAiRepository {
+	if baseUrl == "" {
+		baseUrl = DefaultBaseUrl
+	}
+	if model == "" {
+		model = DefaultModel
+	}
+	if wordsNumber == 0 {
+		wordsNumber = DefaultWordsNumber
+	}
 
-type Choice struct {
-	Message ChatMessage `json:"message"`
+	return &OpenAiRepository{
+		apiKey:      apiKey,
+		baseUrl:     strings.TrimSuffix(baseUrl, "/"),
+		model:       model,
+		wordsNumber: wordsNumber,
+		httpClient:  &http.Client{Timeout: requestTimeout},
+	}
 }
 
-const chatCompletionUrl = "https://api.openai.com/v1/chat/completions"
-
-// GenerateTypingText sends a request to the OpenAI API and returns the generated text
-func (oar *OpenAiRepository) GenerateTypingText(language string, punctuation bool, specialCharacters, numbers int) (string, error) {
+// GenerateTypingText sends a request to the OpenAI API and returns the generated text.
+// The text is requested again if it does not contain the requested punctuation, special characters and numbers.
+func (oar *OpenAiRepository) GenerateTypingText(ctx context.Context, language string, punctuation bool, specialCharacters, numbers int) (string, error) {
 	const op errors.Op = "open_ai_repo.OpenAiRepository.GenerateTypingText"
 	systemPrompt := "You return a text that will be used for practicing 10 finger typing. " +
 		"You will receive several input variables. The length of the text, if the text should include punctuation or" +
 		" not (if it includes punctuation then include punctuation and capital letters at the beginning of a new sentence, " +
 		"if not then all the letters should be lowercase), number of special characters and number of numbers that should be " +
-		"used in the text. Only return the text itself, not any explanation. The text doesn't have to make sense."
-	requestBody := oar.createRequestBody(language, systemPrompt, punctuation, specialCharacters, numbers)
-
-	jsonBody, err := json.Marshal(requestBody)
+		"used in the text. Only use these special characters: " + textgen.SpecialCharacters + ". " +
+		"Only return the text itself, not any explanation. The text doesn't have to make sense."
+	content := fmt.Sprintf("language: %s, punctuation: %t, number of special characters: %d, number of numbers: %d, length: %d words",
+		language, punctuation, specialCharacters, numbers, oar.wordsNumber)
+
+	text, err := oar.generateText(ctx, systemPrompt, content, func(text string) error {
+		return textgen.ValidateTypingText(text, punctuation, specialCharacters, numbers)
+	})
 	if err != nil {
 		return "", errors.E(op, err)
 	}
 
-	req, err := oar.createRequest(jsonBody)
-	if err != nil {
-		return "", errors.E(op, fmt.Errorf("error creating request to OpenAI: %w", err))
-	}
-
-	var client = &http.Client{}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", errors.E(op, fmt.Errorf("error sending request to OpenAI: %w", err))
-	}
-	defer resp.Body.Close()
-
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", errors.E(op, fmt.Errorf("error reading response body: %w", err))
-	}
+	return text, nil
+}
 
-	var response ChatCompletionResponse
-	err = json.Unmarshal(responseBody, &response)
+// GenerateDrillText sends a request to the OpenAI API and returns a generated text that contains many words with the target keys
+func (oar *OpenAiRepository) GenerateDrillText(ctx context.Context, language string, punctuation bool, targetKeys []string) (string, error) {
+	const op errors.Op = "open_ai_repo.OpenAiRepository.GenerateDrillText"
+	systemPrompt := "You return a text that will be used for practicing 10 finger typing. " +
+		"The user has problems with some keys, so the text should contain as many words as possible that include these keys. " +
+		"You will receive several input variables. The length of the text, if the text should include punctuation or" +
+		" not (if it includes punctuation then include punctuation and capital letters at the beginning of a new sentence, " +
+		"if not then all the letters should be lowercase) and the keys that should be practiced. " +
+		"Only return the text itself, not any explanation. The text doesn't have to make sense."
+	content := fmt.Sprintf("language: %s, punctuation: %t, keys to practice: %s, length: %d words",
+		language, punctuation, strings.Join(targetKeys, " "), oar.wordsNumber)
+
+	text, err := oar.generateText(ctx, systemPrompt, content, func(text string) error {
+		return textgen.ValidatePunctuation(text, punctuation)
+	})
 	if err != nil {
-		return "", errors.E(op, fmt.Errorf("error unmarshalling response body: %w", err))
+		return "", errors.E(op, err)
 	}
 
-	return response.Choices[0].Message.Content, nil
+	return text, nil
 }
 
-func (oar *OpenAiRepository) createRequestBody(language, systemPrompt string, punctuation bool, specialCharacters, numbers int) ChatCompletionRequest {
-	content := fmt.Sprintf("language: %s, punctuation: %t, number of special characters: %d, number of numbers: %d, length: 100 words", language, punctuation, specialCharacters, numbers)
-	return ChatCompletionRequest{
-		Model: "gpt-3.5-turbo",
+// generateText requests a text until validate accepts it or maxGenerationAttempts texts have been rejected.
+// The whitespace of the returned texts is normalized to single spaces.
+func (oar *OpenAiRepository) generateText(ctx context.Context, systemPrompt, content string, validate func(text string) error) (string, error) {
+	const op errors.Op = "open_ai_repo.OpenAiRepository.generateText"
+	requestBody := ChatCompletionRequest{
+		Model: oar.model,
 		Messages: []ChatMessage{
 			{Content: systemPrompt, Role: "system"}, {Content: content, Role: "user"},
 		},
 	}
-}
 
-func (oar *OpenAiRepository) createRequest(jsonBody []byte) (*http.Request, error) {
-	const op errors.Op = "open_ai_repo.OpenAiRepository.createRequest"
-	req, err := http.NewRequest("POST", chatCompletionUrl, bytes.NewReader(jsonBody))
-	if err != nil {
-		return nil, errors.E(op, err)
+	start := time.Now()
+	defer func() {
+		metrics.TextGenerationDuration.Observe(time.Since(start).Seconds())
+	}()
+
+	var validationErr error
+	for attempt := 0; attempt < maxGenerationAttempts; attempt++ {
+		text, err := oar.sendChatCompletionRequest(ctx, requestBody)
+		if err != nil {
+			metrics.TextGenerationErrors.WithLabelValues("request").Inc()
+			return "", errors.E(op, err)
+		}
+
+		text = strings.Join(strings.Fields(text), " ")
+		if validationErr = validate(text); validationErr == nil {
+			return text, nil
+		}
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+oar.ApiKey)
 
-	return req, nil
+	metrics.TextGenerationErrors.WithLabelValues("validation").Inc()
+
+	return "", errors.E(op,
+		fmt.Errorf("no valid text after %d attempts: %w", maxGenerationAttempts, validationErr),
+		http.StatusBadGateway,
+		errors.Messages{"message": "The generated text did not match the requested settings, please try again"},
+	)
 }
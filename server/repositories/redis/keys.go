@@ -1,6 +1,11 @@
 package redis_repo
 
-import "github.com/google/uuid"
+import (
+	"10-typing/models"
+	"strconv"
+
+	"github.com/google/uuid"
+)
 
 // -----ROOM ----
 
@@ -92,17 +97,23 @@ func getRoomSubscriberConnectionKey(roomId, userId uuid.UUID) string {
 // ---- USER ----
 
 const (
-	userUsernameField     = "username"
-	userPasswordHashField = "password_hash"
-	userFirstNameField    = "first_name"
-	userLastNameField     = "last_name"
-	userEmailField        = "email"
-	userIsVerifiedField   = "is_verified"
+	userUsernameField       = "username"
+	userPasswordHashField   = "password_hash"
+	userFirstNameField      = "first_name"
+	userLastNameField       = "last_name"
+	userEmailField          = "email"
+	userIsVerifiedField     = "is_verified"
+	userRatingField         = "rating"
+	userRatedGamesField     = "rated_games"
+	userKeyboardLayoutField = "keyboard_layout"
+	userRoleField           = "role"
+	userBannedAtField       = "banned_at"
 )
 
 // getUserKey returns a redis key: users:[userid]
 //
-// The key holds a HASH value: username, password_hash, first_name, last_name, email, is_verified
+// The key holds a HASH value: username, password_hash, first_name, last_name, email, is_verified, rating, rated_games, keyboard_layout, role, banned_at
+// banned_at is the unix time in milliseconds or empty if the user is not banned
 func getUserKey(userId uuid.UUID) string {
 	return "users:" + userId.String()
 }
@@ -111,6 +122,22 @@ func getUserEmailKey(email string) string {
 	return "user_emails:" + email
 }
 
+// ---- USER STATS ----
+
+// getUserStatsKey returns a redis key: users:[userid]:stats:[interval]
+//
+// The key holds the STRINGIFIED JSON representation of a models.UserStats value.
+func getUserStatsKey(userId uuid.UUID, interval models.StatsInterval) string {
+	return getUserKey(userId) + ":stats:" + string(interval)
+}
+
+// getUserKeyboardStatsKey returns a redis key: users:[userid]:stats:keyboard:[layout]
+//
+// The key holds the STRINGIFIED JSON representation of a models.KeyboardStats value.
+func getUserKeyboardStatsKey(userId uuid.UUID, layoutName string) string {
+	return getUserKey(userId) + ":stats:keyboard:" + layoutName
+}
+
 // ---- USER NOTIFICATIONS ----
 
 // getRoomSubscriberConnectionKey returns a redis key: users:[userid]:notifications
@@ -127,6 +154,48 @@ const (
 	textIdsKey = "text_ids"
 )
 
+// ---- MATCHMAKING ----
+
+const (
+	matchmakingTicketLanguageField     = "language"
+	matchmakingTicketPunctuationField  = "punctuation"
+	matchmakingTicketSkillBracketField = "skill_bracket"
+	matchmakingTicketCreatedAtField    = "created_at"
+)
+
+// getMatchmakingQueueKey returns a redis key: matchmaking:queues:[language]:[punctuation]:[skill_bracket]
+//
+// The key holds a SORTED SET value: score:time the user joined the queue in milliseconds, member:user id
+func getMatchmakingQueueKey(language string, punctuation bool, skillBracket string) string {
+	return "matchmaking:queues:" + language + ":" + strconv.FormatBool(punctuation) + ":" + skillBracket
+}
+
+// getMatchmakingTicketKey returns a redis key: matchmaking:tickets:[user_id]
+//
+// The key holds a HASH value with the fields: language, punctuation, skill_bracket, created_at
+func getMatchmakingTicketKey(userId uuid.UUID) string {
+	return "matchmaking:tickets:" + userId.String()
+}
+
+// ---- LEADERBOARD ----
+
+// getLeaderboardKey returns a redis key: leaderboards:[window]:[period]:[language|all]:[punctuation|all]
+//
+// The key holds a SORTED SET value: score:best words per minute of the user in the period, member:user id
+func getLeaderboardKey(filter models.LeaderboardFilter) string {
+	language := "all"
+	if filter.Language != "" {
+		language = filter.Language
+	}
+
+	punctuation := "all"
+	if filter.Punctuation != nil {
+		punctuation = strconv.FormatBool(*filter.Punctuation)
+	}
+
+	return "leaderboards:" + string(filter.Window) + ":" + filter.Period + ":" + language + ":" + punctuation
+}
+
 // ---- SESSION ----
 
 // getSessionKey returns a redis key: users:[tokenhash]
@@ -136,6 +205,13 @@ func getSessionKey(tokenHash string) string {
 	return "sessions:" + tokenHash
 }
 
+// getUserSessionsKey returns a redis key: user_sessions:[user_id]
+//
+// The key holds a SORTED SET value: score:time the session expires in milliseconds, member:token hash
+func getUserSessionsKey(userId uuid.UUID) string {
+	return "user_sessions:" + userId.String()
+}
+
 // ---- ROOM STREAM ----
 
 const (
@@ -150,3 +226,31 @@ const (
 func getRoomStreamKey(roomId uuid.UUID) string {
 	return getRoomKey(roomId) + ":stream"
 }
+
+// ---- RATE LIMIT ----
+
+const (
+	rateLimitTokensField    = "tokens"
+	rateLimitUpdatedAtField = "updated_at"
+)
+
+// getRateLimitKey returns a redis key: rate_limits:[key]
+//
+// The key holds a HASH value with the following fields: tokens, updated_at
+func getRateLimitKey(key string) string {
+	return "rate_limits:" + key
+}
+
+// getLoginFailuresKey returns a redis key: login_failures:[email]:[client ip]
+//
+// The key holds a STRING value: number of failed logins
+func getLoginFailuresKey(email, clientIp string) string {
+	return "login_failures:" + email + ":" + clientIp
+}
+
+// getEmailLoginFailuresKey returns a redis key: email_login_failures:[email]
+//
+// The key holds a STRING value: number of failed logins from all client ips
+func getEmailLoginFailuresKey(email string) string {
+	return "email_login_failures:" + email
+}
@@ -0,0 +1,111 @@
+package redis_repo
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/models"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// SetLeaderboardScore sets the words per minute of the user in the leaderboard if they are higher than the user's current best.
+// If expireAt is not the zero time, the leaderboard expires at that time.
+func (repo *RedisRepository) SetLeaderboardScore(ctx context.Context, tx common.Transaction, filter models.LeaderboardFilter, userId uuid.UUID, wordsPerMinute float64, expireAt time.Time) error {
+	const op errors.Op = "redis_repo.RedisRepository.SetLeaderboardScore"
+	var leaderboardKey = getLeaderboardKey(filter)
+
+	// PIPELINE start if no outer pipeline exists
+	cmd, innerTx := repo.beginPipelineIfNoOuterTransactionExists(tx)
+
+	cmd.ZAddGT(ctx, leaderboardKey, redis.Z{Score: wordsPerMinute, Member: userId.String()})
+	if !expireAt.IsZero() {
+		cmd.ExpireAt(ctx, leaderboardKey, expireAt)
+	}
+
+	// PIPELINE commit
+	if innerTx != nil {
+		if err := innerTx.Commit(ctx); err != nil {
+			return errors.E(op, err)
+		}
+	}
+
+	return nil
+}
+
+// GetLeaderboardEntries returns the entries of the leaderboard ordered by words per minute together with the total number of entries.
+// The usernames of the entries are not set.
+func (repo *RedisRepository) GetLeaderboardEntries(ctx context.Context, filter models.LeaderboardFilter, limit, offset int) ([]models.LeaderboardEntry, int64, error) {
+	const op errors.Op = "redis_repo.RedisRepository.GetLeaderboardEntries"
+	var leaderboardKey = getLeaderboardKey(filter)
+	var cmd redis.Cmdable = repo.redisClient
+
+	total, err := cmd.ZCard(ctx, leaderboardKey).Result()
+	if err != nil {
+		return nil, 0, errors.E(op, err)
+	}
+
+	r, err := cmd.ZRevRangeWithScores(ctx, leaderboardKey, int64(offset), int64(offset+limit-1)).Result()
+	if err != nil {
+		return nil, 0, errors.E(op, err)
+	}
+
+	entries := make([]models.LeaderboardEntry, 0, len(r))
+	for i, z := range r {
+		userId, err := uuid.Parse(z.Member.(string))
+		if err != nil {
+			return nil, 0, errors.E(op, err)
+		}
+
+		entries = append(entries, models.LeaderboardEntry{
+			Rank:           int64(offset+i) + 1,
+			UserId:         userId,
+			WordsPerMinute: z.Score,
+		})
+	}
+
+	return entries, total, nil
+}
+
+// GetLeaderboardEntry returns the entry of the user in the leaderboard. The username of the entry is not set.
+// It returns common.ErrNotFound if the user has no entry in the leaderboard.
+func (repo *RedisRepository) GetLeaderboardEntry(ctx context.Context, filter models.LeaderboardFilter, userId uuid.UUID) (*models.LeaderboardEntry, error) {
+	const op errors.Op = "redis_repo.RedisRepository.GetLeaderboardEntry"
+	var leaderboardKey = getLeaderboardKey(filter)
+	var cmd redis.Cmdable = repo.redisClient
+
+	rank, err := cmd.ZRevRank(ctx, leaderboardKey, userId.String()).Result()
+	switch {
+	case err == redis.Nil:
+		return nil, errors.E(op, common.ErrNotFound)
+	case err != nil:
+		return nil, errors.E(op, err)
+	}
+
+	wordsPerMinute, err := cmd.ZScore(ctx, leaderboardKey, userId.String()).Result()
+	switch {
+	case err == redis.Nil:
+		return nil, errors.E(op, common.ErrNotFound)
+	case err != nil:
+		return nil, errors.E(op, err)
+	}
+
+	return &models.LeaderboardEntry{
+		Rank:           rank + 1,
+		UserId:         userId,
+		WordsPerMinute: wordsPerMinute,
+	}, nil
+}
+
+func (repo *RedisRepository) DeleteAllLeaderboards(ctx context.Context) error {
+	const op errors.Op = "redis_repo.RedisRepository.DeleteAllLeaderboards"
+	var pattern = "leaderboards:*"
+
+	if err := deleteKeysByPattern(ctx, repo, pattern); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
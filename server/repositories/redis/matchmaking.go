@@ -0,0 +1,181 @@
+package redis_repo
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/models"
+	"10-typing/utils"
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// CreateMatchmakingTicket adds the ticket's user to the matchmaking queue that matches the ticket's preferences
+// and stores the ticket in the matchmaking:tickets:[user_id] key, which expires after models.MatchmakingTicketTTLSeconds.
+// Nothing is changed if the user already has a ticket, in which case created is false.
+// It does this by using a transaction with WATCH on the ticket key and starts the whole transaction again after the previous transaction was discarded.
+func (repo *RedisRepository) CreateMatchmakingTicket(ctx context.Context, ticket models.MatchmakingTicket) (created bool, err error) {
+	const op errors.Op = "redis_repo.RedisRepository.CreateMatchmakingTicket"
+	var matchmakingQueueKey = getMatchmakingQueueKey(ticket.Language, ticket.Punctuation, ticket.SkillBracket)
+	var matchmakingTicketKey = getMatchmakingTicketKey(ticket.UserId)
+
+	for i := 0; i < retries; i++ {
+		created = false
+		err := repo.redisClient.Watch(ctx, func(tx *redis.Tx) error {
+			exists, err := tx.Exists(ctx, matchmakingTicketKey).Result()
+			if err != nil || exists > 0 {
+				return err
+			}
+
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.ZAdd(ctx, matchmakingQueueKey, redis.Z{
+					Score:  float64(ticket.CreatedAt.UnixMilli()),
+					Member: ticket.UserId.String(),
+				})
+				pipe.HSet(ctx, matchmakingTicketKey, map[string]any{
+					matchmakingTicketLanguageField:     ticket.Language,
+					matchmakingTicketPunctuationField:  strconv.FormatBool(ticket.Punctuation),
+					matchmakingTicketSkillBracketField: ticket.SkillBracket,
+					matchmakingTicketCreatedAtField:    ticket.CreatedAt.UnixMilli(),
+				})
+				pipe.Expire(ctx, matchmakingTicketKey, models.MatchmakingTicketTTLSeconds*time.Second)
+
+				return nil
+			})
+			created = err == nil
+
+			return err
+		}, matchmakingTicketKey)
+		switch {
+		case err == redis.TxFailedErr:
+			continue
+		case err != nil:
+			return false, errors.E(op, err)
+		default:
+			return created, nil
+		}
+	}
+
+	return false, errors.E(op, redis.TxFailedErr)
+}
+
+func (repo *RedisRepository) GetMatchmakingTicket(ctx context.Context, userId uuid.UUID) (*models.MatchmakingTicket, error) {
+	const op errors.Op = "redis_repo.RedisRepository.GetMatchmakingTicket"
+	var matchmakingTicketKey = getMatchmakingTicketKey(userId)
+	var cmd redis.Cmdable = repo.redisClient
+
+	r, err := cmd.HGetAll(ctx, matchmakingTicketKey).Result()
+	switch {
+	case err != nil:
+		return nil, errors.E(op, err)
+	case len(r) == 0:
+		return nil, errors.E(op, common.ErrNotFound)
+	}
+
+	punctuation, err := strconv.ParseBool(r[matchmakingTicketPunctuationField])
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	createdAt, err := utils.StringToTime(r[matchmakingTicketCreatedAtField])
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return &models.MatchmakingTicket{
+		UserId:       userId,
+		Language:     r[matchmakingTicketLanguageField],
+		Punctuation:  punctuation,
+		SkillBracket: r[matchmakingTicketSkillBracketField],
+		CreatedAt:    createdAt,
+	}, nil
+}
+
+// DeleteMatchmakingTicket removes the user from the matchmaking queue and deletes the matchmaking:tickets:[user_id] key.
+func (repo *RedisRepository) DeleteMatchmakingTicket(ctx context.Context, ticket models.MatchmakingTicket) error {
+	const op errors.Op = "redis_repo.RedisRepository.DeleteMatchmakingTicket"
+	var matchmakingQueueKey = getMatchmakingQueueKey(ticket.Language, ticket.Punctuation, ticket.SkillBracket)
+	var matchmakingTicketKey = getMatchmakingTicketKey(ticket.UserId)
+
+	tx := repo.BeginTx()
+	cmd := repo.cmdable(tx)
+
+	cmd.ZRem(ctx, matchmakingQueueKey, ticket.UserId.String())
+	cmd.Del(ctx, matchmakingTicketKey)
+
+	if err := tx.Commit(ctx); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+// PopMatchmakingGroup removes the groupSize users that have been waiting the longest from the queue that the ticket belongs to
+// and deletes their tickets, which are returned. Users whose tickets have expired at the time now are dropped from the queue beforehand.
+// It returns common.ErrNotFound if the queue does not hold enough users.
+// It does this by using a transaction with WATCH on the queue key and starts the whole transaction again after the previous transaction was discarded.
+func (repo *RedisRepository) PopMatchmakingGroup(ctx context.Context, ticket models.MatchmakingTicket, groupSize int, now time.Time) ([]models.MatchmakingTicket, error) {
+	const op errors.Op = "redis_repo.RedisRepository.PopMatchmakingGroup"
+	var matchmakingQueueKey = getMatchmakingQueueKey(ticket.Language, ticket.Punctuation, ticket.SkillBracket)
+	var tickets []models.MatchmakingTicket
+
+	for i := 0; i < retries; i++ {
+		err := repo.redisClient.Watch(ctx, func(tx *redis.Tx) error {
+			expiredMilli := now.Add(-models.MatchmakingTicketTTLSeconds * time.Second).UnixMilli()
+			if err := tx.ZRemRangeByScore(ctx, matchmakingQueueKey, "0", strconv.FormatInt(expiredMilli, 10)).Err(); err != nil {
+				return err
+			}
+
+			r, err := tx.ZRangeWithScores(ctx, matchmakingQueueKey, 0, int64(groupSize-1)).Result()
+			if err != nil {
+				return err
+			}
+			if len(r) < groupSize {
+				return common.ErrNotFound
+			}
+
+			// the tickets of a queue only differ in their user and their creation time, which is the score of the user in the queue
+			tickets = make([]models.MatchmakingTicket, 0, len(r))
+			for _, z := range r {
+				userId, err := uuid.Parse(z.Member.(string))
+				if err != nil {
+					return err
+				}
+
+				tickets = append(tickets, models.MatchmakingTicket{
+					UserId:       userId,
+					Language:     ticket.Language,
+					Punctuation:  ticket.Punctuation,
+					SkillBracket: ticket.SkillBracket,
+					CreatedAt:    time.UnixMilli(int64(z.Score)),
+				})
+			}
+
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				for _, poppedTicket := range tickets {
+					pipe.ZRem(ctx, matchmakingQueueKey, poppedTicket.UserId.String())
+					pipe.Del(ctx, getMatchmakingTicketKey(poppedTicket.UserId))
+				}
+
+				return nil
+			})
+
+			return err
+		}, matchmakingQueueKey)
+		switch {
+		case err == redis.TxFailedErr:
+			continue
+		case errors.Is(err, common.ErrNotFound):
+			return nil, errors.E(op, common.ErrNotFound)
+		case err != nil:
+			return nil, errors.E(op, err)
+		default:
+			return tickets, nil
+		}
+	}
+
+	return nil, errors.E(op, common.ErrNotFound)
+}
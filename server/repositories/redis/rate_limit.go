@@ -0,0 +1,116 @@
+package redis_repo
+
+import (
+	"10-typing/errors"
+	"10-typing/models"
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// TakeRateLimitToken refills the token bucket of the key and takes a token if one is available.
+// It does this by using a transaction with WATCH on the bucket key and starts the whole transaction again after the previous transaction was discarded.
+// The bucket expires once it would be full again.
+func (repo *RedisRepository) TakeRateLimitToken(ctx context.Context, key string, limit models.RateLimit) (*models.RateLimitResult, error) {
+	const op errors.Op = "redis_repo.RedisRepository.TakeRateLimitToken"
+	var rateLimitKey = getRateLimitKey(key)
+	var result models.RateLimitResult
+
+	for i := 0; i < retries; i++ {
+		err := repo.redisClient.Watch(ctx, func(tx *redis.Tx) error {
+			r, err := tx.HMGet(ctx, rateLimitKey, rateLimitTokensField, rateLimitUpdatedAtField).Result()
+			if err != nil {
+				return err
+			}
+
+			var bucket models.TokenBucket
+			if tokensStr, ok := r[0].(string); ok {
+				updatedAtStr, _ := r[1].(string)
+				if bucket.Tokens, err = strconv.ParseFloat(tokensStr, 64); err != nil {
+					return err
+				}
+				updatedAtMilli, err := strconv.ParseInt(updatedAtStr, 10, 64)
+				if err != nil {
+					return err
+				}
+				bucket.UpdatedAt = time.UnixMilli(updatedAtMilli)
+			}
+
+			result = bucket.Take(limit, time.Now())
+
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.HSet(ctx, rateLimitKey, rateLimitTokensField, strconv.FormatFloat(bucket.Tokens, 'f', -1, 64), rateLimitUpdatedAtField, bucket.UpdatedAt.UnixMilli())
+				pipe.PExpire(ctx, rateLimitKey, limit.Per)
+
+				return nil
+			})
+
+			return err
+		}, rateLimitKey)
+		switch {
+		case err == redis.TxFailedErr:
+			continue
+		case err != nil:
+			return nil, errors.E(op, err)
+		default:
+			return &result, nil
+		}
+	}
+
+	return nil, errors.E(op, redis.TxFailedErr)
+}
+
+func (repo *RedisRepository) GetLoginFailures(ctx context.Context, email, clientIp string) (*models.LoginFailures, error) {
+	const op errors.Op = "redis_repo.RedisRepository.GetLoginFailures"
+
+	r, err := repo.redisClient.MGet(ctx, getLoginFailuresKey(email, clientIp), getEmailLoginFailuresKey(email)).Result()
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	// keys that don't exist are nil
+	var loginFailures [2]int
+	for i, v := range r {
+		if v == nil {
+			continue
+		}
+
+		loginFailures[i], err = strconv.Atoi(v.(string))
+		if err != nil {
+			return nil, errors.E(op, err)
+		}
+	}
+
+	return &models.LoginFailures{FromClientIp: loginFailures[0], FromAllClientIps: loginFailures[1]}, nil
+}
+
+// IncrementLoginFailures counts the failed logins in fixed windows: the keys are only created with an expiration by the first failed login,
+// the following failed logins increment them without changing their expiration.
+func (repo *RedisRepository) IncrementLoginFailures(ctx context.Context, email, clientIp string) (*models.LoginFailures, error) {
+	const op errors.Op = "redis_repo.RedisRepository.IncrementLoginFailures"
+	var loginFailuresKey = getLoginFailuresKey(email, clientIp)
+	var emailLoginFailuresKey = getEmailLoginFailuresKey(email)
+
+	pipe := repo.redisClient.TxPipeline()
+	pipe.SetNX(ctx, loginFailuresKey, 0, models.LoginLockoutDurationSec*time.Second)
+	incrCmd := pipe.Incr(ctx, loginFailuresKey)
+	pipe.SetNX(ctx, emailLoginFailuresKey, 0, models.LoginLockoutDurationSec*time.Second)
+	emailIncrCmd := pipe.Incr(ctx, emailLoginFailuresKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return &models.LoginFailures{FromClientIp: int(incrCmd.Val()), FromAllClientIps: int(emailIncrCmd.Val())}, nil
+}
+
+func (repo *RedisRepository) DeleteLoginFailures(ctx context.Context, email, clientIp string) error {
+	const op errors.Op = "redis_repo.RedisRepository.DeleteLoginFailures"
+
+	if err := repo.redisClient.Del(ctx, getLoginFailuresKey(email, clientIp)).Err(); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"10-typing/errors"
 	"context"
 
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -14,7 +15,13 @@ type RedisRepository struct {
 	redisClient *redis.Client
 }
 
+// NewRedisRepository returns the repository and adds the tracing hook to the client, which must therefore only be passed once.
+// Commands outside of a trace are not recorded, so that the blocking XREAD calls of the stream subscriptions do not start a trace every few seconds.
 func NewRedisRepository(redisClient *redis.Client) *RedisRepository {
+	if err := redisotel.InstrumentTracing(redisClient); err != nil {
+		panic("Failed to add the tracing hook!")
+	}
+
 	return &RedisRepository{redisClient}
 }
 
@@ -90,6 +97,16 @@ func (repo *RedisRepository) BeginTx() common.Transaction {
 	return &RedisTransaction{pipe: repo.redisClient.TxPipeline()}
 }
 
+func (repo *RedisRepository) Ping(ctx context.Context) error {
+	const op errors.Op = "redis_repo.RedisRepository.Ping"
+
+	if err := repo.redisClient.Ping(ctx).Err(); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
 func (repo *RedisRepository) cmdable(tx common.Transaction) redis.Cmdable {
 	if tx != nil {
 		return tx.Conn().(redis.Pipeliner)
@@ -98,6 +98,30 @@ func (repo *RedisRepository) SetRoom(ctx context.Context, tx common.Transaction,
 	return nil
 }
 
+func (repo *RedisRepository) SetRoomAdmin(ctx context.Context, tx common.Transaction, roomId, adminId uuid.UUID) error {
+	const op errors.Op = "redis_repo.RedisRepository.SetRoomAdmin"
+	var roomKey = getRoomKey(roomId)
+	var cmd = repo.cmdable(tx)
+
+	if err := cmd.HSet(ctx, roomKey, roomAdminIdField, adminId.String()).Err(); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+func (repo *RedisRepository) SetRoomGameDurationSec(ctx context.Context, tx common.Transaction, roomId uuid.UUID, gameDurationSec int) error {
+	const op errors.Op = "redis_repo.RedisRepository.SetRoomGameDurationSec"
+	var roomKey = getRoomKey(roomId)
+	var cmd = repo.cmdable(tx)
+
+	if err := cmd.HSet(ctx, roomKey, roomGameDurationSecField, gameDurationSec).Err(); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
 func (repo *RedisRepository) RoomHasAdmin(ctx context.Context, roomId, adminId uuid.UUID) (bool, error) {
 	const op errors.Op = "redis_repo.RedisRepository.RoomHasAdmin"
 	var roomKey = getRoomKey(roomId)
@@ -3,6 +3,7 @@ package redis_repo
 import (
 	"10-typing/common"
 	"10-typing/errors"
+	"10-typing/metrics"
 	"10-typing/models"
 	"context"
 	"encoding/json"
@@ -34,6 +35,10 @@ func (repo *RedisRepository) PublishPushMessage(ctx context.Context, tx common.T
 		return errors.E(op, err)
 	}
 
+	// the type is valid, since the push message could be marshalled
+	pushMessageType, _ := pushMessage.Type.String()
+	metrics.PushMessagesPublished.WithLabelValues(pushMessageType).Inc()
+
 	return nil
 }
 
@@ -59,10 +64,7 @@ func (repo *RedisRepository) GetPushMessages(ctx context.Context, roomId uuid.UU
 	const op errors.Op = "redis_repo.RedisRepository.GetPushMessages"
 	var roomStreamKey = getRoomStreamKey(roomId)
 
-	startId := ""
-	if (startTime != time.Time{}) {
-		startId = strconv.FormatInt(startTime.UnixMilli(), 10)
-	}
+	startId := getStreamStartId(startTime)
 
 	return getStreamEntry[[]byte](ctx, repo, roomStreamKey, startId, func(values map[string]interface{}, entryId string) ([]byte, error) {
 		streamEntryType, err := getStreamEntryTypeFromMap(values)
@@ -101,10 +103,7 @@ func (repo *RedisRepository) GetAction(ctx context.Context, roomId uuid.UUID, st
 	const op errors.Op = "redis_repo.RedisRepository.GetAction"
 	var roomStreamKey = getRoomStreamKey(roomId)
 
-	startId := ""
-	if (startTime != time.Time{}) {
-		startId = strconv.FormatInt(startTime.UnixMilli(), 10)
-	}
+	startId := getStreamStartId(startTime)
 
 	return getStreamEntry[models.StreamActionType](ctx, repo, roomStreamKey, startId, func(values map[string]interface{}, entryId string) (models.StreamActionType, error) {
 		streamEntryType, err := getStreamEntryTypeFromMap(values)
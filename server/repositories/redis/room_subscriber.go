@@ -135,6 +135,65 @@ func (repo *RedisRepository) GetRoomSubscribers(ctx context.Context, roomId uuid
 	return roomSubscribers, nil
 }
 
+// GetActiveRoomSubscribersNumber returns the number of room subscribers whose status is active.
+func (repo *RedisRepository) GetActiveRoomSubscribersNumber(ctx context.Context, roomId uuid.UUID) (int, error) {
+	const op errors.Op = "redis_repo.RedisRepository.GetActiveRoomSubscribersNumber"
+
+	roomSubscriberIds, err := repo.getRoomSubscribersIds(ctx, roomId)
+	if err != nil {
+		return 0, errors.E(op, err)
+	}
+	if len(roomSubscriberIds) == 0 {
+		return 0, nil
+	}
+
+	pipe := repo.redisClient.Pipeline()
+	statusCmds := make([]*redis.StringCmd, 0, len(roomSubscriberIds))
+	for _, roomSubscriberId := range roomSubscriberIds {
+		statusCmds = append(statusCmds, pipe.HGet(ctx, getRoomSubscriberKey(roomId, roomSubscriberId), roomSubscriberStatusField))
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return 0, errors.E(op, err)
+	}
+
+	activeRoomSubscribersNumber := 0
+	for _, statusCmd := range statusCmds {
+		if statusCmd.Val() == strconv.Itoa(int(models.ActiveSubscriberStatus)) {
+			activeRoomSubscribersNumber++
+		}
+	}
+
+	return activeRoomSubscribersNumber, nil
+}
+
+// SetRoomSubscriber adds the user to the rooms:[room_id]:subscribers_ids key's set value and creates the rooms:[room_id]:subscribers:[user_id] key
+// with an inactive status and an unstarted game status.
+func (repo *RedisRepository) SetRoomSubscriber(ctx context.Context, tx common.Transaction, roomId uuid.UUID, user models.User) error {
+	const op errors.Op = "redis_repo.RedisRepository.SetRoomSubscriber"
+	var roomSubscriberKey = getRoomSubscriberKey(roomId, user.ID)
+	var roomSubscriberIdsKey = getRoomSubscriberIdsKey(roomId)
+
+	// PIPELINE start if no outer pipeline exists
+	cmd, innerTx := repo.beginPipelineIfNoOuterTransactionExists(tx)
+
+	cmd.HSet(ctx, roomSubscriberKey, map[string]any{
+		roomSubscriberUsernameField:   user.Username,
+		roomSubscriberStatusField:     strconv.Itoa(int(models.InactiveSubscriberStatus)),
+		roomSubscriberGameStatusField: strconv.Itoa(int(models.UnstartedSubscriberGameStatus)),
+	})
+	cmd.SAdd(ctx, roomSubscriberIdsKey, user.ID.String())
+
+	// PIPELINE commit
+	if innerTx != nil {
+		if err := innerTx.Commit(ctx); err != nil {
+			return errors.E(op, err)
+		}
+	}
+
+	return nil
+}
+
 func (repo *RedisRepository) SetRoomSubscriberGameStatus(ctx context.Context, tx common.Transaction, roomId, userId uuid.UUID, status models.SubscriberGameStatus) error {
 	const op errors.Op = "redis_repo.RedisRepository.SetRoomSubscriberGameStatus"
 	var roomSubscriberKey = getRoomSubscriberKey(roomId, userId)
@@ -197,16 +256,17 @@ func (repo *RedisRepository) SetRoomSubscriberConnection(ctx context.Context, ro
 	return roomSubscriberStatusHasBeenUpdated, nil
 }
 
-// DeleteRoomSubscriber deletes the rooms:[room_id]:subscribers:[user_id] key and the user id from the rooms:[room_id]:subscribers_ids key's set value.
+// DeleteRoomSubscriber deletes the rooms:[room_id]:subscribers:[user_id] key, its connections key and the user id from the rooms:[room_id]:subscribers_ids key's set value.
 // It does so by using a MULTI/EXEC transaction.
 func (repo *RedisRepository) DeleteRoomSubscriber(ctx context.Context, roomId, userId uuid.UUID) error {
 	const op errors.Op = "redis_repo.RedisRepository.DeleteRoomSubscriber"
 	var roomSubscriberKey = getRoomSubscriberKey(roomId, userId)
+	var roomSubscriberConnectionKey = getRoomSubscriberConnectionKey(roomId, userId)
 	var roomSubscriberIdsKey = getRoomSubscriberIdsKey(roomId)
 
 	tx := repo.BeginTx()
 	cmd := repo.cmdable(tx)
-	err := cmd.Del(ctx, roomSubscriberKey).Err()
+	err := cmd.Del(ctx, roomSubscriberKey, roomSubscriberConnectionKey).Err()
 	if err != nil {
 		err := errors.E(op, err)
 		return utils.RollbackAndErr(op, err, tx)
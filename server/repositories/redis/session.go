@@ -5,18 +5,38 @@ import (
 	"10-typing/errors"
 	"10-typing/models"
 	"context"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 )
 
+// SetSession stores the session and adds its token hash to the sessions of the user, from which the sessions that have expired are removed,
+// so that all sessions of the user can be deleted.
 func (repo *RedisRepository) SetSession(ctx context.Context, tx common.Transaction, tokenHash string, userId uuid.UUID) error {
 	const op errors.Op = "redis_repo.RedisRepository.SetSession"
 	var sessionKey = getSessionKey(tokenHash)
-	var cmd = repo.cmdable(tx)
+	var userSessionsKey = getUserSessionsKey(userId)
+	now := time.Now()
+	expiresAt := now.Add(models.SessionDurationSec * time.Second)
 
-	if err := cmd.Set(ctx, sessionKey, userId.String(), models.SessionDurationSec*time.Second).Err(); err != nil {
-		return errors.E(op, err)
+	// PIPELINE start if no outer pipeline exists
+	cmd, innerTx := repo.beginPipelineIfNoOuterTransactionExists(tx)
+
+	cmd.Set(ctx, sessionKey, userId.String(), models.SessionDurationSec*time.Second)
+	cmd.ZRemRangeByScore(ctx, userSessionsKey, "0", strconv.FormatInt(now.UnixMilli(), 10))
+	cmd.ZAdd(ctx, userSessionsKey, redis.Z{
+		Score:  float64(expiresAt.UnixMilli()),
+		Member: tokenHash,
+	})
+	cmd.ExpireAt(ctx, userSessionsKey, expiresAt)
+
+	// PIPELINE commit
+	if innerTx != nil {
+		if err := innerTx.Commit(ctx); err != nil {
+			return errors.E(op, err)
+		}
 	}
 
 	return nil
@@ -34,6 +54,29 @@ func (repo *RedisRepository) DeleteSession(ctx context.Context, tx common.Transa
 	return nil
 }
 
+// DeleteUserSessions deletes all sessions of the user, e.g. when the user is banned.
+func (repo *RedisRepository) DeleteUserSessions(ctx context.Context, userId uuid.UUID) error {
+	const op errors.Op = "redis_repo.RedisRepository.DeleteUserSessions"
+	var userSessionsKey = getUserSessionsKey(userId)
+
+	tokenHashes, err := repo.redisClient.ZRange(ctx, userSessionsKey, 0, -1).Result()
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	keys := make([]string, 0, len(tokenHashes)+1)
+	for _, tokenHash := range tokenHashes {
+		keys = append(keys, getSessionKey(tokenHash))
+	}
+	keys = append(keys, userSessionsKey)
+
+	if err := repo.redisClient.Del(ctx, keys...).Err(); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
 func (repo *RedisRepository) DeleteAllSessions(ctx context.Context) error {
 	const op errors.Op = "redis_repo.RedisRepository.DeleteAllSessions"
 
@@ -41,5 +84,9 @@ func (repo *RedisRepository) DeleteAllSessions(ctx context.Context) error {
 		return errors.E(op, err)
 	}
 
+	if err := deleteKeysByPattern(ctx, repo, "user_sessions:*"); err != nil {
+		return errors.E(op, err)
+	}
+
 	return nil
 }
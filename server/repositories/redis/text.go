@@ -49,6 +49,22 @@ func (repo *RedisRepository) TextIdExists(ctx context.Context, textId uuid.UUID)
 	return r[0], nil
 }
 
+func (repo *RedisRepository) RemoveTextId(ctx context.Context, tx common.Transaction, textIds ...uuid.UUID) error {
+	const op errors.Op = "redis_repo.RedisRepository.RemoveTextId"
+	var cmd = repo.cmdable(tx)
+
+	textIdsStr := make([]any, 0, len(textIds))
+	for _, textId := range textIds {
+		textIdsStr = append(textIdsStr, textId.String())
+	}
+
+	if err := cmd.SRem(ctx, textIdsKey, textIdsStr...).Err(); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
 func (repo *RedisRepository) DeleteTextIdsKey(ctx context.Context, tx common.Transaction) error {
 	const op errors.Op = "redis_repo.RedisRepository.DeleteTextIdsKey"
 	var cmd = repo.cmdable(tx)
@@ -5,6 +5,8 @@ import (
 	"10-typing/errors"
 	"10-typing/models"
 	"context"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
@@ -116,18 +118,28 @@ func (repo *RedisRepository) SetUser(ctx context.Context, tx common.Transaction,
 	userEmailKey := getUserEmailKey(user.Email)
 	var userKey = getUserKey(user.ID)
 
+	var bannedAt string
+	if user.BannedAt != nil {
+		bannedAt = strconv.FormatInt(user.BannedAt.UnixMilli(), 10)
+	}
+
 	// PIPELINE start if no outer pipeline exists
 	cmd, innerTx := repo.beginPipelineIfNoOuterTransactionExists(tx)
 
 	cmd.Set(ctx, userEmailKey, user.ID.String(), 0)
 
 	cmd.HSet(ctx, userKey, map[string]any{
-		userUsernameField:     user.Username,
-		userPasswordHashField: user.PasswordHash,
-		userFirstNameField:    user.FirstName,
-		userLastNameField:     user.LastName,
-		userEmailField:        user.Email,
-		userIsVerifiedField:   user.IsVerified,
+		userUsernameField:       user.Username,
+		userPasswordHashField:   user.PasswordHash,
+		userFirstNameField:      user.FirstName,
+		userLastNameField:       user.LastName,
+		userEmailField:          user.Email,
+		userIsVerifiedField:     user.IsVerified,
+		userRatingField:         user.Rating,
+		userRatedGamesField:     user.RatedGames,
+		userKeyboardLayoutField: user.KeyboardLayout,
+		userRoleField:           string(user.Role),
+		userBannedAtField:       bannedAt,
 	})
 
 	// PIPELINE commit
@@ -152,6 +164,18 @@ func (repo *RedisRepository) VerifyUser(ctx context.Context, tx common.Transacti
 	return nil
 }
 
+func (repo *RedisRepository) SetUserRating(ctx context.Context, tx common.Transaction, userId uuid.UUID, rating float64, ratedGames int) error {
+	const op errors.Op = "redis_repo.RedisRepository.SetUserRating"
+	var userKey = getUserKey(userId)
+	var cmd = repo.cmdable(tx)
+
+	if err := cmd.HSet(ctx, userKey, userRatingField, rating, userRatedGamesField, ratedGames).Err(); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
 func (repo *RedisRepository) DeleteAllUsers(ctx context.Context) error {
 	const op errors.Op = "redis_repo.RedisRepository.DeleteAllUsers"
 
@@ -202,13 +226,49 @@ func (repo *RedisRepository) getUser(ctx context.Context, userId uuid.UUID) (*mo
 
 	isVerifiedStr := r[userIsVerifiedField]
 
+	// users that have been cached before ratings, keyboard layouts and roles existed are reloaded from the db
+	ratingStr, ok := r[userRatingField]
+	if !ok {
+		return nil, errors.E(op, common.ErrNotFound)
+	}
+	keyboardLayout, ok := r[userKeyboardLayoutField]
+	if !ok {
+		return nil, errors.E(op, common.ErrNotFound)
+	}
+	role, ok := r[userRoleField]
+	if !ok {
+		return nil, errors.E(op, common.ErrNotFound)
+	}
+	rating, err := strconv.ParseFloat(ratingStr, 64)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+	ratedGames, err := strconv.Atoi(r[userRatedGamesField])
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+	var bannedAt *time.Time
+	if bannedAtStr := r[userBannedAtField]; bannedAtStr != "" {
+		bannedAtMilli, err := strconv.ParseInt(bannedAtStr, 10, 64)
+		if err != nil {
+			return nil, errors.E(op, err)
+		}
+		t := time.UnixMilli(bannedAtMilli)
+		bannedAt = &t
+	}
+
 	return &models.User{
-		ID:           userId,
-		Username:     r[userUsernameField],
-		PasswordHash: r[userPasswordHashField],
-		FirstName:    r[userFirstNameField],
-		LastName:     r[userLastNameField],
-		Email:        r[userEmailField],
-		IsVerified:   isVerifiedStr == "1",
+		ID:             userId,
+		Username:       r[userUsernameField],
+		PasswordHash:   r[userPasswordHashField],
+		FirstName:      r[userFirstNameField],
+		LastName:       r[userLastNameField],
+		Email:          r[userEmailField],
+		IsVerified:     isVerifiedStr == "1",
+		Rating:         rating,
+		RatedGames:     ratedGames,
+		KeyboardLayout: keyboardLayout,
+		Role:           models.UserRole(role),
+		BannedAt:       bannedAt,
 	}, nil
 }
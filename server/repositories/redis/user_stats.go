@@ -0,0 +1,109 @@
+package redis_repo
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/keyboard"
+	"10-typing/models"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+func (repo *RedisRepository) GetUserStats(ctx context.Context, userId uuid.UUID, interval models.StatsInterval) (*models.UserStats, error) {
+	const op errors.Op = "redis_repo.RedisRepository.GetUserStats"
+	var userStatsKey = getUserStatsKey(userId, interval)
+	var cmd redis.Cmdable = repo.redisClient
+
+	userStatsStr, err := cmd.Get(ctx, userStatsKey).Result()
+	switch {
+	case err == redis.Nil:
+		return nil, errors.E(op, common.ErrNotFound)
+	case err != nil:
+		return nil, errors.E(op, err)
+	}
+
+	var userStats models.UserStats
+	if err := json.Unmarshal([]byte(userStatsStr), &userStats); err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return &userStats, nil
+}
+
+// SetUserStats caches the statistics of the user for models.UserStatsTTLSeconds
+func (repo *RedisRepository) SetUserStats(ctx context.Context, tx common.Transaction, userId uuid.UUID, userStats models.UserStats) error {
+	const op errors.Op = "redis_repo.RedisRepository.SetUserStats"
+	var userStatsKey = getUserStatsKey(userId, userStats.Interval)
+	var cmd = repo.cmdable(tx)
+
+	userStatsJson, err := json.Marshal(&userStats)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	if err := cmd.Set(ctx, userStatsKey, userStatsJson, models.UserStatsTTLSeconds*time.Second).Err(); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+func (repo *RedisRepository) GetUserKeyboardStats(ctx context.Context, userId uuid.UUID, layoutName string) (*models.KeyboardStats, error) {
+	const op errors.Op = "redis_repo.RedisRepository.GetUserKeyboardStats"
+	var userKeyboardStatsKey = getUserKeyboardStatsKey(userId, layoutName)
+	var cmd redis.Cmdable = repo.redisClient
+
+	keyboardStatsStr, err := cmd.Get(ctx, userKeyboardStatsKey).Result()
+	switch {
+	case err == redis.Nil:
+		return nil, errors.E(op, common.ErrNotFound)
+	case err != nil:
+		return nil, errors.E(op, err)
+	}
+
+	var keyboardStats models.KeyboardStats
+	if err := json.Unmarshal([]byte(keyboardStatsStr), &keyboardStats); err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return &keyboardStats, nil
+}
+
+// SetUserKeyboardStats caches the keyboard statistics of the user for models.UserStatsTTLSeconds
+func (repo *RedisRepository) SetUserKeyboardStats(ctx context.Context, tx common.Transaction, userId uuid.UUID, keyboardStats models.KeyboardStats) error {
+	const op errors.Op = "redis_repo.RedisRepository.SetUserKeyboardStats"
+	var userKeyboardStatsKey = getUserKeyboardStatsKey(userId, keyboardStats.KeyboardLayout)
+	var cmd = repo.cmdable(tx)
+
+	keyboardStatsJson, err := json.Marshal(&keyboardStats)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	if err := cmd.Set(ctx, userKeyboardStatsKey, keyboardStatsJson, models.UserStatsTTLSeconds*time.Second).Err(); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+// DeleteUserStats deletes the cached statistics of the user for all intervals and the keyboard statistics for all layouts
+func (repo *RedisRepository) DeleteUserStats(ctx context.Context, tx common.Transaction, userId uuid.UUID) error {
+	const op errors.Op = "redis_repo.RedisRepository.DeleteUserStats"
+	var cmd = repo.cmdable(tx)
+
+	userStatsKeys := []string{getUserStatsKey(userId, models.DayStatsInterval), getUserStatsKey(userId, models.WeekStatsInterval)}
+	for _, layoutName := range keyboard.LayoutNames {
+		userStatsKeys = append(userStatsKeys, getUserKeyboardStatsKey(userId, layoutName))
+	}
+
+	if err := cmd.Del(ctx, userStatsKeys...).Err(); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
@@ -2,10 +2,13 @@ package redis_repo
 
 import (
 	"10-typing/errors"
+	"10-typing/metrics"
 	"10-typing/models"
 	"context"
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,6 +22,17 @@ var (
 	errIsIgnoredStreamEntry            = errors.New("stream entry is ignored")
 )
 
+// getStreamStartId returns the id of the last possible entry before the millisecond of the start time.
+// Reading after this id returns all entries that have been added since the start time, including the first entry of the same millisecond, whose sequence number is 0.
+// Without start time an empty id is returned, so that only new entries are read.
+func getStreamStartId(startTime time.Time) string {
+	if (startTime == time.Time{}) {
+		return ""
+	}
+
+	return strconv.FormatInt(startTime.UnixMilli()-1, 10) + "-" + strconv.FormatUint(math.MaxUint64, 10)
+}
+
 func getStreamEntry[T []byte | models.StreamActionType | *models.UserNotification](
 	ctx context.Context,
 	repo *RedisRepository,
@@ -38,6 +52,8 @@ func getStreamEntry[T []byte | models.StreamActionType | *models.UserNotificatio
 		if startId != "" {
 			id = startId
 		}
+		// the entries before the subscription are replayed from the start id, so their lag is the age of the entry and not the delay of the delivery
+		subscribedAt := time.Now()
 
 		for {
 			select {
@@ -59,6 +75,7 @@ func getStreamEntry[T []byte | models.StreamActionType | *models.UserNotificatio
 
 				id = r[0].Messages[0].ID
 				values := r[0].Messages[0].Values
+				observeStreamReadLag(streamKey, id, subscribedAt)
 
 				v, err := processStreamEntry(values, id)
 				switch {
@@ -87,6 +104,21 @@ func getStreamEntry[T []byte | models.StreamActionType | *models.UserNotificatio
 	return out
 }
 
+// observeStreamReadLag records the duration since the entry has been added to the stream, which is the time of its id.
+// Entries that have been added before the millisecond of the subscription are not recorded.
+func observeStreamReadLag(streamKey, entryId string, subscribedAt time.Time) {
+	msStr, _, _ := strings.Cut(entryId, "-")
+	ms, err := strconv.ParseInt(msStr, 10, 64)
+	if err != nil || ms < subscribedAt.UnixMilli() {
+		return
+	}
+
+	// the first part of the key is used as label, e.g. rooms or users, because the ids would create a series for every stream
+	stream, _, _ := strings.Cut(streamKey, ":")
+	lag := time.Since(time.UnixMilli(ms))
+	metrics.StreamReadLag.WithLabelValues(stream).Observe(lag.Seconds())
+}
+
 func sendErrorResult[T []byte | models.StreamActionType | *models.UserNotification](ctx context.Context, outCh chan<- models.StreamSubscriptionResult[T], err error) {
 	result := models.StreamSubscriptionResult[T]{
 		Error: err,
@@ -0,0 +1,66 @@
+package sql_repo
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/models"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UpdateRatings sets the rating and the number of rated games of the users and adds the rating changes to the rating history
+func (repo *SQLRepository) UpdateRatings(ctx context.Context, tx common.Transaction, users []models.User, ratingChanges []models.RatingChange) error {
+	const op errors.Op = "sql_repo.SQLRepository.UpdateRatings"
+	db := repo.dbConn(tx)
+
+	for _, user := range users {
+		if err := db.WithContext(ctx).Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]any{
+			"rating":      user.Rating,
+			"rated_games": user.RatedGames,
+		}).Error; err != nil {
+			return errors.E(op, err)
+		}
+	}
+
+	if len(ratingChanges) > 0 {
+		if err := db.WithContext(ctx).Create(&ratingChanges).Error; err != nil {
+			return errors.E(op, err)
+		}
+	}
+
+	return nil
+}
+
+// FindRatingLeaderboard returns the users that have played at least one rated game ordered by their rating
+func (repo *SQLRepository) FindRatingLeaderboard(ctx context.Context, tx common.Transaction, limit, offset int) ([]models.RatingLeaderboardEntry, int64, error) {
+	const op errors.Op = "sql_repo.SQLRepository.FindRatingLeaderboard"
+	db := repo.dbConn(tx)
+	var entries []models.RatingLeaderboardEntry
+	var total int64
+
+	leaderboardDbQuery := db.WithContext(ctx).Model(&models.User{}).Where("rated_games > 0").Session(&gorm.Session{})
+
+	if err := leaderboardDbQuery.Count(&total).Error; err != nil {
+		return nil, 0, errors.E(op, err)
+	}
+
+	if err := leaderboardDbQuery.Select("id, username, rating").Order("rating desc").Order("username asc").Limit(limit).Offset(offset).Find(&entries).Error; err != nil {
+		return nil, 0, errors.E(op, err)
+	}
+
+	return entries, total, nil
+}
+
+func (repo *SQLRepository) FindRatingChanges(ctx context.Context, tx common.Transaction, userId uuid.UUID, limit int) ([]models.RatingChange, error) {
+	const op errors.Op = "sql_repo.SQLRepository.FindRatingChanges"
+	db := repo.dbConn(tx)
+	var ratingChanges []models.RatingChange
+
+	if err := db.WithContext(ctx).Where("user_id = ?", userId).Order("created_at desc").Limit(limit).Find(&ratingChanges).Error; err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return ratingChanges, nil
+}
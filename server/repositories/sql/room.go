@@ -5,19 +5,21 @@ import (
 	"10-typing/errors"
 	"10-typing/models"
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func (repo *SQLRepository) FindRoomWithUsers(ctx context.Context, tx common.Transaction, roomId uuid.UUID) (*models.Room, error) {
-	const op errors.Op = "sql_repo.SQLRepository.FindRoomByUser"
+	const op errors.Op = "sql_repo.SQLRepository.FindRoomWithUsers"
 	db := repo.dbConn(tx)
 
 	var room = models.Room{
 		ID: roomId,
 	}
-	if err := db.WithContext(ctx).First(&room).Error; err != nil {
+	if err := db.WithContext(ctx).Preload("Users").First(&room).Error; err != nil {
 		switch {
 		case errors.Is(err, gorm.ErrRecordNotFound):
 			return nil, errors.E(op, common.ErrNotFound)
@@ -29,6 +31,44 @@ func (repo *SQLRepository) FindRoomWithUsers(ctx context.Context, tx common.Tran
 	return &room, nil
 }
 
+// FindRoomsByUser returns an overview of every room the user is a member of. ActiveSubscriberCount is not set since it is only known by the cache.
+func (repo *SQLRepository) FindRoomsByUser(ctx context.Context, tx common.Transaction, userId uuid.UUID, sortOptions []models.SortOption, limit, offset int) ([]models.RoomOverview, int64, error) {
+	const op errors.Op = "sql_repo.SQLRepository.FindRoomsByUser"
+	db := repo.dbConn(tx)
+	var roomOverviews []models.RoomOverview
+	var total int64
+
+	findRoomsDbQuery := db.WithContext(ctx).
+		Table("rooms").
+		Joins("INNER JOIN user_rooms ON user_rooms.room_id = rooms.id AND user_rooms.user_id = ?", userId).
+		Where("rooms.deleted_at IS NULL").
+		Session(&gorm.Session{})
+
+	if err := findRoomsDbQuery.Count(&total).Error; err != nil {
+		return nil, 0, errors.E(op, err)
+	}
+
+	findRoomsDbQuery = findRoomsDbQuery.
+		Select("rooms.id, rooms.created_at, rooms.updated_at, rooms.admin_id, rooms.game_duration_sec, " +
+			"admins.username AS admin_username, " +
+			"rooms.last_game_at, " +
+			"(SELECT COUNT(*) FROM user_rooms members WHERE members.room_id = rooms.id) AS member_count").
+		Joins("INNER JOIN users admins ON admins.id = rooms.admin_id")
+
+	for _, sortOption := range sortOptions {
+		findRoomsDbQuery = findRoomsDbQuery.Order(clause.OrderByColumn{Column: clause.Column{Name: sortOption.Column}, Desc: sortOption.Order == "desc"})
+	}
+	if len(sortOptions) == 0 {
+		findRoomsDbQuery = findRoomsDbQuery.Order("rooms.created_at desc")
+	}
+
+	if err := findRoomsDbQuery.Limit(limit).Offset(offset).Scan(&roomOverviews).Error; err != nil {
+		return nil, 0, errors.E(op, err)
+	}
+
+	return roomOverviews, total, nil
+}
+
 func (repo *SQLRepository) FindRoom(ctx context.Context, tx common.Transaction, roomId uuid.UUID) (*models.Room, error) {
 	const op errors.Op = "sql_repo.SQLRepository.FindRoom"
 	db := repo.dbConn(tx)
@@ -59,6 +99,51 @@ func (repo *SQLRepository) CreateRoom(ctx context.Context, tx common.Transaction
 	return &newRoom, nil
 }
 
+func (repo *SQLRepository) UpdateRoomAdmin(ctx context.Context, tx common.Transaction, roomId, adminId uuid.UUID) error {
+	const op errors.Op = "sql_repo.SQLRepository.UpdateRoomAdmin"
+	db := repo.dbConn(tx)
+
+	result := db.WithContext(ctx).Model(&models.Room{}).Where("id = ?", roomId).Update("admin_id", adminId)
+	switch {
+	case result.Error != nil:
+		return errors.E(op, result.Error)
+	case result.RowsAffected == 0:
+		return errors.E(op, common.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (repo *SQLRepository) UpdateRoomGameDurationSec(ctx context.Context, tx common.Transaction, roomId uuid.UUID, gameDurationSec int) error {
+	const op errors.Op = "sql_repo.SQLRepository.UpdateRoomGameDurationSec"
+	db := repo.dbConn(tx)
+
+	result := db.WithContext(ctx).Model(&models.Room{}).Where("id = ?", roomId).Update("game_duration_sec", gameDurationSec)
+	switch {
+	case result.Error != nil:
+		return errors.E(op, result.Error)
+	case result.RowsAffected == 0:
+		return errors.E(op, common.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (repo *SQLRepository) UpdateRoomLastGameAt(ctx context.Context, tx common.Transaction, roomId uuid.UUID, lastGameAt time.Time) error {
+	const op errors.Op = "sql_repo.SQLRepository.UpdateRoomLastGameAt"
+	db := repo.dbConn(tx)
+
+	result := db.WithContext(ctx).Model(&models.Room{}).Where("id = ?", roomId).UpdateColumn("last_game_at", lastGameAt)
+	switch {
+	case result.Error != nil:
+		return errors.E(op, result.Error)
+	case result.RowsAffected == 0:
+		return errors.E(op, common.ErrNotFound)
+	}
+
+	return nil
+}
+
 func (repo *SQLRepository) SoftDeleteRoom(ctx context.Context, tx common.Transaction, roomId uuid.UUID) error {
 	const op errors.Op = "sql_repo.SQLRepository.SoftDeleteRoom"
 	db := repo.dbConn(tx)
@@ -5,44 +5,125 @@ import (
 	"10-typing/errors"
 	"10-typing/models"
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm/clause"
 )
 
-func (repo *SQLRepository) FindScores(ctx context.Context, tx common.Transaction, userId, gameId uuid.UUID, username string, sortOptions []models.SortOption) ([]models.Score, error) {
+// FindScores returns up to limit scores that match the filter, sorted by the sort option and the score id.
+// If a cursor is given, the scores after (next) or before (prev) the score the cursor points to are returned.
+// The scores are always returned in the order of the sort option.
+func (repo *SQLRepository) FindScores(
+	ctx context.Context,
+	tx common.Transaction,
+	filter models.ScoreFilter,
+	sortOption models.SortOption,
+	cursor *models.ScoreCursor,
+	limit int,
+) ([]models.Score, error) {
 	const op errors.Op = "sql_repo.SQLRepository.FindScores"
 	db := repo.dbConn(tx)
 	var scores []models.Score
 
 	findScoresDbQuery := db.WithContext(ctx)
-	if userId != uuid.Nil {
-		findScoresDbQuery = findScoresDbQuery.Where("user_id = ?", userId)
+	if filter.UserId != uuid.Nil {
+		findScoresDbQuery = findScoresDbQuery.Where("scores.user_id = ?", filter.UserId)
 	}
-	if gameId != uuid.Nil {
-		findScoresDbQuery = findScoresDbQuery.Where("game_id = ?", gameId)
+	if filter.GameId != uuid.Nil {
+		findScoresDbQuery = findScoresDbQuery.Where("scores.game_id = ?", filter.GameId)
 	}
-	if username != "" {
+	if filter.Username != "" {
 		findScoresDbQuery = findScoresDbQuery.Joins("INNER JOIN users ON scores.user_id = users.id").
-			Where("users.username = ?", username)
+			Where("users.username = ?", filter.Username)
+	}
+	if filter.From != nil {
+		findScoresDbQuery = findScoresDbQuery.Where("scores.created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		findScoresDbQuery = findScoresDbQuery.Where("scores.created_at < ?", *filter.To)
+	}
+	if filter.Language != "" || filter.Punctuation != nil {
+		findScoresDbQuery = findScoresDbQuery.Joins("INNER JOIN texts ON scores.text_id = texts.id")
+	}
+	if filter.Language != "" {
+		findScoresDbQuery = findScoresDbQuery.Where("texts.language = ?", filter.Language)
+	}
+	if filter.Punctuation != nil {
+		findScoresDbQuery = findScoresDbQuery.Where("texts.punctuation = ?", *filter.Punctuation)
+	}
+	if filter.MinWordsPerMinute != nil {
+		findScoresDbQuery = findScoresDbQuery.Where("scores.words_per_minute >= ?", *filter.MinWordsPerMinute)
+	}
+	if filter.MaxWordsPerMinute != nil {
+		findScoresDbQuery = findScoresDbQuery.Where("scores.words_per_minute <= ?", *filter.MaxWordsPerMinute)
+	}
+	if filter.MinAccuracy != nil {
+		findScoresDbQuery = findScoresDbQuery.Where("scores.accuracy >= ?", *filter.MinAccuracy)
+	}
+	if filter.MaxAccuracy != nil {
+		findScoresDbQuery = findScoresDbQuery.Where("scores.accuracy <= ?", *filter.MaxAccuracy)
 	}
 
-	for _, sortOption := range sortOptions {
-		findScoresDbQuery = findScoresDbQuery.Order(clause.OrderByColumn{Column: clause.Column{Name: sortOption.Column}, Desc: sortOption.Order == "desc"})
+	// a previous page is searched in reverse order and reversed afterwards
+	desc := sortOption.Order == "desc"
+	isPrevPage := cursor != nil && cursor.Direction == models.PrevCursorDirection
+	if isPrevPage {
+		desc = !desc
 	}
-	if len(sortOptions) == 0 {
-		findScoresDbQuery = findScoresDbQuery.Order("created_at desc")
+
+	if cursor != nil {
+		comparator := ">"
+		if desc {
+			comparator = "<"
+		}
+		findScoresDbQuery = findScoresDbQuery.Where(
+			"(scores."+sortOption.Column+", scores.id) "+comparator+" (?, ?)",
+			cursor.Value(), cursor.Id,
+		)
 	}
 
-	findScoresDbQuery.Find(&scores)
+	findScoresDbQuery = findScoresDbQuery.
+		Order(clause.OrderByColumn{Column: clause.Column{Table: "scores", Name: sortOption.Column}, Desc: desc}).
+		Order(clause.OrderByColumn{Column: clause.Column{Table: "scores", Name: "id"}, Desc: desc}).
+		Limit(limit)
 
-	if findScoresDbQuery.Error != nil {
-		return nil, errors.E(op, findScoresDbQuery.Error)
+	if err := findScoresDbQuery.Find(&scores).Error; err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	if isPrevPage {
+		for i, j := 0, len(scores)-1; i < j; i, j = i+1, j-1 {
+			scores[i], scores[j] = scores[j], scores[i]
+		}
 	}
 
 	return scores, nil
 }
 
+// FindLeaderboardScores returns the scores that have been created since the given time together with the language and punctuation setting of their texts,
+// ordered by their creation time.
+func (repo *SQLRepository) FindLeaderboardScores(ctx context.Context, tx common.Transaction, since time.Time, limit, offset int) ([]models.LeaderboardScore, error) {
+	const op errors.Op = "sql_repo.SQLRepository.FindLeaderboardScores"
+	db := repo.dbConn(tx)
+	var leaderboardScores []models.LeaderboardScore
+
+	if err := db.WithContext(ctx).
+		Model(&models.Score{}).
+		Select("scores.user_id, scores.words_per_minute, texts.language, texts.punctuation, scores.created_at").
+		Joins("INNER JOIN texts ON scores.text_id = texts.id").
+		Where("scores.created_at >= ?", since).
+		Order("scores.created_at asc").
+		Order("scores.id asc").
+		Limit(limit).
+		Offset(offset).
+		Scan(&leaderboardScores).Error; err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return leaderboardScores, nil
+}
+
 func (repo *SQLRepository) CreateScore(ctx context.Context, tx common.Transaction, score models.Score) (*models.Score, error) {
 	const op errors.Op = "sql_repo.SQLRepository.CreateScore"
 	db := repo.dbConn(tx)
@@ -6,6 +6,7 @@ import (
 	"context"
 
 	"gorm.io/gorm"
+	otelgorm "gorm.io/plugin/opentelemetry/tracing"
 )
 
 type SQLTransaction struct {
@@ -40,7 +41,13 @@ type SQLRepository struct {
 	db *gorm.DB
 }
 
+// NewSQLRepository returns the repository and registers the callbacks that trace the statements of the db
 func NewSQLRepository(db *gorm.DB) *SQLRepository {
+	// the pool statistics are not reported, because the metrics are recorded with prometheus
+	if err := db.Use(otelgorm.NewPlugin(otelgorm.WithoutMetrics())); err != nil && !errors.Is(err, gorm.ErrRegistered) {
+		panic("Failed to register tracing callbacks!")
+	}
+
 	return &SQLRepository{db}
 }
 
@@ -49,6 +56,21 @@ func (sr *SQLRepository) BeginTx() common.Transaction {
 	return &SQLTransaction{tx}
 }
 
+func (sr *SQLRepository) Ping(ctx context.Context) error {
+	const op errors.Op = "sql_repo.SQLRepository.Ping"
+
+	sqlDB, err := sr.db.DB()
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
 func (sr *SQLRepository) dbConn(tx common.Transaction) *gorm.DB {
 	if tx != nil {
 		return tx.Conn().(*gorm.DB)
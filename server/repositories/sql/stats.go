@@ -0,0 +1,182 @@
+package sql_repo
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/models"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const scoreTotalsSelect = "COUNT(*) AS scores, " +
+	"COALESCE(SUM(scores.words_typed), 0) AS words_typed, " +
+	"COALESCE(SUM(scores.time_elapsed), 0) AS time_typed_sec, " +
+	"COALESCE(AVG(scores.words_per_minute), 0) AS avg_words_per_minute, " +
+	"COALESCE(MAX(scores.words_per_minute), 0) AS best_words_per_minute, " +
+	"COALESCE(AVG(scores.accuracy), 0) AS avg_accuracy"
+
+func (repo *SQLRepository) FindScoreTotals(ctx context.Context, tx common.Transaction, userId uuid.UUID) (*models.ScoreTotals, error) {
+	const op errors.Op = "sql_repo.SQLRepository.FindScoreTotals"
+	db := repo.dbConn(tx)
+	var scoreTotals models.ScoreTotals
+
+	if err := db.WithContext(ctx).
+		Model(&models.Score{}).
+		Select(scoreTotalsSelect).
+		Where("scores.user_id = ?", userId).
+		Scan(&scoreTotals).Error; err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return &scoreTotals, nil
+}
+
+// FindScoreTrend aggregates the scores of the user that have been created since the given time into buckets of a day or a week (UTC).
+func (repo *SQLRepository) FindScoreTrend(ctx context.Context, tx common.Transaction, userId uuid.UUID, interval models.StatsInterval, since time.Time) ([]models.ScoreTrendBucket, error) {
+	const op errors.Op = "sql_repo.SQLRepository.FindScoreTrend"
+	db := repo.dbConn(tx)
+	var scoreTrendBuckets []models.ScoreTrendBucket
+
+	if err := db.WithContext(ctx).
+		Model(&models.Score{}).
+		Select("date_trunc(?, scores.created_at AT TIME ZONE 'UTC') AS start, "+
+			"COUNT(*) AS scores, "+
+			"AVG(scores.words_per_minute) AS avg_words_per_minute, "+
+			"MAX(scores.words_per_minute) AS best_words_per_minute, "+
+			"AVG(scores.accuracy) AS avg_accuracy", string(interval)).
+		Where("scores.user_id = ?", userId).
+		Where("scores.created_at >= ?", since).
+		Group("start").
+		Order("start asc").
+		Scan(&scoreTrendBuckets).Error; err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return scoreTrendBuckets, nil
+}
+
+// FindKeyErrors sums up the errors per key of all scores of the user, ordered by the number of errors.
+func (repo *SQLRepository) FindKeyErrors(ctx context.Context, tx common.Transaction, userId uuid.UUID) ([]models.KeyErrorCount, error) {
+	const op errors.Op = "sql_repo.SQLRepository.FindKeyErrors"
+	db := repo.dbConn(tx)
+	var keyErrorCounts []models.KeyErrorCount
+
+	if err := db.WithContext(ctx).
+		Model(&models.Score{}).
+		Select("key_errors.key AS key, SUM(key_errors.value::INTEGER) AS errors").
+		Joins("CROSS JOIN LATERAL jsonb_each_text(scores.errors) AS key_errors").
+		Where("scores.user_id = ?", userId).
+		Group("key_errors.key").
+		Order("errors desc").
+		Order("key asc").
+		Scan(&keyErrorCounts).Error; err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return keyErrorCounts, nil
+}
+
+// FindScoreDays returns the distinct days (UTC) on which the user created scores, starting with the most recent day.
+func (repo *SQLRepository) FindScoreDays(ctx context.Context, tx common.Transaction, userId uuid.UUID) ([]time.Time, error) {
+	const op errors.Op = "sql_repo.SQLRepository.FindScoreDays"
+	db := repo.dbConn(tx)
+	var days []time.Time
+
+	if err := db.WithContext(ctx).
+		Model(&models.Score{}).
+		Distinct("date_trunc('day', scores.created_at AT TIME ZONE 'UTC') AS day").
+		Where("scores.user_id = ?", userId).
+		Order("day desc").
+		Pluck("day", &days).Error; err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return days, nil
+}
+
+func (repo *SQLRepository) FindScoreTotalsByLanguage(ctx context.Context, tx common.Transaction, userId uuid.UUID) ([]models.ScoreTotalsByFilter, error) {
+	const op errors.Op = "sql_repo.SQLRepository.FindScoreTotalsByLanguage"
+	db := repo.dbConn(tx)
+	var scoreTotals []models.ScoreTotalsByFilter
+
+	if err := db.WithContext(ctx).
+		Model(&models.Score{}).
+		Select("texts.language AS language, "+scoreTotalsSelect).
+		Joins("INNER JOIN texts ON scores.text_id = texts.id").
+		Where("scores.user_id = ?", userId).
+		Group("texts.language").
+		Order("texts.language asc").
+		Scan(&scoreTotals).Error; err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return scoreTotals, nil
+}
+
+func (repo *SQLRepository) FindScoreTotalsByPunctuation(ctx context.Context, tx common.Transaction, userId uuid.UUID) ([]models.ScoreTotalsByFilter, error) {
+	const op errors.Op = "sql_repo.SQLRepository.FindScoreTotalsByPunctuation"
+	db := repo.dbConn(tx)
+	var scoreTotals []models.ScoreTotalsByFilter
+
+	if err := db.WithContext(ctx).
+		Model(&models.Score{}).
+		Select("texts.punctuation AS punctuation, "+scoreTotalsSelect).
+		Joins("INNER JOIN texts ON scores.text_id = texts.id").
+		Where("scores.user_id = ?", userId).
+		Group("texts.punctuation").
+		Order("texts.punctuation asc").
+		Scan(&scoreTotals).Error; err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return scoreTotals, nil
+}
+
+// FindTypedTexts returns the most recent scores of the user together with the texts that have been typed
+func (repo *SQLRepository) FindTypedTexts(ctx context.Context, tx common.Transaction, userId uuid.UUID, limit int) ([]models.TypedText, error) {
+	const op errors.Op = "sql_repo.SQLRepository.FindTypedTexts"
+	db := repo.dbConn(tx)
+	var typedTexts []models.TypedText
+
+	if err := db.WithContext(ctx).
+		Model(&models.Score{}).
+		Select("scores.words_typed, scores.time_elapsed, scores.errors, texts.text").
+		Joins("INNER JOIN texts ON scores.text_id = texts.id").
+		Where("scores.user_id = ?", userId).
+		Order("scores.created_at desc").
+		Limit(limit).
+		Scan(&typedTexts).Error; err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return typedTexts, nil
+}
+
+// FindSystemStats counts the rows of the tables that are not soft deleted
+func (repo *SQLRepository) FindSystemStats(ctx context.Context, tx common.Transaction) (*models.SystemStats, error) {
+	const op errors.Op = "sql_repo.SQLRepository.FindSystemStats"
+	db := repo.dbConn(tx).WithContext(ctx)
+	var stats models.SystemStats
+
+	counts := []struct {
+		query *gorm.DB
+		count *int64
+	}{
+		{db.Model(&models.User{}), &stats.Users},
+		{db.Model(&models.User{}).Where("banned_at IS NOT NULL"), &stats.BannedUsers},
+		{db.Model(&models.Text{}), &stats.Texts},
+		{db.Model(&models.Score{}).Where("game_id IS NOT NULL").Distinct("game_id"), &stats.Games},
+		{db.Model(&models.Score{}), &stats.Scores},
+		{db.Model(&models.Room{}), &stats.Rooms},
+	}
+	for _, c := range counts {
+		if err := c.query.Count(c.count).Error; err != nil {
+			return nil, errors.E(op, err)
+		}
+	}
+
+	return &stats, nil
+}
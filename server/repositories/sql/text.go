@@ -6,17 +6,20 @@ import (
 	"10-typing/models"
 
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-// returns nil for *models.Text and nil for error when no record could be found
-func (repo *SQLRepository) FindNewTextForUser(ctx context.Context,
+// FindNewTextForUser returns the first published text that matches the filter and that the user has not typed yet, sorted by the sort option
+func (repo *SQLRepository) FindNewTextForUser(
+	ctx context.Context,
 	tx common.Transaction,
-	userId uuid.UUID, language string,
-	punctuation bool,
-	specialCharactersGte, specialCharactersLte, numbersGte, numbersLte int,
+	userId uuid.UUID,
+	filter models.TextFilter,
+	sortOption models.SortOption,
 ) (*models.Text, error) {
 	const op errors.Op = "sql_repo.SQLRepository.FindNewTextForUser"
 	db := repo.dbConn(tx)
@@ -25,21 +28,35 @@ func (repo *SQLRepository) FindNewTextForUser(ctx context.Context,
 		Joins("LEFT JOIN scores s1 ON texts.id = s1.text_id").
 		Joins("LEFT JOIN scores s2 ON s1.text_id = s2.text_id AND s2.user_id = ?", userId).
 		Where("s2.text_id IS NULL").
-		Where("language = ?", language).
-		Where("punctuation = ?", punctuation).
-		Order("created_at DESC")
+		Scopes(publishedTexts).
+		Where("language = ?", filter.Language).
+		Where("punctuation = ?", filter.Punctuation).
+		Order(clause.OrderByColumn{Column: clause.Column{Table: "texts", Name: sortOption.Column}, Desc: sortOption.Order == "desc"}).
+		Order("texts.id")
 
-	if specialCharactersGte != 0 {
-		result = result.Where("special_characters >= ?", specialCharactersGte)
+	if filter.SpecialCharactersGte != 0 {
+		result = result.Where("special_characters >= ?", filter.SpecialCharactersGte)
+	}
+	if filter.SpecialCharactersLte != 0 {
+		result = result.Where("special_characters <= ?", filter.SpecialCharactersLte)
+	}
+	if filter.NumbersGte != 0 {
+		result = result.Where("numbers >= ?", filter.NumbersGte)
+	}
+	if filter.NumbersLte != 0 {
+		result = result.Where("numbers <= ?", filter.NumbersLte)
+	}
+	if filter.DifficultyGte != nil {
+		result = result.Where("difficulty >= ?", *filter.DifficultyGte)
 	}
-	if specialCharactersLte != 0 {
-		result = result.Where("special_characters <= ?", specialCharactersLte)
+	if filter.DifficultyLte != nil {
+		result = result.Where("difficulty <= ?", *filter.DifficultyLte)
 	}
-	if numbersGte != 0 {
-		result = result.Where("numbers >= ?", numbersGte)
+	if filter.WordsGte != nil {
+		result = result.Where("words >= ?", *filter.WordsGte)
 	}
-	if numbersLte != 0 {
-		result = result.Where("numbers <= ?", numbersLte)
+	if filter.WordsLte != nil {
+		result = result.Where("words <= ?", *filter.WordsLte)
 	}
 
 	var text models.Text
@@ -56,12 +73,58 @@ func (repo *SQLRepository) FindNewTextForUser(ctx context.Context,
 	return &text, nil
 }
 
+// FindNewTextForUsers returns the newest text with the given language and punctuation that none of the users has typed yet
+func (repo *SQLRepository) FindNewTextForUsers(ctx context.Context, tx common.Transaction, userIds []uuid.UUID, language string, punctuation bool) (*models.Text, error) {
+	const op errors.Op = "sql_repo.SQLRepository.FindNewTextForUsers"
+	db := repo.dbConn(tx)
+	var text models.Text
+
+	result := db.WithContext(ctx).
+		Scopes(publishedTexts).
+		Where("language = ?", language).
+		Where("punctuation = ?", punctuation).
+		Where("NOT EXISTS (SELECT 1 FROM scores WHERE scores.text_id = texts.id AND scores.user_id IN ?)", userIds).
+		Order("created_at DESC").
+		First(&text)
+
+	switch {
+	case errors.Is(result.Error, gorm.ErrRecordNotFound):
+		return nil, errors.E(op, common.ErrNotFound)
+	case result.Error != nil:
+		return nil, errors.E(op, result.Error)
+	}
+
+	return &text, nil
+}
+
+// FindNewTextsForUser returns up to limit of the newest published texts and private texts of the user with the given language and punctuation that the user has not typed yet
+func (repo *SQLRepository) FindNewTextsForUser(ctx context.Context, tx common.Transaction, userId uuid.UUID, language string, punctuation bool, limit int) ([]models.Text, error) {
+	const op errors.Op = "sql_repo.SQLRepository.FindNewTextsForUser"
+	db := repo.dbConn(tx)
+	var texts []models.Text
+
+	if err := db.WithContext(ctx).
+		Where("(texts.visibility = ? AND texts.status = ?) OR (texts.visibility = ? AND texts.user_id = ?)",
+			models.PublicTextVisibility, models.ApprovedTextStatus, models.PrivateTextVisibility, userId).
+		Where("language = ?", language).
+		Where("punctuation = ?", punctuation).
+		Where("NOT EXISTS (SELECT 1 FROM scores WHERE scores.text_id = texts.id AND scores.user_id = ?)", userId).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&texts).Error; err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return texts, nil
+}
+
+// FindAllTextIds returns the ids of all published texts, which are public and approved
 func (repo *SQLRepository) FindAllTextIds(ctx context.Context, tx common.Transaction) ([]uuid.UUID, error) {
 	const op errors.Op = "sql_repo.SQLRepository.FindAllTextIds"
 	db := repo.dbConn(tx)
 	var textIds []uuid.UUID
 
-	result := db.WithContext(ctx).Model(&models.Text{}).Pluck("id", &textIds)
+	result := db.WithContext(ctx).Model(&models.Text{}).Scopes(publishedTexts).Pluck("id", &textIds)
 
 	if result.Error != nil {
 		return nil, errors.E(op, result.Error)
@@ -70,6 +133,139 @@ func (repo *SQLRepository) FindAllTextIds(ctx context.Context, tx common.Transac
 	return textIds, nil
 }
 
+// FindTextsForAnalysis returns up to limit texts ordered by id that come after the text with the id afterId.
+// If onlyUnanalyzed is true, only texts without words, which have not been analyzed yet, are returned.
+func (repo *SQLRepository) FindTextsForAnalysis(ctx context.Context, tx common.Transaction, afterId uuid.UUID, onlyUnanalyzed bool, limit int) ([]models.Text, error) {
+	const op errors.Op = "sql_repo.SQLRepository.FindTextsForAnalysis"
+	db := repo.dbConn(tx)
+	var texts []models.Text
+
+	query := db.WithContext(ctx).Where("id > ?", afterId)
+	if onlyUnanalyzed {
+		query = query.Where("words = 0")
+	}
+
+	if err := query.Order("id").Limit(limit).Find(&texts).Error; err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return texts, nil
+}
+
+// FindTextsAfterId returns up to limit texts ordered by id that come after the text with the id afterId
+func (repo *SQLRepository) FindTextsAfterId(ctx context.Context, tx common.Transaction, afterId uuid.UUID, limit int) ([]models.Text, error) {
+	const op errors.Op = "sql_repo.SQLRepository.FindTextsAfterId"
+	db := repo.dbConn(tx)
+	var texts []models.Text
+
+	if err := db.WithContext(ctx).Where("id > ?", afterId).Order("id").Limit(limit).Find(&texts).Error; err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return texts, nil
+}
+
+// FindExistingContentHashes returns the content hashes of the given ones that belong to an existing public text that has not been rejected, which are unique
+func (repo *SQLRepository) FindExistingContentHashes(ctx context.Context, tx common.Transaction, contentHashes []string) ([]string, error) {
+	const op errors.Op = "sql_repo.SQLRepository.FindExistingContentHashes"
+	db := repo.dbConn(tx)
+	var existingContentHashes []string
+
+	if err := db.WithContext(ctx).Model(&models.Text{}).
+		Scopes(uniqueContentHashTexts).
+		Where("content_hash IN ?", contentHashes).
+		Distinct().
+		Pluck("content_hash", &existingContentHashes).Error; err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return existingContentHashes, nil
+}
+
+// FindPublicTextByContentHash returns the public text with the content hash that has not been rejected, which is unique
+func (repo *SQLRepository) FindPublicTextByContentHash(ctx context.Context, tx common.Transaction, contentHash string) (*models.Text, error) {
+	const op errors.Op = "sql_repo.SQLRepository.FindPublicTextByContentHash"
+	db := repo.dbConn(tx)
+	var text models.Text
+
+	if err := db.WithContext(ctx).Scopes(uniqueContentHashTexts).Where("content_hash = ?", contentHash).First(&text).Error; err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, errors.E(op, common.ErrNotFound)
+		default:
+			return nil, errors.E(op, err)
+		}
+	}
+
+	return &text, nil
+}
+
+// FindDuplicatePublicTextIds returns the ids of the public texts that have not been rejected and that have the content hash of an older one,
+// e.g. because they have been created before the content hashes of the public texts were unique
+func (repo *SQLRepository) FindDuplicatePublicTextIds(ctx context.Context, tx common.Transaction) ([]uuid.UUID, error) {
+	const op errors.Op = "sql_repo.SQLRepository.FindDuplicatePublicTextIds"
+	db := repo.dbConn(tx)
+	var textIds []uuid.UUID
+
+	ranked := db.Model(&models.Text{}).
+		Select("id, ROW_NUMBER() OVER (PARTITION BY content_hash ORDER BY created_at, id) AS n").
+		Scopes(uniqueContentHashTexts).
+		Where("content_hash <> ''")
+
+	if err := db.WithContext(ctx).Table("(?) AS ranked", ranked).Where("n > 1").Order("id").Pluck("id", &textIds).Error; err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return textIds, nil
+}
+
+// FindTextUsageStats aggregates the scores of the texts. Texts that have never been typed are not included.
+func (repo *SQLRepository) FindTextUsageStats(ctx context.Context, tx common.Transaction, textIds []uuid.UUID) ([]models.TextUsageStats, error) {
+	const op errors.Op = "sql_repo.SQLRepository.FindTextUsageStats"
+	db := repo.dbConn(tx)
+	var usageStats []models.TextUsageStats
+
+	if err := db.WithContext(ctx).Model(&models.Score{}).
+		Select("text_id, "+
+			"COUNT(*) AS times_typed, "+
+			"COUNT(DISTINCT user_id) AS typists, "+
+			"AVG(words_per_minute) AS avg_words_per_minute, "+
+			"AVG(accuracy) AS avg_accuracy, "+
+			"MAX(created_at) AS last_typed_at").
+		Where("text_id IN ?", textIds).
+		Group("text_id").
+		Scan(&usageStats).Error; err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return usageStats, nil
+}
+
+// UpdateTextAnalysis updates the columns of the text that are computed by the text analysis
+func (repo *SQLRepository) UpdateTextAnalysis(ctx context.Context, tx common.Transaction, text models.Text) error {
+	const op errors.Op = "sql_repo.SQLRepository.UpdateTextAnalysis"
+	db := repo.dbConn(tx)
+
+	if err := db.WithContext(ctx).Model(&models.Text{ID: text.ID}).Updates(map[string]any{
+		"special_characters":  text.SpecialCharacters,
+		"numbers":             text.Numbers,
+		"characters":          text.Characters,
+		"letters":             text.Letters,
+		"upper_case_letters":  text.UpperCaseLetters,
+		"digits":              text.Digits,
+		"punctuation_marks":   text.PunctuationMarks,
+		"words":               text.Words,
+		"average_word_length": text.AverageWordLength,
+		"bigram_rarity":       text.BigramRarity,
+		"difficulty":          text.Difficulty,
+		"content_hash":        text.ContentHash,
+	}).Error; err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
 func (repo *SQLRepository) FindTextById(ctx context.Context, tx common.Transaction, textId uuid.UUID) (*models.Text, error) {
 	const op errors.Op = "sql_repo.SQLRepository.FindTextById"
 	db := repo.dbConn(tx)
@@ -89,6 +285,7 @@ func (repo *SQLRepository) FindTextById(ctx context.Context, tx common.Transacti
 	return &text, nil
 }
 
+// CreateTextAndCache creates the text and adds its id to the text ids cache if the text is published
 func (repo *SQLRepository) CreateTextAndCache(ctx context.Context, tx common.Transaction, cacheRepo common.CacheRepository, text models.Text) (*models.Text, error) {
 	const op errors.Op = "sql_repo.SQLRepository.CreateText"
 	db := repo.dbConn(tx)
@@ -97,29 +294,186 @@ func (repo *SQLRepository) CreateTextAndCache(ctx context.Context, tx common.Tra
 		return nil, errors.E(op, err)
 	}
 
+	if !text.IsPublished() {
+		return &text, nil
+	}
+
+	if err := repo.cacheTextId(ctx, tx, cacheRepo, text.ID); err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return &text, nil
+}
+
+// CreateTextsAndCache creates the texts with a single insert and adds the ids of the published ones to the text ids cache
+func (repo *SQLRepository) CreateTextsAndCache(ctx context.Context, tx common.Transaction, cacheRepo common.CacheRepository, texts []models.Text) ([]models.Text, error) {
+	const op errors.Op = "sql_repo.SQLRepository.CreateTextsAndCache"
+	db := repo.dbConn(tx)
+
+	if len(texts) == 0 {
+		return texts, nil
+	}
+
+	if err := db.WithContext(ctx).CreateInBatches(&texts, len(texts)).Error; err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	var publishedTextIds []uuid.UUID
+	for _, text := range texts {
+		if text.IsPublished() {
+			publishedTextIds = append(publishedTextIds, text.ID)
+		}
+	}
+	if len(publishedTextIds) == 0 {
+		return texts, nil
+	}
+
+	if err := repo.cacheTextId(ctx, tx, cacheRepo, publishedTextIds...); err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return texts, nil
+}
+
+// FindTextsByUserId returns the custom texts of the user, newest first, and the total number of them
+func (repo *SQLRepository) FindTextsByUserId(ctx context.Context, tx common.Transaction, userId uuid.UUID, limit, offset int) ([]models.Text, int64, error) {
+	const op errors.Op = "sql_repo.SQLRepository.FindTextsByUserId"
+	db := repo.dbConn(tx)
+	var texts []models.Text
+	var total int64
+
+	query := db.WithContext(ctx).Model(&models.Text{}).Where("user_id = ?", userId)
+
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, errors.E(op, err)
+	}
+
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&texts).Error; err != nil {
+		return nil, 0, errors.E(op, err)
+	}
+
+	return texts, total, nil
+}
+
+// FindTextsByStatus returns the public custom texts with the given moderation status, oldest first, and the total number of them
+func (repo *SQLRepository) FindTextsByStatus(ctx context.Context, tx common.Transaction, status models.TextStatus, limit, offset int) ([]models.Text, int64, error) {
+	const op errors.Op = "sql_repo.SQLRepository.FindTextsByStatus"
+	db := repo.dbConn(tx)
+	var texts []models.Text
+	var total int64
+
+	query := db.WithContext(ctx).Model(&models.Text{}).
+		Where("visibility = ?", models.PublicTextVisibility).
+		Where("status = ?", status)
+
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, errors.E(op, err)
+	}
+
+	if err := query.Order("created_at ASC").Limit(limit).Offset(offset).Find(&texts).Error; err != nil {
+		return nil, 0, errors.E(op, err)
+	}
+
+	return texts, total, nil
+}
+
+// UpdateTextStatusAndCache sets the moderation status of the text and adds its id to the text ids cache if the text has been published
+// or removes it from the cache if the text is not published anymore, e.g. because it has been rejected, so that no new games are started with it.
+func (repo *SQLRepository) UpdateTextStatusAndCache(
+	ctx context.Context,
+	tx common.Transaction,
+	cacheRepo common.CacheRepository,
+	textId uuid.UUID,
+	status models.TextStatus,
+	moderationNote string,
+) (*models.Text, error) {
+	const op errors.Op = "sql_repo.SQLRepository.UpdateTextStatusAndCache"
+	db := repo.dbConn(tx)
+	var text = models.Text{ID: textId}
+
+	result := db.WithContext(ctx).Model(&text).Clauses(clause.Returning{}).Updates(map[string]any{
+		"status":          status,
+		"moderation_note": moderationNote,
+		"moderated_at":    time.Now(),
+	})
+	switch {
+	case result.Error != nil:
+		return nil, errors.E(op, result.Error)
+	case result.RowsAffected == 0:
+		return nil, errors.E(op, common.ErrNotFound)
+	}
+
+	if !text.IsPublished() {
+		if err := cacheRepo.RemoveTextId(ctx, nil, text.ID); err != nil {
+			return nil, errors.E(op, err)
+		}
+
+		return &text, nil
+	}
+
+	if err := repo.cacheTextId(ctx, tx, cacheRepo, text.ID); err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return &text, nil
+}
+
+// SoftDeleteTextAndCache deletes the text and removes its id from the text ids cache, so that it isn't served anymore and no new games are started with it.
+func (repo *SQLRepository) SoftDeleteTextAndCache(ctx context.Context, tx common.Transaction, cacheRepo common.CacheRepository, textId uuid.UUID) error {
+	const op errors.Op = "sql_repo.SQLRepository.SoftDeleteTextAndCache"
+	db := repo.dbConn(tx)
+
+	result := db.WithContext(ctx).Delete(&models.Text{}, textId)
+	switch {
+	case result.Error != nil:
+		return errors.E(op, result.Error)
+	case result.RowsAffected == 0:
+		return errors.E(op, common.ErrNotFound)
+	}
+
+	if err := cacheRepo.RemoveTextId(ctx, nil, textId); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+func (repo *SQLRepository) cacheTextId(ctx context.Context, tx common.Transaction, cacheRepo common.CacheRepository, textIds ...uuid.UUID) error {
+	const op errors.Op = "sql_repo.SQLRepository.cacheTextId"
+
 	// create text in redis and additionally: if no text ids key exists, query all text ids from DB and write them to text ids key
 	allTextsAreInRedis, err := cacheRepo.TextIdsKeyExists(ctx)
 	switch {
 	case err != nil:
-		return nil, errors.E(op, err)
+		return errors.E(op, err)
 	case !allTextsAreInRedis:
 		allTextIds, err := repo.FindAllTextIds(ctx, tx)
 		if err != nil {
-			return nil, errors.E(op, err)
+			return errors.E(op, err)
 		}
 
-		allTextIds = append(allTextIds, text.ID)
+		allTextIds = append(allTextIds, textIds...)
 
 		if err = cacheRepo.SetTextId(ctx, nil, allTextIds...); err != nil {
-			return nil, errors.E(op, err)
+			return errors.E(op, err)
 		}
 	default:
-		if err = cacheRepo.SetTextId(ctx, nil, text.ID); err != nil {
-			return nil, errors.E(op, err)
+		if err = cacheRepo.SetTextId(ctx, nil, textIds...); err != nil {
+			return errors.E(op, err)
 		}
 	}
 
-	return &text, nil
+	return nil
+}
+
+// publishedTexts restricts a query to texts that may be served to all users
+func publishedTexts(db *gorm.DB) *gorm.DB {
+	return db.Where("texts.visibility = ?", models.PublicTextVisibility).Where("texts.status = ?", models.ApprovedTextStatus)
+}
+
+// uniqueContentHashTexts restricts a query to the texts that are covered by the unique index of the content hashes of the public texts
+func uniqueContentHashTexts(db *gorm.DB) *gorm.DB {
+	return db.Where("texts.visibility = ?", models.PublicTextVisibility).Where("texts.status <> ?", models.RejectedTextStatus)
 }
 
 func (repo *SQLRepository) DeleteAllTexts(ctx context.Context, tx common.Transaction) error {
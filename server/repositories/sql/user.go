@@ -5,9 +5,11 @@ import (
 	"10-typing/errors"
 	"10-typing/models"
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func (repo *SQLRepository) FindUserByEmail(ctx context.Context, tx common.Transaction, email string) (*models.User, error) {
@@ -69,6 +71,33 @@ func (repo *SQLRepository) FindUserById(ctx context.Context, tx common.Transacti
 	return &user, nil
 }
 
+func (repo *SQLRepository) FindUsersByIds(ctx context.Context, tx common.Transaction, userIds []uuid.UUID) ([]models.User, error) {
+	const op errors.Op = "sql_repo.SQLRepository.FindUsersByIds"
+	db := repo.dbConn(tx)
+	var users []models.User
+
+	if err := db.WithContext(ctx).Where("id IN ?", userIds).Find(&users).Error; err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return users, nil
+}
+
+// FindUsersByIdsForUpdate locks the users until the end of the transaction and returns them,
+// so that concurrent transactions can't change e.g. the ratings of the users between reading and writing them.
+// The users are locked in the order of their ids, so that transactions that lock overlapping users don't deadlock.
+func (repo *SQLRepository) FindUsersByIdsForUpdate(ctx context.Context, tx common.Transaction, userIds []uuid.UUID) ([]models.User, error) {
+	const op errors.Op = "sql_repo.SQLRepository.FindUsersByIdsForUpdate"
+	db := repo.dbConn(tx)
+	var users []models.User
+
+	if err := db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id IN ?", userIds).Order("id").Find(&users).Error; err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return users, nil
+}
+
 func (repo *SQLRepository) CreateUserAndCache(ctx context.Context, tx common.Transaction, cacheRepo common.CacheRepository, newUser models.User) (*models.User, error) {
 	const op errors.Op = "sql_repo.SQLRepository.CreateUserAndCache"
 
@@ -107,6 +136,75 @@ func (repo *SQLRepository) VerifyUserAndCache(ctx context.Context, tx common.Tra
 	return nil
 }
 
+// FindAllUsers returns the users that match the filter, sorted by username, and the total number of them
+func (repo *SQLRepository) FindAllUsers(ctx context.Context, tx common.Transaction, filter models.UserFilter, limit, offset int) ([]models.User, int64, error) {
+	const op errors.Op = "sql_repo.SQLRepository.FindAllUsers"
+	db := repo.dbConn(tx)
+	var users []models.User
+	var total int64
+
+	query := db.WithContext(ctx).Model(&models.User{})
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.Banned != nil {
+		if *filter.Banned {
+			query = query.Where("banned_at IS NOT NULL")
+		} else {
+			query = query.Where("banned_at IS NULL")
+		}
+	}
+
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, errors.E(op, err)
+	}
+
+	if err := query.Order("username ASC").Limit(limit).Offset(offset).Find(&users).Error; err != nil {
+		return nil, 0, errors.E(op, err)
+	}
+
+	return users, total, nil
+}
+
+func (repo *SQLRepository) UpdateUserRoleAndCache(ctx context.Context, tx common.Transaction, cacheRepo common.CacheRepository, userId uuid.UUID, role models.UserRole) (*models.User, error) {
+	const op errors.Op = "sql_repo.SQLRepository.UpdateUserRoleAndCache"
+
+	user, err := repo.updateUserAndCache(ctx, tx, cacheRepo, userId, "role", role)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return user, nil
+}
+
+// UpdateUserBannedAndCache bans the user now or unbans the user
+func (repo *SQLRepository) UpdateUserBannedAndCache(ctx context.Context, tx common.Transaction, cacheRepo common.CacheRepository, userId uuid.UUID, banned bool) (*models.User, error) {
+	const op errors.Op = "sql_repo.SQLRepository.UpdateUserBannedAndCache"
+	var bannedAt *time.Time
+	if banned {
+		now := time.Now()
+		bannedAt = &now
+	}
+
+	user, err := repo.updateUserAndCache(ctx, tx, cacheRepo, userId, "banned_at", bannedAt)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return user, nil
+}
+
+func (repo *SQLRepository) UpdateUserKeyboardLayoutAndCache(ctx context.Context, tx common.Transaction, cacheRepo common.CacheRepository, userId uuid.UUID, keyboardLayout string) (*models.User, error) {
+	const op errors.Op = "sql_repo.SQLRepository.UpdateUserKeyboardLayoutAndCache"
+
+	user, err := repo.updateUserAndCache(ctx, tx, cacheRepo, userId, "keyboard_layout", keyboardLayout)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return user, nil
+}
+
 func (repo *SQLRepository) DeleteAllUsers(ctx context.Context, tx common.Transaction) error {
 	const op errors.Op = "sql_repo.SQLRepository.DeleteAllUsers"
 	db := repo.dbConn(tx)
@@ -118,6 +216,27 @@ func (repo *SQLRepository) DeleteAllUsers(ctx context.Context, tx common.Transac
 	return nil
 }
 
+// updateUserAndCache updates the column of the user and replaces the cached user, so that the change applies to the sessions of the user immediately
+func (repo *SQLRepository) updateUserAndCache(ctx context.Context, tx common.Transaction, cacheRepo common.CacheRepository, userId uuid.UUID, column string, value any) (*models.User, error) {
+	const op errors.Op = "sql_repo.SQLRepository.updateUserAndCache"
+	db := repo.dbConn(tx)
+	var user = models.User{ID: userId}
+
+	result := db.WithContext(ctx).Model(&user).Clauses(clause.Returning{}).Update(column, value)
+	switch {
+	case result.Error != nil:
+		return nil, errors.E(op, result.Error)
+	case result.RowsAffected == 0:
+		return nil, errors.E(op, common.ErrNotFound)
+	}
+
+	if err := cacheRepo.SetUser(ctx, nil, user); err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return &user, nil
+}
+
 func (repo *SQLRepository) verifyUser(ctx context.Context, tx common.Transaction, userId uuid.UUID) error {
 	const op errors.Op = "sql_repo.SQLRepository.verifyUser"
 	db := repo.dbConn(tx)
@@ -3,9 +3,12 @@ package sql_repo
 import (
 	"10-typing/common"
 	"10-typing/errors"
+	"10-typing/models"
 	"context"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func (repo *SQLRepository) CreateUserRoom(ctx context.Context, tx common.Transaction, userId, roomId uuid.UUID) error {
@@ -19,3 +22,53 @@ func (repo *SQLRepository) CreateUserRoom(ctx context.Context, tx common.Transac
 
 	return nil
 }
+
+func (repo *SQLRepository) DeleteUserRoom(ctx context.Context, tx common.Transaction, userId, roomId uuid.UUID) error {
+	const op errors.Op = "sql_repo.SQLRepository.DeleteUserRoom"
+	db := repo.dbConn(tx)
+
+	if err := db.WithContext(ctx).Table("user_rooms").Where("room_id = ? AND user_id = ?", roomId, userId).Delete(nil).Error; err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+// FindRoomMemberIdsForUpdate locks the room until the end of the transaction and returns the ids of its members,
+// so that the members can't change between reading them and writing new members in the same transaction
+func (repo *SQLRepository) FindRoomMemberIdsForUpdate(ctx context.Context, tx common.Transaction, roomId uuid.UUID) ([]uuid.UUID, error) {
+	const op errors.Op = "sql_repo.SQLRepository.FindRoomMemberIdsForUpdate"
+	db := repo.dbConn(tx)
+	var memberIds []uuid.UUID
+
+	if err := db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.Room{ID: roomId}).Error; err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, errors.E(op, common.ErrNotFound)
+		default:
+			return nil, errors.E(op, err)
+		}
+	}
+
+	if err := db.WithContext(ctx).Table("user_rooms").Where("room_id = ?", roomId).Pluck("user_id", &memberIds).Error; err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return memberIds, nil
+}
+
+// FindRoomIdsByUserId returns the ids of the rooms of which the user is a member
+func (repo *SQLRepository) FindRoomIdsByUserId(ctx context.Context, tx common.Transaction, userId uuid.UUID) ([]uuid.UUID, error) {
+	const op errors.Op = "sql_repo.SQLRepository.FindRoomIdsByUserId"
+	db := repo.dbConn(tx)
+	var roomIds []uuid.UUID
+
+	if err := db.WithContext(ctx).Table("user_rooms").
+		Joins("JOIN rooms ON rooms.id = user_rooms.room_id AND rooms.deleted_at IS NULL").
+		Where("user_rooms.user_id = ?", userId).
+		Pluck("user_rooms.room_id", &roomIds).Error; err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return roomIds, nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"10-typing/models"
+	redis_repo "10-typing/repositories/redis"
+	sql_repo "10-typing/repositories/sql"
+	"10-typing/services"
+	"10-typing/zerologger"
+	"context"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// computes the analysis and difficulty of the texts that have been created before texts were analyzed
+// with -all every text is analyzed again
+func main() {
+	all := flag.Bool("all", false, "analyze all texts instead of only the texts that have not been analyzed yet")
+	flag.Parse()
+
+	models.ConnectDatabase()
+
+	var ctx = context.Background()
+	cacheRepo := redis_repo.NewRedisRepository(models.RedisClient)
+	dbRepo := sql_repo.NewSQLRepository(models.DB)
+
+	zl := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339}).With().Timestamp().Logger()
+	logger := zerologger.New(zl)
+
+	textService := services.NewTextService(dbRepo, cacheRepo, nil, nil, logger)
+
+	updated, err := textService.AnalyzeTexts(ctx, *all)
+	if err != nil {
+		log.Print(err)
+		os.Exit(1)
+	}
+
+	logger.Info("analyzed texts: ", updated)
+}
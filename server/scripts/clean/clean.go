@@ -44,6 +44,13 @@ func main() {
 		return
 	}
 
+	err = cacheRepo.DeleteAllLeaderboards(ctx)
+	if err != nil {
+		log.Print(err)
+		os.Exit(1)
+		return
+	}
+
 	err = dbRepo.DeleteAllTexts(ctx, nil)
 	if err != nil {
 		log.Print(err)
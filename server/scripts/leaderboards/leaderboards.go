@@ -0,0 +1,34 @@
+package main
+
+import (
+	"10-typing/models"
+	redis_repo "10-typing/repositories/redis"
+	sql_repo "10-typing/repositories/sql"
+	"10-typing/services"
+	"10-typing/zerologger"
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// rebuilds all leaderboards in redis from the scores that are stored in postgres
+func main() {
+	models.ConnectDatabase()
+
+	var ctx = context.Background()
+	cacheRepo := redis_repo.NewRedisRepository(models.RedisClient)
+	dbRepo := sql_repo.NewSQLRepository(models.DB)
+
+	zl := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339}).With().Timestamp().Logger()
+	logger := zerologger.New(zl)
+
+	leaderboardService := services.NewLeaderboardService(dbRepo, cacheRepo, logger)
+
+	if err := leaderboardService.RebuildLeaderboards(ctx); err != nil {
+		log.Print(err)
+		os.Exit(1)
+	}
+}
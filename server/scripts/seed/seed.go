@@ -2,8 +2,9 @@ package main
 
 import (
 	"10-typing/errors"
+	"10-typing/keyboard"
 	"10-typing/models"
-	open_ai_repo "10-typing/repositories/open_ai"
+	local_text_repo "10-typing/repositories/local_text"
 	redis_repo "10-typing/repositories/redis"
 	sql_repo "10-typing/repositories/sql"
 	"10-typing/services"
@@ -20,6 +21,8 @@ import (
 	"github.com/rs/zerolog"
 )
 
+const seedTextGeneratorSeed = 10
+
 var (
 	userService  *services.UserService
 	scoreService *services.ScoreService
@@ -27,16 +30,21 @@ var (
 )
 
 func init() {
+	models.ConnectDatabase()
+
 	cacheRepo := redis_repo.NewRedisRepository(models.RedisClient)
 	dbRepo := sql_repo.NewSQLRepository(models.DB)
-	openAiRepo := open_ai_repo.NewOpenAiRepository("")
+	// the seed data is generated locally with a fixed seed, so that seeding works offline and always creates the same texts
+	openAiRepo := local_text_repo.NewLocalTextRepository(seedTextGeneratorSeed)
 
 	zl := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339}).With().Timestamp().Logger()
 	logger := zerologger.New(zl)
 
 	userService = services.NewUserService(dbRepo, cacheRepo, logger, 32)
-	scoreService = services.NewScoreService(dbRepo, logger)
-	textService = services.NewTextService(dbRepo, cacheRepo, openAiRepo, logger)
+	leaderboardService := services.NewLeaderboardService(dbRepo, cacheRepo, logger)
+	scoreService = services.NewScoreService(dbRepo, cacheRepo, leaderboardService, logger)
+	statsService := services.NewStatsService(dbRepo, cacheRepo, logger)
+	textService = services.NewTextService(dbRepo, cacheRepo, openAiRepo, statsService, logger)
 }
 
 func main() {
@@ -106,6 +114,7 @@ func seedUsers(ctx context.Context) ([]*models.User, error) {
 			userInputData.FirstName,
 			userInputData.LastName,
 			userInputData.Password,
+			keyboard.DefaultLayoutName,
 		)
 		if err != nil {
 			return nil, errors.E(op, err)
@@ -140,6 +149,7 @@ func seedFakeUsers(ctx context.Context, n int) ([]*models.User, error) {
 			userInputData.FirstName,
 			userInputData.LastName,
 			userInputData.Password,
+			keyboard.DefaultLayoutName,
 		)
 		if err != nil {
 			return nil, errors.E(op, err)
@@ -162,13 +172,13 @@ func seedFakeTexts(ctx context.Context, n int) ([]*models.Text, error) {
 	texts := make([]*models.Text, 0, n)
 
 	for i := 0; i < n; i++ {
-		gptText := "The quick brown fox jumps over the lazy dogs back. The five boxing wizards jump quickly. Special characters: @#$%^&* (8). Numbers: 12345678. 1234567890. 1234567890. The quick brown fox jumps over the lazy dogs back. The five boxing wizards jump quickly. Special characters: @#$%^&* (8). Numbers: 12345678. 1234567890. 1234567890."
 		newText, err := generateFakeData[models.Text]()
 		if err != nil {
 			return nil, errors.E(op, err)
 		}
 
-		text, err := textService.Create(ctx, newText.Language, gptText, newText.Punctuation, newText.SpecialCharacters, newText.Numbers)
+		// without a text, the text is generated with the requested number of special characters and numbers
+		text, err := textService.Create(ctx, newText.Language, "", newText.Punctuation, newText.SpecialCharacters, newText.Numbers)
 		if err != nil {
 			return nil, errors.E(op, err)
 		}
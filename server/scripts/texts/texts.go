@@ -0,0 +1,303 @@
+package main
+
+import (
+	"10-typing/common"
+	"10-typing/models"
+	redis_repo "10-typing/repositories/redis"
+	sql_repo "10-typing/repositories/sql"
+	"10-typing/services"
+	"10-typing/textgen"
+	"10-typing/zerologger"
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const usage = `usage:
+  go run scripts/texts/texts.go import -file corpus.jsonl [-format jsonl|csv|txt] [-language en] [-source name] [-passage-words 60]
+  go run scripts/texts/texts.go export [-format jsonl|csv] [-out texts.jsonl]
+  go run scripts/texts/texts.go dedupe
+
+import reads texts from a JSONL file with one {"text", "language", "title", "source"} object per line,
+from a CSV file with a header row that contains a text column and optionally language, title and source columns,
+or from a plain text file that holds a single document. Long texts are split into passages of about -passage-words words.
+The format is taken from the file extension if -format is not set.
+
+export writes all texts together with their usage statistics to -out or to stdout.
+
+dedupe deletes the public texts that have the same content as an older public text, which have been created before the content
+of the public texts was unique, and creates the unique index of the content hashes.`
+
+// importRecord is a single text of an import file, which may be split into several passages
+type importRecord struct {
+	Text     string `json:"text"`
+	Language string `json:"language"`
+	Title    string `json:"title"`
+	Source   string `json:"source"`
+}
+
+// imports texts from corpora, exports all texts with their usage statistics or deletes the duplicates of public texts
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	models.ConnectDatabase()
+
+	var ctx = context.Background()
+	cacheRepo := redis_repo.NewRedisRepository(models.RedisClient)
+	dbRepo := sql_repo.NewSQLRepository(models.DB)
+
+	zl := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339}).With().Timestamp().Logger()
+	logger := zerologger.New(zl)
+
+	textService := services.NewTextService(dbRepo, cacheRepo, nil, nil, logger)
+
+	var err error
+	switch os.Args[1] {
+	case "import":
+		err = importTexts(ctx, textService, logger, os.Args[2:])
+	case "export":
+		err = exportTexts(ctx, textService, os.Args[2:])
+	case "dedupe":
+		err = dedupeTexts(ctx, textService, logger)
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Print(err)
+		os.Exit(1)
+	}
+}
+
+func importTexts(ctx context.Context, textService *services.TextService, logger common.Logger, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	file := flags.String("file", "", "the file to import")
+	format := flags.String("format", "", "the format of the file: jsonl, csv or txt")
+	language := flags.String("language", "", "the language of texts without a language")
+	source := flags.String("source", "", "the source of texts without a source")
+	passageWords := flags.Int("passage-words", 60, "the number of words that long texts are split into")
+	flags.Parse(args)
+
+	if *file == "" {
+		return fmt.Errorf("-file is required")
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(filepath.Ext(*file), ".")
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var records []importRecord
+	switch *format {
+	case "jsonl":
+		records, err = readJSONL(f)
+	case "csv":
+		records, err = readCSV(f)
+	case "txt":
+		records, err = readPlainText(f, strings.TrimSuffix(filepath.Base(*file), filepath.Ext(*file)))
+	default:
+		err = fmt.Errorf("unsupported format %q", *format)
+	}
+	if err != nil {
+		return err
+	}
+
+	var texts []models.Text
+	for _, record := range records {
+		if record.Language == "" {
+			record.Language = *language
+		}
+		if record.Source == "" {
+			record.Source = *source
+		}
+
+		passages := textgen.SplitPassages(record.Text, *passageWords)
+		for i, passage := range passages {
+			title := record.Title
+			if len(passages) > 1 && title != "" {
+				title = fmt.Sprintf("%s (%d/%d)", title, i+1, len(passages))
+			}
+
+			texts = append(texts, models.Text{Language: record.Language, Text: passage, Title: title, Source: record.Source})
+		}
+	}
+
+	result, err := textService.ImportTexts(ctx, texts)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("import finished: ", result.Created, " created, ", result.Duplicates, " duplicates, ", result.Invalid, " invalid")
+
+	return nil
+}
+
+func readJSONL(r io.Reader) ([]importRecord, error) {
+	var records []importRecord
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var record importRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+
+	return records, scanner.Err()
+}
+
+func readCSV(r io.Reader) ([]importRecord, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int)
+	for i, column := range rows[0] {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	if _, ok := columns["text"]; !ok {
+		return nil, fmt.Errorf("the header row has no text column")
+	}
+
+	get := func(row []string, column string) string {
+		if i, ok := columns[column]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+
+	records := make([]importRecord, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		records = append(records, importRecord{
+			Text:     get(row, "text"),
+			Language: get(row, "language"),
+			Title:    get(row, "title"),
+			Source:   get(row, "source"),
+		})
+	}
+
+	return records, nil
+}
+
+func readPlainText(r io.Reader, title string) ([]importRecord, error) {
+	document, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return []importRecord{{Text: string(document), Title: title}}, nil
+}
+
+func dedupeTexts(ctx context.Context, textService *services.TextService, logger common.Logger) error {
+	deleted, err := textService.DeleteDuplicateTexts(ctx)
+	if err != nil {
+		return err
+	}
+	logger.Info("deleted duplicate texts: ", deleted)
+
+	return models.CreateUniquePublicContentHashIndex(models.DB)
+}
+
+func exportTexts(ctx context.Context, textService *services.TextService, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", "jsonl", "the format of the export: jsonl or csv")
+	out := flags.String("out", "", "the file to write to instead of stdout")
+	flags.Parse(args)
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+
+	switch *format {
+	case "jsonl":
+		encoder := json.NewEncoder(bw)
+		return textService.ExportTexts(ctx, func(text models.TextExport) error {
+			return encoder.Encode(text)
+		})
+	case "csv":
+		csvWriter := csv.NewWriter(bw)
+		header := []string{
+			"id", "created_at", "language", "title", "source", "visibility", "status", "punctuation", "special_characters", "numbers",
+			"words", "difficulty", "content_hash", "times_typed", "typists", "avg_words_per_minute", "avg_accuracy", "last_typed_at", "text",
+		}
+		if err := csvWriter.Write(header); err != nil {
+			return err
+		}
+
+		err := textService.ExportTexts(ctx, func(text models.TextExport) error {
+			lastTypedAt := ""
+			if text.Usage.LastTypedAt != nil {
+				lastTypedAt = text.Usage.LastTypedAt.UTC().Format(time.RFC3339)
+			}
+
+			return csvWriter.Write([]string{
+				text.ID.String(),
+				text.CreatedAt.UTC().Format(time.RFC3339),
+				text.Language,
+				text.Title,
+				text.Source,
+				string(text.Visibility),
+				string(text.Status),
+				strconv.FormatBool(text.Punctuation),
+				strconv.Itoa(text.SpecialCharacters),
+				strconv.Itoa(text.Numbers),
+				strconv.Itoa(text.Words),
+				strconv.FormatFloat(text.Difficulty, 'f', -1, 64),
+				text.ContentHash,
+				strconv.FormatInt(text.Usage.TimesTyped, 10),
+				strconv.FormatInt(text.Usage.Typists, 10),
+				strconv.FormatFloat(text.Usage.AvgWordsPerMinute, 'f', 2, 64),
+				strconv.FormatFloat(text.Usage.AvgAccuracy, 'f', 2, 64),
+				lastTypedAt,
+				text.Text.Text,
+			})
+		})
+		if err != nil {
+			return err
+		}
+
+		csvWriter.Flush()
+		return csvWriter.Error()
+	default:
+		return fmt.Errorf("unsupported format %q", *format)
+	}
+}
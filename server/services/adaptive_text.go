@@ -0,0 +1,201 @@
+package services
+
+import (
+	"10-typing/errors"
+	"10-typing/keyboard"
+	"10-typing/models"
+	"10-typing/textanalysis"
+	"10-typing/textgen"
+	"10-typing/tracing"
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	adaptiveTextCandidatesNumber = 50
+	targetKeysNumber             = 5
+	// keys with fewer keystrokes are not considered to be weak, because their error rate is not meaningful
+	targetKeysMinKeystrokes = 20
+	// existing texts are only selected if they contain the target keys at least this much more often than the average candidate
+	minExistingTextEmphasis = 1.25
+	drillWordsNumber        = 100
+)
+
+// FindAdaptiveTextForUser returns a text to practice the keys the user makes the most errors on.
+// An existing text that the user has not typed yet is selected if the target keys are over-represented in it,
+// otherwise a new drill is generated with the given generator and stored as a private text of the user, which is reused until the user has typed it.
+// If the user has no weak keys yet, the newest text the user has not typed yet is returned.
+func (ts *TextService) FindAdaptiveTextForUser(
+	ctx context.Context,
+	userId uuid.UUID,
+	language string,
+	punctuation bool,
+	generator models.TextGenerator,
+) (*models.AdaptiveText, error) {
+	const op errors.Op = "services.TextService.FindAdaptiveTextForUser"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	keyboardStats, err := ts.statsService.FindUserKeyboardStats(ctx, userId, "")
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+	targetKeys := getTargetKeys(keyboardStats.Keys, targetKeysNumber)
+
+	candidates, err := ts.dbRepo.FindNewTextsForUser(ctx, nil, userId, language, punctuation, adaptiveTextCandidatesNumber)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	if len(targetKeys) == 0 && len(candidates) > 0 {
+		return &models.AdaptiveText{Text: &candidates[0], TargetKeys: targetKeys, Source: models.ExistingAdaptiveTextSource, Emphasis: 1}, nil
+	}
+
+	if bestCandidate, emphasis := selectMostEmphasizingText(candidates, targetKeys); bestCandidate != nil && emphasis >= minExistingTextEmphasis {
+		return &models.AdaptiveText{Text: bestCandidate, TargetKeys: targetKeys, Source: models.ExistingAdaptiveTextSource, Emphasis: emphasis}, nil
+	}
+
+	var drill string
+	switch generator {
+	case models.OpenAiTextGenerator:
+		drill, err = ts.openAiRepo.GenerateDrillText(ctx, language, punctuation, targetKeys)
+		if err != nil {
+			return nil, errors.E(op, err)
+		}
+	default:
+		rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+		drill = textgen.GenerateDrill(rng, language, targetKeys, punctuation, drillWordsNumber)
+	}
+
+	if drill == "" {
+		err := fmt.Errorf("no drill could be generated for language %s", language)
+		return nil, errors.E(op, err, http.StatusBadRequest)
+	}
+
+	// the drill is a private text of the user, so that it isn't served to other users and is reused as a candidate until the user has typed it
+	newText := models.Text{
+		Language:    language,
+		Text:        drill,
+		Punctuation: punctuation,
+		UserId:      &userId,
+		Visibility:  models.PrivateTextVisibility,
+		Status:      models.ApprovedTextStatus,
+	}
+	newText.SetAnalysis(textanalysis.Analyze(drill, language))
+
+	createdText, err := ts.dbRepo.CreateTextAndCache(ctx, nil, ts.cacheRepo, newText)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return &models.AdaptiveText{
+		Text:       createdText,
+		TargetKeys: targetKeys,
+		Source:     models.GeneratedAdaptiveTextSource,
+		Emphasis:   getEmphasis(createdText.Text, targetKeys, getAverageTargetKeysFrequency(candidates, targetKeys)),
+	}, nil
+}
+
+// getTargetKeys returns the keys with the highest error rates. Upper case letters are practiced with their lower case letter.
+func getTargetKeys(keyStats []models.KeyStats, number int) []string {
+	errorRates := make(map[string]*models.ZoneStats)
+	for _, keyStat := range keyStats {
+		if keyStat.Finger == keyboard.Thumb {
+			continue
+		}
+
+		key := strings.ToLower(keyStat.Key)
+		zoneStats, ok := errorRates[key]
+		if !ok {
+			zoneStats = &models.ZoneStats{}
+			errorRates[key] = zoneStats
+		}
+		zoneStats.Errors += keyStat.Errors
+		zoneStats.Keystrokes += keyStat.Keystrokes
+	}
+
+	targetKeys := make([]string, 0, len(errorRates))
+	for key, zoneStats := range errorRates {
+		if zoneStats.Errors == 0 || zoneStats.Keystrokes < targetKeysMinKeystrokes {
+			continue
+		}
+		zoneStats.ErrorRate = float64(zoneStats.Errors) / float64(zoneStats.Keystrokes)
+		targetKeys = append(targetKeys, key)
+	}
+
+	sort.Slice(targetKeys, func(i, j int) bool {
+		if errorRates[targetKeys[i]].ErrorRate == errorRates[targetKeys[j]].ErrorRate {
+			return targetKeys[i] < targetKeys[j]
+		}
+		return errorRates[targetKeys[i]].ErrorRate > errorRates[targetKeys[j]].ErrorRate
+	})
+
+	if len(targetKeys) > number {
+		targetKeys = targetKeys[:number]
+	}
+
+	return targetKeys
+}
+
+// selectMostEmphasizingText returns the text in which the target keys are the most frequent, together with its emphasis
+func selectMostEmphasizingText(texts []models.Text, targetKeys []string) (*models.Text, float64) {
+	averageFrequency := getAverageTargetKeysFrequency(texts, targetKeys)
+
+	var bestText *models.Text
+	var bestEmphasis float64
+	for i := range texts {
+		if emphasis := getEmphasis(texts[i].Text, targetKeys, averageFrequency); bestText == nil || emphasis > bestEmphasis {
+			bestText = &texts[i]
+			bestEmphasis = emphasis
+		}
+	}
+
+	return bestText, bestEmphasis
+}
+
+func getAverageTargetKeysFrequency(texts []models.Text, targetKeys []string) float64 {
+	if len(texts) == 0 {
+		return 0
+	}
+
+	var frequencySum float64
+	for _, text := range texts {
+		frequencySum += getTargetKeysFrequency(text.Text, targetKeys)
+	}
+
+	return frequencySum / float64(len(texts))
+}
+
+// getEmphasis returns the frequency of the target keys in the text relative to the average frequency.
+// Without an average frequency to compare to, the emphasis is 1.
+func getEmphasis(text string, targetKeys []string, averageFrequency float64) float64 {
+	if averageFrequency == 0 {
+		return 1
+	}
+
+	return getTargetKeysFrequency(text, targetKeys) / averageFrequency
+}
+
+// getTargetKeysFrequency returns the share of the characters of the text that are typed with the target keys
+func getTargetKeysFrequency(text string, targetKeys []string) float64 {
+	charsNumber := utf8.RuneCountInString(text)
+	if charsNumber == 0 {
+		return 0
+	}
+
+	lowerText := strings.ToLower(text)
+	targetKeysNumber := 0
+	for _, targetKey := range targetKeys {
+		targetKeysNumber += strings.Count(lowerText, targetKey)
+	}
+
+	return float64(targetKeysNumber) / float64(charsNumber)
+}
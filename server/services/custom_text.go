@@ -0,0 +1,158 @@
+package services
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/models"
+	"10-typing/textanalysis"
+	"10-typing/textgen"
+	"10-typing/tracing"
+	"context"
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// CreateCustomText normalizes the text of the user and computes its punctuation and analysis from its content.
+// Private texts can be typed by the user right away, public texts are pending until an admin approves them.
+func (ts *TextService) CreateCustomText(
+	ctx context.Context,
+	userId uuid.UUID,
+	title, language, source, text string,
+	visibility models.TextVisibility,
+) (*models.Text, error) {
+	const op errors.Op = "services.TextService.CreateCustomText"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	text = textgen.NormalizeText(text)
+	if length := utf8.RuneCountInString(text); length < models.CustomTextMinLength || length > models.CustomTextMaxLength {
+		err := fmt.Errorf("normalized text has %d characters", length)
+		return nil, errors.E(op, err, http.StatusBadRequest, errors.Messages{
+			"message": fmt.Sprintf("The text must have between %d and %d characters", models.CustomTextMinLength, models.CustomTextMaxLength),
+		})
+	}
+
+	status := models.ApprovedTextStatus
+	if visibility == models.PublicTextVisibility {
+		status = models.PendingTextStatus
+	}
+
+	analysis := textanalysis.Analyze(text, language)
+
+	// the content of public texts is unique
+	if visibility == models.PublicTextVisibility {
+		existingContentHashes, err := ts.dbRepo.FindExistingContentHashes(ctx, nil, []string{analysis.ContentHash})
+		switch {
+		case err != nil:
+			return nil, errors.E(op, err)
+		case len(existingContentHashes) > 0:
+			err := fmt.Errorf("public text with content hash %s already exists", analysis.ContentHash)
+			return nil, errors.E(op, err, http.StatusBadRequest, errors.Messages{"message": "This text has already been published"})
+		}
+	}
+
+	newText := models.Text{
+		Language:    language,
+		Text:        text,
+		Punctuation: textgen.HasPunctuation(text),
+		Title:       textgen.NormalizeLine(title),
+		Source:      textgen.NormalizeLine(source),
+		UserId:      &userId,
+		Visibility:  visibility,
+		Status:      status,
+	}
+	newText.SetAnalysis(analysis)
+
+	createdText, err := ts.dbRepo.CreateTextAndCache(ctx, nil, ts.cacheRepo, newText)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return createdText, nil
+}
+
+func (ts *TextService) FindCustomTextsOfUser(ctx context.Context, userId uuid.UUID, limit, offset int) ([]models.Text, int64, error) {
+	const op errors.Op = "services.TextService.FindCustomTextsOfUser"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	texts, total, err := ts.dbRepo.FindTextsByUserId(ctx, nil, userId, limit, offset)
+	if err != nil {
+		return nil, 0, errors.E(op, err)
+	}
+
+	return texts, total, nil
+}
+
+// FindModerationQueue returns the public texts that wait for moderation, oldest first
+func (ts *TextService) FindModerationQueue(ctx context.Context, limit, offset int) ([]models.Text, int64, error) {
+	const op errors.Op = "services.TextService.FindModerationQueue"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	texts, total, err := ts.dbRepo.FindTextsByStatus(ctx, nil, models.PendingTextStatus, limit, offset)
+	if err != nil {
+		return nil, 0, errors.E(op, err)
+	}
+
+	return texts, total, nil
+}
+
+// ModerateText approves or rejects a public custom text. Approved texts are added to the text ids and can be served to all users.
+func (ts *TextService) ModerateText(ctx context.Context, textId uuid.UUID, status models.TextStatus, moderationNote string) (*models.Text, error) {
+	const op errors.Op = "services.TextService.ModerateText"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	text, err := ts.dbRepo.FindTextById(ctx, nil, textId)
+	switch {
+	case errors.Is(err, common.ErrNotFound):
+		return nil, errors.E(op, err, http.StatusNotFound)
+	case err != nil:
+		return nil, errors.E(op, err)
+	}
+
+	if text.Visibility != models.PublicTextVisibility {
+		err := fmt.Errorf("text %s is not public", textId)
+		return nil, errors.E(op, err, http.StatusBadRequest, errors.Messages{"message": "Only public texts can be moderated"})
+	}
+
+	// a rejected text may have been submitted again, which must stay rejected while the new one is public
+	if text.Status == models.RejectedTextStatus && status != models.RejectedTextStatus {
+		existingContentHashes, err := ts.dbRepo.FindExistingContentHashes(ctx, nil, []string{text.ContentHash})
+		switch {
+		case err != nil:
+			return nil, errors.E(op, err)
+		case len(existingContentHashes) > 0:
+			err := fmt.Errorf("public text with content hash %s already exists", text.ContentHash)
+			return nil, errors.E(op, err, http.StatusBadRequest, errors.Messages{"message": "This text has already been published"})
+		}
+	}
+
+	moderatedText, err := ts.dbRepo.UpdateTextStatusAndCache(ctx, nil, ts.cacheRepo, textId, status, moderationNote)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return moderatedText, nil
+}
+
+// DeleteText deletes the text, e.g. when a moderator removes an offensive text. Games that have already been played with the text keep their scores.
+func (ts *TextService) DeleteText(ctx context.Context, textId uuid.UUID) error {
+	const op errors.Op = "services.TextService.DeleteText"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	err := ts.dbRepo.SoftDeleteTextAndCache(ctx, nil, ts.cacheRepo, textId)
+	switch {
+	case errors.Is(err, common.ErrNotFound):
+		return errors.E(op, err, http.StatusNotFound)
+	case err != nil:
+		return errors.E(op, err)
+	}
+
+	return nil
+}
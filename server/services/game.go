@@ -3,15 +3,20 @@ package services
 import (
 	"10-typing/common"
 	"10-typing/errors"
+	"10-typing/metrics"
 	"10-typing/models"
+	"10-typing/tracing"
 	"10-typing/utils"
 	"context"
 	"fmt"
 
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -20,30 +25,34 @@ const (
 )
 
 type GameService struct {
-	dbRepo    common.DBRepository
-	cacheRepo common.CacheRepository
-	logger    common.Logger
+	dbRepo             common.DBRepository
+	cacheRepo          common.CacheRepository
+	ratingService      *RatingService
+	leaderboardService *LeaderboardService
+	clock              common.Clock
+	runningGames       *runningGames
+	logger             common.Logger
 }
 
 func NewGameService(
 	dbRepo common.DBRepository,
 	cacheRepo common.CacheRepository,
+	ratingService *RatingService,
+	leaderboardService *LeaderboardService,
+	clock common.Clock,
 	logger common.Logger,
 ) *GameService {
-	return &GameService{dbRepo, cacheRepo, logger}
+	return &GameService{dbRepo, cacheRepo, ratingService, leaderboardService, clock, newRunningGames(), logger}
 }
 
 func (gs *GameService) CreateNewCurrentGame(ctx context.Context, userId, roomId, textId uuid.UUID) (uuid.UUID, error) {
 	const op errors.Op = "services.GameService.SetNewCurrentGame"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	// validate
-	textExists, err := gs.cacheRepo.TextIdExists(ctx, textId)
-	switch {
-	case err != nil:
+	if err := gs.validateGameText(ctx, userId, textId); err != nil {
 		return uuid.Nil, errors.E(op, err)
-	case !textExists:
-		err := fmt.Errorf("text does not exist")
-		return uuid.Nil, errors.E(op, err, http.StatusBadRequest)
 	}
 
 	currentGameStatus, err := gs.cacheRepo.GetCurrentGameStatus(ctx, roomId)
@@ -69,6 +78,10 @@ func (gs *GameService) CreateNewCurrentGame(ctx context.Context, userId, roomId,
 		return uuid.Nil, errors.E(op, err)
 	}
 
+	if err := gs.dbRepo.UpdateRoomLastGameAt(ctx, nil, roomId, gs.clock.Now()); err != nil {
+		return uuid.Nil, errors.E(op, err)
+	}
+
 	if err := gs.cacheRepo.SetNewCurrentGame(ctx, nil, gameId, textId, roomId, userId); err != nil {
 		return uuid.Nil, errors.E(op, err)
 	}
@@ -88,6 +101,53 @@ func (gs *GameService) CreateNewCurrentGame(ctx context.Context, userId, roomId,
 	return gameId, nil
 }
 
+// validateGameText returns an error if the text can't be used for a new game of the user.
+// Published texts are looked up in the text ids cache. Private custom texts are not in the cache, so they are looked up in the db and may only be used by their owner.
+func (gs *GameService) validateGameText(ctx context.Context, userId, textId uuid.UUID) error {
+	const op errors.Op = "services.GameService.validateGameText"
+
+	textExists, err := gs.cacheRepo.TextIdExists(ctx, textId)
+	switch {
+	case err != nil:
+		return errors.E(op, err)
+	case textExists:
+		return nil
+	}
+
+	text, err := gs.dbRepo.FindTextById(ctx, nil, textId)
+	switch {
+	case errors.Is(err, common.ErrNotFound):
+		err := fmt.Errorf("text does not exist")
+		return errors.E(op, err, http.StatusBadRequest)
+	case err != nil:
+		return errors.E(op, err)
+	case text.Visibility != models.PrivateTextVisibility || text.UserId == nil || *text.UserId != userId:
+		err := fmt.Errorf("text %s is not published and not a private text of user %s", textId, userId)
+		return errors.E(op, err, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// FindCurrentGameText returns the text of the current game of the room
+func (gs *GameService) FindCurrentGameText(ctx context.Context, roomId uuid.UUID) (*models.Text, error) {
+	const op errors.Op = "services.GameService.FindCurrentGameText"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	currentGame, err := gs.cacheRepo.GetCurrentGame(ctx, roomId)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	text, err := gs.dbRepo.FindTextById(ctx, nil, currentGame.TextId)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return text, nil
+}
+
 func (gs *GameService) UserFinishesGame(
 	ctx context.Context,
 	roomId,
@@ -98,6 +158,8 @@ func (gs *GameService) UserFinishesGame(
 	errorsJSON models.ErrorsJSON,
 ) error {
 	const op errors.Op = "services.GameService.UserFinishesGame"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	// validate: check if game status is not already finished
 	currentGameStatus, err := gs.cacheRepo.GetCurrentGameStatus(ctx, roomId)
@@ -126,6 +188,7 @@ func (gs *GameService) UserFinishesGame(
 	if err != nil {
 		return errors.E(op, err)
 	}
+	ctx = common.ContextWithLogFields(ctx, common.LogFields{common.GameIdLogField: gameId})
 
 	numberErrors := 0
 	for _, value := range errorsJSON {
@@ -146,6 +209,7 @@ func (gs *GameService) UserFinishesGame(
 	if err != nil {
 		return errors.E(op, err)
 	}
+	metrics.ScoresSubmitted.WithLabelValues("game").Inc()
 
 	// post action on stream
 	err = gs.cacheRepo.PublishAction(ctx, nil, roomId, models.GameUserScoreAction)
@@ -155,7 +219,14 @@ func (gs *GameService) UserFinishesGame(
 
 	// the error should only be logged but not returned because the score is already saved in the DB
 	if err := gs.cacheRepo.SetCurrentGameScore(ctx, nil, roomId, *createdScore); err != nil {
-		gs.logger.Error(errors.E(op, err))
+		gs.logger.WithContext(ctx).Error(errors.E(op, err))
+	}
+
+	if err := gs.leaderboardService.AddScore(ctx, *createdScore); err != nil {
+		gs.logger.WithContext(ctx).Error(errors.E(op, err))
+	}
+	if err := gs.cacheRepo.DeleteUserStats(ctx, nil, userId); err != nil {
+		gs.logger.WithContext(ctx).Error(errors.E(op, err))
 	}
 
 	return nil
@@ -163,6 +234,8 @@ func (gs *GameService) UserFinishesGame(
 
 func (gs *GameService) AddUserToGame(ctx context.Context, roomId, userId uuid.UUID) error {
 	const op errors.Op = "services.GameService.AddUserToGame"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	// comment out
 	isCurrentGameUser, err := gs.cacheRepo.IsCurrentGameUser(ctx, roomId, userId)
@@ -214,6 +287,8 @@ func (gs *GameService) AddUserToGame(ctx context.Context, roomId, userId uuid.UU
 
 func (gs *GameService) InitiateGameIfReady(ctx context.Context, roomId uuid.UUID) error {
 	const op errors.Op = "services.GameService.InitiateGameIfReady"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	numberGameUsers, err := gs.cacheRepo.GetCurrentGameUsersNumber(ctx, roomId)
 	switch {
@@ -242,19 +317,37 @@ func (gs *GameService) InitiateGameIfReady(ctx context.Context, roomId uuid.UUID
 		return errors.E(op, err)
 	}
 
-	ctx = context.Background()
+	// the game outlives the request but keeps its log fields. Its spans belong to a new trace, which is linked to the trace of the request.
+	requestLink := trace.LinkFromContext(ctx)
+	ctx = common.ContextWithLogFields(context.Background(), common.LogFieldsFromContext(ctx))
+	ctx, gameSpan := tracing.Start(ctx, "services.GameService.game", trace.WithLinks(requestLink), trace.WithAttributes(
+		attribute.String(common.RoomIdLogField, roomId.String()),
+	))
+	if gameSpan.SpanContext().IsValid() {
+		ctx = common.ContextWithLogFields(ctx, common.LogFields{common.TraceIdLogField: gameSpan.SpanContext().TraceID().String()})
+	}
+
+	gameMetric := newRunningGameMetric(gs.clock.Now())
+
+	// the game is canceled if it is aborted on shutdown, but it is cleaned up with the context that is not canceled
+	cleanupCtx := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	runningGameId := gs.runningGames.add(cancel)
 
 	go gs.countdown(ctx, roomId, countdownDurationSeconds)
 	go func() {
-		defer gs.cleanupGame(ctx, roomId)
+		defer gs.runningGames.done(runningGameId)
+		defer gameSpan.End()
+		defer gameMetric.setStatus(models.FinishedGameStatus)
+		defer gs.cleanupGame(cleanupCtx, roomId)
 
-		if err = gs.handleGameDuration(ctx, gameDurationSec, roomId); err != nil {
-			gs.logger.Error(errors.E(op, err))
+		if err = gs.handleGameDuration(ctx, gameDurationSec, roomId, gameMetric); err != nil {
+			gs.logger.WithContext(ctx).Error(errors.E(op, err))
 			return
 		}
 
 		if err = gs.handleGameResults(ctx, roomId); err != nil {
-			gs.logger.Error(errors.E(op, err))
+			gs.logger.WithContext(ctx).Error(errors.E(op, err))
 		}
 	}()
 
@@ -263,9 +356,8 @@ func (gs *GameService) InitiateGameIfReady(ctx context.Context, roomId uuid.UUID
 
 func (gs *GameService) countdown(ctx context.Context, roomId uuid.UUID, countdownDurationSeconds int) {
 	const op errors.Op = "services.GameService.countdown"
-
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	for countdownDurationSeconds > 0 {
 		countdownPushMessage := models.PushMessage{
@@ -274,24 +366,32 @@ func (gs *GameService) countdown(ctx context.Context, roomId uuid.UUID, countdow
 		}
 		err := gs.cacheRepo.PublishPushMessage(ctx, nil, roomId, countdownPushMessage)
 		if err != nil {
-			gs.logger.Error(errors.E(op, err))
+			gs.logger.WithContext(ctx).Error(errors.E(op, err))
 			return
 		}
 
 		countdownDurationSeconds--
-		<-ticker.C
+		if err := utils.Sleep(ctx, gs.clock, 1*time.Second); err != nil {
+			return
+		}
 	}
 }
 
-func (gs *GameService) handleGameDuration(ctx context.Context, gameDurationSec int, roomId uuid.UUID) error {
+func (gs *GameService) handleGameDuration(ctx context.Context, gameDurationSec int, roomId uuid.UUID, gameMetric *runningGameMetric) error {
 	const op errors.Op = "services.GameService.handleGameDuration"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
-	time.Sleep(countdownDurationSeconds * time.Second)
+	if err := utils.Sleep(ctx, gs.clock, countdownDurationSeconds*time.Second); err != nil {
+		return errors.E(op, err)
+	}
 
 	// after blocking for countdown duration, set game status to "started"
 	if err := gs.cacheRepo.SetCurrentGameStatus(ctx, nil, roomId, models.StartedGameStatus); err != nil {
 		return errors.E(op, err)
 	}
+	metrics.CountdownToStartDuration.Observe(gs.clock.Now().Sub(gameMetric.countdownStart).Seconds())
+	gameMetric.setStatus(models.StartedGameStatus)
 
 	gameStartedPushMessage := models.PushMessage{
 		Type: models.GameStarted,
@@ -300,13 +400,17 @@ func (gs *GameService) handleGameDuration(ctx context.Context, gameDurationSec i
 		return errors.E(op, err)
 	}
 
-	time.Sleep(time.Duration(gameDurationSec) * time.Second)
+	if err := utils.Sleep(ctx, gs.clock, time.Duration(gameDurationSec)*time.Second); err != nil {
+		return errors.E(op, err)
+	}
 
 	return nil
 }
 
 func (gs *GameService) handleGameResults(ctx context.Context, roomId uuid.UUID) error {
 	const op errors.Op = "services.GameService.handleGameResults"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	numberGameUsers, err := gs.cacheRepo.GetCurrentGameUsersNumber(ctx, roomId)
 	if err != nil {
@@ -315,15 +419,20 @@ func (gs *GameService) handleGameResults(ctx context.Context, roomId uuid.UUID)
 	cancelCtx, cancel := context.WithCancel(ctx)
 	allResultsReceivedCh := gs.getAllResultsReceived(cancelCtx, numberGameUsers, roomId)
 
-	timer := time.NewTimer(waitForResultsDurationSeconds * time.Second)
+	timer := gs.clock.NewTimer(waitForResultsDurationSeconds * time.Second)
 
 	select {
-	case <-timer.C:
+	case <-timer.C():
 	case <-allResultsReceivedCh:
 		timer.Stop()
 	}
 	cancel()
 
+	// the results are not published if the game has been aborted, since the channel is also closed when the context is done
+	if err := ctx.Err(); err != nil {
+		return errors.E(op, err)
+	}
+
 	currentGameScores, err := gs.cacheRepo.GetCurrentGameScores(ctx, roomId)
 	if err != nil {
 		return errors.E(op, err)
@@ -337,6 +446,20 @@ func (gs *GameService) handleGameResults(ctx context.Context, roomId uuid.UUID)
 		return errors.E(op, err)
 	}
 
+	gameId, err := gs.cacheRepo.GetCurrentGameId(ctx, roomId)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	gameUserIds, err := gs.cacheRepo.GetCurrentGameUserIds(ctx, roomId)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	if _, err := gs.ratingService.UpdateRatingsFromGameScores(ctx, gameId, gameUserIds, currentGameScores); err != nil {
+		return errors.E(op, err)
+	}
+
 	return nil
 }
 
@@ -359,7 +482,7 @@ func (gs *GameService) getAllResultsReceived(ctx context.Context, playersNumber
 					return
 				}
 				if actionResult.Error != nil {
-					gs.logger.Error(errors.E(op, actionResult.Error))
+					gs.logger.WithContext(ctx).Error(errors.E(op, actionResult.Error))
 					return
 				}
 				if actionResult.Value == models.GameUserScoreAction {
@@ -379,6 +502,8 @@ func (gs *GameService) getAllResultsReceived(ctx context.Context, playersNumber
 
 func (gs *GameService) cleanupGame(ctx context.Context, roomId uuid.UUID) error {
 	const op errors.Op = "services.GameService.cleanupGame"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	// PIPELINE start
 	tx := gs.cacheRepo.BeginPipeline()
@@ -402,3 +527,133 @@ func (gs *GameService) cleanupGame(ctx context.Context, roomId uuid.UUID) error
 
 	return nil
 }
+
+// runningGameMetric keeps the games gauge up to date with the status of a game from the start of its countdown until it is finished
+type runningGameMetric struct {
+	countdownStart time.Time
+	status         models.GameStatus
+}
+
+func newRunningGameMetric(countdownStart time.Time) *runningGameMetric {
+	metrics.Games.WithLabelValues(gameStatusLabel(models.CountdownGameStatus)).Inc()
+
+	return &runningGameMetric{countdownStart, models.CountdownGameStatus}
+}
+
+// setStatus moves the game to the status in the games gauge. Finished games are removed from the gauge.
+func (m *runningGameMetric) setStatus(status models.GameStatus) {
+	if status == m.status {
+		return
+	}
+
+	metrics.Games.WithLabelValues(gameStatusLabel(m.status)).Dec()
+	if status != models.FinishedGameStatus {
+		metrics.Games.WithLabelValues(gameStatusLabel(status)).Inc()
+	}
+	m.status = status
+}
+
+func gameStatusLabel(status models.GameStatus) string {
+	label, err := status.String()
+	if err != nil {
+		return "invalid"
+	}
+
+	return label
+}
+
+// runningGames tracks the games whose lifecycle goroutines run on this server instance, so that they can be drained or aborted on shutdown.
+// The games are identified by ids of their own and not by their rooms, so that a finished game whose cleanup is still running
+// can't remove the cancel func of the next game of the same room.
+type runningGames struct {
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	nextId  uint64
+	cancels map[uint64]context.CancelFunc
+}
+
+func newRunningGames() *runningGames {
+	return &runningGames{cancels: make(map[uint64]context.CancelFunc)}
+}
+
+// add tracks the game with the cancel func and returns the id of the running game, which is passed to done when the game is finished
+func (rg *runningGames) add(cancel context.CancelFunc) uint64 {
+	rg.mu.Lock()
+	defer rg.mu.Unlock()
+
+	rg.nextId++
+	rg.wg.Add(1)
+	rg.cancels[rg.nextId] = cancel
+
+	return rg.nextId
+}
+
+func (rg *runningGames) done(id uint64) {
+	rg.mu.Lock()
+	defer rg.mu.Unlock()
+
+	if cancel, ok := rg.cancels[id]; ok {
+		cancel()
+		delete(rg.cancels, id)
+	}
+	rg.wg.Done()
+}
+
+func (rg *runningGames) cancelAll() {
+	rg.mu.Lock()
+	defer rg.mu.Unlock()
+
+	for _, cancel := range rg.cancels {
+		cancel()
+	}
+}
+
+func (rg *runningGames) count() int {
+	rg.mu.Lock()
+	defer rg.mu.Unlock()
+
+	return len(rg.cancels)
+}
+
+// wait returns a channel that is closed when all games are done
+func (rg *runningGames) wait() <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		rg.wg.Wait()
+		close(done)
+	}()
+
+	return done
+}
+
+// RunningGamesNumber returns the number of games that run on this server instance
+func (gs *GameService) RunningGamesNumber() int {
+	return gs.runningGames.count()
+}
+
+// DrainGames waits until the running games of this server instance are finished or the context is done
+func (gs *GameService) DrainGames(ctx context.Context) error {
+	const op errors.Op = "services.GameService.DrainGames"
+
+	select {
+	case <-gs.runningGames.wait():
+		return nil
+	case <-ctx.Done():
+		return errors.E(op, ctx.Err())
+	}
+}
+
+// AbortGames cancels the running games of this server instance and waits until they have been cleaned up or the context is done.
+// The aborted games are reset in the cache like finished games, so that the players can start a new game on another server instance.
+func (gs *GameService) AbortGames(ctx context.Context) error {
+	const op errors.Op = "services.GameService.AbortGames"
+
+	gs.runningGames.cancelAll()
+
+	select {
+	case <-gs.runningGames.wait():
+		return nil
+	case <-ctx.Done():
+		return errors.E(op, ctx.Err())
+	}
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"10-typing/models"
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestGameServiceCreateNewCurrentGame(t *testing.T) {
+	tests := []struct {
+		name string
+		// setup returns the id of the text of the new game
+		setup      func(t *testing.T, ts *testServices, admin, other *models.User, roomId uuid.UUID) uuid.UUID
+		wantStatus int
+	}{
+		{
+			name: "published text",
+			setup: func(t *testing.T, ts *testServices, admin, other *models.User, roomId uuid.UUID) uuid.UUID {
+				return createTestText(t, ts, models.Text{Language: "en", Text: "the quick brown fox"}).ID
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "private text of the user",
+			setup: func(t *testing.T, ts *testServices, admin, other *models.User, roomId uuid.UUID) uuid.UUID {
+				return createTestText(t, ts, models.Text{Language: "en", Text: "the quick brown fox", UserId: &admin.ID, Visibility: models.PrivateTextVisibility}).ID
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "private text of another user",
+			setup: func(t *testing.T, ts *testServices, admin, other *models.User, roomId uuid.UUID) uuid.UUID {
+				return createTestText(t, ts, models.Text{Language: "en", Text: "the quick brown fox", UserId: &other.ID, Visibility: models.PrivateTextVisibility}).ID
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "text that does not exist",
+			setup: func(t *testing.T, ts *testServices, admin, other *models.User, roomId uuid.UUID) uuid.UUID {
+				return uuid.New()
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "room with a started game",
+			setup: func(t *testing.T, ts *testServices, admin, other *models.User, roomId uuid.UUID) uuid.UUID {
+				text := createTestText(t, ts, models.Text{Language: "en", Text: "the quick brown fox"})
+				if _, err := ts.gameService.CreateNewCurrentGame(context.Background(), admin.ID, roomId, text.ID); err != nil {
+					t.Fatalf("CreateNewCurrentGame() error = %v", err)
+				}
+				if err := ts.cacheRepo.SetCurrentGameStatus(context.Background(), nil, roomId, models.StartedGameStatus); err != nil {
+					t.Fatalf("SetCurrentGameStatus() error = %v", err)
+				}
+
+				return text.ID
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ts := newTestServices(t)
+			admin := ts.createUser(t, "admin")
+			other := ts.createUser(t, "other")
+			room := ts.createRoom(t, admin, other)
+			textId := tt.setup(t, ts, admin, other, room.ID)
+
+			gameId, err := ts.gameService.CreateNewCurrentGame(ctx, admin.ID, room.ID, textId)
+			wantStatus(t, err, tt.wantStatus)
+			if err != nil {
+				return
+			}
+
+			currentGame, err := ts.cacheRepo.GetCurrentGame(ctx, room.ID)
+			if err != nil {
+				t.Fatalf("GetCurrentGame() error = %v", err)
+			}
+			if currentGame.ID != gameId || currentGame.TextId != textId || currentGame.Status != models.UnstartedGameStatus {
+				t.Errorf("current game = %+v, want unstarted game %s of text %s", currentGame, gameId, textId)
+			}
+		})
+	}
+}
+
+func TestGameServiceAddUserToGame(t *testing.T) {
+	ctx := context.Background()
+	ts := newTestServices(t)
+	admin := ts.createUser(t, "admin")
+	other := ts.createUser(t, "other")
+	room := ts.createRoom(t, admin, other)
+	text := createTestText(t, ts, models.Text{Language: "en", Text: "the quick brown fox"})
+
+	if _, err := ts.gameService.CreateNewCurrentGame(ctx, admin.ID, room.ID, text.ID); err != nil {
+		t.Fatalf("CreateNewCurrentGame() error = %v", err)
+	}
+
+	wantStatus(t, ts.gameService.AddUserToGame(ctx, room.ID, admin.ID), http.StatusOK)
+	wantStatus(t, ts.gameService.AddUserToGame(ctx, room.ID, admin.ID), http.StatusBadRequest)
+
+	if err := ts.cacheRepo.SetCurrentGameStatus(ctx, nil, room.ID, models.StartedGameStatus); err != nil {
+		t.Fatalf("SetCurrentGameStatus() error = %v", err)
+	}
+	wantStatus(t, ts.gameService.AddUserToGame(ctx, room.ID, other.ID), http.StatusBadRequest)
+}
+
+// TestGameServiceGame plays a game of two users from its creation to the game result with the fake clock
+func TestGameServiceGame(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ts := newTestServices(t)
+	admin := ts.createUser(t, "admin")
+	other := ts.createUser(t, "other")
+	room := ts.createRoom(t, admin, other)
+	text := createTestText(t, ts, models.Text{Language: "en", Text: "the quick brown fox"})
+	startTime := ts.clock.Now()
+
+	if _, err := ts.gameService.CreateNewCurrentGame(ctx, admin.ID, room.ID, text.ID); err != nil {
+		t.Fatalf("CreateNewCurrentGame() error = %v", err)
+	}
+	for _, user := range []*models.User{admin, other} {
+		if err := ts.gameService.AddUserToGame(ctx, room.ID, user.ID); err != nil {
+			t.Fatalf("AddUserToGame() error = %v", err)
+		}
+		if err := ts.gameService.InitiateGameIfReady(ctx, room.ID); err != nil {
+			t.Fatalf("InitiateGameIfReady() error = %v", err)
+		}
+	}
+
+	// the countdown and the game each wait for a timer until the countdown is over
+	for second := 0; second < countdownDurationSeconds; second++ {
+		if err := ts.clock.BlockUntilTimers(ctx, countdownDurationSeconds*time.Second, 2); err != nil {
+			t.Fatalf("waiting for the countdown: %v", err)
+		}
+		ts.clock.Advance(time.Second)
+	}
+
+	// the game waits for its duration
+	if err := ts.clock.BlockUntilTimers(ctx, time.Duration(room.GameDurationSec)*time.Second, 1); err != nil {
+		t.Fatalf("waiting for the game: %v", err)
+	}
+	if status, _ := ts.cacheRepo.GetCurrentGameStatus(ctx, room.ID); status != models.StartedGameStatus {
+		t.Fatalf("game status = %v, want %v", status, models.StartedGameStatus)
+	}
+
+	for _, user := range []*models.User{admin, other} {
+		if err := ts.gameService.UserFinishesGame(ctx, room.ID, user.ID, text.ID, 4, 10, models.ErrorsJSON{"q": 1}); err != nil {
+			t.Fatalf("UserFinishesGame() error = %v", err)
+		}
+	}
+	ts.clock.Advance(time.Duration(room.GameDurationSec) * time.Second)
+
+	// the results are published when the scores have been received or when waiting for them times out
+	if err := ts.clock.BlockUntilTimers(ctx, waitForResultsDurationSeconds*time.Second, 1); err != nil {
+		t.Fatalf("waiting for the results: %v", err)
+	}
+	ts.clock.Advance(waitForResultsDurationSeconds * time.Second)
+
+	if err := ts.gameService.DrainGames(ctx); err != nil {
+		t.Fatalf("DrainGames() error = %v", err)
+	}
+
+	gotPushMessageTypes := ts.readPushMessageTypes(t, ctx, room.ID, startTime, "game_result")
+	wantPushMessageTypes := []string{
+		"new_game",
+		"user_started_game", "user_started_game",
+		"countdown", "countdown", "countdown", "countdown", "countdown",
+		"game_started",
+		"user_finished_game", "user_finished_game",
+		"game_result",
+	}
+	if !reflect.DeepEqual(gotPushMessageTypes, wantPushMessageTypes) {
+		t.Errorf("push messages = %v, want %v", gotPushMessageTypes, wantPushMessageTypes)
+	}
+
+	if status, _ := ts.cacheRepo.GetCurrentGameStatus(ctx, room.ID); status != models.FinishedGameStatus {
+		t.Errorf("game status = %v, want %v", status, models.FinishedGameStatus)
+	}
+	if usersNumber, _ := ts.cacheRepo.GetCurrentGameUsersNumber(ctx, room.ID); usersNumber != 0 {
+		t.Errorf("game has %d users after it has finished, want 0", usersNumber)
+	}
+}
+
+func createTestText(t *testing.T, ts *testServices, text models.Text) *models.Text {
+	t.Helper()
+
+	createdText, err := ts.dbRepo.CreateTextAndCache(context.Background(), nil, ts.cacheRepo, text)
+	if err != nil {
+		t.Fatalf("CreateTextAndCache() error = %v", err)
+	}
+
+	return createdText
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/models"
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// readinessCheckTimeout is the maximum duration of a readiness check of a dependency
+const readinessCheckTimeout = 2 * time.Second
+
+type HealthService struct {
+	dbRepo       common.DBRepository
+	cacheRepo    common.CacheRepository
+	shuttingDown atomic.Bool
+	logger       common.Logger
+}
+
+func NewHealthService(dbRepo common.DBRepository, cacheRepo common.CacheRepository, logger common.Logger) *HealthService {
+	return &HealthService{dbRepo: dbRepo, cacheRepo: cacheRepo, logger: logger}
+}
+
+// SetShuttingDown marks the server as not ready, so that the load balancer stops sending new requests to it
+func (hs *HealthService) SetShuttingDown() {
+	hs.shuttingDown.Store(true)
+}
+
+// CheckReadiness checks that postgres and redis can be reached and that the server is not shutting down.
+// The server is ready if all checks passed.
+func (hs *HealthService) CheckReadiness(ctx context.Context) models.Readiness {
+	const op errors.Op = "services.HealthService.CheckReadiness"
+
+	readiness := models.Readiness{
+		Ready:  true,
+		Checks: make(map[string]string),
+	}
+
+	check := func(name string, ping func(ctx context.Context) error) {
+		ctx, cancel := context.WithTimeout(ctx, readinessCheckTimeout)
+		defer cancel()
+
+		if err := ping(ctx); err != nil {
+			hs.logger.WithContext(ctx).Error(errors.E(op, err))
+			readiness.Ready = false
+			readiness.Checks[name] = models.FailedReadinessCheck
+			return
+		}
+		readiness.Checks[name] = models.PassedReadinessCheck
+	}
+	check("postgres", hs.dbRepo.Ping)
+	check("redis", hs.cacheRepo.Ping)
+
+	if hs.shuttingDown.Load() {
+		readiness.Ready = false
+		readiness.Checks["shutdown"] = models.FailedReadinessCheck
+	}
+
+	return readiness
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/keyboard"
+	"10-typing/models"
+	"10-typing/tracing"
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	weakestFingersNumber = 3
+	// fingers with fewer keystrokes are not considered to be weak, because their error rate is not meaningful
+	weakestFingersMinKeystrokes = 50
+)
+
+// FindUserKeyboardStats returns the errors and keystrokes of the user grouped by key, finger, hand and row of the layout.
+// If no layout name is given, the user's keyboard layout is used.
+func (ss *StatsService) FindUserKeyboardStats(ctx context.Context, userId uuid.UUID, layoutName string) (*models.KeyboardStats, error) {
+	const op errors.Op = "services.StatsService.FindUserKeyboardStats"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if layoutName == "" {
+		user, err := ss.cacheRepo.GetUserByIdInCacheOrDB(ctx, ss.dbRepo, userId)
+		if err != nil {
+			return nil, errors.E(op, err)
+		}
+		layoutName = user.KeyboardLayout
+	}
+
+	layout, ok := keyboard.GetLayout(layoutName)
+	if !ok {
+		return nil, errors.E(op, common.ErrNotFound, http.StatusNotFound, errors.Messages{"message": "unknown keyboard layout"})
+	}
+
+	keyboardStats, err := ss.cacheRepo.GetUserKeyboardStats(ctx, userId, layout.Name)
+	switch {
+	case err == nil:
+		return keyboardStats, nil
+	case !errors.Is(err, common.ErrNotFound):
+		return nil, errors.E(op, err)
+	}
+
+	typedTexts, err := ss.dbRepo.FindTypedTexts(ctx, nil, userId, models.KeyboardStatsScoresNumber)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	keyboardStats = calculateKeyboardStats(layout, typedTexts)
+
+	// the error should only be logged but not returned because the statistics have been calculated successfully
+	if err := ss.cacheRepo.SetUserKeyboardStats(ctx, nil, userId, *keyboardStats); err != nil {
+		ss.logger.WithContext(ctx).Error(errors.E(op, err))
+	}
+
+	return keyboardStats, nil
+}
+
+func calculateKeyboardStats(layout *keyboard.Layout, typedTexts []models.TypedText) *models.KeyboardStats {
+	keyZones := make(map[string]*models.ZoneStats)
+	fingerZones := make(map[keyboard.Finger]*models.ZoneStats, len(keyboard.Fingers))
+	handZones := make(map[keyboard.Hand]*models.ZoneStats)
+	rowZones := make(map[keyboard.Row]*models.ZoneStats, len(keyboard.Rows))
+	var minutes float64
+
+	addToZones := func(key string, keyPosition keyboard.KeyPosition, numberErrors, keystrokes int) {
+		for _, zoneStats := range []*models.ZoneStats{
+			getZone(keyZones, key),
+			getZone(fingerZones, keyPosition.Finger),
+			getZone(handZones, keyPosition.Hand),
+			getZone(rowZones, keyPosition.Row),
+		} {
+			zoneStats.Errors += numberErrors
+			zoneStats.Keystrokes += keystrokes
+		}
+	}
+
+	for _, typedText := range typedTexts {
+		minutes += typedText.TimeElapsed / 60
+
+		for _, char := range getTypedPart(typedText.Text, typedText.WordsTyped) {
+			if keyPosition, ok := layout.Position(string(char)); ok {
+				addToZones(string(char), keyPosition, 0, 1)
+			}
+		}
+
+		for key, numberErrors := range typedText.Errors {
+			if keyPosition, ok := layout.Position(key); ok {
+				addToZones(key, keyPosition, numberErrors, 0)
+			}
+		}
+	}
+
+	keyboardStats := models.KeyboardStats{
+		KeyboardLayout: layout.Name,
+		Scores:         len(typedTexts),
+		Keys:           make([]models.KeyStats, 0, len(keyZones)),
+		Fingers:        make([]models.FingerStats, 0, len(fingerZones)),
+		Hands:          make([]models.HandStats, 0, len(handZones)),
+		Rows:           make([]models.RowStats, 0, len(rowZones)),
+		CalculatedAt:   time.Now().UTC(),
+	}
+
+	for _, key := range layout.Keys() {
+		if zoneStats, ok := keyZones[key]; ok {
+			keyPosition, _ := layout.Position(key)
+			keyboardStats.Keys = append(keyboardStats.Keys, models.KeyStats{Key: key, KeyPosition: keyPosition, ZoneStats: finishZone(zoneStats, minutes)})
+		}
+	}
+	for _, finger := range keyboard.Fingers {
+		if zoneStats, ok := fingerZones[finger]; ok {
+			keyboardStats.Fingers = append(keyboardStats.Fingers, models.FingerStats{Finger: finger, Hand: finger.Hand(), ZoneStats: finishZone(zoneStats, minutes)})
+		}
+	}
+	for _, hand := range []keyboard.Hand{keyboard.LeftHand, keyboard.RightHand, keyboard.BothHands} {
+		if zoneStats, ok := handZones[hand]; ok {
+			keyboardStats.Hands = append(keyboardStats.Hands, models.HandStats{Hand: hand, ZoneStats: finishZone(zoneStats, minutes)})
+		}
+	}
+	for _, row := range keyboard.Rows {
+		if zoneStats, ok := rowZones[row]; ok {
+			keyboardStats.Rows = append(keyboardStats.Rows, models.RowStats{Row: row, ZoneStats: finishZone(zoneStats, minutes)})
+		}
+	}
+
+	keyboardStats.WeakestFingers = getWeakestFingers(keyboardStats.Fingers, weakestFingersNumber)
+
+	return &keyboardStats
+}
+
+// getTypedPart returns the first wordsTyped words of the text, separated by single spaces
+func getTypedPart(text string, wordsTyped int) string {
+	words := strings.Fields(text)
+	if wordsTyped < len(words) {
+		words = words[:wordsTyped]
+	}
+
+	return strings.Join(words, " ")
+}
+
+// getWeakestFingers returns the fingers with the highest error rates, excluding the thumbs
+func getWeakestFingers(fingerStats []models.FingerStats, number int) []keyboard.Finger {
+	candidates := make([]models.FingerStats, 0, len(fingerStats))
+	for _, fingerStat := range fingerStats {
+		if fingerStat.Finger != keyboard.Thumb && fingerStat.Keystrokes >= weakestFingersMinKeystrokes && fingerStat.Errors > 0 {
+			candidates = append(candidates, fingerStat)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].ErrorRate > candidates[j].ErrorRate
+	})
+
+	if len(candidates) < number {
+		number = len(candidates)
+	}
+
+	weakestFingers := make([]keyboard.Finger, 0, number)
+	for _, candidate := range candidates[:number] {
+		weakestFingers = append(weakestFingers, candidate.Finger)
+	}
+
+	return weakestFingers
+}
+
+func getZone[T comparable](zones map[T]*models.ZoneStats, zone T) *models.ZoneStats {
+	zoneStats, ok := zones[zone]
+	if !ok {
+		zoneStats = &models.ZoneStats{}
+		zones[zone] = zoneStats
+	}
+
+	return zoneStats
+}
+
+// finishZone calculates the error rate and keystrokes per minute of the zone
+func finishZone(zoneStats *models.ZoneStats, minutes float64) models.ZoneStats {
+	if zoneStats.Keystrokes > 0 {
+		zoneStats.ErrorRate = float64(zoneStats.Errors) / float64(zoneStats.Keystrokes)
+	}
+	if minutes > 0 {
+		zoneStats.KeystrokesPerMinute = float64(zoneStats.Keystrokes) / minutes
+	}
+
+	return *zoneStats
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/models"
+	"10-typing/tracing"
+	"10-typing/utils"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const rebuildLeaderboardsBatchSize = 1000
+
+type LeaderboardService struct {
+	dbRepo    common.DBRepository
+	cacheRepo common.CacheRepository
+	logger    common.Logger
+}
+
+func NewLeaderboardService(dbRepo common.DBRepository, cacheRepo common.CacheRepository, logger common.Logger) *LeaderboardService {
+	return &LeaderboardService{dbRepo, cacheRepo, logger}
+}
+
+// AddScore adds the score to all leaderboards it belongs to: every time window, combined with every language and punctuation filter.
+func (ls *LeaderboardService) AddScore(ctx context.Context, score models.Score) error {
+	const op errors.Op = "services.LeaderboardService.AddScore"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	text, err := ls.dbRepo.FindTextById(ctx, nil, score.TextId)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	leaderboardScore := models.LeaderboardScore{
+		UserId:         score.UserId,
+		WordsPerMinute: score.WordsPerMinute,
+		Language:       text.Language,
+		Punctuation:    text.Punctuation,
+		CreatedAt:      score.CreatedAt,
+	}
+	if leaderboardScore.CreatedAt.IsZero() {
+		leaderboardScore.CreatedAt = time.Now()
+	}
+
+	if err := ls.addLeaderboardScore(ctx, leaderboardScore, time.Now()); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+// FindLeaderboard returns a page of the leaderboard of the current period of the time window together with the entry of the user.
+// If the user has no entry in the leaderboard, OwnEntry is nil.
+func (ls *LeaderboardService) FindLeaderboard(
+	ctx context.Context,
+	userId uuid.UUID,
+	window models.LeaderboardWindow,
+	language string,
+	punctuation *bool,
+	limit, offset int,
+) (*models.Leaderboard, error) {
+	const op errors.Op = "services.LeaderboardService.FindLeaderboard"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	filter := models.LeaderboardFilter{
+		Window:      window,
+		Period:      window.Period(time.Now()),
+		Language:    language,
+		Punctuation: punctuation,
+	}
+
+	entries, total, err := ls.cacheRepo.GetLeaderboardEntries(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	ownEntry, err := ls.cacheRepo.GetLeaderboardEntry(ctx, filter, userId)
+	switch {
+	case errors.Is(err, common.ErrNotFound):
+		ownEntry = nil
+	case err != nil:
+		return nil, errors.E(op, err)
+	}
+
+	if err := ls.setUsernames(ctx, entries, ownEntry); err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return &models.Leaderboard{
+		Window:      window,
+		Period:      filter.Period,
+		Language:    language,
+		Punctuation: punctuation,
+		Entries:     entries,
+		Total:       total,
+		OwnEntry:    ownEntry,
+	}, nil
+}
+
+// RebuildLeaderboards deletes all leaderboards and rebuilds them from the scores in the database.
+// Only scores whose leaderboards have not expired yet are added to the time window leaderboards.
+func (ls *LeaderboardService) RebuildLeaderboards(ctx context.Context) error {
+	const op errors.Op = "services.LeaderboardService.RebuildLeaderboards"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if err := ls.cacheRepo.DeleteAllLeaderboards(ctx); err != nil {
+		return errors.E(op, err)
+	}
+
+	now := time.Now()
+
+	for offset := 0; ; offset += rebuildLeaderboardsBatchSize {
+		leaderboardScores, err := ls.dbRepo.FindLeaderboardScores(ctx, nil, time.Time{}, rebuildLeaderboardsBatchSize, offset)
+		if err != nil {
+			return errors.E(op, err)
+		}
+
+		for _, leaderboardScore := range leaderboardScores {
+			if err := ls.addLeaderboardScore(ctx, leaderboardScore, now); err != nil {
+				return errors.E(op, err)
+			}
+		}
+
+		if len(leaderboardScores) < rebuildLeaderboardsBatchSize {
+			return nil
+		}
+	}
+}
+
+func (ls *LeaderboardService) addLeaderboardScore(ctx context.Context, leaderboardScore models.LeaderboardScore, now time.Time) error {
+	const op errors.Op = "services.LeaderboardService.addLeaderboardScore"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	// PIPELINE start
+	tx := ls.cacheRepo.BeginPipeline()
+
+	for _, window := range models.LeaderboardWindows {
+		var expireAt time.Time
+		if periodEnd := window.PeriodEnd(leaderboardScore.CreatedAt); !periodEnd.IsZero() {
+			expireAt = periodEnd.Add(models.LeaderboardRetention)
+			if expireAt.Before(now) {
+				continue
+			}
+		}
+
+		for _, filter := range getLeaderboardFilters(window, leaderboardScore) {
+			if err := ls.cacheRepo.SetLeaderboardScore(ctx, tx, filter, leaderboardScore.UserId, leaderboardScore.WordsPerMinute, expireAt); err != nil {
+				err := errors.E(op, err)
+				return utils.RollbackAndErr(op, err, tx)
+			}
+		}
+	}
+
+	// PIPELINE commit
+	if err := tx.Commit(ctx); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+func (ls *LeaderboardService) setUsernames(ctx context.Context, entries []models.LeaderboardEntry, ownEntry *models.LeaderboardEntry) error {
+	const op errors.Op = "services.LeaderboardService.setUsernames"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	userIds := make([]uuid.UUID, 0, len(entries)+1)
+	for _, entry := range entries {
+		userIds = append(userIds, entry.UserId)
+	}
+	if ownEntry != nil {
+		userIds = append(userIds, ownEntry.UserId)
+	}
+	if len(userIds) == 0 {
+		return nil
+	}
+
+	users, err := ls.dbRepo.FindUsersByIds(ctx, nil, userIds)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	usernames := make(map[uuid.UUID]string, len(users))
+	for _, user := range users {
+		usernames[user.ID] = user.Username
+	}
+
+	for i := range entries {
+		entries[i].Username = usernames[entries[i].UserId]
+	}
+	if ownEntry != nil {
+		ownEntry.Username = usernames[ownEntry.UserId]
+	}
+
+	return nil
+}
+
+// getLeaderboardFilters returns the filters of all leaderboards of the time window that the score belongs to
+func getLeaderboardFilters(window models.LeaderboardWindow, leaderboardScore models.LeaderboardScore) []models.LeaderboardFilter {
+	period := window.Period(leaderboardScore.CreatedAt)
+	punctuation := leaderboardScore.Punctuation
+
+	return []models.LeaderboardFilter{
+		{Window: window, Period: period},
+		{Window: window, Period: period, Language: leaderboardScore.Language},
+		{Window: window, Period: period, Punctuation: &punctuation},
+		{Window: window, Period: period, Language: leaderboardScore.Language, Punctuation: &punctuation},
+	}
+}
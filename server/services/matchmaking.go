@@ -0,0 +1,212 @@
+package services
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/models"
+	"10-typing/tracing"
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type MatchmakingService struct {
+	dbRepo      common.DBRepository
+	cacheRepo   common.CacheRepository
+	roomService *RoomService
+	gameService *GameService
+	textService *TextService
+	clock       common.Clock
+	logger      common.Logger
+}
+
+func NewMatchmakingService(
+	dbRepo common.DBRepository,
+	cacheRepo common.CacheRepository,
+	roomService *RoomService,
+	gameService *GameService,
+	textService *TextService,
+	clock common.Clock,
+	logger common.Logger,
+) *MatchmakingService {
+	return &MatchmakingService{dbRepo, cacheRepo, roomService, gameService, textService, clock, logger}
+}
+
+// JoinQueue puts the user into the matchmaking queue for the given preferences. If enough compatible users are waiting,
+// a room with a new game is created for them right away and every matched user is notified through the user notification stream.
+func (ms *MatchmakingService) JoinQueue(ctx context.Context, user models.User, language string, punctuation bool, skillBracket string) (*models.MatchmakingStatus, error) {
+	const op errors.Op = "services.MatchmakingService.JoinQueue"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	ticket := models.MatchmakingTicket{
+		UserId:       user.ID,
+		Language:     language,
+		Punctuation:  punctuation,
+		SkillBracket: skillBracket,
+		CreatedAt:    ms.clock.Now(),
+	}
+
+	// the ticket is only created if the user has none, so that concurrent requests of the user can't put the user into several queues
+	created, err := ms.cacheRepo.CreateMatchmakingTicket(ctx, ticket)
+	switch {
+	case err != nil:
+		return nil, errors.E(op, err)
+	case !created:
+		err := fmt.Errorf("user is already in the matchmaking queue")
+		return nil, errors.E(op, err, http.StatusBadRequest)
+	}
+
+	queuedStatus := &models.MatchmakingStatus{
+		Status: "queued",
+		Ticket: &ticket,
+	}
+
+	matchedTickets, err := ms.cacheRepo.PopMatchmakingGroup(ctx, ticket, models.MatchmakingGroupSize, ms.clock.Now())
+	switch {
+	case errors.Is(err, common.ErrNotFound):
+		return queuedStatus, nil
+	case err != nil:
+		return nil, errors.E(op, err)
+	}
+
+	matchedUserIds := make([]uuid.UUID, 0, len(matchedTickets))
+	for _, matchedTicket := range matchedTickets {
+		matchedUserIds = append(matchedUserIds, matchedTicket.UserId)
+	}
+
+	room, gameId, err := ms.createMatch(ctx, matchedUserIds, ticket)
+	if err != nil {
+		ms.requeueTickets(ctx, matchedTickets, user.ID)
+		return nil, errors.E(op, err)
+	}
+
+	for _, matchedUserId := range matchedUserIds {
+		if matchedUserId == user.ID {
+			return &models.MatchmakingStatus{
+				Status: "matched",
+				RoomId: &room.ID,
+				GameId: &gameId,
+			}, nil
+		}
+	}
+
+	return queuedStatus, nil
+}
+
+func (ms *MatchmakingService) FindQueueStatus(ctx context.Context, userId uuid.UUID) (*models.MatchmakingStatus, error) {
+	const op errors.Op = "services.MatchmakingService.FindQueueStatus"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	ticket, err := ms.cacheRepo.GetMatchmakingTicket(ctx, userId)
+	switch {
+	case errors.Is(err, common.ErrNotFound):
+		return nil, errors.E(op, err, http.StatusNotFound)
+	case err != nil:
+		return nil, errors.E(op, err)
+	}
+
+	return &models.MatchmakingStatus{
+		Status: "queued",
+		Ticket: ticket,
+	}, nil
+}
+
+func (ms *MatchmakingService) LeaveQueue(ctx context.Context, userId uuid.UUID) error {
+	const op errors.Op = "services.MatchmakingService.LeaveQueue"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	ticket, err := ms.cacheRepo.GetMatchmakingTicket(ctx, userId)
+	switch {
+	case errors.Is(err, common.ErrNotFound):
+		return errors.E(op, err, http.StatusNotFound)
+	case err != nil:
+		return errors.E(op, err)
+	}
+
+	if err := ms.cacheRepo.DeleteMatchmakingTicket(ctx, *ticket); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+// requeueTickets puts the popped tickets of the other users back into the queue with their original creation time after the match could not be created,
+// so that they keep their place in the queue. The ticket of the user whose request failed is not put back, so that the user can join the queue again,
+// and users that have joined the queue again in the meantime keep their new ticket. Errors are only logged, since the match has already failed.
+func (ms *MatchmakingService) requeueTickets(ctx context.Context, tickets []models.MatchmakingTicket, failedUserId uuid.UUID) {
+	const op errors.Op = "services.MatchmakingService.requeueTickets"
+
+	for _, ticket := range tickets {
+		if ticket.UserId == failedUserId {
+			continue
+		}
+
+		if _, err := ms.cacheRepo.CreateMatchmakingTicket(ctx, ticket); err != nil {
+			ms.logger.WithContext(ctx).Error(errors.E(op, err))
+		}
+	}
+}
+
+// createMatch creates a room and a new game with a text that none of the matched users has typed yet and notifies the matched users.
+// If no such text exists, a new text is generated.
+func (ms *MatchmakingService) createMatch(ctx context.Context, userIds []uuid.UUID, ticket models.MatchmakingTicket) (*models.Room, uuid.UUID, error) {
+	const op errors.Op = "services.MatchmakingService.createMatch"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	users := make([]models.User, 0, len(userIds))
+	usernames := make([]string, 0, len(userIds))
+	for _, userId := range userIds {
+		user, err := ms.cacheRepo.GetUserByIdInCacheOrDB(ctx, ms.dbRepo, userId)
+		if err != nil {
+			return nil, uuid.Nil, errors.E(op, err)
+		}
+
+		users = append(users, *user)
+		usernames = append(usernames, user.Username)
+	}
+
+	text, err := ms.dbRepo.FindNewTextForUsers(ctx, nil, userIds, ticket.Language, ticket.Punctuation)
+	switch {
+	case errors.Is(err, common.ErrNotFound):
+		text, err = ms.textService.Create(ctx, ticket.Language, "", ticket.Punctuation, 0, 0)
+		if err != nil {
+			return nil, uuid.Nil, errors.E(op, err)
+		}
+	case err != nil:
+		return nil, uuid.Nil, errors.E(op, err)
+	}
+
+	room, err := ms.roomService.CreateMatchmakingRoom(ctx, users)
+	if err != nil {
+		return nil, uuid.Nil, errors.E(op, err)
+	}
+
+	gameId, err := ms.gameService.CreateNewCurrentGame(ctx, room.AdminId, room.ID, text.ID)
+	if err != nil {
+		return nil, uuid.Nil, errors.E(op, err)
+	}
+
+	for _, userId := range userIds {
+		userNotification := models.UserNotification{
+			Type: models.MatchFound,
+			Payload: map[string]any{
+				"roomId":  room.ID,
+				"gameId":  gameId,
+				"textId":  text.ID,
+				"players": usernames,
+			},
+		}
+
+		if err := ms.cacheRepo.PublishUserNotification(ctx, nil, userId, userNotification); err != nil {
+			return nil, uuid.Nil, errors.E(op, err)
+		}
+	}
+
+	return room, gameId, nil
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/models"
+	"10-typing/rating"
+	"10-typing/tracing"
+	"10-typing/utils"
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+type RatingService struct {
+	dbRepo    common.DBRepository
+	cacheRepo common.CacheRepository
+	logger    common.Logger
+}
+
+func NewRatingService(dbRepo common.DBRepository, cacheRepo common.CacheRepository, logger common.Logger) *RatingService {
+	return &RatingService{dbRepo, cacheRepo, logger}
+}
+
+// UpdateRatingsFromGameScores computes the new ratings of all users that took part in a game and stores them together with the rating history.
+// Users are placed by their words per minute; users that have no score are placed last together.
+// Games with less than two participants are not rated.
+func (rs *RatingService) UpdateRatingsFromGameScores(ctx context.Context, gameId uuid.UUID, gameUserIds []uuid.UUID, scores []models.Score) ([]models.RatingChange, error) {
+	const op errors.Op = "services.RatingService.UpdateRatingsFromGameScores"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if len(gameUserIds) < 2 {
+		return nil, nil
+	}
+
+	// TRANSACTION start
+	tx := rs.dbRepo.BeginTx()
+
+	// the users are locked until the commit, so that the ratings of concurrent games are computed from the committed ratings
+	users, err := rs.dbRepo.FindUsersByIdsForUpdate(ctx, tx, gameUserIds)
+	switch {
+	case err != nil:
+		err := errors.E(op, err)
+		return nil, utils.RollbackAndErr(op, err, tx)
+	case len(users) < 2:
+		if err := tx.Rollback(); err != nil {
+			return nil, errors.E(op, err)
+		}
+		return nil, nil
+	}
+
+	placements := getPlacements(users, scores)
+
+	ratings := make([]float64, 0, len(users))
+	for _, user := range users {
+		ratings = append(ratings, user.Rating)
+	}
+	newRatings := rating.Elo(ratings, placements)
+
+	ratingChanges := make([]models.RatingChange, 0, len(users))
+	for i := range users {
+		ratingChanges = append(ratingChanges, models.RatingChange{
+			UserId:       users[i].ID,
+			GameId:       gameId,
+			RatingBefore: users[i].Rating,
+			RatingAfter:  newRatings[i],
+			Placement:    placements[i],
+			Participants: len(users),
+		})
+
+		users[i].Rating = newRatings[i]
+		users[i].RatedGames++
+	}
+
+	if err := rs.dbRepo.UpdateRatings(ctx, tx, users, ratingChanges); err != nil {
+		err := errors.E(op, err)
+		return nil, utils.RollbackAndErr(op, err, tx)
+	}
+
+	// TRANSACTION commit
+	if err := tx.Commit(ctx); err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	// the errors should only be logged but not returned because the ratings are already saved in the DB
+	for _, user := range users {
+		if err := rs.cacheUserRating(ctx, user); err != nil {
+			rs.logger.WithContext(ctx).Error(errors.E(op, err))
+		}
+	}
+
+	return ratingChanges, nil
+}
+
+// cacheUserRating updates the rating of the user if the user is cached
+func (rs *RatingService) cacheUserRating(ctx context.Context, user models.User) error {
+	const op errors.Op = "services.RatingService.cacheUserRating"
+
+	userKeyExists, err := rs.cacheRepo.UserExists(ctx, user.ID)
+	switch {
+	case err != nil:
+		return errors.E(op, err)
+	case !userKeyExists:
+		return nil
+	}
+
+	if err := rs.cacheRepo.SetUserRating(ctx, nil, user.ID, user.Rating, user.RatedGames); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+func (rs *RatingService) FindRatingLeaderboard(ctx context.Context, limit, offset int) ([]models.RatingLeaderboardEntry, int64, error) {
+	const op errors.Op = "services.RatingService.FindRatingLeaderboard"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	entries, total, err := rs.dbRepo.FindRatingLeaderboard(ctx, nil, limit, offset)
+	if err != nil {
+		return nil, 0, errors.E(op, err)
+	}
+
+	return entries, total, nil
+}
+
+func (rs *RatingService) FindRatingHistory(ctx context.Context, userId uuid.UUID, limit int) ([]models.RatingChange, error) {
+	const op errors.Op = "services.RatingService.FindRatingHistory"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	ratingChanges, err := rs.dbRepo.FindRatingChanges(ctx, nil, userId, limit)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return ratingChanges, nil
+}
+
+// getPlacements returns the placement of every user, starting with 1 for the fastest user.
+// Users with equal words per minute share the same placement.
+func getPlacements(users []models.User, scores []models.Score) []int {
+	wordsPerMinuteByUserId := make(map[uuid.UUID]float64, len(scores))
+	for _, score := range scores {
+		if score.TimeElapsed <= 0 {
+			continue
+		}
+		wordsPerMinuteByUserId[score.UserId] = float64(score.WordsTyped) * 60.0 / score.TimeElapsed
+	}
+
+	// users without a score get -1 words per minute so that they are placed last
+	wordsPerMinute := make([]float64, len(users))
+	for i, user := range users {
+		wpm, ok := wordsPerMinuteByUserId[user.ID]
+		if !ok {
+			wpm = -1
+		}
+		wordsPerMinute[i] = wpm
+	}
+
+	order := make([]int, len(users))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return wordsPerMinute[order[a]] > wordsPerMinute[order[b]]
+	})
+
+	placements := make([]int, len(users))
+	for rank, i := range order {
+		if rank > 0 && wordsPerMinute[i] == wordsPerMinute[order[rank-1]] {
+			placements[i] = placements[order[rank-1]]
+			continue
+		}
+		placements[i] = rank + 1
+	}
+
+	return placements
+}
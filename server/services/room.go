@@ -4,12 +4,13 @@ import (
 	"10-typing/common"
 	"10-typing/errors"
 	"10-typing/models"
+	"10-typing/tracing"
+	"10-typing/utils"
 
 	"context"
 	"fmt"
 
 	"net/http"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -20,25 +21,36 @@ type RoomService struct {
 	dbRepo               common.DBRepository
 	cacheRepo            common.CacheRepository
 	emailTransactionRepo common.EmailTransactionRepository
-	logger               common.Logger
+	clock                common.Clock
+	connections          *roomConnections
+	// wsOriginPatterns are the hosts of the origins that may connect to the WebSockets of the rooms besides the host of the API, e.g. localhost:3000
+	wsOriginPatterns []string
+	logger           common.Logger
 }
 
 func NewRoomService(
 	dbRepo common.DBRepository,
 	cacheRepo common.CacheRepository,
 	emailTransactionRepo common.EmailTransactionRepository,
+	clock common.Clock,
+	wsOriginPatterns []string,
 	logger common.Logger,
 ) *RoomService {
 	return &RoomService{
 		dbRepo,
 		cacheRepo,
 		emailTransactionRepo,
+		clock,
+		newRoomConnections(),
+		wsOriginPatterns,
 		logger,
 	}
 }
 
 func (rs *RoomService) CreateRoom(ctx context.Context, userIds []uuid.UUID, emails []string, gameDurationSec int, authenticatedUser models.User) (*models.Room, error) {
 	const op errors.Op = "services.RoomService.CreateRoom"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	// validate
 	if (len(userIds) == 0) && (len(emails) == 0) {
@@ -149,8 +161,112 @@ func (rs *RoomService) CreateRoom(ctx context.Context, userIds []uuid.UUID, emai
 	return room, nil
 }
 
+// CreateMatchmakingRoom creates a room for users that have been matched by the matchmaking queue.
+// The user that has been waiting the longest (first user) becomes the admin. No invitations are sent.
+func (rs *RoomService) CreateMatchmakingRoom(ctx context.Context, users []models.User) (*models.Room, error) {
+	const op errors.Op = "services.RoomService.CreateMatchmakingRoom"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if len(users) < 2 {
+		err := fmt.Errorf("a matchmaking room needs at least two users")
+		return nil, errors.E(op, err)
+	}
+
+	userIds := make([]uuid.UUID, 0, len(users))
+	for _, user := range users {
+		userIds = append(userIds, user.ID)
+	}
+
+	tx := rs.dbRepo.BeginTx()
+
+	room, err := rs.createRoomWithSubscribers(ctx, tx, userIds, nil, users[0].ID, 0)
+	if err != nil {
+		err := errors.E(op, err)
+		return nil, utils.RollbackAndErr(op, err, tx)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return room, nil
+}
+
+func (rs *RoomService) FindRoomsByUser(ctx context.Context, userId uuid.UUID, sortOptions []models.SortOption, limit, offset int) ([]models.RoomOverview, int64, error) {
+	const op errors.Op = "services.RoomService.FindRoomsByUser"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	roomOverviews, total, err := rs.dbRepo.FindRoomsByUser(ctx, nil, userId, sortOptions, limit, offset)
+	if err != nil {
+		return nil, 0, errors.E(op, err)
+	}
+
+	for i := range roomOverviews {
+		activeSubscriberCount, err := rs.cacheRepo.GetActiveRoomSubscribersNumber(ctx, roomOverviews[i].ID)
+		if err != nil {
+			return nil, 0, errors.E(op, err)
+		}
+
+		roomOverviews[i].ActiveSubscriberCount = activeSubscriberCount
+	}
+
+	return roomOverviews, total, nil
+}
+
+// FindRoomWithMembers returns the room with all its members. The members' online and game state is taken from the cache.
+func (rs *RoomService) FindRoomWithMembers(ctx context.Context, roomId uuid.UUID) (*models.RoomWithMembers, error) {
+	const op errors.Op = "services.RoomService.FindRoomWithMembers"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	room, err := rs.dbRepo.FindRoomWithUsers(ctx, nil, roomId)
+	switch {
+	case errors.Is(err, common.ErrNotFound):
+		return nil, errors.E(op, err, http.StatusNotFound)
+	case err != nil:
+		return nil, errors.E(op, err)
+	}
+
+	roomSubscribers, err := rs.cacheRepo.GetRoomSubscribers(ctx, roomId)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	roomSubscribersByUserId := make(map[uuid.UUID]models.RoomSubscriber, len(roomSubscribers))
+	for _, roomSubscriber := range roomSubscribers {
+		roomSubscribersByUserId[roomSubscriber.UserId] = roomSubscriber
+	}
+
+	members := make([]models.RoomMember, 0, len(room.Users))
+	for _, user := range room.Users {
+		member := models.RoomMember{
+			UserId:     user.ID,
+			Username:   user.Username,
+			IsAdmin:    user.ID == room.AdminId,
+			Status:     models.InactiveSubscriberStatus,
+			GameStatus: models.UnstartedSubscriberGameStatus,
+		}
+
+		if roomSubscriber, ok := roomSubscribersByUserId[user.ID]; ok {
+			member.Status = roomSubscriber.Status
+			member.GameStatus = roomSubscriber.GameStatus
+		}
+
+		members = append(members, member)
+	}
+
+	return &models.RoomWithMembers{
+		Room:    *room,
+		Members: members,
+	}, nil
+}
+
 func (rs *RoomService) DeleteRoom(ctx context.Context, roomId uuid.UUID) error {
 	const op errors.Op = "services.RoomService.DeleteRoom"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	if err := rs.dbRepo.SoftDeleteRoom(ctx, nil, roomId); err != nil {
 		return errors.E(op, err)
@@ -163,8 +279,69 @@ func (rs *RoomService) DeleteRoom(ctx context.Context, roomId uuid.UUID) error {
 	return nil
 }
 
+// InspectRoom returns the room with its members, the current game and the scores of the current game
+func (rs *RoomService) InspectRoom(ctx context.Context, roomId uuid.UUID) (*models.RoomInspection, error) {
+	const op errors.Op = "services.RoomService.InspectRoom"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	roomWithMembers, err := rs.FindRoomWithMembers(ctx, roomId)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	currentGame, err := rs.cacheRepo.GetCurrentGame(ctx, roomId)
+	switch {
+	case errors.Is(err, common.ErrNotFound):
+		currentGame = nil
+	case err != nil:
+		return nil, errors.E(op, err)
+	}
+
+	currentGameScores, err := rs.cacheRepo.GetCurrentGameScores(ctx, roomId)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return &models.RoomInspection{
+		RoomWithMembers:   *roomWithMembers,
+		CurrentGame:       currentGame,
+		CurrentGameScores: currentGameScores,
+	}, nil
+}
+
+// TerminateRoom disconnects all members of the room on all server instances and deletes the room, e.g. when a moderator closes an abusive room
+func (rs *RoomService) TerminateRoom(ctx context.Context, roomId uuid.UUID) error {
+	const op errors.Op = "services.RoomService.TerminateRoom"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	_, err := rs.dbRepo.FindRoom(ctx, nil, roomId)
+	switch {
+	case errors.Is(err, common.ErrNotFound):
+		return errors.E(op, err, http.StatusNotFound)
+	case err != nil:
+		return errors.E(op, err)
+	}
+
+	// first need to send terminate action message so that all websocket that remained connected, disconnect
+	if err := rs.cacheRepo.PublishAction(ctx, nil, roomId, models.TerminateAction); err != nil {
+		return errors.E(op, err)
+	}
+
+	if err := rs.DeleteRoom(ctx, roomId); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+// LeaveRoom removes the user from the room. When the admin leaves, the admin rights are transferred to another room member first,
+// preferably one that is currently connected. Only when no other member remains, the room is terminated and deleted.
 func (rs *RoomService) LeaveRoom(ctx context.Context, roomId, userId uuid.UUID) error {
 	const op errors.Op = "services.RoomService.LeaveRoom"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	isAdmin, err := rs.cacheRepo.RoomHasAdmin(ctx, roomId, userId)
 	if err != nil {
@@ -172,30 +349,297 @@ func (rs *RoomService) LeaveRoom(ctx context.Context, roomId, userId uuid.UUID)
 	}
 
 	if isAdmin {
-		// first need to send terminate action message so that all websocket that remained connected, disconnect
-		if err := rs.cacheRepo.PublishAction(ctx, nil, roomId, models.TerminateAction); err != nil {
+		successorId, err := rs.findSuccessorAdmin(ctx, roomId, userId)
+		switch {
+		case errors.Is(err, common.ErrNotFound):
+			// first need to send terminate action message so that all websocket that remained connected, disconnect
+			if err := rs.cacheRepo.PublishAction(ctx, nil, roomId, models.TerminateAction); err != nil {
+				return errors.E(op, err)
+			}
+
+			if err := rs.DeleteRoom(ctx, roomId); err != nil {
+				return errors.E(op, err)
+			}
+
+			return nil
+		case err != nil:
 			return errors.E(op, err)
 		}
 
-		if err := rs.DeleteRoom(ctx, roomId); err != nil {
+		if err := rs.TransferRoomAdmin(ctx, roomId, successorId); err != nil {
 			return errors.E(op, err)
 		}
+	}
 
-		return nil
+	if err = rs.removeRoomMember(ctx, roomId, userId); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+// InviteToRoom adds registered users (by user id or email) to an existing room and sends invitations to emails of non registered users.
+func (rs *RoomService) InviteToRoom(ctx context.Context, roomId uuid.UUID, userIds []uuid.UUID, emails []string, authenticatedUser models.User) error {
+	const op errors.Op = "services.RoomService.InviteToRoom"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	// validate
+	if (len(userIds) == 0) && (len(emails) == 0) {
+		err := fmt.Errorf("at least one user id or email must be specified")
+		return errors.E(op, err, http.StatusBadRequest)
+	}
+
+	var newUsers []models.User
+	var newEmails []string
+	// a user may be invited several times, e.g. by id and by email, but is only added once
+	isNewUser := make(map[uuid.UUID]bool)
+	isNewEmail := make(map[string]bool)
+
+	for _, userId := range userIds {
+		user, err := rs.cacheRepo.GetUserByIdInCacheOrDB(ctx, rs.dbRepo, userId)
+		switch {
+		case errors.Is(err, common.ErrNotFound):
+			err := fmt.Errorf("user with id %s does not exist", userId)
+			return errors.E(op, err, http.StatusBadRequest)
+		case err != nil:
+			return errors.E(op, err)
+		}
+
+		if !isNewUser[user.ID] {
+			isNewUser[user.ID] = true
+			newUsers = append(newUsers, *user)
+		}
+	}
+
+	for _, email := range emails {
+		user, err := rs.cacheRepo.GetUserByEmailInCacheOrDB(ctx, rs.dbRepo, email)
+		switch {
+		case errors.Is(err, common.ErrNotFound):
+			if !isNewEmail[email] {
+				isNewEmail[email] = true
+				newEmails = append(newEmails, email)
+			}
+			continue
+		case err != nil:
+			return errors.E(op, err)
+		}
+
+		if !isNewUser[user.ID] {
+			isNewUser[user.ID] = true
+			newUsers = append(newUsers, *user)
+		}
+	}
+
+	// the members are checked and added in the same transaction, so that concurrent invitations can't add a member twice
+	tx := rs.dbRepo.BeginTx()
+
+	memberIds, err := rs.dbRepo.FindRoomMemberIdsForUpdate(ctx, tx, roomId)
+	switch {
+	case errors.Is(err, common.ErrNotFound):
+		return utils.RollbackAndErr(op, errors.E(op, err, http.StatusNotFound), tx)
+	case err != nil:
+		return utils.RollbackAndErr(op, errors.E(op, err), tx)
+	}
+
+	isMember := make(map[uuid.UUID]bool, len(memberIds))
+	for _, memberId := range memberIds {
+		isMember[memberId] = true
+	}
+
+	for _, newUser := range newUsers {
+		if isMember[newUser.ID] {
+			err := fmt.Errorf("user %s is already a member of the room", newUser.Username)
+			return utils.RollbackAndErr(op, errors.E(op, err, http.StatusBadRequest), tx)
+		}
+	}
+
+	// add room subscribers
+	for _, newUser := range newUsers {
+		if err := rs.dbRepo.CreateUserRoom(ctx, tx, newUser.ID, roomId); err != nil {
+			err := errors.E(op, err)
+			return utils.RollbackAndErr(op, err, tx)
+		}
 	}
 
-	if err = rs.cacheRepo.DeleteRoomSubscriber(ctx, roomId, userId); err != nil {
+	if err := tx.Commit(ctx); err != nil {
+		return errors.E(op, err)
+	}
+
+	for _, newUser := range newUsers {
+		if err := rs.cacheRepo.SetRoomSubscriber(ctx, nil, roomId, newUser); err != nil {
+			return errors.E(op, err)
+		}
+
+		userInvitedPushMessage := models.PushMessage{
+			Type: models.UserInvited,
+			Payload: map[string]any{
+				"userId":   newUser.ID,
+				"username": newUser.Username,
+			},
+		}
+		if err := rs.cacheRepo.PublishPushMessage(ctx, nil, roomId, userInvitedPushMessage); err != nil {
+			return errors.E(op, err)
+		}
+
+		userNotification := models.UserNotification{
+			Type: models.RoomInvitation,
+			Payload: map[string]any{
+				"by":     authenticatedUser.Username,
+				"roomId": roomId,
+			},
+		}
+		if err := rs.cacheRepo.PublishUserNotification(ctx, nil, newUser.ID, userNotification); err != nil {
+			return errors.E(op, err)
+		}
+
+		if err := rs.emailTransactionRepo.InviteUserToRoom(newUser.Email, newUser.Username); err != nil {
+			return errors.E(op, err)
+		}
+	}
+
+	// create tokens and send invites to non registered users
+	for _, email := range newEmails {
+		token, err := rs.dbRepo.CreateToken(ctx, nil, roomId)
+		if err != nil {
+			return errors.E(op, err)
+		}
+
+		if err = rs.emailTransactionRepo.InviteNewUserToRoom(email, token.ID); err != nil {
+			return errors.E(op, err)
+		}
+	}
+
+	return nil
+}
+
+// RemoveRoomMember removes a member from the room. The user_removed push message terminates all WebSocket connections of the removed member.
+func (rs *RoomService) RemoveRoomMember(ctx context.Context, roomId, userId uuid.UUID, authenticatedUser models.User) error {
+	const op errors.Op = "services.RoomService.RemoveRoomMember"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	// validate
+	if userId == authenticatedUser.ID {
+		err := fmt.Errorf("the admin cannot remove themselves from the room")
+		return errors.E(op, err, http.StatusBadRequest, errors.Messages{"message": "you cannot remove yourself, leave the room instead"})
+	}
+
+	isRoomMember, err := rs.cacheRepo.RoomHasSubscribers(ctx, roomId, userId)
+	switch {
+	case err != nil:
+		return errors.E(op, err)
+	case !isRoomMember:
+		err := fmt.Errorf("user with id %s is not a member of room with id %s", userId, roomId)
+		return errors.E(op, err, http.StatusNotFound)
+	}
+
+	if err := rs.removeRoomMember(ctx, roomId, userId); err != nil {
+		return errors.E(op, err)
+	}
+
+	userRemovedPushMessage := models.PushMessage{
+		Type:    models.UserRemoved,
+		Payload: userId,
+	}
+	if err := rs.cacheRepo.PublishPushMessage(ctx, nil, roomId, userRemovedPushMessage); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+// TransferRoomAdmin makes the room member identified by newAdminId the admin of the room.
+func (rs *RoomService) TransferRoomAdmin(ctx context.Context, roomId, newAdminId uuid.UUID) error {
+	const op errors.Op = "services.RoomService.TransferRoomAdmin"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	// validate
+	isAdmin, err := rs.cacheRepo.RoomHasAdmin(ctx, roomId, newAdminId)
+	switch {
+	case err != nil:
+		return errors.E(op, err)
+	case isAdmin:
+		err := fmt.Errorf("user with id %s is already the admin of room with id %s", newAdminId, roomId)
+		return errors.E(op, err, http.StatusBadRequest)
+	}
+
+	isRoomMember, err := rs.cacheRepo.RoomHasSubscribers(ctx, roomId, newAdminId)
+	switch {
+	case err != nil:
+		return errors.E(op, err)
+	case !isRoomMember:
+		err := fmt.Errorf("user with id %s is not a member of room with id %s", newAdminId, roomId)
+		return errors.E(op, err, http.StatusBadRequest)
+	}
+
+	if err := rs.dbRepo.UpdateRoomAdmin(ctx, nil, roomId, newAdminId); err != nil {
+		return errors.E(op, err)
+	}
+
+	if err := rs.cacheRepo.SetRoomAdmin(ctx, nil, roomId, newAdminId); err != nil {
+		return errors.E(op, err)
+	}
+
+	adminChangedPushMessage := models.PushMessage{
+		Type:    models.AdminChanged,
+		Payload: newAdminId,
+	}
+	if err := rs.cacheRepo.PublishPushMessage(ctx, nil, roomId, adminChangedPushMessage); err != nil {
 		return errors.E(op, err)
 	}
 
 	return nil
 }
 
+// UpdateRoomSettings updates every room setting that is not nil and announces the settings of the room to the room.
+// A new game duration applies to the games that are created afterwards.
+func (rs *RoomService) UpdateRoomSettings(ctx context.Context, roomId uuid.UUID, settings models.RoomSettings) (*models.Room, error) {
+	const op errors.Op = "services.RoomService.UpdateRoomSettings"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if settings.IsEmpty() {
+		err := fmt.Errorf("no room setting to update")
+		return nil, errors.E(op, err, http.StatusBadRequest, errors.Messages{"message": "At least one setting must be changed"})
+	}
+
+	if settings.GameDurationSec != nil {
+		if err := rs.dbRepo.UpdateRoomGameDurationSec(ctx, nil, roomId, *settings.GameDurationSec); err != nil {
+			return nil, errors.E(op, err)
+		}
+
+		if err := rs.cacheRepo.SetRoomGameDurationSec(ctx, nil, roomId, *settings.GameDurationSec); err != nil {
+			return nil, errors.E(op, err)
+		}
+	}
+
+	room, err := rs.cacheRepo.GetRoomInCacheOrDb(ctx, rs.dbRepo, roomId)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	roomSettingsChangedPushMessage := models.PushMessage{
+		Type: models.RoomSettingsChanged,
+		Payload: map[string]any{
+			"gameDurationSec": room.GameDurationSec,
+		},
+	}
+	if err := rs.cacheRepo.PublishPushMessage(ctx, nil, roomId, roomSettingsChangedPushMessage); err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return room, nil
+}
+
 // RoomConnect reads from connection and handles incoming ping and cursor messages.
 // It gets initial_state data and sends it as message to client.
 // It subscribes to room redis stream and sends messages to client.
 func (rs *RoomService) RoomConnect(ctx context.Context, c *gin.Context, roomId uuid.UUID, user *models.User) error {
 	const op errors.Op = "services.RoomService.RoomConnect"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	room, err := rs.cacheRepo.GetRoomInCacheOrDb(ctx, rs.dbRepo, roomId)
 	switch {
@@ -206,7 +650,7 @@ func (rs *RoomService) RoomConnect(ctx context.Context, c *gin.Context, roomId u
 	}
 
 	conn, err := websocket.Accept(c.Writer, c.Request, &websocket.AcceptOptions{
-		OriginPatterns: []string{"*"},
+		OriginPatterns: rs.wsOriginPatterns,
 	})
 	if err != nil {
 		return errors.E(op, err, http.StatusBadRequest)
@@ -214,15 +658,17 @@ func (rs *RoomService) RoomConnect(ctx context.Context, c *gin.Context, roomId u
 
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
-	roomSubscription := newRoomSubscription(conn, room.ID, user.ID, rs.cacheRepo, rs.logger)
+	roomSubscription := newRoomSubscription(conn, room.ID, user.ID, rs.cacheRepo, rs.clock, rs.logger.WithContext(ctx))
+	rs.connections.add(roomSubscription)
+	defer rs.connections.remove(roomSubscription)
 	defer roomSubscription.close(ctx)
 
-	timeStamp := time.Now()
+	timeStamp := rs.clock.Now()
 	errCh := make(chan error)
 
 	go func() {
 		err := roomSubscription.handleMessages(ctx)
-		rs.logger.Error(errors.E(op, err))
+		roomSubscription.logger.Error(errors.E(op, err))
 
 		select {
 		case errCh <- err:
@@ -234,7 +680,7 @@ func (rs *RoomService) RoomConnect(ctx context.Context, c *gin.Context, roomId u
 		err := roomSubscription.handleRoomSubscriberStatus(ctx)
 
 		if err != nil {
-			rs.logger.Error(errors.E(op, err))
+			roomSubscription.logger.Error(errors.E(op, err))
 
 			select {
 			case errCh <- err:
@@ -247,7 +693,7 @@ func (rs *RoomService) RoomConnect(ctx context.Context, c *gin.Context, roomId u
 		err := roomSubscription.sendInitialState(ctx, *room)
 
 		if err != nil {
-			rs.logger.Error(errors.E(op, err))
+			roomSubscription.logger.Error(errors.E(op, err))
 
 			select {
 			case errCh <- err:
@@ -258,7 +704,7 @@ func (rs *RoomService) RoomConnect(ctx context.Context, c *gin.Context, roomId u
 
 	go func() {
 		err := roomSubscription.subscribe(ctx, timeStamp)
-		rs.logger.Error(errors.E(op, err))
+		roomSubscription.logger.Error(errors.E(op, err))
 
 		select {
 		case errCh <- err:
@@ -273,6 +719,8 @@ func (rs *RoomService) RoomConnect(ctx context.Context, c *gin.Context, roomId u
 
 func (rs *RoomService) createRoomWithSubscribers(ctx context.Context, tx common.Transaction, userIds []uuid.UUID, emails []string, adminId uuid.UUID, gameDurationSec int) (*models.Room, error) {
 	const op errors.Op = "services.RoomService.createRoomWithSubscribers"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	newRoom := models.Room{
 		AdminId: adminId,
@@ -304,3 +752,71 @@ func (rs *RoomService) createRoomWithSubscribers(ctx context.Context, tx common.
 
 	return createdRoom, nil
 }
+
+func (rs *RoomService) removeRoomMember(ctx context.Context, roomId, userId uuid.UUID) error {
+	const op errors.Op = "services.RoomService.removeRoomMember"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if err := rs.dbRepo.DeleteUserRoom(ctx, nil, userId, roomId); err != nil {
+		return errors.E(op, err)
+	}
+
+	if err := rs.cacheRepo.DeleteRoomSubscriber(ctx, roomId, userId); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+// findSuccessorAdmin returns the id of a room member other than the current admin, preferring members that are currently connected.
+// It returns common.ErrNotFound if the admin is the only member of the room.
+func (rs *RoomService) findSuccessorAdmin(ctx context.Context, roomId, adminId uuid.UUID) (uuid.UUID, error) {
+	const op errors.Op = "services.RoomService.findSuccessorAdmin"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	roomSubscribers, err := rs.cacheRepo.GetRoomSubscribers(ctx, roomId)
+	if err != nil {
+		return uuid.Nil, errors.E(op, err)
+	}
+
+	successorId := uuid.Nil
+	for _, roomSubscriber := range roomSubscribers {
+		if roomSubscriber.UserId == adminId {
+			continue
+		}
+
+		if roomSubscriber.Status == models.ActiveSubscriberStatus {
+			return roomSubscriber.UserId, nil
+		}
+
+		if successorId == uuid.Nil {
+			successorId = roomSubscriber.UserId
+		}
+	}
+
+	if successorId == uuid.Nil {
+		return uuid.Nil, errors.E(op, common.ErrNotFound)
+	}
+
+	return successorId, nil
+}
+
+// ConnectionsNumber returns the number of WebSocket connections to the rooms on this server instance
+func (rs *RoomService) ConnectionsNumber() int {
+	return rs.connections.count()
+}
+
+// CloseConnections closes the WebSocket connections of this server instance with the reason, e.g. on shutdown,
+// and waits until the room subscribers have been removed or the context is done
+func (rs *RoomService) CloseConnections(ctx context.Context, reason string) error {
+	const op errors.Op = "services.RoomService.CloseConnections"
+
+	select {
+	case <-rs.connections.closeAll(websocket.StatusServiceRestart, reason):
+		return nil
+	case <-ctx.Done():
+		return errors.E(op, ctx.Err())
+	}
+}
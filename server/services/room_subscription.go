@@ -3,11 +3,14 @@ package services
 import (
 	"10-typing/common"
 	"10-typing/errors"
+	"10-typing/metrics"
 	"10-typing/models"
+	"10-typing/tracing"
 	"10-typing/utils"
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 
 	"time"
 
@@ -21,6 +24,11 @@ const (
 	observeRoomSubscriberStatusIntervalSeconds    = 4
 )
 
+// roomSubscriptionMessageRateLimit limits the messages of a connection, which are mostly cursor updates on every keystroke
+var roomSubscriptionMessageRateLimit = models.RateLimit{Limit: 50, Per: time.Second}
+
+var errRoomSubscriberRemoved = errors.New("room subscriber has been removed from room")
+
 type Message struct {
 	Type    string `json:"type"`
 	Payload any    `json:"payload"`
@@ -62,18 +70,91 @@ type CursorPayload struct {
 	Position int `json:"position"`
 }
 
+// roomConnections tracks the open WebSocket connections of this server instance by room for the metrics and to close them on shutdown
+type roomConnections struct {
+	mu     sync.Mutex
+	wg     sync.WaitGroup
+	byRoom map[uuid.UUID]map[*roomSubscription]struct{}
+}
+
+func newRoomConnections() *roomConnections {
+	return &roomConnections{byRoom: make(map[uuid.UUID]map[*roomSubscription]struct{})}
+}
+
+func (rc *roomConnections) add(subscription *roomSubscription) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if rc.byRoom[subscription.roomId] == nil {
+		rc.byRoom[subscription.roomId] = make(map[*roomSubscription]struct{})
+	}
+	rc.byRoom[subscription.roomId][subscription] = struct{}{}
+	rc.wg.Add(1)
+
+	metrics.WebSocketConnections.Inc()
+	metrics.ActiveRooms.Set(float64(len(rc.byRoom)))
+}
+
+func (rc *roomConnections) remove(subscription *roomSubscription) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	delete(rc.byRoom[subscription.roomId], subscription)
+	if len(rc.byRoom[subscription.roomId]) == 0 {
+		delete(rc.byRoom, subscription.roomId)
+	}
+	rc.wg.Done()
+
+	metrics.WebSocketConnections.Dec()
+	metrics.ActiveRooms.Set(float64(len(rc.byRoom)))
+}
+
+func (rc *roomConnections) count() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	var connectionsNumber int
+	for _, subscriptions := range rc.byRoom {
+		connectionsNumber += len(subscriptions)
+	}
+
+	return connectionsNumber
+}
+
+// closeAll sends a close frame with the status and reason to all connections and returns a channel that is closed when all connections have been removed
+func (rc *roomConnections) closeAll(status websocket.StatusCode, reason string) <-chan struct{} {
+	rc.mu.Lock()
+	for _, subscriptions := range rc.byRoom {
+		for subscription := range subscriptions {
+			// Close blocks until the client answers the close frame, which must not block closing the other connections
+			go subscription.conn.Close(status, reason)
+		}
+	}
+	rc.mu.Unlock()
+
+	removed := make(chan struct{})
+	go func() {
+		rc.wg.Wait()
+		close(removed)
+	}()
+
+	return removed
+}
+
 type roomSubscription struct {
 	connectionId uuid.UUID
 	roomId       uuid.UUID
 	userId       uuid.UUID
 	conn         *websocket.Conn
 	cacheRepo    common.CacheRepository
+	clock        common.Clock
 	logger       common.Logger
 }
 
 func newRoomSubscription(
 	conn *websocket.Conn, roomId, userId uuid.UUID,
 	cacheRepo common.CacheRepository,
+	clock common.Clock,
 	logger common.Logger,
 ) *roomSubscription {
 	roomSubscriptionConnectionId := uuid.New()
@@ -84,20 +165,25 @@ func newRoomSubscription(
 		userId:       userId,
 		conn:         conn,
 		cacheRepo:    cacheRepo,
-		logger:       logger,
+		clock:        clock,
+		// the room id and the user id are already log fields of the request context
+		logger: logger.With(common.LogFields{common.ConnectionIdLogField: roomSubscriptionConnectionId}),
 	}
 }
 
 func (rs *roomSubscription) sendInitialState(ctx context.Context, room models.Room) error {
 	const op errors.Op = "services.roomSubscription.sendInitialState"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	roomSubscribers, err := rs.cacheRepo.GetRoomSubscribers(ctx, room.ID)
 	if err != nil {
 		return errors.E(op, err)
 	}
 
+	// a room has no current game until the first game is created
 	currentGame, err := rs.cacheRepo.GetCurrentGame(ctx, room.ID)
-	if err != nil {
+	if err != nil && !errors.Is(err, common.ErrNotFound) {
 		return errors.E(op, err)
 	}
 
@@ -123,7 +209,9 @@ func (rs *roomSubscription) sendInitialState(ctx context.Context, room models.Ro
 	initialState.GameDurationSec = room.GameDurationSec
 	initialState.Subscribers = roomSubscribers
 	initialState.CurrentGame = currentGame
-	initialState.CurrentGame.GameSubscribers = currentGameUserIds
+	if currentGame != nil {
+		initialState.CurrentGame.GameSubscribers = currentGameUserIds
+	}
 	initialState.CurrentGameScores = currentGameScores
 
 	initialMessage := &models.PushMessage{
@@ -141,16 +229,26 @@ func (rs *roomSubscription) sendInitialState(ctx context.Context, room models.Ro
 // reads from WS connection and handles incoming ping and cursor messages.
 func (rs *roomSubscription) handleMessages(ctx context.Context) error {
 	const op errors.Op = "services.roomSubscription.handleMessages"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
-	execute, cleanup := utils.Throttle(400 * time.Millisecond)
+	execute, cleanup := utils.Throttle(rs.clock, 400*time.Millisecond)
 	defer cleanup()
 
+	var messageBucket models.TokenBucket
+
 	for {
 		messageType, message, err := rs.conn.Read(ctx)
 		if err != nil {
 			return errors.E(op, err)
 		}
 
+		if result := messageBucket.Take(roomSubscriptionMessageRateLimit, rs.clock.Now()); !result.Allowed {
+			rs.conn.Close(websocket.StatusPolicyViolation, "too many messages")
+			err := fmt.Errorf("connection exceeded the rate limit of %d messages per %s", roomSubscriptionMessageRateLimit.Limit, roomSubscriptionMessageRateLimit.Per)
+			return errors.E(op, err)
+		}
+
 		if messageType == websocket.MessageText {
 			var msg Message
 			if err := json.Unmarshal(message, &msg); err != nil {
@@ -203,6 +301,8 @@ func (rs *roomSubscription) handleMessages(ctx context.Context) error {
 
 func (rs *roomSubscription) handleRoomSubscriberStatus(ctx context.Context) error {
 	const op errors.Op = "services.roomSubscription.handleRoomSubscriberStatus"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	// TODO: it must be clearer what the following code is doing
 	roomSubscriberStatusHasBeenUpdated, err := rs.cacheRepo.SetRoomSubscriberConnection(ctx, rs.roomId, rs.userId, rs.connectionId)
@@ -211,7 +311,7 @@ func (rs *roomSubscription) handleRoomSubscriberStatus(ctx context.Context) erro
 	}
 
 	if roomSubscriberStatusHasBeenUpdated {
-		go observeRoomSubscriberStatus(context.Background(), rs.cacheRepo, rs.roomId, rs.userId, rs.logger)
+		go observeRoomSubscriberStatus(context.Background(), rs.cacheRepo, rs.clock, rs.roomId, rs.userId, rs.logger)
 
 		if err := rs.cacheRepo.PublishPushMessage(ctx, nil, rs.roomId, models.PushMessage{
 			Type:    models.UserJoined,
@@ -226,6 +326,8 @@ func (rs *roomSubscription) handleRoomSubscriberStatus(ctx context.Context) erro
 
 func (rs *roomSubscription) close(ctx context.Context) error {
 	const op errors.Op = "services.roomSubscription.close"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	roomSubscriberStatusHasBeenUpdated, err := rs.cacheRepo.DeleteRoomSubscriberConnection(ctx, rs.roomId, rs.userId, rs.connectionId)
 	if err != nil {
@@ -251,6 +353,8 @@ func (rs *roomSubscription) close(ctx context.Context) error {
 
 func (rs *roomSubscription) subscribe(ctx context.Context, startTimestamp time.Time) error {
 	const op errors.Op = "services.roomSubscription.subscribe"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	pushMessageResultCh := rs.cacheRepo.GetPushMessages(ctx, rs.roomId, startTimestamp)
 
@@ -272,10 +376,32 @@ func (rs *roomSubscription) subscribe(ctx context.Context, startTimestamp time.T
 			if err := rs.conn.Write(ctx, websocket.MessageText, pushMessageResult.Value); err != nil {
 				return errors.E(op, err)
 			}
+
+			if rs.isRemovedBy(pushMessageResult.Value) {
+				if err := rs.conn.Close(websocket.StatusNormalClosure, "removed from room"); err != nil {
+					return errors.E(op, err)
+				}
+
+				return errors.E(op, errRoomSubscriberRemoved)
+			}
 		}
 	}
 }
 
+// isRemovedBy reports whether the push message removes the user of this room subscription from the room.
+func (rs *roomSubscription) isRemovedBy(pushMessageData []byte) bool {
+	var pushMessage struct {
+		Type    models.PushMessageType `json:"type"`
+		Payload uuid.UUID              `json:"payload"`
+	}
+
+	if err := json.Unmarshal(pushMessageData, &pushMessage); err != nil {
+		return false
+	}
+
+	return pushMessage.Type == models.UserRemoved && pushMessage.Payload == rs.userId
+}
+
 func (rs *roomSubscription) writeTimeout(ctx context.Context, timeout time.Duration, msg []byte) error {
 	const op errors.Op = "services.roomSubscription.writeTimeout"
 
@@ -289,12 +415,12 @@ func (rs *roomSubscription) writeTimeout(ctx context.Context, timeout time.Durat
 	return nil
 }
 
-func observeRoomSubscriberStatus(ctx context.Context, cacheRepo common.CacheRepository, roomId, userId uuid.UUID, logger common.Logger) {
+func observeRoomSubscriberStatus(ctx context.Context, cacheRepo common.CacheRepository, clock common.Clock, roomId, userId uuid.UUID, logger common.Logger) {
 	const op errors.Op = "services.observeRoomSubscriberStatus"
 
 	ctx, cancel := context.WithCancel(ctx)
-	t := time.NewTicker(observeRoomSubscriberStatusIntervalSeconds * time.Second)
-	maxT := time.NewTimer(observeRoomSubscriberStatusMaxDurationMinutes * time.Second)
+	t := clock.NewTicker(observeRoomSubscriberStatusIntervalSeconds * time.Second)
+	maxT := clock.NewTimer(observeRoomSubscriberStatusMaxDurationMinutes * time.Second)
 	defer maxT.Stop()
 	defer t.Stop()
 	defer cancel()
@@ -325,20 +451,24 @@ func observeRoomSubscriberStatus(ctx context.Context, cacheRepo common.CacheRepo
 				logger.Error(errors.E(op, err))
 				return
 			}
+			// only the payload of user_left messages is a user id, the other messages must not stop the observation
+			if message.Type != models.UserLeft {
+				continue
+			}
 			messagePayloadStr, ok := message.Payload.(string)
 			if !ok {
 				err := fmt.Errorf("payload is not a string")
 				logger.Error(errors.E(op, err))
 				return
 			}
-			if message.Type == models.UserLeft && messagePayloadStr == userId.String() {
+			if messagePayloadStr == userId.String() {
 				logger.Info("stop roomSubscriber checker after receiving user_left message")
 				return
 			}
 		case <-ctx.Done():
 			logger.Error(errors.E(op, ctx.Err()))
 			return
-		case <-t.C:
+		case <-t.C():
 			numberRoomSubscriberConns, roomSubscriberStatusHasBeenUpdated, err := cacheRepo.GetRoomSubscriberStatus(ctx, roomId, userId)
 			if err != nil {
 				logger.Error(errors.E(op, err))
@@ -362,7 +492,7 @@ func observeRoomSubscriberStatus(ctx context.Context, cacheRepo common.CacheRepo
 				logger.Info("stop roomSubscriber checker numberRoomSubscriberConns == 0")
 				return
 			}
-		case <-maxT.C:
+		case <-maxT.C():
 			logger.Info("max time to update roomSubscriberStatus reached")
 			return
 		}
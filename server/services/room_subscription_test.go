@@ -0,0 +1,143 @@
+package services
+
+import (
+	"10-typing/models"
+	"10-typing/zerologger"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"nhooyr.io/websocket"
+	"nhooyr.io/websocket/wsjson"
+)
+
+func TestRoomSubscriptionSendInitialState(t *testing.T) {
+	tests := []struct {
+		name                string
+		setup               func(t *testing.T, ts *testServices, admin *models.User, roomId uuid.UUID)
+		wantCurrentGame     bool
+		wantGameSubscribers int
+	}{
+		{
+			name:  "room without game",
+			setup: func(t *testing.T, ts *testServices, admin *models.User, roomId uuid.UUID) {},
+		},
+		{
+			name: "room with game",
+			setup: func(t *testing.T, ts *testServices, admin *models.User, roomId uuid.UUID) {
+				text := createTestText(t, ts, models.Text{Language: "en", Text: "the quick brown fox"})
+				if _, err := ts.gameService.CreateNewCurrentGame(context.Background(), admin.ID, roomId, text.ID); err != nil {
+					t.Fatalf("CreateNewCurrentGame() error = %v", err)
+				}
+				if err := ts.gameService.AddUserToGame(context.Background(), roomId, admin.ID); err != nil {
+					t.Fatalf("AddUserToGame() error = %v", err)
+				}
+			},
+			wantCurrentGame:     true,
+			wantGameSubscribers: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			ts := newTestServices(t)
+			admin := ts.createUser(t, "admin")
+			member := ts.createUser(t, "member")
+			room := ts.createRoom(t, admin, member)
+			tt.setup(t, ts, admin, room.ID)
+
+			sendErr := make(chan error, 1)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				conn, err := websocket.Accept(w, r, nil)
+				if err != nil {
+					sendErr <- err
+					return
+				}
+				defer conn.Close(websocket.StatusNormalClosure, "")
+
+				roomSubscription := newRoomSubscription(conn, room.ID, admin.ID, ts.cacheRepo, ts.clock, zerologger.New(io.Discard))
+				sendErr <- roomSubscription.sendInitialState(r.Context(), *room)
+			}))
+			defer server.Close()
+
+			conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(server.URL, "http"), nil)
+			if err != nil {
+				t.Fatalf("Dial() error = %v", err)
+			}
+			defer conn.Close(websocket.StatusNormalClosure, "")
+
+			var initialState struct {
+				Type    string `json:"type"`
+				Payload struct {
+					AdminId         uuid.UUID         `json:"adminId"`
+					GameDurationSec int               `json:"gameDurationSec"`
+					Subscribers     []json.RawMessage `json:"roomSubscribers"`
+					CurrentGame     *struct {
+						GameSubscribers []uuid.UUID `json:"gameSubscribers"`
+					} `json:"currentGame"`
+				} `json:"payload"`
+			}
+			if err := wsjson.Read(ctx, conn, &initialState); err != nil {
+				t.Fatalf("reading the initial state error = %v", err)
+			}
+			if err := <-sendErr; err != nil {
+				t.Fatalf("sendInitialState() error = %v", err)
+			}
+
+			if initialState.Type != "initial_state" {
+				t.Errorf("message type = %s, want initial_state", initialState.Type)
+			}
+			if initialState.Payload.AdminId != admin.ID || initialState.Payload.GameDurationSec != room.GameDurationSec {
+				t.Errorf("initial state = %+v, want admin %s and game duration %d", initialState.Payload, admin.ID, room.GameDurationSec)
+			}
+			if len(initialState.Payload.Subscribers) != 2 {
+				t.Errorf("initial state has %d room subscribers, want 2", len(initialState.Payload.Subscribers))
+			}
+			if gotCurrentGame := initialState.Payload.CurrentGame != nil; gotCurrentGame != tt.wantCurrentGame {
+				t.Fatalf("initial state has current game = %v, want %v", gotCurrentGame, tt.wantCurrentGame)
+			}
+			if tt.wantCurrentGame && len(initialState.Payload.CurrentGame.GameSubscribers) != tt.wantGameSubscribers {
+				t.Errorf("current game has %d subscribers, want %d", len(initialState.Payload.CurrentGame.GameSubscribers), tt.wantGameSubscribers)
+			}
+		})
+	}
+}
+
+func TestRoomSubscriptionIsRemovedBy(t *testing.T) {
+	userId := uuid.New()
+
+	tests := []struct {
+		name        string
+		pushMessage models.PushMessage
+		want        bool
+	}{
+		{name: "removal of the user", pushMessage: models.PushMessage{Type: models.UserRemoved, Payload: userId}, want: true},
+		{name: "removal of another user", pushMessage: models.PushMessage{Type: models.UserRemoved, Payload: uuid.New()}, want: false},
+		{name: "other push message of the user", pushMessage: models.PushMessage{Type: models.UserFinishedGame, Payload: userId}, want: false},
+		{name: "push message without user", pushMessage: models.PushMessage{Type: models.GameStarted}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roomSubscription := &roomSubscription{userId: userId}
+
+			pushMessageData, err := json.Marshal(tt.pushMessage)
+			if err != nil {
+				t.Fatalf("marshal push message error = %v", err)
+			}
+
+			if got := roomSubscription.isRemovedBy(pushMessageData); got != tt.want {
+				t.Errorf("isRemovedBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
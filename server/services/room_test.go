@@ -0,0 +1,258 @@
+package services
+
+import (
+	"10-typing/models"
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestRoomServiceCreateRoom(t *testing.T) {
+	tests := []struct {
+		name       string
+		userIds    func(admin, other *models.User) []uuid.UUID
+		emails     func(admin, other *models.User) []string
+		wantStatus int
+	}{
+		{
+			name:       "room with another user",
+			userIds:    func(admin, other *models.User) []uuid.UUID { return []uuid.UUID{other.ID} },
+			emails:     func(admin, other *models.User) []string { return nil },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "room without other users",
+			userIds:    func(admin, other *models.User) []uuid.UUID { return nil },
+			emails:     func(admin, other *models.User) []string { return nil },
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "room with the id of the admin",
+			userIds:    func(admin, other *models.User) []uuid.UUID { return []uuid.UUID{admin.ID} },
+			emails:     func(admin, other *models.User) []string { return nil },
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "room with the email of the admin",
+			userIds:    func(admin, other *models.User) []uuid.UUID { return nil },
+			emails:     func(admin, other *models.User) []string { return []string{admin.Email} },
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ts := newTestServices(t)
+			admin := ts.createUser(t, "admin")
+			other := ts.createUser(t, "other")
+
+			room, err := ts.roomService.CreateRoom(ctx, tt.userIds(admin, other), tt.emails(admin, other), 30, *admin)
+			wantStatus(t, err, tt.wantStatus)
+			if err != nil {
+				return
+			}
+
+			for _, userId := range []uuid.UUID{admin.ID, other.ID} {
+				if isRoomMember, _ := ts.cacheRepo.RoomHasSubscribers(ctx, room.ID, userId); !isRoomMember {
+					t.Errorf("user %s is not a room subscriber", userId)
+				}
+			}
+			if isAdmin, _ := ts.cacheRepo.RoomHasAdmin(ctx, room.ID, admin.ID); !isAdmin {
+				t.Errorf("user %s is not the admin of the room", admin.ID)
+			}
+		})
+	}
+}
+
+func TestRoomServiceInviteToRoom(t *testing.T) {
+	tests := []struct {
+		name       string
+		invite     func(member, invited *models.User) []uuid.UUID
+		wantStatus int
+	}{
+		{
+			name:       "new member",
+			invite:     func(member, invited *models.User) []uuid.UUID { return []uuid.UUID{invited.ID} },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "new member twice",
+			invite:     func(member, invited *models.User) []uuid.UUID { return []uuid.UUID{invited.ID, invited.ID} },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "existing member",
+			invite:     func(member, invited *models.User) []uuid.UUID { return []uuid.UUID{invited.ID, member.ID} },
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "user that does not exist",
+			invite:     func(member, invited *models.User) []uuid.UUID { return []uuid.UUID{uuid.New()} },
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "nobody",
+			invite:     func(member, invited *models.User) []uuid.UUID { return nil },
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ts := newTestServices(t)
+			admin := ts.createUser(t, "admin")
+			member := ts.createUser(t, "member")
+			invited := ts.createUser(t, "invited")
+			room := ts.createRoom(t, admin, member)
+
+			err := ts.roomService.InviteToRoom(ctx, room.ID, tt.invite(member, invited), nil, *admin)
+			wantStatus(t, err, tt.wantStatus)
+
+			// the invited user is added in the same transaction in which the members are checked, so a failed invitation adds nobody
+			wantMembers := []uuid.UUID{admin.ID, member.ID}
+			if err == nil {
+				wantMembers = append(wantMembers, invited.ID)
+			}
+			gotMembers, err := ts.dbRepo.FindRoomMemberIdsForUpdate(ctx, nil, room.ID)
+			if err != nil {
+				t.Fatalf("FindRoomMemberIdsForUpdate() error = %v", err)
+			}
+			if !sameIds(gotMembers, wantMembers) {
+				t.Errorf("room members = %v, want %v", gotMembers, wantMembers)
+			}
+		})
+	}
+}
+
+func TestRoomServiceLeaveRoom(t *testing.T) {
+	ctx := context.Background()
+	ts := newTestServices(t)
+	admin := ts.createUser(t, "admin")
+	member := ts.createUser(t, "member")
+	room := ts.createRoom(t, admin, member)
+
+	// the admin rights are transferred to the remaining member
+	if err := ts.roomService.LeaveRoom(ctx, room.ID, admin.ID); err != nil {
+		t.Fatalf("LeaveRoom() of the admin error = %v", err)
+	}
+	if isAdmin, _ := ts.cacheRepo.RoomHasAdmin(ctx, room.ID, member.ID); !isAdmin {
+		t.Errorf("the remaining member is not the admin of the room")
+	}
+	if isRoomMember, _ := ts.cacheRepo.RoomHasSubscribers(ctx, room.ID, admin.ID); isRoomMember {
+		t.Errorf("the admin is still a member of the room after leaving it")
+	}
+
+	// the room is deleted when its last member leaves
+	if err := ts.roomService.LeaveRoom(ctx, room.ID, member.ID); err != nil {
+		t.Fatalf("LeaveRoom() of the last member error = %v", err)
+	}
+	if exists, _ := ts.cacheRepo.RoomExists(ctx, room.ID); exists {
+		t.Errorf("room exists after its last member has left")
+	}
+	if _, err := ts.dbRepo.FindRoom(ctx, nil, room.ID); err == nil {
+		t.Errorf("room is in the db after its last member has left")
+	}
+}
+
+func TestRoomServiceRemoveRoomMember(t *testing.T) {
+	tests := []struct {
+		name       string
+		userId     func(admin, member *models.User) uuid.UUID
+		wantStatus int
+	}{
+		{
+			name:       "member",
+			userId:     func(admin, member *models.User) uuid.UUID { return member.ID },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "admin",
+			userId:     func(admin, member *models.User) uuid.UUID { return admin.ID },
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "user that is not a member",
+			userId:     func(admin, member *models.User) uuid.UUID { return uuid.New() },
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ts := newTestServices(t)
+			admin := ts.createUser(t, "admin")
+			member := ts.createUser(t, "member")
+			room := ts.createRoom(t, admin, member)
+			startTime := ts.clock.Now()
+			userId := tt.userId(admin, member)
+
+			err := ts.roomService.RemoveRoomMember(ctx, room.ID, userId, *admin)
+			wantStatus(t, err, tt.wantStatus)
+			if err != nil {
+				return
+			}
+
+			if isRoomMember, _ := ts.cacheRepo.RoomHasSubscribers(ctx, room.ID, userId); isRoomMember {
+				t.Errorf("user is still a member of the room")
+			}
+			if got := ts.readPushMessageTypes(t, ctx, room.ID, startTime, "user_removed"); !reflect.DeepEqual(got, []string{"user_removed"}) {
+				t.Errorf("push messages = %v, want [user_removed]", got)
+			}
+		})
+	}
+}
+
+func TestRoomServiceUpdateRoomSettings(t *testing.T) {
+	ctx := context.Background()
+	ts := newTestServices(t)
+	admin := ts.createUser(t, "admin")
+	member := ts.createUser(t, "member")
+	room := ts.createRoom(t, admin, member)
+	startTime := ts.clock.Now()
+
+	_, err := ts.roomService.UpdateRoomSettings(ctx, room.ID, models.RoomSettings{})
+	wantStatus(t, err, http.StatusBadRequest)
+
+	gameDurationSec := 60
+	updatedRoom, err := ts.roomService.UpdateRoomSettings(ctx, room.ID, models.RoomSettings{GameDurationSec: &gameDurationSec})
+	if err != nil {
+		t.Fatalf("UpdateRoomSettings() error = %v", err)
+	}
+	if updatedRoom.GameDurationSec != 60 {
+		t.Errorf("game duration = %d, want 60", updatedRoom.GameDurationSec)
+	}
+	if gameDurationSec, _ := ts.cacheRepo.GetRoomGameDurationSec(ctx, room.ID); gameDurationSec != 60 {
+		t.Errorf("game duration in the cache = %d, want 60", gameDurationSec)
+	}
+	if got := ts.readPushMessageTypes(t, ctx, room.ID, startTime, "room_settings_changed"); !reflect.DeepEqual(got, []string{"room_settings_changed"}) {
+		t.Errorf("push messages = %v, want [room_settings_changed]", got)
+	}
+}
+
+// sameIds reports whether the ids contain the same ids in any order
+func sameIds(ids, otherIds []uuid.UUID) bool {
+	if len(ids) != len(otherIds) {
+		return false
+	}
+
+	count := make(map[uuid.UUID]int, len(ids))
+	for _, id := range ids {
+		count[id]++
+	}
+	for _, id := range otherIds {
+		count[id]--
+	}
+	for _, n := range count {
+		if n != 0 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -3,19 +3,25 @@ package services
 import (
 	"10-typing/common"
 	"10-typing/errors"
+	"10-typing/metrics"
 	"10-typing/models"
+	"10-typing/tracing"
 	"context"
+	"fmt"
+	"net/http"
 
 	"github.com/google/uuid"
 )
 
 type ScoreService struct {
-	dbRepo common.DBRepository
-	logger common.Logger
+	dbRepo             common.DBRepository
+	cacheRepo          common.CacheRepository
+	leaderboardService *LeaderboardService
+	logger             common.Logger
 }
 
-func NewScoreService(dbRepo common.DBRepository, logger common.Logger) *ScoreService {
-	return &ScoreService{dbRepo, logger}
+func NewScoreService(dbRepo common.DBRepository, cacheRepo common.CacheRepository, leaderboardService *LeaderboardService, logger common.Logger) *ScoreService {
+	return &ScoreService{dbRepo, cacheRepo, leaderboardService, logger}
 }
 
 func (ss *ScoreService) Create(
@@ -26,6 +32,8 @@ func (ss *ScoreService) Create(
 	errorsJSON models.ErrorsJSON,
 ) (*models.Score, error) {
 	const op errors.Op = "services.ScoreService.Create"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	numberErrors := 0
 	for _, value := range errorsJSON {
@@ -46,22 +54,82 @@ func (ss *ScoreService) Create(
 	if err != nil {
 		return nil, errors.E(op, err)
 	}
+	metrics.ScoresSubmitted.WithLabelValues("single").Inc()
+
+	// the error should only be logged but not returned because the score is already saved in the DB
+	if err := ss.leaderboardService.AddScore(ctx, *createdScore); err != nil {
+		ss.logger.WithContext(ctx).Error(errors.E(op, err))
+	}
+	if err := ss.cacheRepo.DeleteUserStats(ctx, nil, userId); err != nil {
+		ss.logger.WithContext(ctx).Error(errors.E(op, err))
+	}
 
 	return createdScore, nil
 }
 
+// FindScores returns a page of at most limit scores that match the filter together with the cursors of the next and previous page.
+// A cursor is empty if there is no next or previous page.
 func (ss *ScoreService) FindScores(
 	ctx context.Context,
-	userId, gameId uuid.UUID,
-	username string,
-	sortOptions []models.SortOption,
-) ([]models.Score, error) {
+	filter models.ScoreFilter,
+	sortOption models.SortOption,
+	encodedCursor string,
+	limit int,
+) (*models.ScorePage, error) {
 	const op errors.Op = "services.ScoreService.FindScores"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
-	scores, err := ss.dbRepo.FindScores(ctx, nil, userId, gameId, username, sortOptions)
+	var cursor *models.ScoreCursor
+	if encodedCursor != "" {
+		var err error
+		cursor, err = models.DecodeScoreCursor(encodedCursor)
+		if err != nil {
+			return nil, errors.E(op, err, http.StatusBadRequest, errors.Messages{"message": "invalid cursor"})
+		}
+		if cursor.Column != sortOption.Column {
+			err := fmt.Errorf("cursor has been created for a different sort column")
+			return nil, errors.E(op, err, http.StatusBadRequest, errors.Messages{"message": "cursor does not match sort_by"})
+		}
+	}
+
+	// one additional score is searched to find out if there is another page
+	scores, err := ss.dbRepo.FindScores(ctx, nil, filter, sortOption, cursor, limit+1)
 	if err != nil {
 		return nil, errors.E(op, err)
 	}
 
-	return scores, nil
+	hasMore := len(scores) > limit
+	isPrevPage := cursor != nil && cursor.Direction == models.PrevCursorDirection
+	if hasMore {
+		if isPrevPage {
+			// the previous page is returned in sort order, so the additional score is the first one
+			scores = scores[1:]
+		} else {
+			scores = scores[:limit]
+		}
+	}
+
+	scorePage := models.ScorePage{Scores: scores}
+	if len(scores) == 0 {
+		return &scorePage, nil
+	}
+
+	hasNextPage := isPrevPage || hasMore
+	hasPrevPage := (isPrevPage && hasMore) || (!isPrevPage && cursor != nil)
+
+	if hasNextPage {
+		scorePage.NextCursor, err = models.NewScoreCursor(scores[len(scores)-1], sortOption.Column, models.NextCursorDirection).Encode()
+		if err != nil {
+			return nil, errors.E(op, err)
+		}
+	}
+	if hasPrevPage {
+		scorePage.PrevCursor, err = models.NewScoreCursor(scores[0], sortOption.Column, models.PrevCursorDirection).Encode()
+		if err != nil {
+			return nil, errors.E(op, err)
+		}
+	}
+
+	return &scorePage, nil
 }
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"10-typing/clock"
+	"10-typing/errors"
+	"10-typing/models"
+	memory_cache_repo "10-typing/repositories/memory_cache"
+	memory_db_repo "10-typing/repositories/memory_db"
+	"10-typing/zerologger"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// testServices holds the services of the tests, which share the in-memory repositories and a fake clock
+type testServices struct {
+	clock       *clock.FakeClock
+	dbRepo      *memory_db_repo.MemoryDBRepository
+	cacheRepo   *memory_cache_repo.MemoryCacheRepository
+	gameService *GameService
+	roomService *RoomService
+}
+
+func newTestServices(t *testing.T) *testServices {
+	t.Helper()
+
+	logger := zerologger.New(io.Discard)
+	fakeClock := clock.NewFake(time.Now())
+	dbRepo := memory_db_repo.NewMemoryDBRepository(fakeClock)
+	cacheRepo := memory_cache_repo.NewMemoryCacheRepository(fakeClock)
+
+	ratingService := NewRatingService(dbRepo, cacheRepo, logger)
+	leaderboardService := NewLeaderboardService(dbRepo, cacheRepo, logger)
+
+	return &testServices{
+		clock:       fakeClock,
+		dbRepo:      dbRepo,
+		cacheRepo:   cacheRepo,
+		gameService: NewGameService(dbRepo, cacheRepo, ratingService, leaderboardService, fakeClock, logger),
+		roomService: NewRoomService(dbRepo, cacheRepo, &testEmailTransactionRepository{}, fakeClock, []string{"localhost:3000"}, logger),
+	}
+}
+
+func (ts *testServices) createUser(t *testing.T, username string) *models.User {
+	t.Helper()
+
+	user, err := ts.dbRepo.CreateUserAndCache(context.Background(), nil, ts.cacheRepo, models.User{
+		Username: username,
+		Email:    username + "@example.com",
+		Password: "secret123",
+	})
+	if err != nil {
+		t.Fatalf("CreateUserAndCache() error = %v", err)
+	}
+
+	return user
+}
+
+// createRoom creates a room of the admin with the other users as members
+func (ts *testServices) createRoom(t *testing.T, admin *models.User, users ...*models.User) *models.Room {
+	t.Helper()
+
+	var userIds []uuid.UUID
+	for _, user := range users {
+		userIds = append(userIds, user.ID)
+	}
+
+	room, err := ts.roomService.CreateRoom(context.Background(), userIds, nil, 30, *admin)
+	if err != nil {
+		t.Fatalf("CreateRoom() error = %v", err)
+	}
+
+	return room
+}
+
+// readPushMessageTypes reads the types of the push messages of the room from the start time until the message of the last type has been read
+func (ts *testServices) readPushMessageTypes(t *testing.T, ctx context.Context, roomId uuid.UUID, startTime time.Time, lastType string) []string {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var pushMessageTypes []string
+	for result := range ts.cacheRepo.GetPushMessages(ctx, roomId, startTime) {
+		if result.Error != nil {
+			t.Fatalf("GetPushMessages() error = %v", result.Error)
+		}
+
+		var pushMessage struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(result.Value, &pushMessage); err != nil {
+			t.Fatalf("unmarshal push message error = %v", err)
+		}
+
+		pushMessageTypes = append(pushMessageTypes, pushMessage.Type)
+		if pushMessage.Type == lastType {
+			return pushMessageTypes
+		}
+	}
+
+	t.Fatalf("push messages ended before %s, got %v", lastType, pushMessageTypes)
+	return nil
+}
+
+// wantStatus fails the test if the error doesn't have the status or if there is no error but a status other than 200 is wanted
+func wantStatus(t *testing.T, err error, status int) {
+	t.Helper()
+
+	if status == http.StatusOK {
+		if err != nil {
+			t.Fatalf("error = %v, want no error", err)
+		}
+		return
+	}
+
+	var e *errors.Error
+	if !errors.As(err, &e) {
+		t.Fatalf("error = %v, want status %d", err, status)
+	}
+	if e.Status() != status {
+		t.Fatalf("error status = %d, want %d (error = %v)", e.Status(), status, err)
+	}
+}
+
+type testEmailTransactionRepository struct{}
+
+func (r *testEmailTransactionRepository) InviteNewUserToRoom(email string, token uuid.UUID) error {
+	return nil
+}
+
+func (r *testEmailTransactionRepository) InviteUserToRoom(email, username string) error {
+	return nil
+}
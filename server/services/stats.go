@@ -0,0 +1,193 @@
+package services
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/keyboard"
+	"10-typing/models"
+	"10-typing/tracing"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	statsTrendDays  = 30
+	statsTrendWeeks = 26
+	statsKeysNumber = 5
+	hoursPerDay     = 24
+)
+
+type StatsService struct {
+	dbRepo    common.DBRepository
+	cacheRepo common.CacheRepository
+	logger    common.Logger
+}
+
+func NewStatsService(dbRepo common.DBRepository, cacheRepo common.CacheRepository, logger common.Logger) *StatsService {
+	return &StatsService{dbRepo, cacheRepo, logger}
+}
+
+// FindUserStats returns the statistics of the user. The statistics are cached for models.UserStatsTTLSeconds
+// and the cache is invalidated when the user creates a new score.
+func (ss *StatsService) FindUserStats(ctx context.Context, userId uuid.UUID, interval models.StatsInterval) (*models.UserStats, error) {
+	const op errors.Op = "services.StatsService.FindUserStats"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	userStats, err := ss.cacheRepo.GetUserStats(ctx, userId, interval)
+	switch {
+	case err == nil:
+		return userStats, nil
+	case !errors.Is(err, common.ErrNotFound):
+		return nil, errors.E(op, err)
+	}
+
+	userStats, err = ss.calculateUserStats(ctx, userId, interval)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	// the error should only be logged but not returned because the statistics have been calculated successfully
+	if err := ss.cacheRepo.SetUserStats(ctx, nil, userId, *userStats); err != nil {
+		ss.logger.WithContext(ctx).Error(errors.E(op, err))
+	}
+
+	return userStats, nil
+}
+
+func (ss *StatsService) calculateUserStats(ctx context.Context, userId uuid.UUID, interval models.StatsInterval) (*models.UserStats, error) {
+	const op errors.Op = "services.StatsService.calculateUserStats"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	now := time.Now().UTC()
+
+	user, err := ss.cacheRepo.GetUserByIdInCacheOrDB(ctx, ss.dbRepo, userId)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	scoreTotals, err := ss.dbRepo.FindScoreTotals(ctx, nil, userId)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	startOfToday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	since := startOfToday.AddDate(0, 0, -statsTrendDays+1)
+	if interval == models.WeekStatsInterval {
+		daysSinceMonday := (int(now.Weekday()) + 6) % 7
+		since = startOfToday.AddDate(0, 0, -daysSinceMonday-7*(statsTrendWeeks-1))
+	}
+
+	scoreTrend, err := ss.dbRepo.FindScoreTrend(ctx, nil, userId, interval, since)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	keyErrorCounts, err := ss.dbRepo.FindKeyErrors(ctx, nil, userId)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	scoreDays, err := ss.dbRepo.FindScoreDays(ctx, nil, userId)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	scoreTotalsByLanguage, err := ss.dbRepo.FindScoreTotalsByLanguage(ctx, nil, userId)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	scoreTotalsByPunctuation, err := ss.dbRepo.FindScoreTotalsByPunctuation(ctx, nil, userId)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	layout, ok := keyboard.GetLayout(user.KeyboardLayout)
+	if !ok {
+		layout, _ = keyboard.GetLayout(keyboard.DefaultLayoutName)
+	}
+
+	keyErrors := make(map[string]int, len(keyErrorCounts))
+	fingerErrors := make(map[keyboard.Finger]int, len(keyboard.Fingers))
+	for _, keyErrorCount := range keyErrorCounts {
+		keyErrors[keyErrorCount.Key] = keyErrorCount.Errors
+
+		// keys that cannot be typed with the user's layout can not be assigned to a finger
+		if keyPosition, ok := layout.Position(keyErrorCount.Key); ok {
+			fingerErrors[keyPosition.Finger] += keyErrorCount.Errors
+		}
+	}
+
+	currentStreak, longestStreak := getStreaks(scoreDays, startOfToday)
+
+	return &models.UserStats{
+		Totals:         *scoreTotals,
+		Interval:       interval,
+		Trend:          scoreTrend,
+		KeyErrors:      keyErrors,
+		KeyboardLayout: layout.Name,
+		FingerErrors:   fingerErrors,
+		BestKeys:       getBestKeys(keyErrorCounts, statsKeysNumber),
+		WorstKeys:      getWorstKeys(keyErrorCounts, statsKeysNumber),
+		CurrentStreak:  currentStreak,
+		LongestStreak:  longestStreak,
+		ByLanguage:     scoreTotalsByLanguage,
+		ByPunctuation:  scoreTotalsByPunctuation,
+		CalculatedAt:   now,
+	}, nil
+}
+
+// getStreaks returns the number of consecutive days with scores up to today (or yesterday, if there is no score today yet)
+// and the longest number of consecutive days with scores. The days must be sorted starting with the most recent day.
+func getStreaks(days []time.Time, today time.Time) (currentStreak, longestStreak int) {
+	streak := 0
+	currentStreakEnded := len(days) == 0 || today.Sub(days[0]) > hoursPerDay*time.Hour
+
+	for i, day := range days {
+		if i > 0 && days[i-1].Sub(day) == hoursPerDay*time.Hour {
+			streak++
+		} else {
+			if i > 0 {
+				currentStreakEnded = true
+			}
+			streak = 1
+		}
+
+		if !currentStreakEnded {
+			currentStreak = streak
+		}
+		if streak > longestStreak {
+			longestStreak = streak
+		}
+	}
+
+	return currentStreak, longestStreak
+}
+
+// getWorstKeys returns the keys with the most errors. The key error counts must be sorted by errors in descending order.
+func getWorstKeys(keyErrorCounts []models.KeyErrorCount, number int) []models.KeyErrorCount {
+	if len(keyErrorCounts) < number {
+		number = len(keyErrorCounts)
+	}
+
+	return keyErrorCounts[:number]
+}
+
+// getBestKeys returns the keys with the fewest errors out of the keys that have been mistyped at least once.
+// The key error counts must be sorted by errors in descending order.
+func getBestKeys(keyErrorCounts []models.KeyErrorCount, number int) []models.KeyErrorCount {
+	if len(keyErrorCounts) < number {
+		number = len(keyErrorCounts)
+	}
+
+	bestKeys := make([]models.KeyErrorCount, 0, number)
+	for i := len(keyErrorCounts) - 1; i >= len(keyErrorCounts)-number; i-- {
+		bestKeys = append(bestKeys, keyErrorCounts[i])
+	}
+
+	return bestKeys
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/models"
+	"10-typing/tracing"
+	"context"
+	"runtime"
+)
+
+type SystemStatsService struct {
+	dbRepo      common.DBRepository
+	roomService *RoomService
+	gameService *GameService
+	logger      common.Logger
+}
+
+func NewSystemStatsService(dbRepo common.DBRepository, roomService *RoomService, gameService *GameService, logger common.Logger) *SystemStatsService {
+	return &SystemStatsService{dbRepo, roomService, gameService, logger}
+}
+
+// FindSystemStats returns the totals of the database and the connections and games of this server instance
+func (ss *SystemStatsService) FindSystemStats(ctx context.Context) (*models.SystemStats, error) {
+	const op errors.Op = "services.SystemStatsService.FindSystemStats"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	stats, err := ss.dbRepo.FindSystemStats(ctx, nil)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	stats.Instance = models.InstanceStats{
+		WebSocketConnections: ss.roomService.ConnectionsNumber(),
+		RunningGames:         ss.gameService.RunningGamesNumber(),
+		Goroutines:           runtime.NumGoroutine(),
+	}
+
+	return stats, nil
+}
@@ -4,6 +4,10 @@ import (
 	"10-typing/common"
 	"10-typing/errors"
 	"10-typing/models"
+	"10-typing/textanalysis"
+	"10-typing/textgen"
+	"10-typing/tracing"
+	"fmt"
 	"net/http"
 
 	"context"
@@ -12,35 +16,29 @@ import (
 )
 
 type TextService struct {
-	dbRepo     common.DBRepository
-	cacheRepo  common.CacheRepository
-	openAiRepo common.OpenAiRepository
-	logger     common.Logger
+	dbRepo       common.DBRepository
+	cacheRepo    common.CacheRepository
+	openAiRepo   common.OpenAiRepository
+	statsService *StatsService
+	logger       common.Logger
 }
 
-func NewTextService(dbRepo common.DBRepository, cacheRepo common.CacheRepository, openAiRepo common.OpenAiRepository, logger common.Logger) *TextService {
-	return &TextService{dbRepo, cacheRepo, openAiRepo, logger}
+func NewTextService(
+	dbRepo common.DBRepository,
+	cacheRepo common.CacheRepository,
+	openAiRepo common.OpenAiRepository,
+	statsService *StatsService,
+	logger common.Logger,
+) *TextService {
+	return &TextService{dbRepo, cacheRepo, openAiRepo, statsService, logger}
 }
 
-func (ts *TextService) FindNewTextForUser(
-	ctx context.Context,
-	userId uuid.UUID, language string,
-	punctuation bool,
-	specialCharactersGte, specialCharactersLte, numbersGte, numbersLte int,
-) (*models.Text, error) {
+func (ts *TextService) FindNewTextForUser(ctx context.Context, userId uuid.UUID, filter models.TextFilter, sortOption models.SortOption) (*models.Text, error) {
 	const op errors.Op = "services.TextService.FindNewTextForUser"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
-	text, err := ts.dbRepo.FindNewTextForUser(
-		ctx,
-		nil,
-		userId,
-		language,
-		punctuation,
-		specialCharactersGte,
-		specialCharactersLte,
-		numbersGte,
-		numbersLte,
-	)
+	text, err := ts.dbRepo.FindNewTextForUser(ctx, nil, userId, filter, sortOption)
 	switch {
 	case errors.Is(err, common.ErrNotFound):
 		return nil, errors.E(op, err, http.StatusNotFound)
@@ -51,14 +49,25 @@ func (ts *TextService) FindNewTextForUser(
 	return text, nil
 }
 
-func (ts *TextService) FindTextById(ctx context.Context, textId uuid.UUID) (*models.Text, error) {
+// FindTextById returns the text if it is visible to the user. Texts that are not visible are reported as not found.
+func (ts *TextService) FindTextById(ctx context.Context, user *models.User, textId uuid.UUID) (*models.Text, error) {
 	const op errors.Op = "services.TextService.FindTextById"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	text, err := ts.dbRepo.FindTextById(ctx, nil, textId)
-	if err != nil {
+	switch {
+	case errors.Is(err, common.ErrNotFound):
+		return nil, errors.E(op, err, http.StatusNotFound)
+	case err != nil:
 		return nil, errors.E(op, err)
 	}
 
+	if !text.IsVisibleTo(user) {
+		err := fmt.Errorf("text %s is not visible to user %s", textId, user.ID)
+		return nil, errors.E(op, err, http.StatusNotFound)
+	}
+
 	return text, nil
 }
 
@@ -69,9 +78,11 @@ func (ts *TextService) Create(
 	specialCharacters, numbers int,
 ) (*models.Text, error) {
 	const op errors.Op = "services.TextService.Create"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	if text == "" {
-		gptText, err := ts.openAiRepo.GenerateTypingText(language, punctuation, specialCharacters, numbers)
+		gptText, err := ts.openAiRepo.GenerateTypingText(ctx, language, punctuation, specialCharacters, numbers)
 		if err != nil {
 			return nil, errors.E(op, err)
 		}
@@ -79,18 +90,51 @@ func (ts *TextService) Create(
 		text = gptText
 	}
 
+	// the text is validated before it is saved because every game with this text relies on its settings
+	if err := textgen.ValidateTypingText(text, punctuation, specialCharacters, numbers); err != nil {
+		return nil, errors.E(op, err, http.StatusBadGateway, errors.Messages{"message": "The text does not match the requested settings"})
+	}
+
 	newText := models.Text{
 		Language:          language,
 		Text:              text,
 		Punctuation:       punctuation,
 		SpecialCharacters: specialCharacters,
 		Numbers:           numbers,
+		Visibility:        models.PublicTextVisibility,
+		Status:            models.ApprovedTextStatus,
+	}
+	newText.SetAnalysis(textanalysis.Analyze(text, language))
+
+	// the same text may be generated again, e.g. with a seeded text generator, and public texts are unique, so the existing text is returned instead
+	existingText, err := ts.dbRepo.FindPublicTextByContentHash(ctx, nil, newText.ContentHash)
+	switch {
+	case err == nil:
+		return reuseExistingText(op, existingText)
+	case !errors.Is(err, common.ErrNotFound):
+		return nil, errors.E(op, err)
 	}
 
 	createdText, err := ts.dbRepo.CreateTextAndCache(ctx, nil, ts.cacheRepo, newText)
 	if err != nil {
-		return nil, errors.E(op, err)
+		// the text may have been created concurrently since it was searched
+		existingText, findErr := ts.dbRepo.FindPublicTextByContentHash(ctx, nil, newText.ContentHash)
+		if findErr != nil {
+			return nil, errors.E(op, err)
+		}
+
+		return reuseExistingText(op, existingText)
 	}
 
 	return createdText, nil
 }
+
+// reuseExistingText returns the existing public text with the same content as a new text unless it is still waiting for moderation
+func reuseExistingText(op errors.Op, text *models.Text) (*models.Text, error) {
+	if text.Status != models.ApprovedTextStatus {
+		err := fmt.Errorf("public text %s with content hash %s is %s", text.ID, text.ContentHash, text.Status)
+		return nil, errors.E(op, err, http.StatusConflict, errors.Messages{"message": "This text has already been submitted and is waiting for moderation"})
+	}
+
+	return text, nil
+}
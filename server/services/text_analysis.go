@@ -0,0 +1,92 @@
+package services
+
+import (
+	"10-typing/errors"
+	"10-typing/models"
+	"10-typing/textanalysis"
+	"10-typing/tracing"
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// analyzeTextsBatchSize is the number of texts that are loaded and updated at once by AnalyzeTexts
+const analyzeTextsBatchSize = 500
+
+// AnalyzeTexts computes the analysis of the texts that have been created before texts were analyzed and returns the number of updated texts.
+// If all is true, every text is analyzed again, e.g. after the difficulty formula has changed.
+func (ts *TextService) AnalyzeTexts(ctx context.Context, all bool) (int, error) {
+	const op errors.Op = "services.TextService.AnalyzeTexts"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if err := ts.LoadBigramFrequencies(ctx); err != nil {
+		return 0, errors.E(op, err)
+	}
+
+	var updated int
+	var afterId uuid.UUID
+
+	for {
+		texts, err := ts.dbRepo.FindTextsForAnalysis(ctx, nil, afterId, !all, analyzeTextsBatchSize)
+		if err != nil {
+			return updated, errors.E(op, err)
+		}
+
+		for _, text := range texts {
+			text.SetAnalysis(textanalysis.Analyze(text.Text, text.Language))
+			if err := ts.dbRepo.UpdateTextAnalysis(ctx, nil, text); err != nil {
+				return updated, errors.E(op, err)
+			}
+			updated++
+		}
+
+		if len(texts) < analyzeTextsBatchSize {
+			return updated, nil
+		}
+		afterId = texts[len(texts)-1].ID
+	}
+}
+
+// LoadBigramFrequencies counts the letter bigrams of the approved public texts of each language,
+// so that the bigram rarity of the texts analyzed afterwards is measured against the stored texts instead of the small corpus of the language.
+// Languages with too few stored texts keep using their corpus.
+func (ts *TextService) LoadBigramFrequencies(ctx context.Context) error {
+	const op errors.Op = "services.TextService.LoadBigramFrequencies"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	frequenciesByLanguage := map[string]*textanalysis.BigramFrequencies{}
+	var afterId uuid.UUID
+
+	for {
+		texts, err := ts.dbRepo.FindTextsForAnalysis(ctx, nil, afterId, false, analyzeTextsBatchSize)
+		if err != nil {
+			return errors.E(op, err)
+		}
+
+		for _, text := range texts {
+			if text.Visibility != models.PublicTextVisibility || text.Status != models.ApprovedTextStatus {
+				continue
+			}
+			if _, ok := frequenciesByLanguage[text.Language]; !ok {
+				frequenciesByLanguage[text.Language] = textanalysis.NewBigramFrequencies()
+			}
+			frequenciesByLanguage[text.Language].Add(text.Text)
+		}
+
+		if len(texts) < analyzeTextsBatchSize {
+			break
+		}
+		afterId = texts[len(texts)-1].ID
+	}
+
+	for language, frequencies := range frequenciesByLanguage {
+		if !textanalysis.SetBigramFrequencies(language, frequencies) {
+			ts.logger.Info(fmt.Sprintf("%s texts have %d bigrams, the bigram rarity is measured against the corpus", language, frequencies.Total()))
+		}
+	}
+
+	return nil
+}
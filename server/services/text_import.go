@@ -0,0 +1,167 @@
+package services
+
+import (
+	"10-typing/errors"
+	"10-typing/models"
+	"10-typing/textanalysis"
+	"10-typing/textgen"
+	"10-typing/tracing"
+	"context"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// textImportBatchSize is the number of texts whose content hashes are looked up and which are created at once
+const textImportBatchSize = 100
+
+// textExportBatchSize is the number of texts that are loaded together with their usage statistics at once
+const textExportBatchSize = 500
+
+// ImportTexts normalizes and analyzes the texts and creates them as public and approved texts in batches.
+// Texts whose content already exists, either as a public text in the database or earlier in the import, and texts with an unsupported language
+// or a length outside the bounds of custom texts are skipped.
+func (ts *TextService) ImportTexts(ctx context.Context, texts []models.Text) (models.TextImportResult, error) {
+	const op errors.Op = "services.TextService.ImportTexts"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	var result models.TextImportResult
+	var importedContentHashes = make(map[string]bool)
+
+	for start := 0; start < len(texts); start += textImportBatchSize {
+		end := start + textImportBatchSize
+		if end > len(texts) {
+			end = len(texts)
+		}
+
+		batch := make([]models.Text, 0, end-start)
+		contentHashes := make([]string, 0, end-start)
+		for _, text := range texts[start:end] {
+			text.Text = textgen.NormalizeText(text.Text)
+			text.Title = textgen.NormalizeLine(text.Title)
+			text.Source = textgen.NormalizeLine(text.Source)
+			if !isValidImportText(text) {
+				result.Invalid++
+				continue
+			}
+
+			text.Punctuation = textgen.HasPunctuation(text.Text)
+			text.Visibility = models.PublicTextVisibility
+			text.Status = models.ApprovedTextStatus
+			text.SetAnalysis(textanalysis.Analyze(text.Text, text.Language))
+
+			if importedContentHashes[text.ContentHash] {
+				result.Duplicates++
+				continue
+			}
+			importedContentHashes[text.ContentHash] = true
+
+			batch = append(batch, text)
+			contentHashes = append(contentHashes, text.ContentHash)
+		}
+
+		if len(batch) == 0 {
+			continue
+		}
+
+		existingContentHashes, err := ts.dbRepo.FindExistingContentHashes(ctx, nil, contentHashes)
+		if err != nil {
+			return result, errors.E(op, err)
+		}
+		isExisting := make(map[string]bool, len(existingContentHashes))
+		for _, contentHash := range existingContentHashes {
+			isExisting[contentHash] = true
+		}
+
+		newTexts := make([]models.Text, 0, len(batch))
+		for _, text := range batch {
+			if isExisting[text.ContentHash] {
+				result.Duplicates++
+				continue
+			}
+			newTexts = append(newTexts, text)
+		}
+
+		createdTexts, err := ts.dbRepo.CreateTextsAndCache(ctx, nil, ts.cacheRepo, newTexts)
+		if err != nil {
+			return result, errors.E(op, err)
+		}
+		result.Created += len(createdTexts)
+
+		ts.logger.WithContext(ctx).Info("imported texts: ", result.Created, ", duplicates: ", result.Duplicates, ", invalid: ", result.Invalid)
+	}
+
+	return result, nil
+}
+
+// ExportTexts passes every text together with its usage statistics to write, ordered by the text id
+func (ts *TextService) ExportTexts(ctx context.Context, write func(text models.TextExport) error) error {
+	const op errors.Op = "services.TextService.ExportTexts"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	var afterId uuid.UUID
+
+	for {
+		texts, err := ts.dbRepo.FindTextsAfterId(ctx, nil, afterId, textExportBatchSize)
+		if err != nil {
+			return errors.E(op, err)
+		}
+		if len(texts) == 0 {
+			return nil
+		}
+
+		textIds := make([]uuid.UUID, 0, len(texts))
+		for _, text := range texts {
+			textIds = append(textIds, text.ID)
+		}
+
+		usageStats, err := ts.dbRepo.FindTextUsageStats(ctx, nil, textIds)
+		if err != nil {
+			return errors.E(op, err)
+		}
+		usageStatsByTextId := make(map[uuid.UUID]models.TextUsageStats, len(usageStats))
+		for _, textUsageStats := range usageStats {
+			usageStatsByTextId[textUsageStats.TextId] = textUsageStats
+		}
+
+		for _, text := range texts {
+			if err := write(models.TextExport{Text: text, Usage: usageStatsByTextId[text.ID]}); err != nil {
+				return errors.E(op, err)
+			}
+		}
+
+		if len(texts) < textExportBatchSize {
+			return nil
+		}
+		afterId = texts[len(texts)-1].ID
+	}
+}
+
+// DeleteDuplicateTexts soft deletes the public texts that have the content of an older public text, which have been created before the content
+// of the public texts was unique, so that their unique index can be created. Rejected texts are not duplicates. The scores of the deleted texts are kept.
+func (ts *TextService) DeleteDuplicateTexts(ctx context.Context) (int, error) {
+	const op errors.Op = "services.TextService.DeleteDuplicateTexts"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	textIds, err := ts.dbRepo.FindDuplicatePublicTextIds(ctx, nil)
+	if err != nil {
+		return 0, errors.E(op, err)
+	}
+
+	for i, textId := range textIds {
+		if err := ts.dbRepo.SoftDeleteTextAndCache(ctx, nil, ts.cacheRepo, textId); err != nil {
+			return i, errors.E(op, err)
+		}
+	}
+
+	return len(textIds), nil
+}
+
+func isValidImportText(text models.Text) bool {
+	length := utf8.RuneCountInString(text.Text)
+
+	return textgen.GetCorpus(text.Language) != "" && length >= models.CustomTextMinLength && length <= models.CustomTextMaxLength
+}
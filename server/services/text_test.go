@@ -0,0 +1,44 @@
+package services
+
+import (
+	"10-typing/models"
+	"10-typing/zerologger"
+	"context"
+	"io"
+	"testing"
+)
+
+func TestTextServiceCreate(t *testing.T) {
+	const text = "the quick brown fox jumps over the lazy dog"
+
+	ctx := context.Background()
+	ts := newTestServices(t)
+	textService := NewTextService(ts.dbRepo, ts.cacheRepo, nil, nil, zerologger.New(io.Discard))
+
+	createdText, err := textService.Create(ctx, "en", text, false, 0, 0)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// a text that is generated again is not created twice
+	existingText, err := textService.Create(ctx, "en", text, false, 0, 0)
+	switch {
+	case err != nil:
+		t.Fatalf("Create() of the same text error = %v", err)
+	case existingText.ID != createdText.ID:
+		t.Errorf("Create() of the same text = %s, want the existing text %s", existingText.ID, createdText.ID)
+	}
+
+	// rejected texts don't keep the same text from being created again
+	if _, err := ts.dbRepo.UpdateTextStatusAndCache(ctx, nil, ts.cacheRepo, createdText.ID, models.RejectedTextStatus, ""); err != nil {
+		t.Fatalf("UpdateTextStatusAndCache() error = %v", err)
+	}
+
+	newText, err := textService.Create(ctx, "en", text, false, 0, 0)
+	switch {
+	case err != nil:
+		t.Fatalf("Create() of the rejected text error = %v", err)
+	case newText.ID == createdText.ID:
+		t.Errorf("Create() of the rejected text = the rejected text %s, want a new text", newText.ID)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"10-typing/errors"
 	"10-typing/models"
 	"10-typing/rand"
+	"10-typing/tracing"
 	"10-typing/utils"
 	"context"
 	"fmt"
@@ -32,6 +33,8 @@ func NewUserService(dbRepo common.DBRepository, cacheRepo common.CacheRepository
 
 func (us *UserService) FindUsers(ctx context.Context, username, usernameSubstr string) ([]models.User, error) {
 	const op errors.Op = "services.UserService.FindUsers"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	users, err := us.dbRepo.FindUsers(ctx, nil, username, usernameSubstr)
 	if err != nil {
@@ -43,6 +46,8 @@ func (us *UserService) FindUsers(ctx context.Context, username, usernameSubstr s
 
 func (us *UserService) FindUserById(ctx context.Context, userId uuid.UUID) (*models.User, error) {
 	const op errors.Op = "services.UserService.FindUserById"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	user, err := us.dbRepo.FindUserById(ctx, nil, userId)
 	if err != nil {
@@ -52,8 +57,133 @@ func (us *UserService) FindUserById(ctx context.Context, userId uuid.UUID) (*mod
 	return user, nil
 }
 
-func (us *UserService) Create(ctx context.Context, email, username, firstName, lastName, password string) (*models.User, error) {
+// FindAllUsers returns the users that match the filter and the total number of them, including the banned users
+func (us *UserService) FindAllUsers(ctx context.Context, filter models.UserFilter, limit, offset int) ([]models.User, int64, error) {
+	const op errors.Op = "services.UserService.FindAllUsers"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	users, total, err := us.dbRepo.FindAllUsers(ctx, nil, filter, limit, offset)
+	if err != nil {
+		return nil, 0, errors.E(op, err)
+	}
+
+	return users, total, nil
+}
+
+// UpdateUserRole sets the site role of the user. Admins can't change their own role, so that the site doesn't end up without admin.
+func (us *UserService) UpdateUserRole(ctx context.Context, userId uuid.UUID, role models.UserRole, authenticatedUser models.User) (*models.User, error) {
+	const op errors.Op = "services.UserService.UpdateUserRole"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if userId == authenticatedUser.ID {
+		err := fmt.Errorf("user %s can't change the own role", userId)
+		return nil, errors.E(op, err, http.StatusBadRequest, errors.Messages{"message": "You can't change your own role"})
+	}
+
+	user, err := us.dbRepo.UpdateUserRoleAndCache(ctx, nil, us.cacheRepo, userId, role)
+	switch {
+	case errors.Is(err, common.ErrNotFound):
+		return nil, errors.E(op, err, http.StatusNotFound)
+	case err != nil:
+		return nil, errors.E(op, err)
+	}
+
+	us.logger.WithContext(ctx).Info("user ", userId, " has the role ", role)
+
+	return user, nil
+}
+
+// UpdateUserBanned bans or unbans the user. Banned users can't log in and the requests of their sessions are rejected. Admins can't be banned.
+func (us *UserService) UpdateUserBanned(ctx context.Context, userId uuid.UUID, banned bool) (*models.User, error) {
+	const op errors.Op = "services.UserService.UpdateUserBanned"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	user, err := us.dbRepo.FindUserById(ctx, nil, userId)
+	switch {
+	case errors.Is(err, common.ErrNotFound):
+		return nil, errors.E(op, err, http.StatusNotFound)
+	case err != nil:
+		return nil, errors.E(op, err)
+	}
+
+	if banned && user.HasRole(models.AdminUserRole) {
+		err := fmt.Errorf("admin %s can't be banned", userId)
+		return nil, errors.E(op, err, http.StatusBadRequest, errors.Messages{"message": "Admins can't be banned"})
+	}
+
+	user, err = us.dbRepo.UpdateUserBannedAndCache(ctx, nil, us.cacheRepo, userId, banned)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	us.logger.WithContext(ctx).Info("user ", userId, " banned: ", banned)
+
+	if banned {
+		if err := us.disconnectUser(ctx, userId); err != nil {
+			return nil, errors.E(op, err)
+		}
+	}
+
+	return user, nil
+}
+
+// disconnectUser deletes the sessions of the user and closes the WebSocket connections of the user to all rooms of the user,
+// so that a banned user is logged out right away instead of at the next request
+func (us *UserService) disconnectUser(ctx context.Context, userId uuid.UUID) error {
+	const op errors.Op = "services.UserService.disconnectUser"
+
+	if err := us.cacheRepo.DeleteUserSessions(ctx, userId); err != nil {
+		return errors.E(op, err)
+	}
+
+	roomIds, err := us.dbRepo.FindRoomIdsByUserId(ctx, nil, userId)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	// the user_removed push message terminates the connections of the user, who stays a member of the rooms in case the ban is lifted
+	userRemovedPushMessage := models.PushMessage{
+		Type:    models.UserRemoved,
+		Payload: userId,
+	}
+	for _, roomId := range roomIds {
+		if err := us.cacheRepo.PublishPushMessage(ctx, nil, roomId, userRemovedPushMessage); err != nil {
+			return errors.E(op, err)
+		}
+	}
+
+	return nil
+}
+
+// UpdateUserKeyboardLayout sets the keyboard layout of the user, with which the typing errors and the keyboard statistics of the user are evaluated
+func (us *UserService) UpdateUserKeyboardLayout(ctx context.Context, userId uuid.UUID, keyboardLayout string) (*models.User, error) {
+	const op errors.Op = "services.UserService.UpdateUserKeyboardLayout"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	user, err := us.dbRepo.UpdateUserKeyboardLayoutAndCache(ctx, nil, us.cacheRepo, userId, keyboardLayout)
+	switch {
+	case errors.Is(err, common.ErrNotFound):
+		return nil, errors.E(op, err, http.StatusNotFound)
+	case err != nil:
+		return nil, errors.E(op, err)
+	}
+
+	// the finger errors of the cached stats have been assigned with the previous layout
+	if err := us.cacheRepo.DeleteUserStats(ctx, nil, userId); err != nil {
+		us.logger.WithContext(ctx).Error(errors.E(op, err))
+	}
+
+	return user, nil
+}
+
+func (us *UserService) Create(ctx context.Context, email, username, firstName, lastName, password, keyboardLayout string) (*models.User, error) {
 	const op errors.Op = "services.UserService.Create"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	hashedPassword, err := us.hashedPassword(password)
 	if err != nil {
@@ -61,12 +191,14 @@ func (us *UserService) Create(ctx context.Context, email, username, firstName, l
 	}
 
 	newUser := models.User{
-		Email:        email,
-		Username:     username,
-		FirstName:    firstName,
-		LastName:     lastName,
-		IsVerified:   false,
-		PasswordHash: hashedPassword,
+		Email:          email,
+		Username:       username,
+		FirstName:      firstName,
+		LastName:       lastName,
+		IsVerified:     false,
+		PasswordHash:   hashedPassword,
+		KeyboardLayout: keyboardLayout,
+		Role:           models.UserUserRole,
 	}
 
 	user, err := us.dbRepo.CreateUserAndCache(ctx, nil, us.cacheRepo, newUser)
@@ -79,6 +211,8 @@ func (us *UserService) Create(ctx context.Context, email, username, firstName, l
 
 func (us *UserService) VerifyUser(ctx context.Context, userId uuid.UUID) error {
 	const op errors.Op = "services.UserService.VerifyUser"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	if err := us.dbRepo.VerifyUserAndCache(ctx, nil, us.cacheRepo, userId); err != nil {
 		return errors.E(op, err)
@@ -87,12 +221,29 @@ func (us *UserService) VerifyUser(ctx context.Context, userId uuid.UUID) error {
 	return nil
 }
 
-func (us *UserService) Login(ctx context.Context, email, password string) (user *models.User, sessionToken string, err error) {
+// Login creates a session for the user with the email and password.
+// The failed logins are counted per email and client ip, so that a client can't lock other clients out of an account,
+// and per email with a higher limit, so that an account can't be attacked from many client ips.
+func (us *UserService) Login(ctx context.Context, email, password, clientIp string) (user *models.User, sessionToken string, err error) {
 	const op errors.Op = "services.UserService.Login"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	loginEmail := models.NormalizeLoginEmail(email)
+
+	loginFailures, err := us.cacheRepo.GetLoginFailures(ctx, loginEmail, clientIp)
+	if err != nil {
+		return nil, "", errors.E(op, err)
+	}
+	if loginFailures.IsLocked() {
+		err := fmt.Errorf("login locked after %d failed logins from the client ip and %d from all client ips", loginFailures.FromClientIp, loginFailures.FromAllClientIps)
+		return nil, "", errors.E(op, err, http.StatusTooManyRequests, errors.Messages{"message": "Too many failed logins, please try again later"})
+	}
 
 	user, err = us.dbRepo.FindUserByEmail(ctx, nil, email)
 	switch {
 	case errors.Is(err, common.ErrNotFound):
+		us.incrementLoginFailures(ctx, loginEmail, clientIp)
 		return nil, "", errors.E(op, err, http.StatusBadRequest)
 	case err != nil:
 		return nil, "", errors.E(op, err)
@@ -103,9 +254,20 @@ func (us *UserService) Login(ctx context.Context, email, password string) (user
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password+userPwPepper))
 	if err != nil {
+		us.incrementLoginFailures(ctx, loginEmail, clientIp)
 		return nil, "", errors.E(op, err, http.StatusBadRequest, errors.Messages{"message": "password is not correct"})
 	}
 
+	// the ban is only revealed after the password has been checked
+	if user.IsBanned() {
+		err := fmt.Errorf("user %s is banned", user.ID)
+		return nil, "", errors.E(op, err, http.StatusForbidden, errors.Messages{"message": "user is banned"})
+	}
+
+	if err := us.cacheRepo.DeleteLoginFailures(ctx, loginEmail, clientIp); err != nil {
+		return nil, "", errors.E(op, err)
+	}
+
 	token, err := us.createSession(ctx, user.ID)
 	if err != nil {
 		return nil, "", errors.E(op, err)
@@ -114,8 +276,21 @@ func (us *UserService) Login(ctx context.Context, email, password string) (user
 	return user, token, nil
 }
 
+// incrementLoginFailures counts a failed login of the email from the client ip, so that the login is locked after models.MaxLoginFailures failed logins
+// from the client ip or models.MaxEmailLoginFailures failed logins from all client ips.
+// Errors are only logged, since the login has already failed.
+func (us *UserService) incrementLoginFailures(ctx context.Context, email, clientIp string) {
+	const op errors.Op = "services.UserService.incrementLoginFailures"
+
+	if _, err := us.cacheRepo.IncrementLoginFailures(ctx, email, clientIp); err != nil {
+		us.logger.WithContext(ctx).Error(errors.E(op, err))
+	}
+}
+
 func (us *UserService) DeleteSession(ctx context.Context, token string) error {
 	const op errors.Op = "services.UserService.DeleteSession"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	tokenHash := utils.HashSessionToken(token)
 
@@ -140,6 +315,8 @@ func (us *UserService) hashedPassword(password string) (hashedPassword string, e
 
 func (us *UserService) createSession(ctx context.Context, userId uuid.UUID) (token string, err error) {
 	const op errors.Op = "services.UserService.createSession"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	bytesPerToken := us.sessionBytesPerToken
 	if bytesPerToken < minBytesPerToken {
@@ -4,7 +4,9 @@ import (
 	"10-typing/common"
 	"10-typing/errors"
 	"10-typing/models"
+	"10-typing/tracing"
 	"context"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,20 +15,33 @@ import (
 const maxRequestDurationSecs = 20
 
 type UserNotificationService struct {
-	cacheRepo common.CacheRepository
-	logger    common.Logger
+	cacheRepo    common.CacheRepository
+	clock        common.Clock
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
+	logger       common.Logger
 }
 
-func NewUserNotificationService(cacheRepo common.CacheRepository, logger common.Logger) *UserNotificationService {
-	return &UserNotificationService{cacheRepo, logger}
+func NewUserNotificationService(cacheRepo common.CacheRepository, clock common.Clock, logger common.Logger) *UserNotificationService {
+	return &UserNotificationService{cacheRepo: cacheRepo, clock: clock, shutdown: make(chan struct{}), logger: logger}
+}
+
+// CancelLongPolls ends the open and following long polls of notifications without a notification on shutdown,
+// so that the server doesn't wait for them and the clients poll again on another server instance.
+func (us *UserNotificationService) CancelLongPolls() {
+	us.shutdownOnce.Do(func() {
+		close(us.shutdown)
+	})
 }
 
 func (us *UserNotificationService) FindRealtimeUserNotification(ctx context.Context, userId uuid.UUID, lastId string) (*models.UserNotification, error) {
 	const op errors.Op = "services.UserNotificationService.FindRealtimeUserNotification"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	userNotificationResultCh := us.cacheRepo.GetUserNotification(ctx, userId, lastId)
 
-	t := time.NewTimer(maxRequestDurationSecs * time.Second)
+	t := us.clock.NewTimer(maxRequestDurationSecs * time.Second)
 	defer t.Stop()
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -40,7 +55,9 @@ func (us *UserNotificationService) FindRealtimeUserNotification(ctx context.Cont
 		}
 
 		return userNotificationResult.Value, nil
-	case <-t.C:
+	case <-t.C():
+		return nil, errors.E(op, common.ErrNotFound)
+	case <-us.shutdown:
 		return nil, errors.E(op, common.ErrNotFound)
 	}
 }
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"10-typing/common"
+	"10-typing/errors"
+	"10-typing/zerologger"
+	"context"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUserServiceUpdateUserBanned(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ts := newTestServices(t)
+	userService := NewUserService(ts.dbRepo, ts.cacheRepo, zerologger.New(io.Discard), 32)
+	admin := ts.createUser(t, "admin")
+	member := ts.createUser(t, "member")
+	room := ts.createRoom(t, admin, member)
+	startTime := ts.clock.Now()
+
+	for _, tokenHash := range []string{"first", "second"} {
+		if err := ts.cacheRepo.SetSession(ctx, nil, tokenHash, member.ID); err != nil {
+			t.Fatalf("SetSession() error = %v", err)
+		}
+	}
+
+	if _, err := userService.UpdateUserBanned(ctx, member.ID, true); err != nil {
+		t.Fatalf("UpdateUserBanned() error = %v", err)
+	}
+
+	// the banned user is logged out and disconnected from the rooms
+	for _, tokenHash := range []string{"first", "second"} {
+		if _, err := ts.cacheRepo.GetUserBySessionTokenHashInCacheOrDB(ctx, ts.dbRepo, tokenHash); !errors.Is(err, common.ErrNotFound) {
+			t.Errorf("GetUserBySessionTokenHashInCacheOrDB() of session %s error = %v, want %v", tokenHash, err, common.ErrNotFound)
+		}
+	}
+	if got := ts.readPushMessageTypes(t, ctx, room.ID, startTime, "user_removed"); !reflect.DeepEqual(got, []string{"user_removed"}) {
+		t.Errorf("push messages = %v, want [user_removed]", got)
+	}
+}
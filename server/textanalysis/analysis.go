@@ -0,0 +1,82 @@
+package textanalysis
+
+import (
+	"10-typing/textgen"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Analysis holds the metadata of a text that is computed from its content
+type Analysis struct {
+	Characters        int
+	Letters           int
+	UpperCaseLetters  int
+	Digits            int
+	Spaces            int
+	PunctuationMarks  int
+	SpecialCharacters int
+	Numbers           int
+	Words             int
+	AverageWordLength float64
+	BigramRarity      float64
+	Difficulty        float64
+	ContentHash       string
+}
+
+// Analyze counts the character classes and words of the text and computes its bigram rarity and difficulty.
+// The bigram rarity is measured against the bigram frequencies of the language, which is why the language has to be passed.
+func Analyze(text, language string) Analysis {
+	analysis := Analysis{
+		Characters:        utf8.RuneCountInString(text),
+		SpecialCharacters: textgen.CountSpecialCharacters(text),
+		Numbers:           textgen.CountNumbers(text),
+	}
+
+	for _, char := range text {
+		switch {
+		case unicode.IsLetter(char):
+			analysis.Letters++
+			if unicode.IsUpper(char) {
+				analysis.UpperCaseLetters++
+			}
+		case unicode.IsDigit(char):
+			analysis.Digits++
+		case unicode.IsSpace(char):
+			analysis.Spaces++
+		case strings.ContainsRune(textgen.SentencePunctuation, char):
+			analysis.PunctuationMarks++
+		}
+	}
+
+	words := strings.Fields(text)
+	analysis.Words = len(words)
+	if analysis.Words > 0 {
+		wordCharacters := 0
+		for _, word := range words {
+			wordCharacters += utf8.RuneCountInString(strings.TrimRight(word, textgen.SentencePunctuation))
+		}
+		analysis.AverageWordLength = round(float64(wordCharacters) / float64(analysis.Words))
+	}
+
+	analysis.BigramRarity = round(getBigramRarity(text, language))
+	analysis.Difficulty = getDifficulty(analysis)
+	analysis.ContentHash = ContentHash(text)
+
+	return analysis
+}
+
+// ContentHash returns the hex encoded SHA-256 hash of the normalized text, so texts that only differ in whitespace or typographic quotes have the same hash
+func ContentHash(text string) string {
+	hash := sha256.Sum256([]byte(textgen.NormalizeText(text)))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// round rounds to two decimal places, which is precise enough for filtering and sorting texts
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,121 @@
+package textanalysis
+
+import (
+	"10-typing/textgen"
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// MinBigrams is the number of bigrams that the texts of a language need to have, so that their bigram frequencies replace the frequencies of the corpus.
+// Fewer bigrams don't represent the language, e.g. the bigrams of a handful of texts.
+const MinBigrams = 100000
+
+// BigramFrequencies holds the number of occurrences of the letter bigrams of the texts of a language
+type BigramFrequencies struct {
+	counts   map[string]int
+	total    int
+	maxCount int
+}
+
+func NewBigramFrequencies() *BigramFrequencies {
+	return &BigramFrequencies{counts: map[string]int{}}
+}
+
+// Add counts the letter bigrams of the text
+func (f *BigramFrequencies) Add(text string) {
+	for _, bigram := range getBigrams(text) {
+		f.counts[bigram]++
+		f.total++
+		if count := f.counts[bigram]; count > f.maxCount {
+			f.maxCount = count
+		}
+	}
+}
+
+// Total returns the number of bigrams that have been counted
+func (f *BigramFrequencies) Total() int {
+	return f.total
+}
+
+var (
+	bigramFrequenciesByLanguage = map[string]*BigramFrequencies{}
+	bigramFrequenciesMu         sync.Mutex
+)
+
+// SetBigramFrequencies sets the bigram frequencies of the language that the bigram rarity of the texts analyzed afterwards is measured against,
+// e.g. the frequencies of the stored texts of the language. Frequencies of less than MinBigrams bigrams are ignored.
+// It returns whether the frequencies have been set.
+func SetBigramFrequencies(language string, frequencies *BigramFrequencies) bool {
+	if frequencies.total < MinBigrams {
+		return false
+	}
+
+	bigramFrequenciesMu.Lock()
+	defer bigramFrequenciesMu.Unlock()
+
+	bigramFrequenciesByLanguage[language] = frequencies
+
+	return true
+}
+
+// getBigramFrequencies returns the bigram frequencies that have been set for the language.
+// Until frequencies have been set, the letter bigrams of the corpus of the language are counted once and used instead.
+func getBigramFrequencies(language string) *BigramFrequencies {
+	bigramFrequenciesMu.Lock()
+	defer bigramFrequenciesMu.Unlock()
+
+	if frequencies, ok := bigramFrequenciesByLanguage[language]; ok {
+		return frequencies
+	}
+
+	frequencies := NewBigramFrequencies()
+	frequencies.Add(textgen.GetCorpus(language))
+	bigramFrequenciesByLanguage[language] = frequencies
+
+	return frequencies
+}
+
+// getBigrams returns the lower case pairs of adjacent letters within the words of the text
+func getBigrams(text string) []string {
+	var bigrams []string
+	var previous rune
+
+	for _, char := range strings.ToLower(text) {
+		if !unicode.IsLetter(char) {
+			previous = 0
+			continue
+		}
+		if previous != 0 {
+			bigrams = append(bigrams, string([]rune{previous, char}))
+		}
+		previous = char
+	}
+
+	return bigrams
+}
+
+// getBigramRarity returns the average rarity of the letter bigrams of the text between 0 and 1.
+// The rarity of a bigram is the logarithmic distance of its frequency in the texts of the language to the frequency of the most common bigram,
+// so the most common bigram has a rarity of 0 and bigrams that don't occur in the texts have a rarity of 1.
+func getBigramRarity(text, language string) float64 {
+	frequencies := getBigramFrequencies(language)
+	bigrams := getBigrams(text)
+	if len(bigrams) == 0 || frequencies.maxCount < 2 {
+		return 0
+	}
+
+	maxLog := math.Log(float64(frequencies.maxCount))
+	totalRarity := 0.0
+	for _, bigram := range bigrams {
+		count, ok := frequencies.counts[bigram]
+		if !ok {
+			totalRarity++
+			continue
+		}
+		totalRarity += math.Log(float64(frequencies.maxCount)/float64(count)) / maxLog
+	}
+
+	return totalRarity / float64(len(bigrams))
+}
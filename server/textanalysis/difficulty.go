@@ -0,0 +1,38 @@
+package textanalysis
+
+import "math"
+
+// MaxDifficulty is the difficulty of the hardest texts. The difficulty of a text is between 0 and MaxDifficulty.
+const MaxDifficulty = 100
+
+// the weights of the difficulty factors add up to MaxDifficulty
+const (
+	wordLengthWeight          = 25
+	bigramRarityWeight        = 25
+	shiftAndPunctuationWeight = 15
+	specialCharactersWeight   = 20
+	digitsWeight              = 15
+)
+
+// getDifficulty weights how hard the text is to type by its word length, its uncommon letter combinations,
+// the keys that need shift or leave the letter keys and its special characters and digits.
+// Every factor is scaled between 0 and 1, where 1 is reached at a density that is hard for most typists.
+func getDifficulty(analysis Analysis) float64 {
+	if analysis.Words == 0 {
+		return 0
+	}
+
+	words := float64(analysis.Words)
+	difficulty := wordLengthWeight*scale(analysis.AverageWordLength-3, 5) +
+		bigramRarityWeight*analysis.BigramRarity +
+		shiftAndPunctuationWeight*scale(float64(analysis.UpperCaseLetters+analysis.PunctuationMarks)/words, 0.5) +
+		specialCharactersWeight*scale(float64(analysis.SpecialCharacters)/words, 0.2) +
+		digitsWeight*scale(float64(analysis.Digits)/float64(analysis.Characters), 0.1)
+
+	return math.Round(difficulty*10) / 10
+}
+
+// scale maps value linearly to a number between 0 and 1, which is 1 for all values of max and above
+func scale(value, max float64) float64 {
+	return math.Max(0, math.Min(1, value/max))
+}
@@ -0,0 +1,31 @@
+package textgen
+
+// sample texts of the supported languages that the markov chains are built from
+var corpora = map[string]string{
+	"en": `The quick brown fox jumps over the lazy dog. Every morning the old man walks along the river to the small market in the village.
+		He buys fresh bread and a little cheese and then he sits on a bench near the bridge. The children of the village run past him on their way to school.
+		Some of them wave at him and he always waves back. In the afternoon the sun is warm and the water of the river is calm and clear.
+		People come from the city to walk in the forest and to look at the mountains. They say that the air is better here than anywhere else in the world.
+		In the evening the lights of the houses go on one after the other. The old man goes back home and makes a cup of tea for himself.
+		He reads a book until it is late and then he goes to sleep. Tomorrow will be another day and he will walk along the river again.
+		A good typist keeps the fingers on the home row and looks at the screen instead of the keyboard. Practice every day and your speed will grow.`,
+	"de": `Der schnelle braune Fuchs springt über den faulen Hund. Jeden Morgen geht der alte Mann am Fluss entlang zum kleinen Markt im Dorf.
+		Er kauft frisches Brot und ein wenig Käse und dann setzt er sich auf eine Bank neben der Brücke. Die Kinder aus dem Dorf laufen auf dem Weg zur Schule an ihm vorbei.
+		Einige von ihnen winken ihm zu und er winkt immer zurück. Am Nachmittag ist die Sonne warm und das Wasser des Flusses ist ruhig und klar.
+		Die Leute kommen aus der Stadt um im Wald zu wandern und die Berge anzusehen. Sie sagen dass die Luft hier besser ist als irgendwo sonst auf der Welt.
+		Am Abend gehen die Lichter der Häuser eines nach dem anderen an. Der alte Mann geht nach Hause und kocht sich eine Tasse Tee.
+		Er liest ein Buch bis es spät ist und dann geht er schlafen. Morgen ist ein neuer Tag und er wird wieder am Fluss entlang gehen.
+		Wer gut tippen will lässt die Finger auf der Grundreihe und schaut auf den Bildschirm statt auf die Tastatur. Übe jeden Tag und du wirst schneller.`,
+	"fr": `Le renard brun rapide saute par dessus le chien paresseux. Chaque matin le vieil homme marche le long de la rivière jusqu'au petit marché du village.
+		Il achète du pain frais et un peu de fromage puis il s'assoit sur un banc près du pont. Les enfants du village passent devant lui en allant à l'école.
+		Certains lui font signe et il leur répond toujours. L'après midi le soleil est chaud et l'eau de la rivière est calme et claire.
+		Les gens viennent de la ville pour marcher dans la forêt et pour regarder les montagnes. Ils disent que l'air est meilleur ici que partout ailleurs dans le monde.
+		Le soir les lumières des maisons s'allument les unes après les autres. Le vieil homme rentre chez lui et se prépare une tasse de thé.
+		Il lit un livre jusqu'à ce qu'il soit tard puis il va dormir. Demain sera un autre jour et il marchera de nouveau le long de la rivière.
+		Une bonne dactylo garde les doigts sur la rangée de repos et regarde l'écran au lieu du clavier. Pratique chaque jour et ta vitesse augmentera.`,
+}
+
+// GetCorpus returns the sample text of the language or an empty string if the language is not supported
+func GetCorpus(language string) string {
+	return corpora[language]
+}
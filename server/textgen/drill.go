@@ -0,0 +1,75 @@
+package textgen
+
+import (
+	"math/rand"
+	"strings"
+	"unicode"
+)
+
+// targetKeyWeight is the additional weight a word gets for every occurrence of a target key
+const targetKeyWeight = 4
+
+// GenerateDrill returns a text of wordsNumber words of the language in which words that contain the target keys are over-represented.
+// Without punctuation all words are lowercase; with punctuation the words are grouped into sentences that start with a capital letter
+// and the words that are always capitalized, e.g. German nouns, are capitalized. The same rng state always produces the same drill.
+func GenerateDrill(rng *rand.Rand, language string, targetKeys []string, punctuation bool, wordsNumber int) string {
+	words := GetWords(language)
+	if len(words) == 0 || wordsNumber <= 0 {
+		return ""
+	}
+
+	weights := make([]int, len(words))
+	totalWeight := 0
+	for i, word := range words {
+		weights[i] = 1
+		for _, targetKey := range targetKeys {
+			weights[i] += targetKeyWeight * strings.Count(word, strings.ToLower(targetKey))
+		}
+		totalWeight += weights[i]
+	}
+
+	drillWords := make([]string, 0, wordsNumber)
+	for len(drillWords) < wordsNumber {
+		n := rng.Intn(totalWeight)
+		for i, weight := range weights {
+			if n < weight {
+				drillWords = append(drillWords, words[i])
+				break
+			}
+			n -= weight
+		}
+	}
+
+	if punctuation {
+		capitalizeWords(language, drillWords)
+		punctuate(rng, drillWords)
+	}
+
+	return strings.Join(drillWords, " ")
+}
+
+// punctuate groups the words into sentences of 5 to 12 words, which start with a capital letter and end with a full stop.
+// Some sentences get a comma in the middle.
+func punctuate(rng *rand.Rand, words []string) {
+	for start := 0; start < len(words); {
+		end := start + 5 + rng.Intn(8)
+		if end > len(words) || len(words)-end < 5 {
+			end = len(words)
+		}
+
+		words[start] = capitalize(words[start])
+		if end-start >= 8 && rng.Intn(2) == 0 {
+			words[start+(end-start)/2] += ","
+		}
+		words[end-1] += "."
+
+		start = end
+	}
+}
+
+func capitalize(word string) string {
+	runes := []rune(word)
+	runes[0] = unicode.ToUpper(runes[0])
+
+	return string(runes)
+}
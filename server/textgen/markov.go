@@ -0,0 +1,119 @@
+package textgen
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"unicode"
+)
+
+// chainMixRate is the probability that the next word is drawn from the word frequency list instead of the markov chain,
+// which keeps the generated texts from repeating the corpus
+const chainMixRate = 0.25
+
+// chain is a first order markov chain of words. Every successor appears in the list of a word as often as it follows the word in the corpus.
+type chain struct {
+	successors map[string][]string
+	starts     []string
+}
+
+var chains = func() map[string]*chain {
+	chains := make(map[string]*chain, len(corpora))
+	for language, corpus := range corpora {
+		chains[language] = newChain(corpus)
+	}
+
+	return chains
+}()
+
+func newChain(corpus string) *chain {
+	c := &chain{successors: make(map[string][]string)}
+
+	for _, sentence := range strings.FieldsFunc(corpus, func(r rune) bool { return r == '.' }) {
+		words := splitWords(strings.ToLower(sentence))
+		if len(words) == 0 {
+			continue
+		}
+
+		c.starts = append(c.starts, words[0])
+		for i := 0; i < len(words)-1; i++ {
+			c.successors[words[i]] = append(c.successors[words[i]], words[i+1])
+		}
+	}
+
+	return c
+}
+
+// splitWords returns the words of the text. Apostrophes within words belong to the word, so elisions like "l'eau" stay one word.
+func splitWords(text string) []string {
+	fields := strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) && r != '\'' })
+
+	words := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if word := strings.Trim(field, "'"); word != "" {
+			words = append(words, word)
+		}
+	}
+
+	return words
+}
+
+// generateWords returns wordsNumber lower case words that follow the markov chain of the language,
+// mixed with words drawn from the word frequency list of the language
+func generateWords(rng *rand.Rand, language string, wordsNumber int) []string {
+	c, ok := chains[language]
+	words := GetWords(language)
+	if !ok || len(words) == 0 {
+		return nil
+	}
+
+	frequencyWeights := getFrequencyWeights(len(words))
+	generatedWords := make([]string, 0, wordsNumber)
+	previousWord := ""
+
+	for len(generatedWords) < wordsNumber {
+		var word string
+		successors := c.successors[previousWord]
+
+		switch {
+		case previousWord == "":
+			word = c.starts[rng.Intn(len(c.starts))]
+		case len(successors) == 0 || rng.Float64() < chainMixRate:
+			word = words[pickWeighted(rng, frequencyWeights)]
+		default:
+			word = successors[rng.Intn(len(successors))]
+		}
+
+		generatedWords = append(generatedWords, word)
+		previousWord = word
+	}
+
+	return generatedWords
+}
+
+// getFrequencyWeights returns weights for a word list that is sorted by frequency, following Zipf's law
+func getFrequencyWeights(wordsNumber int) []float64 {
+	weights := make([]float64, wordsNumber)
+	for rank := range weights {
+		weights[rank] = 1 / math.Pow(float64(rank+1), 0.8)
+	}
+
+	return weights
+}
+
+func pickWeighted(rng *rand.Rand, weights []float64) int {
+	var totalWeight float64
+	for _, weight := range weights {
+		totalWeight += weight
+	}
+
+	n := rng.Float64() * totalWeight
+	for i, weight := range weights {
+		if n < weight {
+			return i
+		}
+		n -= weight
+	}
+
+	return len(weights) - 1
+}
@@ -0,0 +1,101 @@
+package textgen
+
+import (
+	"strings"
+	"unicode"
+)
+
+// typographicReplacer replaces typographic characters, which can't be typed on most keyboards, by their ASCII counterparts
+var typographicReplacer = strings.NewReplacer(
+	"‘", "'", // left single quotation mark
+	"’", "'", // right single quotation mark
+	"‚", "'", // single low-9 quotation mark
+	"‛", "'", // single high-reversed-9 quotation mark
+	"′", "'", // prime
+	"“", `"`, // left double quotation mark
+	"”", `"`, // right double quotation mark
+	"„", `"`, // double low-9 quotation mark
+	"‟", `"`, // double high-reversed-9 quotation mark
+	"″", `"`, // double prime
+	"«", `"`, // left-pointing double angle quotation mark
+	"»", `"`, // right-pointing double angle quotation mark
+	"–", "-", // en dash
+	"—", "-", // em dash
+	"−", "-", // minus sign
+	"…", "...", // horizontal ellipsis
+)
+
+// lineBreakReplacer replaces Windows and old Mac line breaks by \n
+var lineBreakReplacer = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// NormalizeText replaces typographic quotes, dashes and ellipses by their ASCII counterparts and removes invisible control characters.
+// Line breaks and the indentation of the lines are kept, so that code snippets can be typed as they are written,
+// but other whitespace is collapsed to single spaces, trailing whitespace is removed and blank lines are collapsed to a single blank line.
+func NormalizeText(text string) string {
+	text = typographicReplacer.Replace(text)
+	text = lineBreakReplacer.Replace(text)
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = normalizeLine(line)
+		if line == "" && (len(lines) == 0 || lines[len(lines)-1] == "") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// NormalizeLine normalizes the text like NormalizeText, but collapses all whitespace including line breaks to single spaces, e.g. for titles
+func NormalizeLine(text string) string {
+	text = typographicReplacer.Replace(text)
+
+	return strings.Join(strings.Fields(removeControlCharacters(text)), " ")
+}
+
+// normalizeLine keeps the indentation of the line, with tabs replaced by four spaces, and collapses the other whitespace of the line
+func normalizeLine(line string) string {
+	line = removeControlCharacters(line)
+	content := strings.TrimLeftFunc(line, unicode.IsSpace)
+
+	var indentation strings.Builder
+	for _, r := range line[:len(line)-len(content)] {
+		if r == '\t' {
+			indentation.WriteString("    ")
+		} else {
+			indentation.WriteByte(' ')
+		}
+	}
+
+	content = strings.Join(strings.Fields(content), " ")
+	if content == "" {
+		return ""
+	}
+
+	return indentation.String() + content
+}
+
+// removeControlCharacters replaces all whitespace by spaces and removes invisible control characters
+func removeControlCharacters(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t':
+			return r
+		case unicode.IsSpace(r):
+			return ' '
+		case unicode.IsControl(r) || unicode.Is(unicode.Cf, r):
+			return -1
+		default:
+			return r
+		}
+	}, text)
+}
+
+// HasPunctuation reports whether the text has to be typed with punctuation, i.e. whether it contains sentence punctuation or upper case letters
+func HasPunctuation(text string) bool {
+	return strings.ContainsAny(text, SentencePunctuation) || strings.IndexFunc(text, unicode.IsUpper) >= 0
+}
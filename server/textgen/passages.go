@@ -0,0 +1,47 @@
+package textgen
+
+import "strings"
+
+// SplitPassages normalizes the document and splits it into passages of about targetWords words.
+// Passages end at the end of a sentence, which is only broken up if the sentence alone is longer than twice targetWords.
+// A short rest at the end of the document is appended to the previous passage.
+func SplitPassages(document string, targetWords int) []string {
+	words := strings.Fields(NormalizeText(document))
+	if len(words) == 0 || targetWords <= 0 {
+		return nil
+	}
+
+	var passages [][]string
+	var passage []string
+	for i, word := range words {
+		passage = append(passage, word)
+
+		endsSentence := i == len(words)-1 || endsWithSentenceEnd(word)
+		if (len(passage) >= targetWords && endsSentence) || len(passage) >= 2*targetWords {
+			passages = append(passages, passage)
+			passage = nil
+		}
+	}
+
+	if len(passage) > 0 {
+		if len(passage) < targetWords/2 && len(passages) > 0 {
+			passages[len(passages)-1] = append(passages[len(passages)-1], passage...)
+		} else {
+			passages = append(passages, passage)
+		}
+	}
+
+	result := make([]string, 0, len(passages))
+	for _, passage := range passages {
+		result = append(result, strings.Join(passage, " "))
+	}
+
+	return result
+}
+
+// endsWithSentenceEnd reports whether the word ends with a period, exclamation mark or question mark, which may be followed by closing quotes or brackets
+func endsWithSentenceEnd(word string) bool {
+	word = strings.TrimRight(word, `"')]`)
+
+	return strings.HasSuffix(word, ".") || strings.HasSuffix(word, "!") || strings.HasSuffix(word, "?")
+}
@@ -0,0 +1,89 @@
+package textgen
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// SpecialCharacters holds the characters that count as special characters of a text.
+// The sentence punctuation that is added to texts with punctuation (. , ? !) does not count as special characters.
+const SpecialCharacters = "@#$%&*+=/()[]{}<>~|_"
+
+const maxNumber = 10000
+
+// GenerateTypingText returns a text of wordsNumber words of the language that additionally contains
+// exactly specialCharacters special characters and exactly numbers numbers, which are sequences of digits separated by spaces.
+// Without punctuation all words are lowercase; with punctuation the words are grouped into sentences that start with a capital letter
+// and the words that are always capitalized, e.g. German nouns, are capitalized. The same rng state always produces the same text.
+func GenerateTypingText(rng *rand.Rand, language string, punctuation bool, specialCharacters, numbers, wordsNumber int) (string, error) {
+	words := generateWords(rng, language, wordsNumber)
+	if words == nil {
+		return "", fmt.Errorf("language %s is not supported", language)
+	}
+	if specialCharacters < 0 || numbers < 0 {
+		return "", fmt.Errorf("the number of special characters and numbers must not be negative")
+	}
+
+	if punctuation {
+		capitalizeWords(language, words)
+		punctuate(rng, words)
+	}
+
+	tokens := words
+	for i := 0; i < numbers; i++ {
+		tokens = insertAtRandomPosition(rng, tokens, strconv.Itoa(rng.Intn(maxNumber)))
+	}
+	for i := 0; i < specialCharacters; i++ {
+		specialCharacter := string(SpecialCharacters[rng.Intn(len(SpecialCharacters))])
+		tokens = insertAtRandomPosition(rng, tokens, specialCharacter)
+	}
+
+	text := strings.Join(tokens, " ")
+
+	if err := ValidateTypingText(text, punctuation, specialCharacters, numbers); err != nil {
+		return "", err
+	}
+
+	return text, nil
+}
+
+// CountSpecialCharacters returns the number of characters of the text that are in SpecialCharacters
+func CountSpecialCharacters(text string) int {
+	count := 0
+	for _, char := range text {
+		if strings.ContainsRune(SpecialCharacters, char) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// CountNumbers returns the number of digit sequences in the text
+func CountNumbers(text string) int {
+	count := 0
+	inNumber := false
+	for _, char := range text {
+		isDigit := unicode.IsDigit(char)
+		if isDigit && !inNumber {
+			count++
+		}
+		inNumber = isDigit
+	}
+
+	return count
+}
+
+// insertAtRandomPosition inserts the token at a random position. Tokens are joined with spaces, so numbers always stay separate.
+func insertAtRandomPosition(rng *rand.Rand, tokens []string, token string) []string {
+	i := rng.Intn(len(tokens) + 1)
+
+	tokens = append(tokens, "")
+	copy(tokens[i+1:], tokens[i:])
+	tokens[i] = token
+
+	return tokens
+}
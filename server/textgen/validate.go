@@ -0,0 +1,49 @@
+package textgen
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// SentencePunctuation holds the characters that end or structure sentences in texts with punctuation
+const SentencePunctuation = ".,!?;:"
+
+// ValidateTypingText returns an error if the text does not satisfy the constraints it has been generated for:
+// the punctuation constraints of ValidatePunctuation and exactly the given number of special characters and numbers.
+func ValidateTypingText(text string, punctuation bool, specialCharacters, numbers int) error {
+	if err := ValidatePunctuation(text, punctuation); err != nil {
+		return err
+	}
+
+	if gotSpecialCharacters := CountSpecialCharacters(text); gotSpecialCharacters != specialCharacters {
+		return fmt.Errorf("text contains %d special characters instead of %d", gotSpecialCharacters, specialCharacters)
+	}
+	if gotNumbers := CountNumbers(text); gotNumbers != numbers {
+		return fmt.Errorf("text contains %d numbers instead of %d", gotNumbers, numbers)
+	}
+
+	return nil
+}
+
+// ValidatePunctuation returns an error if the text is empty, if a text without punctuation contains upper case letters or sentence punctuation
+// or if a text with punctuation contains no sentence punctuation.
+func ValidatePunctuation(text string, punctuation bool) error {
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("text is empty")
+	}
+
+	hasSentencePunctuation := strings.ContainsAny(text, SentencePunctuation)
+	hasUpperCaseLetters := strings.IndexFunc(text, unicode.IsUpper) >= 0
+
+	switch {
+	case !punctuation && hasSentencePunctuation:
+		return fmt.Errorf("text without punctuation contains sentence punctuation")
+	case !punctuation && hasUpperCaseLetters:
+		return fmt.Errorf("text without punctuation contains upper case letters")
+	case punctuation && !hasSentencePunctuation:
+		return fmt.Errorf("text with punctuation contains no sentence punctuation")
+	}
+
+	return nil
+}
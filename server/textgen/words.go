@@ -0,0 +1,113 @@
+package textgen
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// common words of the supported languages that are used to build drills.
+// Words that are always capitalized, e.g. German nouns, are written capitalized.
+var wordLists = map[string][]string{
+	"en": strings.Fields(`
+		the be to of and a in that have I it for not on with he as you do at this but his by from they we say her she or
+		an will my one all would there their what so up out if about who get which go me when make can like time no just
+		him know take people into year your good some could them see other than then now look only come its over think
+		also back after use two how our work first well way even new want because any these give day most us is was are
+		been has had were said did made find where long down may part place world much great small large hand high right
+		old big still every own point move house school water room mother father night life quick brown fox jumps lazy
+		dog zebra quiet jacket box voice major object subject judge value vivid puzzle frozen jazzy quartz fjord wax kick
+		yellow yesterday joke jungle query equal quote exact oxygen text mix next fix six seven zone size lazy crazy prize
+		keyboard practice finger typing speed accuracy letter word line space shift enter number symbol example simple
+		garden window winter summer spring autumn river mountain forest ocean island village market bridge street
+		friend family question answer problem system program public change follow around between always never often`),
+	"de": strings.Fields(`
+		der die das und in zu den von mit sich des auf für ist im dem nicht ein eine als auch es an er hat aus bei sie
+		nach wird um am sind noch wie einem über einen so zum war haben nur oder aber vor zur bis mehr durch man sein
+		wurde wenn können ihre ich wir Jahr Jahre zwei neue kann schon dann seine gegen werden unter immer viel sehr
+		heute hier Zeit Mann Frau Kind Kinder Haus Stadt Land Welt leben Arbeit Schule Wasser Straße weg Tag Nacht
+		Woche Monat morgen Abend Freund Familie Frage Antwort Problem Beispiel System Sprache Tastatur Finger Übung
+		schnell genau Buchstabe Wort Zeile Zahl Zeichen Quelle Qualität quer Jacke Jagd jung jetzt ja Joghurt
+		zwölf Zeitung Zucker Zug Ziel Xylophon Taxi Hexe boxen Text Mix Pyramide Typ Yacht Handy Hobby Baby
+		grün groß klein alt neu gut schön schwer leicht warm kalt hell dunkel früh spät laut leise ruhig
+		fahren gehen kommen sehen hören sprechen schreiben lesen lernen spielen arbeiten denken wissen finden`),
+	"fr": strings.Fields(`
+		le de un être et à il avoir ne je son que se qui ce dans en du elle au pour pas vous par sur faire plus dire me
+		on mon lui nous comme mais pouvoir avec tout y aller voir bien où sans tu ou leur homme si deux mari moi vouloir
+		te femme venir quand grand celui notre devoir là jour prendre même votre rien petit encore aussi quelque dont
+		tout mer trouver donner temps ça peu même falloir sous parler alors main chose ton mettre vie savoir yeux passer
+		autre après regarder toujours puis avant monde jeune temps enfant maison ville pays eau nuit matin soir ami
+		famille question réponse problème exemple système langue clavier doigt exercice rapide précis lettre mot ligne
+		nombre signe quatre quinze quai quartier qualité jardin jaune jouer juste jamais zéro zone zèbre douze onze
+		taxi texte exact luxe xylophone yaourt yoga crayon voyage noyau fête être forêt âge château hôtel côté goût
+		où déjà très après français garçon leçon ça reçu élève été idée café bébé école étoile écrire lire apprendre`),
+}
+
+// lowerCaseWordLists holds the word lists in lower case, which are used for the texts without punctuation
+var lowerCaseWordLists = func() map[string][]string {
+	lowerCaseWordLists := make(map[string][]string, len(wordLists))
+	for language, words := range wordLists {
+		for _, word := range words {
+			lowerCaseWordLists[language] = append(lowerCaseWordLists[language], strings.ToLower(word))
+		}
+	}
+
+	return lowerCaseWordLists
+}()
+
+// capitalizedWords maps the lower case words of each language that are always capitalized to their capitalized form.
+// These are the capitalized words of the word list and the words that are only capitalized in the middle of the sentences of the corpus, e.g. German nouns.
+var capitalizedWords = func() map[string]map[string]string {
+	capitalizedWords := make(map[string]map[string]string, len(wordLists))
+	for language, words := range wordLists {
+		capitalizedWords[language] = make(map[string]string)
+		for _, word := range words {
+			if isCapitalized(word) {
+				capitalizedWords[language][strings.ToLower(word)] = word
+			}
+		}
+	}
+
+	for language, corpus := range corpora {
+		lowerCaseWords := make(map[string]bool)
+		corpusCapitalizedWords := make(map[string]string)
+		for _, sentence := range strings.FieldsFunc(corpus, func(r rune) bool { return r == '.' }) {
+			words := splitWords(sentence)
+			// the first word of a sentence is capitalized anyway
+			for i := 1; i < len(words); i++ {
+				if isCapitalized(words[i]) {
+					corpusCapitalizedWords[strings.ToLower(words[i])] = words[i]
+				} else {
+					lowerCaseWords[words[i]] = true
+				}
+			}
+		}
+
+		for lowerCaseWord, word := range corpusCapitalizedWords {
+			if !lowerCaseWords[lowerCaseWord] {
+				capitalizedWords[language][lowerCaseWord] = word
+			}
+		}
+	}
+
+	return capitalizedWords
+}()
+
+// GetWords returns the lower case word list of the language or nil if the language is not supported
+func GetWords(language string) []string {
+	return lowerCaseWordLists[language]
+}
+
+// capitalizeWords capitalizes the words of the language that are always capitalized, e.g. the nouns of German texts with punctuation
+func capitalizeWords(language string, words []string) {
+	for i, word := range words {
+		if capitalizedWord, ok := capitalizedWords[language][word]; ok {
+			words[i] = capitalizedWord
+		}
+	}
+}
+
+func isCapitalized(word string) bool {
+	r, _ := utf8.DecodeRuneInString(word)
+	return unicode.IsUpper(r)
+}
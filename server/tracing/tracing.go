@@ -0,0 +1,67 @@
+// Package tracing sets up the OpenTelemetry tracer provider, which exports the spans of the requests, the service operations, the games and the calls to redis and postgres.
+// The trace context is propagated with the W3C traceparent header. Until Init has been called, the global tracer provider records no spans.
+package tracing
+
+import (
+	"10-typing/errors"
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName names the service of the spans unless OTEL_SERVICE_NAME is set
+const ServiceName = "10-typing"
+
+// tracer records the spans of the operations of the services, which use the global tracer provider once Init has been called
+var tracer = otel.Tracer(ServiceName + "/services")
+
+// Start starts a span named after the operation as a child of the span of the context and returns a copy of the context that holds the new span.
+// The span must be ended with End.
+func Start(ctx context.Context, op errors.Op, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return tracer.Start(ctx, string(op), opts...)
+}
+
+// Init sets the global tracer provider, which exports the spans in batches with the exporter, and the propagator of the trace context.
+// The service is described by OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES. The returned shutdown function exports the remaining spans.
+func Init(ctx context.Context, exporter sdktrace.SpanExporter, onError func(err error)) (shutdown func(ctx context.Context) error, err error) {
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(ServiceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(rootSampler{})),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(onError))
+
+	return provider.Shutdown, nil
+}
+
+// rootSampler samples all traces except for the ones that start with a call to another system,
+// e.g. the statements that load the bigram frequencies on start up, which would each start a trace of their own
+type rootSampler struct{}
+
+func (rootSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	if p.Kind == trace.SpanKindClient {
+		return sdktrace.SamplingResult{Decision: sdktrace.Drop}
+	}
+
+	return sdktrace.AlwaysSample().ShouldSample(p)
+}
+
+func (rootSampler) Description() string {
+	return "RootSampler{without client spans}"
+}
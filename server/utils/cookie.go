@@ -6,11 +6,13 @@ import (
 	"net/http"
 )
 
-func NewCookie(name, value string) *http.Cookie {
+func NewCookie(name, value string, config models.CookieConfig) *http.Cookie {
 	cookie := http.Cookie{
 		Name:     name,
 		Value:    value,
 		HttpOnly: true,
+		Secure:   config.Secure,
+		SameSite: config.SameSite,
 		MaxAge:   models.SessionDurationSec,
 		Path:     "/",
 	}
@@ -18,8 +20,8 @@ func NewCookie(name, value string) *http.Cookie {
 	return &cookie
 }
 
-func SetCookie(w http.ResponseWriter, name, value string) {
-	cookie := NewCookie(name, value)
+func SetCookie(w http.ResponseWriter, name, value string, config models.CookieConfig) {
+	cookie := NewCookie(name, value, config)
 	http.SetCookie(w, cookie)
 }
 
@@ -34,8 +36,8 @@ func ReadCookie(r *http.Request, name string) (string, error) {
 	return cookie.Value, nil
 }
 
-func DeleteCookie(w http.ResponseWriter, name string) {
-	cookie := NewCookie(name, "")
+func DeleteCookie(w http.ResponseWriter, name string, config models.CookieConfig) {
+	cookie := NewCookie(name, "", config)
 	cookie.MaxAge = -1
 	http.SetCookie(w, cookie)
 }
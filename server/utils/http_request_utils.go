@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"10-typing/common"
 	"10-typing/errors"
 	"10-typing/models"
 	"fmt"
@@ -54,3 +55,9 @@ func GetUserFromContext(c *gin.Context) (user *models.User, err error) {
 
 	return user, nil
 }
+
+// AddLogFields adds the fields to the log fields of the request context, so that they are written with every log line of the request
+func AddLogFields(c *gin.Context, fields common.LogFields) {
+	ctx := common.ContextWithLogFields(c.Request.Context(), fields)
+	c.Request = c.Request.WithContext(ctx)
+}
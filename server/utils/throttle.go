@@ -1,18 +1,26 @@
 package utils
 
-import "time"
+import (
+	"10-typing/common"
+	"time"
+)
 
-func Throttle(interval time.Duration) (execute func(execFunc func()), cleanup func()) {
+// Throttle returns a function that executes at most one of the functions that are passed to it per interval.
+// Only the latest function that has been passed during an interval is executed at its end.
+func Throttle(clock common.Clock, interval time.Duration) (execute func(execFunc func()), cleanup func()) {
 	executeCh := make(chan func(), 1)
-	ticker := time.NewTicker(interval)
+	ticker := clock.NewTicker(interval)
 
 	go func() {
 		var nextFunc func()
 		for {
 			select {
-			case f := <-executeCh:
+			case f, ok := <-executeCh:
+				if !ok {
+					return
+				}
 				nextFunc = f
-			case <-ticker.C:
+			case <-ticker.C():
 				if nextFunc != nil {
 					nextFunc()
 					nextFunc = nil
@@ -1,7 +1,9 @@
 package utils
 
 import (
+	"10-typing/common"
 	"10-typing/errors"
+	"context"
 	"strconv"
 	"time"
 )
@@ -16,3 +18,18 @@ func StringToTime(data string) (time.Time, error) {
 
 	return time.Unix(intVal/1000, (intVal%1000)*1e6), nil
 }
+
+// Sleep blocks for the duration of the clock and returns early with the error of the context if the context is done before
+func Sleep(ctx context.Context, clock common.Clock, d time.Duration) error {
+	const op errors.Op = "utils.Sleep"
+
+	timer := clock.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		return errors.E(op, ctx.Err())
+	}
+}
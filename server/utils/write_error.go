@@ -3,20 +3,30 @@ package utils
 import (
 	"10-typing/common"
 	"10-typing/errors"
-	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// WriteError writes the user facing message of the error with its status.
+// Errors with a server error status are logged as errors and all other errors as info.
+// The error is recorded as an event of the span of the request, so that the cause of a failed request can be found in its trace.
 func WriteError(c *gin.Context, err error, logger common.Logger) {
+	logger = logger.WithContext(c.Request.Context())
+	trace.SpanFromContext(c.Request.Context()).RecordError(err)
+
 	if cr, ok := err.(interface {
 		Message() errors.Messages
 		Status() int
 	}); ok {
 		status := cr.Status()
 		message := cr.Message()
-		log.Print(err)
+		if status >= http.StatusInternalServerError {
+			logger.Error(err)
+		} else {
+			logger.Info(err)
+		}
 
 		c.JSON(status, message)
 		return
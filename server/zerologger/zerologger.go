@@ -2,6 +2,8 @@ package zerologger
 
 import (
 	"10-typing/common"
+	"10-typing/errors"
+	"context"
 	"fmt"
 	"io"
 	"time"
@@ -19,12 +21,16 @@ func New(writer io.Writer) common.Logger {
 	return &Zerologger{logger}
 }
 
+func (zl *Zerologger) Debug(v ...any) {
+	write(zl.logger.Debug(), v)
+}
+
 func (zl *Zerologger) Info(v ...any) {
-	zl.logger.Info().Msg(fmt.Sprint(v...))
+	write(zl.logger.Info(), v)
 }
 
 func (zl *Zerologger) Error(v ...any) {
-	zl.logger.Error().Msg(fmt.Sprint(v...))
+	write(zl.logger.Error(), v)
 }
 
 func (zl *Zerologger) RequestInfo(method, path, clientIP string, statusCode int, latency time.Duration) {
@@ -36,3 +42,84 @@ func (zl *Zerologger) RequestInfo(method, path, clientIP string, statusCode int,
 		Dur("latency", latency).
 		Msg("")
 }
+
+func (zl *Zerologger) With(fields common.LogFields) common.Logger {
+	if len(fields) == 0 {
+		return zl
+	}
+
+	return &Zerologger{zl.logger.With().Fields(map[string]any(fields)).Logger()}
+}
+
+func (zl *Zerologger) WithContext(ctx context.Context) common.Logger {
+	return zl.With(common.LogFieldsFromContext(ctx))
+}
+
+// SetLevel sets the global level of zerolog, so that the level of all loggers changes
+func (zl *Zerologger) SetLevel(level string) error {
+	const op errors.Op = "zerologger.Zerologger.SetLevel"
+
+	parsedLevel, err := zerolog.ParseLevel(level)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	switch parsedLevel {
+	case zerolog.DebugLevel, zerolog.InfoLevel, zerolog.ErrorLevel:
+	default:
+		err := fmt.Errorf("unsupported log level %q", level)
+		return errors.E(op, err)
+	}
+
+	zerolog.SetGlobalLevel(parsedLevel)
+
+	return nil
+}
+
+func (zl *Zerologger) Level() string {
+	return zerolog.GlobalLevel().String()
+}
+
+// write writes a single error as fields and the message of its cause and all other values as message
+func write(event *zerolog.Event, v []any) {
+	if len(v) != 1 {
+		event.Msg(fmt.Sprint(v...))
+		return
+	}
+
+	err, ok := v[0].(error)
+	if !ok {
+		event.Msg(fmt.Sprint(v...))
+		return
+	}
+
+	var e *errors.Error
+	if !errors.As(err, &e) {
+		event.Msg(err.Error())
+		return
+	}
+
+	ops := errors.Ops(err)
+	opStrs := make([]string, 0, len(ops))
+	for _, op := range ops {
+		opStrs = append(opStrs, string(op))
+	}
+
+	event.Str(common.OpLogField, opStrs[0]).
+		Strs("ops", opStrs).
+		Int("status", e.Status())
+
+	if username := errors.Username(err); username != "" {
+		event.Str("username", username)
+	}
+	if where := errors.Where(err); where != "" {
+		event.Str("where", where)
+	}
+
+	msg := ""
+	if cause := errors.Cause(err); cause != nil {
+		msg = cause.Error()
+	}
+
+	event.Msg(msg)
+}